@@ -102,3 +102,13 @@ type DetailedCondition struct {
 	// +optional
 	Warnings []SubCondition `json:"warnings,omitempty"`
 }
+
+const (
+	// ValidConditionType is the type of the condition that reports
+	// whether Contour accepted an HTTPProxy.
+	ValidConditionType string = "Valid"
+
+	// ConditionTypeCORSError is the type of the error subcondition
+	// that reports an invalid CORS policy.
+	ConditionTypeCORSError string = "CORSError"
+)
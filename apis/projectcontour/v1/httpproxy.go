@@ -30,10 +30,41 @@ type HTTPProxySpec struct {
 	// TCPProxy holds TCP proxy information.
 	// +optional
 	TCPProxy *TCPProxy `json:"tcpproxy,omitempty"`
+	// UDPProxy holds UDP proxy information. It requires a Listener.
+	// +optional
+	UDPProxy *UDPProxy `json:"udpproxy,omitempty"`
+	// Listener binds the TCPProxy or UDPProxy to a dedicated Envoy port
+	// instead of the HTTPS listener. A proxy with a Listener is a root
+	// HTTPProxy that has no VirtualHost, Routes or Includes.
+	// +optional
+	Listener *Listener `json:"listener,omitempty"`
 	// Includes allow for specific routing configuration to be included from another HTTPProxy,
 	// possibly in another namespace.
 	// +optional
 	Includes []Include `json:"includes,omitempty"`
+	// TracingPolicy overrides the global tracing configuration
+	// for the routes of this HTTPProxy, and of the HTTPProxies it
+	// includes that don't set their own policy.
+	// +optional
+	TracingPolicy *TracingPolicy `json:"tracingPolicy,omitempty"`
+}
+
+// Listener is a dedicated Envoy port that proxies plain TCP or UDP traffic.
+type Listener struct {
+	// Port is the port that Envoy listens on. It must be
+	// within the port range permitted by the Contour configuration.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int `json:"port"`
+}
+
+// TracingPolicy overrides the global tracing configuration.
+type TracingPolicy struct {
+	// SamplingRate is the percentage of requests that are traced,
+	// between 0 and 100. For example, "100" traces every request
+	// and "0.5" traces one request in two hundred.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	SamplingRate string `json:"samplingRate"`
 }
 
 // Include describes a set of policies that can be applied to an HTTPProxy in a namespace.
@@ -49,21 +80,39 @@ type Include struct {
 	// When applied, they are merged using AND, with one exception:
 	// There can be only one Prefix MatchCondition per Conditions slice.
 	// More than one Prefix, or contradictory Conditions, will make the
-	// include invalid.
+	// include invalid. Exact and Regex MatchConditions are not allowed
+	// on includes.
 	// +optional
 	Conditions []MatchCondition `json:"conditions,omitempty"`
 }
 
 // MatchCondition are a general holder for matching rules for HTTPProxies.
-// One of Prefix or Header must be provided.
+// One of Prefix, Exact, Regex, Header or QueryParameter must be provided.
 type MatchCondition struct {
 	// Prefix defines a prefix match for a request.
 	// +optional
 	Prefix string `json:"prefix,omitempty"`
 
+	// Exact defines an exact match for the request path.
+	// When included, the prefixes of the including HTTPProxies
+	// are prepended to the path.
+	// +optional
+	Exact string `json:"exact,omitempty"`
+
+	// Regex defines a RE2 regular expression that must match
+	// the whole request path. When included, the prefixes of the
+	// including HTTPProxies are prepended to the expression as
+	// literal text.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+
 	// Header specifies the header condition to match.
 	// +optional
 	Header *HeaderMatchCondition `json:"header,omitempty"`
+
+	// QueryParameter specifies the query parameter condition to match.
+	// +optional
+	QueryParameter *QueryParameterMatchCondition `json:"queryParameter,omitempty"`
 }
 
 // HeaderMatchCondition specifies how to conditionally match against HTTP
@@ -101,19 +150,184 @@ type HeaderMatchCondition struct {
 	NotExact string `json:"notexact,omitempty"`
 }
 
+// QueryParameterMatchCondition specifies how to conditionally match against
+// HTTP query parameters. The Name field is required, but only one of the
+// remaining fields should be provided.
+type QueryParameterMatchCondition struct {
+	// Name is the name of the query parameter to match against. Name is required.
+	// Query parameter names are case sensitive.
+	Name string `json:"name"`
+
+	// Exact specifies a string that the query parameter value must be equal to.
+	// +optional
+	Exact string `json:"exact,omitempty"`
+
+	// Prefix specifies a string that the query parameter value must start with.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Contains specifies a substring that must be present in
+	// the query parameter value.
+	// +optional
+	Contains string `json:"contains,omitempty"`
+
+	// Regex specifies a RE2 regular expression that must match
+	// the whole query parameter value.
+	// +optional
+	Regex string `json:"regex,omitempty"`
+
+	// Present specifies that condition is true when the named query
+	// parameter is present, regardless of its value.
+	// +optional
+	Present bool `json:"present,omitempty"`
+}
+
 // VirtualHost appears at most once. If it is present, the object is considered
 // to be a "root".
 type VirtualHost struct {
 	// The fully qualified domain name of the root of the ingress tree
 	// all leaves of the DAG rooted at this object relate to the fqdn.
+	// The leftmost label may be a wildcard, e.g. "*.example.com",
+	// to match any host in that domain. Exact fqdns take precedence
+	// over wildcards.
 	Fqdn string `json:"fqdn"`
+	// Aliases are additional fully qualified domain names that
+	// are served by this virtual host, with the same routes as the
+	// fqdn. When TLS is enabled, the certificate must be valid for
+	// the fqdn and each alias.
+	// +optional
+	Aliases []string `json:"aliases,omitempty"`
 	// If present describes tls properties. The SNI names that will be matched on
 	// are described in fqdn, the tls.secretName secret must contain a
 	// certificate that itself contains a name that matches the FQDN.
 	// +optional
 	TLS *TLS `json:"tls,omitempty"`
+	// This field configures an extension service to perform
+	// authorization for this virtual host. Authorization can
+	// only be configured on virtual hosts that have TLS enabled.
+	// If the TLS configuration requires client certificate
+	// validation, the client certificate is always included in the
+	// authentication check request.
+	//
+	// +optional
+	Authorization *AuthorizationServer `json:"authorization,omitempty"`
+	// The policy for rate limiting on the virtual host.
+	// +optional
+	RateLimitPolicy *RateLimitPolicy `json:"rateLimitPolicy,omitempty"`
+	// Specifies the cross-origin policy to apply to the VirtualHost.
+	// +optional
+	CORSPolicy *CORSPolicy `json:"corsPolicy,omitempty"`
+}
+
+// ExtensionServiceReference names an ExtensionService resource.
+type ExtensionServiceReference struct {
+	// API version of the referent.
+	// If this field is not specified, the default "projectcontour.io/v1alpha1" will be used
+	//
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Namespace of the referent.
+	// If this field is not specifies, the namespace of the resource that targets the referent will be used.
+	//
+	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/namespaces/
+	//
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the referent.
+	//
+	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names
+	//
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name,omitempty"`
+}
+
+// AuthorizationServer configures an external server to authenticate
+// client requests. The external server must implement the v2 Envoy
+// external authorization GRPC protocol.
+type AuthorizationServer struct {
+	// ExtensionServiceRef specifies the extension resource that will authorize client requests.
+	//
+	// +required
+	ExtensionServiceRef ExtensionServiceReference `json:"extensionRef"`
+
+	// AuthPolicy sets a default authorization policy for client requests.
+	// This policy will be used unless overridden by individual routes.
+	//
+	// +optional
+	AuthPolicy *AuthorizationPolicy `json:"authPolicy,omitempty"`
+
+	// ResponseTimeout configures maximum time to wait for a check response from the authorization server.
+	// Timeout durations are expressed in the Go [Duration format](https://godoc.org/time#ParseDuration).
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// The string "infinity" is also a valid input and specifies no timeout.
+	//
+	// +optional
+	ResponseTimeout string `json:"responseTimeout,omitempty"`
+
+	// If FailOpen is true, the client request is forwarded to the upstream service
+	// even if the authorization server fails to respond. This field should not be
+	// set in most cases. It is intended for use only while migrating applications
+	// from internal authorization to Contour external authorization.
+	//
+	// +optional
+	FailOpen bool `json:"failOpen,omitempty"`
 }
 
+// AuthorizationPolicy modifies how client requests are authenticated.
+type AuthorizationPolicy struct {
+	// When true, this field disables client request authentication
+	// for the scope of the policy.
+	//
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Context is a set of key/value pairs that are sent to the
+	// authentication server in the check request. If a context
+	// is provided at an enclosing scope, the entries are merged
+	// such that the inner scope overrides matching keys from the
+	// outer scope.
+	//
+	// +optional
+	Context map[string]string `json:"context,omitempty"`
+}
+
+// CORSPolicy allows setting the CORS policy
+type CORSPolicy struct {
+	// Specifies whether the resource allows credentials.
+	// +optional
+	AllowCredentials bool `json:"allowCredentials,omitempty"`
+	// AllowOrigin specifies the origins that will be allowed to do CORS requests.
+	// Each entry is either "*", which allows any origin, an exact origin such as
+	// "https://example.com", or a regular expression that the origin must match.
+	// +kubebuilder:validation:MinItems=1
+	AllowOrigin []string `json:"allowOrigin"`
+	// AllowMethods specifies the content for the *access-control-allow-methods* header.
+	// +kubebuilder:validation:MinItems=1
+	AllowMethods []CORSHeaderValue `json:"allowMethods"`
+	// AllowHeaders specifies the content for the *access-control-allow-headers* header.
+	// +optional
+	AllowHeaders []CORSHeaderValue `json:"allowHeaders,omitempty"`
+	// ExposeHeaders Specifies the content for the *access-control-expose-headers* header.
+	// +optional
+	ExposeHeaders []CORSHeaderValue `json:"exposeHeaders,omitempty"`
+	// MaxAge indicates for how long the results of a preflight request can be cached.
+	// MaxAge durations are expressed in the Go [Duration format](https://godoc.org/time#ParseDuration).
+	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
+	// Only positive values are allowed while 0 disables the cache requiring a preflight OPTIONS
+	// check for all cross-origin requests.
+	// +optional
+	MaxAge string `json:"maxAge,omitempty"`
+}
+
+// CORSHeaderValue specifies the value of the string headers returned by a cross-domain request.
+// +kubebuilder:validation:Pattern="^[a-zA-Z0-9!#$%&'*+.^_`|~-]+$"
+type CORSHeaderValue string
+
 // TLS describes tls properties. The SNI names that will be matched on
 // are described in the HTTPProxy's Spec.VirtualHost.Fqdn field.
 type TLS struct {
@@ -125,6 +339,21 @@ type TLS struct {
 	// Minimum TLS version this vhost should negotiate
 	// +optional
 	MinimumProtocolVersion string `json:"minimumProtocolVersion,omitempty"`
+	// Maximum TLS version this vhost should negotiate. If not
+	// specified, the Contour configuration file value is used.
+	// +optional
+	// +kubebuilder:validation:Enum="1.2";"1.3"
+	MaximumProtocolVersion string `json:"maximumProtocolVersion,omitempty"`
+	// CipherSuites is the list of TLS 1.2 cipher suites this vhost
+	// should negotiate, in order of preference. If not specified,
+	// the Contour configuration file value is used.
+	// +optional
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+	// ECDHCurves is the list of ECDH curves this vhost should
+	// negotiate, in order of preference. If not specified, the
+	// Contour configuration file value is used.
+	// +optional
+	ECDHCurves []string `json:"ecdhCurves,omitempty"`
 	// Passthrough defines whether the encrypted TLS handshake will be
 	// passed through to the backing cluster. Either Passthrough or
 	// SecretName must be specified, but not both.
@@ -141,24 +370,67 @@ type TLS struct {
 	// +optional
 	ClientValidation *DownstreamValidation `json:"clientValidation,omitempty"`
 
+	// ForwardClientCertificate defines how details of the client
+	// certificate are forwarded to backends in the
+	// X-Forwarded-Client-Cert header.
+	// +optional
+	ForwardClientCertificate *ForwardClientCertificate `json:"forwardClientCertificate,omitempty"`
+
 	// EnableFallbackCertificate defines if the vhost should allow a default certificate to
 	// be applied which handles all requests which don't match the SNI defined in this vhost.
 	EnableFallbackCertificate bool `json:"enableFallbackCertificate,omitempty"`
 }
 
+// ForwardClientCertificate defines how the X-Forwarded-Client-Cert
+// (XFCC) header is handled for requests to a virtual host.
+type ForwardClientCertificate struct {
+	// Mode is how the XFCC header of a request is handled.
+	//
+	// - sanitize: the XFCC header is removed. This is the default.
+	// - forward-only: the XFCC header is forwarded unchanged.
+	// - append-forward: the details of the client certificate are
+	//   appended to the XFCC header.
+	// - sanitize-set: the XFCC header is replaced by the details
+	//   of the client certificate.
+	//
+	// Modes other than sanitize require client validation.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=sanitize;forward-only;append-forward;sanitize-set
+	Mode string `json:"mode,omitempty"`
+	// Subject includes the subject of the client certificate.
+	// +optional
+	Subject bool `json:"subject,omitempty"`
+	// Cert includes the PEM encoded client certificate.
+	// +optional
+	Cert bool `json:"cert,omitempty"`
+	// Chain includes the PEM encoded client certificate chain.
+	// +optional
+	Chain bool `json:"chain,omitempty"`
+	// DNS includes the DNS subject alternative names of the
+	// client certificate.
+	// +optional
+	DNS bool `json:"dns,omitempty"`
+	// URI includes the URI subject alternative name of the client
+	// certificate, such as a SPIFFE ID.
+	// +optional
+	URI bool `json:"uri,omitempty"`
+}
+
 // Route contains the set of routes for a virtual host.
 type Route struct {
 	// Conditions are a set of rules that are applied to a Route.
 	// When applied, they are merged using AND, with one exception:
-	// There can be only one Prefix MatchCondition per Conditions slice.
-	// More than one Prefix, or contradictory Conditions, will make the
-	// route invalid.
+	// There can be only one Prefix, Exact or Regex MatchCondition per
+	// Conditions slice. More than one path condition, or contradictory
+	// Conditions, will make the route invalid.
 	// +optional
 	Conditions []MatchCondition `json:"conditions,omitempty"`
 	// Services are the services to proxy traffic.
-	// +kubebuilder:validation:MinItems=1
-	// +kubebuilder:validation:Required
-	Services []Service `json:"services"`
+	// Services must be set unless the route has a
+	// requestRedirectPolicy or a directResponsePolicy.
+	// +optional
+	Services []Service `json:"services,omitempty"`
 	// Enables websocket support for the route.
 	// +optional
 	EnableWebsockets bool `json:"enableWebsockets,omitempty"`
@@ -189,6 +461,26 @@ type Route struct {
 	// The policy for managing response headers during proxying
 	// +optional
 	ResponseHeadersPolicy *HeadersPolicy `json:"responseHeadersPolicy,omitempty"`
+	// AuthPolicy updates the authorization policy that was set
+	// on the root HTTPProxy object for client requests that
+	// match this route.
+	// +optional
+	AuthPolicy *AuthorizationPolicy `json:"authPolicy,omitempty"`
+	// The policy for rate limiting on the route.
+	// +optional
+	RateLimitPolicy *RateLimitPolicy `json:"rateLimitPolicy,omitempty"`
+	// RequestRedirectPolicy redirects matching requests instead
+	// of proxying them to services.
+	// +optional
+	RequestRedirectPolicy *HTTPRequestRedirectPolicy `json:"requestRedirectPolicy,omitempty"`
+	// DirectResponsePolicy returns a fixed response to matching
+	// requests instead of proxying them to services.
+	// +optional
+	DirectResponsePolicy *HTTPDirectResponsePolicy `json:"directResponsePolicy,omitempty"`
+	// FaultInjectionPolicy injects delays and aborts into a
+	// percentage of the requests that match this route.
+	// +optional
+	FaultInjectionPolicy *FaultInjectionPolicy `json:"faultInjectionPolicy,omitempty"`
 }
 
 func (r *Route) GetPrefixReplacements() []ReplacePrefix {
@@ -218,6 +510,29 @@ type TCPProxy struct {
 	// The health check policy for this tcp proxy
 	// +optional
 	HealthCheckPolicy *TCPHealthCheckPolicy `json:"healthCheckPolicy,omitempty"`
+	// The outlier detection policy for the services of this tcp proxy.
+	// A policy set on a service takes precedence over this one.
+	// +optional
+	OutlierDetectionPolicy *OutlierDetectionPolicy `json:"outlierDetectionPolicy,omitempty"`
+}
+
+// UDPProxy contains the service to proxy UDP datagrams to.
+type UDPProxy struct {
+	// The load balancing policy for the backend service.
+	// +optional
+	LoadBalancerPolicy *LoadBalancerPolicy `json:"loadBalancerPolicy,omitempty"`
+	// Service is the service to proxy datagrams to.
+	Service UDPProxyService `json:"service"`
+}
+
+// UDPProxyService is the port of a Kubernetes Service that a UDPProxy forwards datagrams to.
+type UDPProxyService struct {
+	// Name is the name of the Kubernetes Service.
+	Name string `json:"name"`
+	// Port is the port of the Kubernetes Service. It must use the UDP protocol.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int `json:"port"`
 }
 
 // TCPProxyInclude describes a target HTTPProxy document which contains the TCPProxy details.
@@ -254,6 +569,13 @@ type Service struct {
 	// UpstreamValidation defines how to verify the backend service's certificate
 	// +optional
 	UpstreamValidation *UpstreamValidation `json:"validation,omitempty"`
+	// ClientCertificate is the name of a TLS secret that Envoy presents
+	// to the backend service when it requests a client certificate.
+	// The secret may be in another namespace, given as namespace/name,
+	// if a TLSCertificateDelegation permits it. Only applies to services
+	// that use the tls or h2 protocol.
+	// +optional
+	ClientCertificate string `json:"clientCertificate,omitempty"`
 	// If Mirror is true the Service will receive a read only mirror of the traffic for this route.
 	Mirror bool `json:"mirror,omitempty"`
 	// The policy for managing request headers during proxying
@@ -262,6 +584,15 @@ type Service struct {
 	// The policy for managing response headers during proxying
 	// +optional
 	ResponseHeadersPolicy *HeadersPolicy `json:"responseHeadersPolicy,omitempty"`
+	// The policy for ejecting unhealthy endpoints of this service
+	// +optional
+	OutlierDetectionPolicy *OutlierDetectionPolicy `json:"outlierDetectionPolicy,omitempty"`
+	// The circuit breaker thresholds of this service
+	// +optional
+	CircuitBreakerPolicy *CircuitBreakerPolicy `json:"circuitBreakerPolicy,omitempty"`
+	// The policy for reusing connections to this service
+	// +optional
+	ConnectionPoolPolicy *ConnectionPoolPolicy `json:"connectionPoolPolicy,omitempty"`
 }
 
 // HTTPHealthCheckPolicy defines health checks on the upstream service.
@@ -304,6 +635,75 @@ type TCPHealthCheckPolicy struct {
 	HealthyThresholdCount uint32 `json:"healthyThresholdCount"`
 }
 
+// CircuitBreakerPolicy defines the circuit breaker thresholds of the
+// upstream service. Thresholds that are not set keep the value of the
+// matching projectcontour.io/max-* annotation of the Kubernetes Service.
+type CircuitBreakerPolicy struct {
+	// The maximum number of connections that a single Envoy instance
+	// allows to the service.
+	// +optional
+	MaxConnections uint32 `json:"maxConnections,omitempty"`
+	// The maximum number of pending requests that a single Envoy
+	// instance allows to the service.
+	// +optional
+	MaxPendingRequests uint32 `json:"maxPendingRequests,omitempty"`
+	// The maximum number of parallel requests that a single Envoy
+	// instance allows to the service.
+	// +optional
+	MaxRequests uint32 `json:"maxRequests,omitempty"`
+	// The maximum number of parallel retries that a single Envoy
+	// instance allows to the service.
+	// +optional
+	MaxRetries uint32 `json:"maxRetries,omitempty"`
+}
+
+// ConnectionPoolPolicy defines how connections to the upstream
+// service are reused.
+type ConnectionPoolPolicy struct {
+	// The maximum number of requests that are sent on a single
+	// upstream connection. If not set, there is no limit.
+	// +optional
+	MaxRequestsPerConnection uint32 `json:"maxRequestsPerConnection,omitempty"`
+	// The maximum number of concurrent streams on a single HTTP/2
+	// upstream connection. Only applies to services that use the
+	// h2 or h2c protocol.
+	// +optional
+	// +kubebuilder:validation:Maximum=2147483647
+	HTTP2MaxConcurrentStreams uint32 `json:"http2MaxConcurrentStreams,omitempty"`
+}
+
+// OutlierDetectionPolicy defines passive health checking of the
+// upstream service. Endpoints that keep failing requests are
+// ejected from the load balancing pool for a period of time.
+//
+// Durations are expressed in the Go [Duration format](https://godoc.org/time#ParseDuration).
+type OutlierDetectionPolicy struct {
+	// The number of consecutive 5xx responses before an endpoint
+	// is ejected. For TCP proxies, connection failures count as
+	// 5xx responses. Defaults to 5.
+	// +optional
+	Consecutive5xxErrors uint32 `json:"consecutive5xxErrors,omitempty"`
+	// The number of consecutive 502, 503 or 504 responses before
+	// an endpoint is ejected. If not set, gateway errors are only
+	// counted as 5xx responses.
+	// +optional
+	ConsecutiveGatewayErrors uint32 `json:"consecutiveGatewayErrors,omitempty"`
+	// The time between ejection sweeps. Defaults to 10s.
+	// +optional
+	Interval string `json:"interval,omitempty"`
+	// The base time that an endpoint is ejected for. The actual
+	// time is the base time multiplied by the number of times
+	// the endpoint has been ejected. Defaults to 30s.
+	// +optional
+	BaseEjectionTime string `json:"baseEjectionTime,omitempty"`
+	// The maximum percentage of the endpoints of the service that
+	// can be ejected at the same time. Defaults to 10. A value
+	// of 0 disables ejection.
+	// +optional
+	// +kubebuilder:validation:Maximum=100
+	MaxEjectionPercent *uint32 `json:"maxEjectionPercent,omitempty"`
+}
+
 // TimeoutPolicy configures timeouts that are used for handling network requests.
 //
 // TimeoutPolicy durations are expressed in the Go [Duration format](https://godoc.org/time#ParseDuration).
@@ -405,17 +805,227 @@ type PathRewritePolicy struct {
 	// ReplacePrefix describes how the path prefix should be replaced.
 	// +optional
 	ReplacePrefix []ReplacePrefix `json:"replacePrefix,omitempty"`
+
+	// RegexRewrite describes how the path should be rewritten
+	// by a regular expression substitution.
+	// +optional
+	RegexRewrite *RegexRewrite `json:"regexRewrite,omitempty"`
+
+	// ReplaceFullPath replaces the whole path, regardless of
+	// the path that was matched. It must begin with a '/'.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	ReplaceFullPath string `json:"replaceFullPath,omitempty"`
+}
+
+// RegexRewrite describes a regular expression substitution
+// of the URL path.
+type RegexRewrite struct {
+	// Pattern is the RE2 regular expression that is matched
+	// against the path. Every non-overlapping match is replaced
+	// by Substitution.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Pattern string `json:"pattern"`
+
+	// Substitution is the string that matches of Pattern are
+	// replaced with. It may refer to numbered capture groups
+	// of Pattern, such as \1.
+	//
+	// +kubebuilder:validation:Required
+	Substitution string `json:"substitution"`
+}
+
+// HTTPRequestRedirectPolicy defines how a request is redirected. Fields
+// that are not set keep the corresponding value of the original request.
+type HTTPRequestRedirectPolicy struct {
+	// Scheme is the scheme of the redirect location.
+	// +optional
+	// +kubebuilder:validation:Enum=http;https
+	Scheme string `json:"scheme,omitempty"`
+	// Hostname is the hostname of the redirect location.
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
+	// Port is the port of the redirect location.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int `json:"port,omitempty"`
+	// Path replaces the whole path of the request.
+	// Only one of Path or Prefix may be set.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/.*$`
+	Path string `json:"path,omitempty"`
+	// Prefix replaces the matched prefix of the request path.
+	// Only one of Path or Prefix may be set.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/.*$`
+	Prefix string `json:"prefix,omitempty"`
+	// StatusCode is the HTTP status code of the redirect response.
+	// Defaults to 302.
+	// +optional
+	// +kubebuilder:validation:Enum=301;302;307;308
+	StatusCode int `json:"statusCode,omitempty"`
+}
+
+// FaultInjectionPolicy defines the faults that are injected into
+// requests. At least one of Delay or Abort must be specified.
+type FaultInjectionPolicy struct {
+	// Delay injects a fixed delay before requests are forwarded.
+	// +optional
+	Delay *FaultDelay `json:"delay,omitempty"`
+	// Abort responds to requests with an error status instead
+	// of forwarding them.
+	// +optional
+	Abort *FaultAbort `json:"abort,omitempty"`
+	// Headers restricts the faults to requests that match all of
+	// the header conditions, so that only test traffic is affected.
+	// +optional
+	Headers []HeaderMatchCondition `json:"headers,omitempty"`
+}
+
+// FaultDelay defines a fixed delay that is injected into requests.
+type FaultDelay struct {
+	// FixedDelay is how long requests are delayed, as a
+	// Go duration string such as "500ms" or "5s".
+	FixedDelay string `json:"fixedDelay"`
+	// Percentage is the percentage of requests that are delayed,
+	// between 0 and 100. Defaults to 100.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	Percentage string `json:"percentage,omitempty"`
+}
+
+// FaultAbort defines an error status that requests are aborted
+// with. Exactly one of HTTPStatus or GRPCStatus must be specified.
+type FaultAbort struct {
+	// HTTPStatus is the HTTP status code of the response.
+	// +optional
+	// +kubebuilder:validation:Minimum=200
+	// +kubebuilder:validation:Maximum=599
+	HTTPStatus uint32 `json:"httpStatus,omitempty"`
+	// GRPCStatus is the gRPC status code of the response.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=16
+	GRPCStatus uint32 `json:"grpcStatus,omitempty"`
+	// Percentage is the percentage of requests that are aborted,
+	// between 0 and 100. Defaults to 100.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	Percentage string `json:"percentage,omitempty"`
+}
+
+// HTTPDirectResponsePolicy defines a fixed response that is returned
+// without contacting any upstream service.
+type HTTPDirectResponsePolicy struct {
+	// StatusCode is the HTTP status code of the response.
+	// +kubebuilder:validation:Minimum=200
+	// +kubebuilder:validation:Maximum=599
+	StatusCode int `json:"statusCode"`
+	// Body is the body of the response. If it is not set,
+	// the response has no body.
+	// +optional
+	// +kubebuilder:validation:MaxLength=4096
+	Body string `json:"body,omitempty"`
 }
 
 // LoadBalancerPolicy defines the load balancing policy.
 type LoadBalancerPolicy struct {
 	// Strategy specifies the policy used to balance requests
 	// across the pool of backend pods. Valid policy names are
-	// `Random`, `RoundRobin`, `WeightedLeastRequest`, `Random`
-	// and `Cookie`. If an unknown strategy name is specified
-	// or no policy is supplied, the default `RoundRobin` policy
-	// is used.
+	// `Random`, `RoundRobin`, `WeightedLeastRequest`, `Random`,
+	// `Cookie` and `RequestHash`. If an unknown strategy name is
+	// specified or no policy is supplied, the default `RoundRobin`
+	// policy is used.
 	Strategy string `json:"strategy,omitempty"`
+	// HashAlgorithm selects the consistent hashing load balancer
+	// used by the `Cookie` and `RequestHash` strategies. Valid
+	// values are `RingHash` and `Maglev`. If not supplied,
+	// `RingHash` is used.
+	// +optional
+	// +kubebuilder:validation:Enum=RingHash;Maglev
+	HashAlgorithm string `json:"hashAlgorithm,omitempty"`
+	// RequestHashPolicies contains the list of hash policies
+	// applied when the `RequestHash` strategy is used. At least
+	// one policy must be supplied for that strategy, and none
+	// for any other strategy.
+	// +optional
+	RequestHashPolicies []RequestHashPolicy `json:"requestHashPolicies,omitempty"`
+}
+
+// RequestHashPolicy contains configuration for an individual hash
+// policy on a request. Exactly one of HeaderHashOptions,
+// CookieHashOptions, QueryParameterHashOptions or HashSourceIP
+// must be set.
+type RequestHashPolicy struct {
+	// Terminal is a flag that allows for short-circuiting computing
+	// of a hash for a given request. If set to true, and the request
+	// attribute specified in the attribute hash options is present,
+	// no further hash policies will be used to calculate a hash for
+	// the request.
+	// +optional
+	Terminal bool `json:"terminal,omitempty"`
+	// HeaderHashOptions should be set when request header hash
+	// based load balancing is desired.
+	// +optional
+	HeaderHashOptions *HeaderHashOptions `json:"headerHashOptions,omitempty"`
+	// CookieHashOptions should be set when request cookie hash
+	// based load balancing is desired.
+	// +optional
+	CookieHashOptions *CookieHashOptions `json:"cookieHashOptions,omitempty"`
+	// QueryParameterHashOptions should be set when request query
+	// parameter hash based load balancing is desired.
+	// +optional
+	QueryParameterHashOptions *QueryParameterHashOptions `json:"queryParameterHashOptions,omitempty"`
+	// HashSourceIP should be set to true when request source IP
+	// hash based load balancing is desired.
+	// +optional
+	HashSourceIP bool `json:"hashSourceIP,omitempty"`
+}
+
+// HeaderHashOptions contains options to configure a HTTP request
+// header hash policy, used in request attribute hash based load
+// balancing.
+type HeaderHashOptions struct {
+	// HeaderName is the name of the HTTP request header that will be
+	// used to calculate the hash key. If the header specified is not
+	// present on a request, no hash will be produced.
+	// +kubebuilder:validation:MinLength=1
+	HeaderName string `json:"headerName"`
+}
+
+// CookieHashOptions contains options to configure a HTTP request
+// cookie hash policy, used in request attribute hash based load
+// balancing.
+type CookieHashOptions struct {
+	// Name is the name of the cookie that will be used to calculate
+	// the hash key.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Path is the path attribute of the cookie generated by Envoy
+	// when TTL is set.
+	// +optional
+	Path string `json:"path,omitempty"`
+	// TTL is the lifetime of the cookie generated by Envoy when the
+	// request does not carry it, for example "1h". A TTL of "0s"
+	// generates a session cookie. If TTL is not supplied Envoy never
+	// generates the cookie, and requests without it are not hashed.
+	// +optional
+	TTL string `json:"ttl,omitempty"`
+}
+
+// QueryParameterHashOptions contains options to configure a query
+// parameter based hash policy, used in request attribute hash based
+// load balancing.
+type QueryParameterHashOptions struct {
+	// ParameterName is the name of the HTTP request query parameter
+	// that will be used to calculate the hash key. If the query
+	// parameter specified is not present on a request, no hash will
+	// be produced.
+	// +kubebuilder:validation:MinLength=1
+	ParameterName string `json:"parameterName"`
 }
 
 // HeadersPolicy defines how headers are managed during forwarding.
@@ -442,14 +1052,167 @@ type HeaderValue struct {
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinLength=1
 	Value string `json:"value"`
+	// Dynamic allows Value to contain Envoy variables, such as
+	// %DOWNSTREAM_REMOTE_ADDRESS%, which are replaced with a value
+	// from the request or the connection. If false, Value is set literally.
+	// +optional
+	Dynamic bool `json:"dynamic,omitempty"`
+}
+
+// RateLimitPolicy defines rate limiting parameters.
+type RateLimitPolicy struct {
+	// Local defines local rate limiting parameters, i.e. parameters
+	// for rate limiting that occurs within each Envoy pod as requests
+	// are handled.
+	// +optional
+	Local *LocalRateLimitPolicy `json:"local,omitempty"`
+
+	// Global defines global rate limiting parameters, i.e. parameters
+	// defining descriptors that are sent to an external rate limit
+	// service (RLS) for a rate limit decision on each request.
+	// +optional
+	Global *GlobalRateLimitPolicy `json:"global,omitempty"`
+}
+
+// LocalRateLimitPolicy defines local rate limiting parameters.
+// Local rate limits are enforced by each Envoy independently,
+// using a token bucket.
+type LocalRateLimitPolicy struct {
+	// Requests defines how many requests per unit of time should
+	// be allowed before rate limiting occurs.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	Requests uint32 `json:"requests"`
+
+	// Unit defines the period of time within which requests
+	// over the limit will be rate limited. Valid values are
+	// "second", "minute" and "hour".
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=second;minute;hour
+	Unit string `json:"unit"`
+
+	// Burst defines the number of requests above the requests per
+	// unit that should be allowed within a short period of time.
+	// +optional
+	Burst uint32 `json:"burst,omitempty"`
+
+	// ResponseStatusCode is the HTTP status code to use for responses
+	// to rate-limited requests. Codes must be in the 400-599 range
+	// (inclusive). If not specified, the Envoy default of 429 (Too
+	// Many Requests) is used.
+	// +optional
+	// +kubebuilder:validation:Minimum=400
+	// +kubebuilder:validation:Maximum=599
+	ResponseStatusCode uint32 `json:"responseStatusCode,omitempty"`
+
+	// ResponseHeadersToAdd is an optional list of response headers to
+	// set when a request is rate-limited.
+	// +optional
+	ResponseHeadersToAdd []HeaderValue `json:"responseHeadersToAdd,omitempty"`
+}
+
+// GlobalRateLimitPolicy defines global rate limiting parameters.
+type GlobalRateLimitPolicy struct {
+	// Descriptors defines the list of descriptors that will
+	// be generated and sent to the rate limit service. Each
+	// descriptor contains 1+ key-value pair entries.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Descriptors []RateLimitDescriptor `json:"descriptors"`
+}
+
+// RateLimitDescriptor defines a list of key-value pair generators.
+type RateLimitDescriptor struct {
+	// Entries is the list of key-value pair generators.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Entries []RateLimitDescriptorEntry `json:"entries"`
+}
+
+// RateLimitDescriptorEntry is a key-value pair generator. Exactly
+// one field on this struct must be non-nil.
+type RateLimitDescriptorEntry struct {
+	// GenericKey defines a descriptor entry with a key of "generic_key"
+	// and a static value.
+	// +optional
+	GenericKey *GenericKeyDescriptor `json:"genericKey,omitempty"`
+
+	// RequestHeader defines a descriptor entry that's populated only if
+	// a given header is present on the request. The descriptor key is static,
+	// and the descriptor value is equal to the value of the header.
+	// +optional
+	RequestHeader *RequestHeaderDescriptor `json:"requestHeader,omitempty"`
+
+	// RemoteAddress defines a descriptor entry with a key of "remote_address"
+	// and a value equal to the client's IP address (from x-forwarded-for).
+	// +optional
+	RemoteAddress *RemoteAddressDescriptor `json:"remoteAddress,omitempty"`
+}
+
+// GenericKeyDescriptor defines a descriptor entry with a key of
+// "generic_key" and a static value.
+type GenericKeyDescriptor struct {
+	// Value defines the value of the descriptor entry.
+	// +kubebuilder:validation:MinLength=1
+	Value string `json:"value"`
+}
+
+// RequestHeaderDescriptor defines a descriptor entry that's populated only
+// if a given header is present on the request. The value of the descriptor
+// entry is equal to the value of the header (if present).
+type RequestHeaderDescriptor struct {
+	// HeaderName defines the name of the header to look for on the request.
+	// +kubebuilder:validation:MinLength=1
+	HeaderName string `json:"headerName"`
+
+	// DescriptorKey defines the key to use on the descriptor entry.
+	// +kubebuilder:validation:MinLength=1
+	DescriptorKey string `json:"descriptorKey"`
 }
 
+// RemoteAddressDescriptor defines a descriptor entry with a key of
+// "remote_address" and a value equal to the client's IP address
+// (from x-forwarded-for).
+type RemoteAddressDescriptor struct{}
+
 // UpstreamValidation defines how to verify the backend service's certificate
 type UpstreamValidation struct {
 	// Name of the Kubernetes secret be used to validate the certificate presented by the backend
 	CACertificate string `json:"caSecret"`
-	// Key which is expected to be present in the 'subjectAltName' of the presented certificate
-	SubjectName string `json:"subjectName"`
+	// Key which is expected to be present in the 'subjectAltName' of the presented certificate.
+	// SubjectName is shorthand for a SubjectAltNames entry with an exact match.
+	// At least one of SubjectName or SubjectAltNames must be specified.
+	// +optional
+	SubjectName string `json:"subjectName,omitempty"`
+	// SubjectAltNames is a list of matchers for the 'subjectAltName' of the presented
+	// certificate. The certificate is accepted if any of its subject alternative names
+	// matches any of the matchers.
+	// +optional
+	SubjectAltNames []SubjectAltNameMatch `json:"subjectAltNames,omitempty"`
+}
+
+// SubjectAltNameMatch matches a subject alternative name of a certificate.
+// Exactly one of Exact, Prefix, Suffix or Regex must be specified.
+type SubjectAltNameMatch struct {
+	// Type is the type of the subject alternative name. It can
+	// only be used with Exact, whose value is validated against
+	// the type. Matching itself does not depend on the type: Envoy
+	// matches subject alternative names of every type.
+	// +optional
+	// +kubebuilder:validation:Enum=DNS;URI;IP;Email
+	Type string `json:"type,omitempty"`
+	// Exact specifies a string that the subject alternative name must be equal to.
+	// +optional
+	Exact string `json:"exact,omitempty"`
+	// Prefix specifies a string that the subject alternative name must start with.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// Suffix specifies a string that the subject alternative name must end with.
+	// +optional
+	Suffix string `json:"suffix,omitempty"`
+	// Regex specifies a regular expression that the subject alternative name must match.
+	// +optional
+	Regex string `json:"regex,omitempty"`
 }
 
 // DownstreamValidation defines how to verify the client certificate.
@@ -459,6 +1222,11 @@ type DownstreamValidation struct {
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinLength=1
 	CACertificate string `json:"caSecret"`
+	// Name of a Kubernetes secret that contains one or more PEM encoded
+	// certificate revocation lists in the "crl.pem" key. Client
+	// certificates that are revoked by a list are rejected.
+	// +optional
+	CertificateRevocationList string `json:"crlSecret,omitempty"`
 }
 
 // HTTPProxyStatus reports the current state of the HTTPProxy.
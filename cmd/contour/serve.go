@@ -24,6 +24,7 @@ import (
 	"syscall"
 	"time"
 
+	envoy_service_metrics_v2 "github.com/envoyproxy/go-control-plane/envoy/service/metrics/v2"
 	projectcontourv1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/annotation"
 	"github.com/projectcontour/contour/internal/contour"
@@ -126,6 +127,7 @@ func registerServe(app *kingpin.Application) (*kingpin.CmdClause, *serveContext)
 
 	serve.Flag("debug", "Enable debug logging.").Short('d').BoolVar(&ctx.Debug)
 	serve.Flag("experimental-service-apis", "Subscribe to the new service-apis types.").BoolVar(&ctx.UseExperimentalServiceAPITypes)
+	serve.Flag("gateway-controller-name", "Controller name of the GatewayClasses served by Contour.").StringVar(&ctx.GatewayControllerName)
 	return serve, ctx
 }
 
@@ -149,6 +151,39 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 		log.WithField("context", "fallback-certificate").Fatalf("invalid fallback certificate configuration: %q", err)
 	}
 
+	// Validate TLS parameters
+	if err := ctx.tlsParameters(); err != nil {
+		log.WithField("context", "tls").Fatalf("invalid TLS configuration: %q", err)
+	}
+
+	// Validate client certificate parameters
+	clientCert, err := ctx.clientCertificate()
+	if err != nil {
+		log.WithField("context", "envoy-client-certificate").Fatalf("invalid client certificate configuration: %q", err)
+	}
+
+	// Validate rate limit service parameters
+	rateLimitService, err := ctx.rateLimitService()
+	if err != nil {
+		log.WithField("context", "rate-limit-service").Fatalf("invalid rate limit service configuration: %q", err)
+	}
+
+	// Validate tracing parameters
+	tracing, err := ctx.tracing()
+	if err != nil {
+		log.WithField("context", "tracing").Fatalf("invalid tracing configuration: %q", err)
+	}
+
+	accessLogService, err := ctx.accessLogService()
+	if err != nil {
+		log.WithField("context", "accesslog-service").Fatalf("invalid access log service configuration: %q", err)
+	}
+
+	listenerPortRange, err := ctx.listenerPortRange()
+	if err != nil {
+		log.WithField("context", "listener-ports").Fatalf("invalid listener ports configuration: %q", err)
+	}
+
 	if rootNamespaces := ctx.proxyRootNamespaces(); len(rootNamespaces) > 0 {
 		// Add the FallbackCertificateNamespace to the root-namespaces if not already
 		if !contains(rootNamespaces, ctx.TLSConfig.FallbackCertificate.Namespace) && fallbackCert != nil {
@@ -156,6 +191,12 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 			log.WithField("context", "fallback-certificate").Infof("fallback certificate namespace %q not defined in 'root-namespaces', adding namespace to watch", ctx.FallbackCertificate.Namespace)
 		}
 
+		// Add the client certificate namespace to the root-namespaces if not already
+		if clientCert != nil && !contains(rootNamespaces, clientCert.Namespace) {
+			rootNamespaces = append(rootNamespaces, clientCert.Namespace)
+			log.WithField("context", "envoy-client-certificate").Infof("client certificate namespace %q not defined in 'root-namespaces', adding namespace to watch", clientCert.Namespace)
+		}
+
 		for _, ns := range rootNamespaces {
 			if _, ok := namespacedInformerFactories[ns]; !ok {
 				namespacedInformerFactories[ns] = clients.NewInformerFactoryForNamespace(ns)
@@ -187,6 +228,9 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 		AccessLogType:                 ctx.AccessLogFormat,
 		AccessLogFields:               ctx.AccessLogFields,
 		MinimumTLSVersion:             annotation.MinTLSVersion(ctx.TLSConfig.MinimumProtocolVersion),
+		MaximumTLSVersion:             annotation.MaxTLSVersion(ctx.TLSConfig.MaximumProtocolVersion),
+		CipherSuites:                  ctx.TLSConfig.CipherSuites,
+		ECDHCurves:                    ctx.TLSConfig.ECDHCurves,
 		RequestTimeout:                getRequestTimeout(log, ctx),
 		ConnectionIdleTimeout:         timeout.Parse(ctx.ConnectionIdleTimeout),
 		StreamIdleTimeout:             timeout.Parse(ctx.StreamIdleTimeout),
@@ -229,6 +273,9 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 				FieldLogger:    log.WithField("context", "KubernetesCache"),
 			},
 			DisablePermitInsecure: ctx.DisablePermitInsecure,
+			GatewayController:     ctx.GatewayControllerName,
+			GatewayHTTPPort:       ctx.httpPort,
+			GatewayHTTPSPort:      ctx.httpsPort,
 		},
 		FieldLogger: log.WithField("context", "contourEventHandler"),
 	}
@@ -237,6 +284,46 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 	if fallbackCert != nil {
 		log.WithField("context", "fallback-certificate").Infof("enabled fallback certificate with secret: %q", fallbackCert)
 		eventHandler.Builder.FallbackCertificate = fallbackCert
+		eventHandler.Builder.Source.ConfiguredSecrets = append(eventHandler.Builder.Source.ConfiguredSecrets, *fallbackCert)
+	}
+
+	// Set the client certificate if configured.
+	if clientCert != nil {
+		log.WithField("context", "envoy-client-certificate").Infof("enabled client certificate with secret: %q", clientCert)
+		eventHandler.Builder.ClientCertificate = clientCert
+		eventHandler.Builder.Source.ConfiguredSecrets = append(eventHandler.Builder.Source.ConfiguredSecrets, *clientCert)
+	}
+
+	// Set the global rate limit service if configured.
+	if rateLimitService != nil {
+		log.WithField("context", "rate-limit-service").Infof("enabled global rate limiting with extension service: %q", rateLimitService.ExtensionService)
+		eventHandler.Builder.RateLimitService = rateLimitService
+	}
+
+	// Enable dedicated listener ports if configured.
+	if listenerPortRange != nil {
+		log.WithField("context", "listener-ports").Infof("enabled dedicated listener ports %d-%d", listenerPortRange.Min, listenerPortRange.Max)
+		eventHandler.Builder.ListenerPortRange = listenerPortRange
+	}
+
+	// Set the tracing configuration if configured.
+	if tracing != nil {
+		log.WithField("context", "tracing").Infof("enabled %s tracing with collector service: %s/%s:%d",
+			tracing.Provider, tracing.Service.Namespace, tracing.Service.Name, tracing.Port)
+		eventHandler.Builder.Tracing = tracing
+		eventHandler.Builder.Source.ConfiguredServices = append(eventHandler.Builder.Source.ConfiguredServices, tracing.Service)
+	}
+
+	// Set the gRPC access log service if configured.
+	if accessLogService != nil {
+		if accessLogService.ExtensionService.Name != "" {
+			log.WithField("context", "accesslog-service").Infof("enabled gRPC access logging with extension service: %q", accessLogService.ExtensionService)
+		} else {
+			log.WithField("context", "accesslog-service").Infof("enabled gRPC access logging with service: %s/%s:%d",
+				accessLogService.Service.Namespace, accessLogService.Service.Name, accessLogService.Port)
+			eventHandler.Builder.Source.ConfiguredServices = append(eventHandler.Builder.Source.ConfiguredServices, accessLogService.Service)
+		}
+		eventHandler.Builder.AccessLogService = accessLogService
 	}
 
 	// Wrap eventHandler in a converter for objects from the dynamic client.
@@ -421,6 +508,13 @@ func doServe(log logrus.FieldLogger, ctx *serveContext) error {
 			registry,
 			ctx.grpcOptions()...)
 
+		// Envoys bootstrapped with --metrics-service stream their
+		// statistics to Contour over the xDS connection.
+		envoy_service_metrics_v2.RegisterMetricsServiceServer(rpcServer, &metrics.EnvoyMetricsService{
+			FieldLogger: log.WithField("context", "metrics-service"),
+			Metrics:     contourMetrics,
+		})
+
 		addr := net.JoinHostPort(ctx.xdsAddr, strconv.Itoa(ctx.xdsPort))
 		l, err := net.Listen("tcp", addr)
 		if err != nil {
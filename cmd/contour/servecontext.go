@@ -25,7 +25,10 @@ import (
 	"strings"
 	"time"
 
+	envoy_api_v2_auth "github.com/envoyproxy/go-control-plane/envoy/api/v2/auth"
+	"github.com/projectcontour/contour/internal/annotation"
 	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/envoy"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -131,6 +134,12 @@ type serveContext struct {
 	// (GatewayClass, Gateway, HTTPRoute, TCPRoute, and any more as they are added)
 	UseExperimentalServiceAPITypes bool `yaml:"-"`
 
+	// GatewayControllerName is the controller name of the
+	// GatewayClasses that Contour serves. If empty, Contour
+	// serves GatewayClasses of the "projectcontour.io/contour"
+	// controller.
+	GatewayControllerName string `yaml:"-"`
+
 	// envoy service details
 
 	// Namespace of the envoy service to inspect for Ingress status details.
@@ -146,6 +155,20 @@ type serveContext struct {
 	//
 	// If this field not specified, all supported versions are accepted.
 	DefaultHTTPVersions []string `yaml:"default-http-versions"`
+
+	// RateLimitServiceConfig configures the global rate limit service.
+	RateLimitServiceConfig `yaml:"rate-limit-service,omitempty"`
+
+	// TracingConfig configures distributed tracing.
+	TracingConfig `yaml:"tracing,omitempty"`
+
+	// AccessLogServiceConfig defines the gRPC access log service
+	// that access logs are streamed to when AccessLogFormat is grpc.
+	AccessLogServiceConfig `yaml:"accesslog-service,omitempty"`
+
+	// ListenerPortsConfig defines the range of Envoy ports that
+	// HTTPProxies may bind dedicated TCP and UDP listeners to.
+	ListenerPortsConfig `yaml:"listener-ports,omitempty"`
 }
 
 // newServeContext returns a serveContext initialized to defaults.
@@ -195,9 +218,29 @@ func newServeContext() *serveContext {
 type TLSConfig struct {
 	MinimumProtocolVersion string `yaml:"minimum-protocol-version"`
 
+	// MaximumProtocolVersion is the maximum TLS protocol version
+	// that Envoy accepts. Valid values are "1.2" and "1.3".
+	// If not set, defaults to "1.3".
+	MaximumProtocolVersion string `yaml:"maximum-protocol-version,omitempty"`
+
+	// CipherSuites is the list of TLS 1.2 cipher suites that Envoy
+	// accepts, in order of preference. If not set, defaults to the
+	// Contour default cipher suites.
+	CipherSuites []string `yaml:"cipher-suites,omitempty"`
+
+	// ECDHCurves is the list of ECDH curves that Envoy accepts, in
+	// order of preference. If not set, defaults to the Envoy default
+	// curves.
+	ECDHCurves []string `yaml:"ecdh-curves,omitempty"`
+
 	// FallbackCertificate defines the namespace/name of the Kubernetes secret to
 	// use as fallback when a non-SNI request is received.
 	FallbackCertificate FallbackCertificate `yaml:"fallback-certificate,omitempty"`
+
+	// ClientCertificate defines the namespace/name of the Kubernetes secret
+	// that Envoy presents to TLS upstream services that request a client
+	// certificate.
+	ClientCertificate ClientCertificate `yaml:"envoy-client-certificate,omitempty"`
 }
 
 // FallbackCertificate defines the namespace/name of the Kubernetes secret to
@@ -228,6 +271,319 @@ func (ctx *serveContext) fallbackCertificate() (*types.NamespacedName, error) {
 	}, nil
 }
 
+// tlsParameters validates the TLS protocol versions, cipher suites
+// and ECDH curves of the Envoy listeners.
+func (ctx *serveContext) tlsParameters() error {
+	if v := ctx.TLSConfig.MaximumProtocolVersion; v != "" {
+		maxVersion := annotation.MaxTLSVersion(v)
+		if maxVersion == envoy_api_v2_auth.TlsParameters_TLS_AUTO {
+			return fmt.Errorf("invalid maximum protocol version %q", v)
+		}
+		if maxVersion < annotation.MinTLSVersion(ctx.TLSConfig.MinimumProtocolVersion) {
+			return fmt.Errorf("maximum protocol version %q is lower than the minimum protocol version %q",
+				v, ctx.TLSConfig.MinimumProtocolVersion)
+		}
+	}
+
+	if err := dag.ValidateCipherSuites(ctx.TLSConfig.CipherSuites); err != nil {
+		return err
+	}
+
+	return dag.ValidateECDHCurves(ctx.TLSConfig.ECDHCurves)
+}
+
+// ClientCertificate defines the namespace/name of the Kubernetes secret
+// that Envoy presents to TLS upstream services.
+type ClientCertificate struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+}
+
+func (ctx *serveContext) clientCertificate() (*types.NamespacedName, error) {
+	if len(strings.TrimSpace(ctx.TLSConfig.ClientCertificate.Name)) == 0 && len(strings.TrimSpace(ctx.TLSConfig.ClientCertificate.Namespace)) == 0 {
+		return nil, nil
+	}
+
+	// Validate namespace is defined
+	if len(strings.TrimSpace(ctx.TLSConfig.ClientCertificate.Namespace)) == 0 {
+		return nil, errors.New("namespace must be defined")
+	}
+
+	// Validate name is defined
+	if len(strings.TrimSpace(ctx.TLSConfig.ClientCertificate.Name)) == 0 {
+		return nil, errors.New("name must be defined")
+	}
+
+	return &types.NamespacedName{
+		Name:      ctx.TLSConfig.ClientCertificate.Name,
+		Namespace: ctx.TLSConfig.ClientCertificate.Namespace,
+	}, nil
+}
+
+// RateLimitServiceConfig holds configuration file details of the
+// global rate limit service.
+type RateLimitServiceConfig struct {
+	// ExtensionService defines the namespace/name of the
+	// ExtensionService that implements the rate limit service.
+	ExtensionService ExtensionServiceConfig `yaml:"extension-service,omitempty"`
+
+	// Domain is passed to the rate limit service.
+	Domain string `yaml:"domain,omitempty"`
+
+	// FailOpen allows requests through when the rate limit
+	// service fails to respond with a decision.
+	FailOpen bool `yaml:"fail-open,omitempty"`
+}
+
+// ExtensionServiceConfig defines the namespace/name of an ExtensionService.
+type ExtensionServiceConfig struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+}
+
+func (ctx *serveContext) rateLimitService() (*dag.RateLimitServiceConfig, error) {
+	ext := ctx.RateLimitServiceConfig.ExtensionService
+	if len(strings.TrimSpace(ext.Name)) == 0 && len(strings.TrimSpace(ext.Namespace)) == 0 {
+		return nil, nil
+	}
+
+	// Validate namespace is defined
+	if len(strings.TrimSpace(ext.Namespace)) == 0 {
+		return nil, errors.New("namespace must be defined")
+	}
+
+	// Validate name is defined
+	if len(strings.TrimSpace(ext.Name)) == 0 {
+		return nil, errors.New("name must be defined")
+	}
+
+	return &dag.RateLimitServiceConfig{
+		ExtensionService: types.NamespacedName{
+			Name:      ext.Name,
+			Namespace: ext.Namespace,
+		},
+		Domain:   ctx.RateLimitServiceConfig.Domain,
+		FailOpen: ctx.RateLimitServiceConfig.FailOpen,
+	}, nil
+}
+
+// TracingConfig holds configuration file details of distributed tracing.
+type TracingConfig struct {
+	// Provider is the tracer that Envoy uses. Valid options
+	// are "zipkin" and "opentelemetry".
+	Provider string `yaml:"provider,omitempty"`
+
+	// Service defines the namespace/name and port of
+	// the Kubernetes Service of the trace collector.
+	Service ServiceConfig `yaml:"service,omitempty"`
+
+	// CollectorEndpoint is the API path of a Zipkin
+	// collector. Defaults to "/api/v2/spans".
+	CollectorEndpoint string `yaml:"collector-endpoint,omitempty"`
+
+	// SamplingRate is the percentage of requests that are
+	// traced, between 0 and 100. Defaults to 100.
+	SamplingRate *float64 `yaml:"sampling-rate,omitempty"`
+
+	// CustomTags are added to every span.
+	CustomTags []CustomTagConfig `yaml:"custom-tags,omitempty"`
+}
+
+// ServiceConfig defines the namespace/name and port of a Service.
+type ServiceConfig struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+	Port      int    `yaml:"port"`
+}
+
+// CustomTagConfig defines a span tag whose value is either
+// a literal or the value of a request header.
+type CustomTagConfig struct {
+	TagName       string `yaml:"tag-name"`
+	Literal       string `yaml:"literal,omitempty"`
+	RequestHeader string `yaml:"request-header,omitempty"`
+}
+
+func (ctx *serveContext) tracing() (*dag.TracingConfig, error) {
+	tc := ctx.TracingConfig
+	if tc.Provider == "" {
+		return nil, nil
+	}
+
+	switch tc.Provider {
+	case "zipkin", "opentelemetry":
+	default:
+		return nil, fmt.Errorf("invalid provider %q: must be one of \"zipkin\" or \"opentelemetry\"", tc.Provider)
+	}
+
+	// Validate namespace is defined
+	if len(strings.TrimSpace(tc.Service.Namespace)) == 0 {
+		return nil, errors.New("service namespace must be defined")
+	}
+
+	// Validate name is defined
+	if len(strings.TrimSpace(tc.Service.Name)) == 0 {
+		return nil, errors.New("service name must be defined")
+	}
+
+	if tc.Service.Port < 1 || tc.Service.Port > 65535 {
+		return nil, fmt.Errorf("invalid service port %d", tc.Service.Port)
+	}
+
+	if tc.CollectorEndpoint != "" && tc.Provider != "zipkin" {
+		return nil, errors.New("collector endpoint is only supported by the zipkin provider")
+	}
+
+	rate := 100.0
+	if tc.SamplingRate != nil {
+		rate = *tc.SamplingRate
+	}
+	if rate < 0 || rate > 100 {
+		return nil, fmt.Errorf("invalid sampling rate %v: must be between 0 and 100", rate)
+	}
+
+	var tags []dag.TracingCustomTag
+	for _, tag := range tc.CustomTags {
+		if tag.TagName == "" {
+			return nil, errors.New("custom tag name must be defined")
+		}
+		if (tag.Literal == "") == (tag.RequestHeader == "") {
+			return nil, fmt.Errorf("custom tag %q must define exactly one of literal or request-header", tag.TagName)
+		}
+		tags = append(tags, dag.TracingCustomTag{
+			TagName:       tag.TagName,
+			Literal:       tag.Literal,
+			RequestHeader: tag.RequestHeader,
+		})
+	}
+
+	return &dag.TracingConfig{
+		Provider: tc.Provider,
+		Service: types.NamespacedName{
+			Name:      tc.Service.Name,
+			Namespace: tc.Service.Namespace,
+		},
+		Port:              tc.Service.Port,
+		CollectorEndpoint: tc.CollectorEndpoint,
+		SamplingRate:      rate,
+		CustomTags:        tags,
+	}, nil
+}
+
+// AccessLogServiceConfig holds configuration file details
+// of the gRPC access log service.
+type AccessLogServiceConfig struct {
+	// ExtensionService defines the namespace/name of the
+	// ExtensionService that implements the access log service.
+	ExtensionService ExtensionServiceConfig `yaml:"extension-service,omitempty"`
+
+	// Service defines the namespace/name and port of the Kubernetes
+	// Service that implements the access log service.
+	Service ServiceConfig `yaml:"service,omitempty"`
+
+	// LogName identifies Envoy's access log stream to the
+	// access log service. Defaults to "contour".
+	LogName string `yaml:"log-name,omitempty"`
+}
+
+func (ctx *serveContext) accessLogService() (*dag.AccessLogServiceConfig, error) {
+	ac := ctx.AccessLogServiceConfig
+	ext := ac.ExtensionService
+	svc := ac.Service
+
+	hasExt := len(strings.TrimSpace(ext.Name)) > 0 || len(strings.TrimSpace(ext.Namespace)) > 0
+	hasSvc := len(strings.TrimSpace(svc.Name)) > 0 || len(strings.TrimSpace(svc.Namespace)) > 0
+
+	if ctx.AccessLogFormat != "grpc" {
+		if hasExt || hasSvc {
+			return nil, fmt.Errorf("access log service requires accesslog-format %q", "grpc")
+		}
+		return nil, nil
+	}
+
+	logName := ac.LogName
+	if logName == "" {
+		logName = "contour"
+	}
+
+	switch {
+	case hasExt && hasSvc:
+		return nil, errors.New("only one of extension-service or service may be defined")
+	case hasExt:
+		// Validate namespace is defined
+		if len(strings.TrimSpace(ext.Namespace)) == 0 {
+			return nil, errors.New("extension service namespace must be defined")
+		}
+
+		// Validate name is defined
+		if len(strings.TrimSpace(ext.Name)) == 0 {
+			return nil, errors.New("extension service name must be defined")
+		}
+
+		return &dag.AccessLogServiceConfig{
+			ExtensionService: types.NamespacedName{
+				Name:      ext.Name,
+				Namespace: ext.Namespace,
+			},
+			LogName: logName,
+		}, nil
+	case hasSvc:
+		// Validate namespace is defined
+		if len(strings.TrimSpace(svc.Namespace)) == 0 {
+			return nil, errors.New("service namespace must be defined")
+		}
+
+		// Validate name is defined
+		if len(strings.TrimSpace(svc.Name)) == 0 {
+			return nil, errors.New("service name must be defined")
+		}
+
+		if svc.Port < 1 || svc.Port > 65535 {
+			return nil, fmt.Errorf("invalid service port %d", svc.Port)
+		}
+
+		return &dag.AccessLogServiceConfig{
+			Service: types.NamespacedName{
+				Name:      svc.Name,
+				Namespace: svc.Namespace,
+			},
+			Port:    svc.Port,
+			LogName: logName,
+		}, nil
+	default:
+		return nil, fmt.Errorf("accesslog-format %q requires an access log service", "grpc")
+	}
+}
+
+// ListenerPortsConfig holds configuration file details of the
+// ports that HTTPProxies may bind dedicated listeners to.
+type ListenerPortsConfig struct {
+	// Min and Max are the first and last port of the range.
+	Min int `yaml:"min,omitempty"`
+	Max int `yaml:"max,omitempty"`
+}
+
+func (ctx *serveContext) listenerPortRange() (*dag.PortRange, error) {
+	lp := ctx.ListenerPortsConfig
+	if lp.Min == 0 && lp.Max == 0 {
+		return nil, nil
+	}
+
+	if lp.Min < 1 || lp.Max > 65535 || lp.Min > lp.Max {
+		return nil, fmt.Errorf("invalid port range %d-%d", lp.Min, lp.Max)
+	}
+
+	r := &dag.PortRange{Min: lp.Min, Max: lp.Max}
+
+	// Envoy's own listeners can't be used by HTTPProxies.
+	for _, port := range []int{ctx.httpPort, ctx.httpsPort, ctx.statsPort} {
+		if r.Contains(port) {
+			return nil, fmt.Errorf("port range %d-%d overlaps Envoy port %d", lp.Min, lp.Max, port)
+		}
+	}
+	return r, nil
+}
+
 // LeaderElectionConfig holds the config bits for leader election inside the
 // configuration file.
 type LeaderElectionConfig struct {
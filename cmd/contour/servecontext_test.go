@@ -27,6 +27,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/envoy"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -285,6 +286,202 @@ func TestFallbackCertificateParams(t *testing.T) {
 	}
 }
 
+func TestClientCertificateParams(t *testing.T) {
+	tests := map[string]struct {
+		ctx         serveContext
+		want        *types.NamespacedName
+		expecterror bool
+	}{
+		"client cert params passed correctly": {
+			ctx: serveContext{
+				TLSConfig: TLSConfig{
+					ClientCertificate: ClientCertificate{
+						Name:      "envoy-client",
+						Namespace: "projectcontour",
+					},
+				},
+			},
+			want: &types.NamespacedName{
+				Name:      "envoy-client",
+				Namespace: "projectcontour",
+			},
+			expecterror: false,
+		},
+		"missing namespace": {
+			ctx: serveContext{
+				TLSConfig: TLSConfig{
+					ClientCertificate: ClientCertificate{
+						Name: "envoy-client",
+					},
+				},
+			},
+			want:        nil,
+			expecterror: true,
+		},
+		"missing name": {
+			ctx: serveContext{
+				TLSConfig: TLSConfig{
+					ClientCertificate: ClientCertificate{
+						Namespace: "projectcontour",
+					},
+				},
+			},
+			want:        nil,
+			expecterror: true,
+		},
+		"client cert not defined": {
+			ctx:         serveContext{},
+			want:        nil,
+			expecterror: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.ctx.clientCertificate()
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+
+			goterror := err != nil
+			if goterror != tc.expecterror {
+				t.Errorf("Expected Client Certificate error: %s", err)
+			}
+		})
+	}
+}
+
+func TestTLSParameters(t *testing.T) {
+	tests := map[string]struct {
+		tls         TLSConfig
+		expecterror bool
+	}{
+		"not defined": {
+			tls:         TLSConfig{},
+			expecterror: false,
+		},
+		"all parameters": {
+			tls: TLSConfig{
+				MinimumProtocolVersion: "1.2",
+				MaximumProtocolVersion: "1.2",
+				CipherSuites:           []string{"ECDHE-ECDSA-AES256-GCM-SHA384", "ECDHE-RSA-AES256-GCM-SHA384"},
+				ECDHCurves:             []string{"X25519", "P-256"},
+			},
+			expecterror: false,
+		},
+		"invalid maximum version": {
+			tls: TLSConfig{
+				MaximumProtocolVersion: "1.4",
+			},
+			expecterror: true,
+		},
+		"maximum version lower than minimum": {
+			tls: TLSConfig{
+				MinimumProtocolVersion: "1.3",
+				MaximumProtocolVersion: "1.2",
+			},
+			expecterror: true,
+		},
+		"unsupported cipher suite": {
+			tls: TLSConfig{
+				CipherSuites: []string{"ECDHE-RSA-RC4-SHA"},
+			},
+			expecterror: true,
+		},
+		"unsupported curve": {
+			tls: TLSConfig{
+				ECDHCurves: []string{"P-192"},
+			},
+			expecterror: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := serveContext{TLSConfig: tc.tls}
+			err := ctx.tlsParameters()
+
+			goterror := err != nil
+			if goterror != tc.expecterror {
+				t.Errorf("Expected TLS parameters error: %v, got: %v", tc.expecterror, err)
+			}
+		})
+	}
+}
+
+func TestRateLimitServiceParams(t *testing.T) {
+	tests := map[string]struct {
+		ctx         serveContext
+		want        *dag.RateLimitServiceConfig
+		expecterror bool
+	}{
+		"rate limit service params passed correctly": {
+			ctx: serveContext{
+				RateLimitServiceConfig: RateLimitServiceConfig{
+					ExtensionService: ExtensionServiceConfig{
+						Name:      "ratelimit",
+						Namespace: "projectcontour",
+					},
+					Domain:   "contour",
+					FailOpen: true,
+				},
+			},
+			want: &dag.RateLimitServiceConfig{
+				ExtensionService: types.NamespacedName{
+					Name:      "ratelimit",
+					Namespace: "projectcontour",
+				},
+				Domain:   "contour",
+				FailOpen: true,
+			},
+			expecterror: false,
+		},
+		"missing namespace": {
+			ctx: serveContext{
+				RateLimitServiceConfig: RateLimitServiceConfig{
+					ExtensionService: ExtensionServiceConfig{
+						Name: "ratelimit",
+					},
+				},
+			},
+			want:        nil,
+			expecterror: true,
+		},
+		"missing name": {
+			ctx: serveContext{
+				RateLimitServiceConfig: RateLimitServiceConfig{
+					ExtensionService: ExtensionServiceConfig{
+						Namespace: "projectcontour",
+					},
+				},
+			},
+			want:        nil,
+			expecterror: true,
+		},
+		"rate limit service not defined": {
+			ctx:         serveContext{},
+			want:        nil,
+			expecterror: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.ctx.rateLimitService()
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+
+			goterror := err != nil
+			if goterror != tc.expecterror {
+				t.Errorf("Expected rate limit service error: %s", err)
+			}
+		})
+	}
+}
+
 // Testdata for this test case can be re-generated by running:
 // make gencerts
 // cp certs/*.pem cmd/contour/testdata/X/
@@ -506,3 +703,351 @@ func TestParseHTTPVersions(t *testing.T) {
 		})
 	}
 }
+
+func TestTracingParams(t *testing.T) {
+	rate := 12.5
+	invalidRate := 101.0
+
+	tests := map[string]struct {
+		ctx         serveContext
+		want        *dag.TracingConfig
+		expecterror bool
+	}{
+		"tracing params passed correctly": {
+			ctx: serveContext{
+				TracingConfig: TracingConfig{
+					Provider: "zipkin",
+					Service: ServiceConfig{
+						Name:      "jaeger-collector",
+						Namespace: "tracing",
+						Port:      9411,
+					},
+					SamplingRate: &rate,
+					CustomTags: []CustomTagConfig{{
+						TagName: "cluster",
+						Literal: "prod",
+					}, {
+						TagName:       "tenant",
+						RequestHeader: "x-tenant",
+					}},
+				},
+			},
+			want: &dag.TracingConfig{
+				Provider: "zipkin",
+				Service: types.NamespacedName{
+					Name:      "jaeger-collector",
+					Namespace: "tracing",
+				},
+				Port:         9411,
+				SamplingRate: 12.5,
+				CustomTags: []dag.TracingCustomTag{{
+					TagName: "cluster",
+					Literal: "prod",
+				}, {
+					TagName:       "tenant",
+					RequestHeader: "x-tenant",
+				}},
+			},
+			expecterror: false,
+		},
+		"default sampling rate": {
+			ctx: serveContext{
+				TracingConfig: TracingConfig{
+					Provider: "opentelemetry",
+					Service: ServiceConfig{
+						Name:      "otel-collector",
+						Namespace: "tracing",
+						Port:      55678,
+					},
+				},
+			},
+			want: &dag.TracingConfig{
+				Provider: "opentelemetry",
+				Service: types.NamespacedName{
+					Name:      "otel-collector",
+					Namespace: "tracing",
+				},
+				Port:         55678,
+				SamplingRate: 100,
+			},
+			expecterror: false,
+		},
+		"unknown provider": {
+			ctx: serveContext{
+				TracingConfig: TracingConfig{
+					Provider: "lightstep",
+					Service: ServiceConfig{
+						Name:      "collector",
+						Namespace: "tracing",
+						Port:      9411,
+					},
+				},
+			},
+			want:        nil,
+			expecterror: true,
+		},
+		"missing service port": {
+			ctx: serveContext{
+				TracingConfig: TracingConfig{
+					Provider: "zipkin",
+					Service: ServiceConfig{
+						Name:      "collector",
+						Namespace: "tracing",
+					},
+				},
+			},
+			want:        nil,
+			expecterror: true,
+		},
+		"invalid sampling rate": {
+			ctx: serveContext{
+				TracingConfig: TracingConfig{
+					Provider: "zipkin",
+					Service: ServiceConfig{
+						Name:      "collector",
+						Namespace: "tracing",
+						Port:      9411,
+					},
+					SamplingRate: &invalidRate,
+				},
+			},
+			want:        nil,
+			expecterror: true,
+		},
+		"custom tag with literal and header": {
+			ctx: serveContext{
+				TracingConfig: TracingConfig{
+					Provider: "zipkin",
+					Service: ServiceConfig{
+						Name:      "collector",
+						Namespace: "tracing",
+						Port:      9411,
+					},
+					CustomTags: []CustomTagConfig{{
+						TagName:       "tenant",
+						Literal:       "prod",
+						RequestHeader: "x-tenant",
+					}},
+				},
+			},
+			want:        nil,
+			expecterror: true,
+		},
+		"tracing not defined": {
+			ctx:         serveContext{},
+			want:        nil,
+			expecterror: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.ctx.tracing()
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+
+			goterror := err != nil
+			if goterror != tc.expecterror {
+				t.Errorf("Expected tracing error: %s", err)
+			}
+		})
+	}
+}
+
+func TestAccessLogServiceParams(t *testing.T) {
+	tests := map[string]struct {
+		ctx         serveContext
+		want        *dag.AccessLogServiceConfig
+		expecterror bool
+	}{
+		"extension service": {
+			ctx: serveContext{
+				AccessLogFormat: "grpc",
+				AccessLogServiceConfig: AccessLogServiceConfig{
+					ExtensionService: ExtensionServiceConfig{
+						Name:      "als",
+						Namespace: "projectcontour",
+					},
+				},
+			},
+			want: &dag.AccessLogServiceConfig{
+				ExtensionService: types.NamespacedName{
+					Name:      "als",
+					Namespace: "projectcontour",
+				},
+				LogName: "contour",
+			},
+			expecterror: false,
+		},
+		"service": {
+			ctx: serveContext{
+				AccessLogFormat: "grpc",
+				AccessLogServiceConfig: AccessLogServiceConfig{
+					Service: ServiceConfig{
+						Name:      "als",
+						Namespace: "logging",
+						Port:      9001,
+					},
+					LogName: "edge",
+				},
+			},
+			want: &dag.AccessLogServiceConfig{
+				Service: types.NamespacedName{
+					Name:      "als",
+					Namespace: "logging",
+				},
+				Port:    9001,
+				LogName: "edge",
+			},
+			expecterror: false,
+		},
+		"grpc format without service": {
+			ctx: serveContext{
+				AccessLogFormat: "grpc",
+			},
+			want:        nil,
+			expecterror: true,
+		},
+		"service without grpc format": {
+			ctx: serveContext{
+				AccessLogFormat: "json",
+				AccessLogServiceConfig: AccessLogServiceConfig{
+					ExtensionService: ExtensionServiceConfig{
+						Name:      "als",
+						Namespace: "projectcontour",
+					},
+				},
+			},
+			want:        nil,
+			expecterror: true,
+		},
+		"both extension service and service": {
+			ctx: serveContext{
+				AccessLogFormat: "grpc",
+				AccessLogServiceConfig: AccessLogServiceConfig{
+					ExtensionService: ExtensionServiceConfig{
+						Name:      "als",
+						Namespace: "projectcontour",
+					},
+					Service: ServiceConfig{
+						Name:      "als",
+						Namespace: "logging",
+						Port:      9001,
+					},
+				},
+			},
+			want:        nil,
+			expecterror: true,
+		},
+		"invalid service port": {
+			ctx: serveContext{
+				AccessLogFormat: "grpc",
+				AccessLogServiceConfig: AccessLogServiceConfig{
+					Service: ServiceConfig{
+						Name:      "als",
+						Namespace: "logging",
+					},
+				},
+			},
+			want:        nil,
+			expecterror: true,
+		},
+		"not configured": {
+			ctx: serveContext{
+				AccessLogFormat: "envoy",
+			},
+			want:        nil,
+			expecterror: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.ctx.accessLogService()
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+
+			goterror := err != nil
+			if goterror != tc.expecterror {
+				t.Errorf("Expected access log service error: %s", err)
+			}
+		})
+	}
+}
+
+func TestListenerPortRangeParams(t *testing.T) {
+	tests := map[string]struct {
+		ctx         *serveContext
+		want        *dag.PortRange
+		expecterror bool
+	}{
+		"port range params passed correctly": {
+			ctx: &serveContext{
+				ListenerPortsConfig: ListenerPortsConfig{Min: 9100, Max: 9200},
+			},
+			want:        &dag.PortRange{Min: 9100, Max: 9200},
+			expecterror: false,
+		},
+		"single port": {
+			ctx: &serveContext{
+				ListenerPortsConfig: ListenerPortsConfig{Min: 5432, Max: 5432},
+			},
+			want:        &dag.PortRange{Min: 5432, Max: 5432},
+			expecterror: false,
+		},
+		"missing max": {
+			ctx: &serveContext{
+				ListenerPortsConfig: ListenerPortsConfig{Min: 9100},
+			},
+			want:        nil,
+			expecterror: true,
+		},
+		"min greater than max": {
+			ctx: &serveContext{
+				ListenerPortsConfig: ListenerPortsConfig{Min: 9200, Max: 9100},
+			},
+			want:        nil,
+			expecterror: true,
+		},
+		"max out of range": {
+			ctx: &serveContext{
+				ListenerPortsConfig: ListenerPortsConfig{Min: 9100, Max: 65536},
+			},
+			want:        nil,
+			expecterror: true,
+		},
+		"overlaps envoy http port": {
+			ctx: func() *serveContext {
+				ctx := newServeContext()
+				ctx.ListenerPortsConfig = ListenerPortsConfig{Min: 8000, Max: 8100}
+				return ctx
+			}(),
+			want:        nil,
+			expecterror: true,
+		},
+		"port range not defined": {
+			ctx:         &serveContext{},
+			want:        nil,
+			expecterror: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.ctx.listenerPortRange()
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+
+			goterror := err != nil
+			if goterror != tc.expecterror {
+				t.Errorf("Expected listener port range error: %s", err)
+			}
+		})
+	}
+}
@@ -238,6 +238,19 @@ func MinTLSVersion(version string) envoy_api_v2_auth.TlsParameters_TlsProtocol {
 	}
 }
 
+// MaxTLSVersion returns the maximum TLS protocol version specified
+// by version, or TLS_AUTO if the version is not supported.
+func MaxTLSVersion(version string) envoy_api_v2_auth.TlsParameters_TlsProtocol {
+	switch version {
+	case "1.3":
+		return envoy_api_v2_auth.TlsParameters_TLSv1_3
+	case "1.2":
+		return envoy_api_v2_auth.TlsParameters_TLSv1_2
+	default:
+		return envoy_api_v2_auth.TlsParameters_TLS_AUTO
+	}
+}
+
 // MaxConnections returns the value of the first matching max-connections
 // annotation for the following annotations:
 // 1. projectcontour.io/max-connections
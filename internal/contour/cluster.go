@@ -102,6 +102,13 @@ func (v *clusterVisitor) visit(vertex dag.Vertex) {
 		}
 	}
 
+	if ext, ok := vertex.(*dag.ExtensionCluster); ok {
+		if _, ok := v.clusters[ext.Name]; !ok {
+			c := envoy.ExtensionCluster(ext)
+			v.clusters[c.Name] = c
+		}
+	}
+
 	// recurse into children of v
 	vertex.Visit(v.visit)
 }
@@ -18,6 +18,7 @@ import (
 	"sync"
 
 	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	envoy_api_v2_endpoint "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
 	resource "github.com/envoyproxy/go-control-plane/pkg/resource/v2"
 	"github.com/golang/protobuf/proto"
@@ -28,6 +29,7 @@ import (
 	"github.com/projectcontour/contour/internal/sorter"
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	k8scache "k8s.io/client-go/tools/cache"
 )
 
@@ -39,11 +41,140 @@ type EndpointsTranslator struct {
 
 	mu      sync.Mutex
 	entries map[string]*v2.ClusterLoadAssignment
+
+	// extensions maps the name of each extension cluster to the
+	// weighted ClusterLoadAssignments of its upstream Services.
+	extensions map[string][]weightedLoadAssignment
+}
+
+// weightedLoadAssignment is the name of a ClusterLoadAssignment
+// and its relative weight in an extension cluster.
+type weightedLoadAssignment struct {
+	name   string
+	weight uint32
 }
 
+// OnChange records the upstream Services of the extension clusters
+// in the DAG and rebuilds their aggregated ClusterLoadAssignments.
 func (e *EndpointsTranslator) OnChange(d *dag.DAG) {
-	// TODO(jpeach) Update the internal model to map which
-	// services are targets of which cluster load assignments.
+	extensions := make(map[string][]weightedLoadAssignment)
+
+	var visit func(dag.Vertex)
+	visit = func(vertex dag.Vertex) {
+		if ext, ok := vertex.(*dag.ExtensionCluster); ok {
+			extensions[ext.Name] = extensionLoadAssignments(ext)
+		}
+		vertex.Visit(visit)
+	}
+	d.Visit(visit)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Remove the aggregated load assignments of extension
+	// clusters that are no longer in the DAG.
+	for name := range e.extensions {
+		if _, ok := extensions[name]; !ok {
+			delete(e.entries, name)
+			e.Notify(name)
+		}
+	}
+
+	e.extensions = extensions
+	for name := range e.extensions {
+		e.recomputeExtension(name)
+	}
+}
+
+// extensionLoadAssignments returns the weighted ClusterLoadAssignment
+// names of the upstream Services of the given extension cluster.
+// Services with a zero weight receive no traffic unless all the
+// Services have a zero weight, in which case they are weighted equally.
+func extensionLoadAssignments(ext *dag.ExtensionCluster) []weightedLoadAssignment {
+	var total uint32
+	for _, u := range ext.Upstreams {
+		total += u.Weight
+	}
+
+	var assignments []weightedLoadAssignment
+	for _, u := range ext.Upstreams {
+		weight := u.Weight
+		if total == 0 {
+			weight = 1
+		}
+		if weight == 0 {
+			continue
+		}
+
+		assignments = append(assignments, weightedLoadAssignment{
+			name: envoy.ClusterLoadAssignmentName(
+				types.NamespacedName{Name: u.Service.Name, Namespace: u.Service.Namespace},
+				u.Service.ServicePort.Name,
+			),
+			weight: weight,
+		})
+	}
+
+	return assignments
+}
+
+// recomputeExtension rebuilds the aggregated ClusterLoadAssignment of
+// the named extension cluster. Each upstream Service becomes a separate
+// locality so that the Service weights can be applied as locality
+// weights. The caller must hold e.mu.
+func (e *EndpointsTranslator) recomputeExtension(name string) {
+	cla := &v2.ClusterLoadAssignment{
+		ClusterName: name,
+	}
+
+	for _, u := range e.extensions[name] {
+		a, ok := e.entries[u.name]
+		if !ok {
+			continue
+		}
+
+		var lbendpoints []*envoy_api_v2_endpoint.LbEndpoint
+		for _, ep := range a.Endpoints {
+			lbendpoints = append(lbendpoints, ep.LbEndpoints...)
+		}
+		if len(lbendpoints) == 0 {
+			continue
+		}
+
+		cla.Endpoints = append(cla.Endpoints, &envoy_api_v2_endpoint.LocalityLbEndpoints{
+			Locality: &envoy_api_v2_core.Locality{
+				SubZone: u.name,
+			},
+			LbEndpoints:         lbendpoints,
+			LoadBalancingWeight: protobuf.UInt32(u.weight),
+		})
+	}
+
+	if e.entries == nil {
+		e.entries = make(map[string]*v2.ClusterLoadAssignment)
+	}
+
+	// Avoid sending a noop notification to watchers.
+	if old, ok := e.entries[name]; ok && proto.Equal(old, cla) {
+		return
+	}
+
+	e.entries[name] = cla
+	e.Notify(name)
+}
+
+// recomputeExtensionsFor rebuilds the aggregated ClusterLoadAssignment
+// of each extension cluster that includes the named ClusterLoadAssignment.
+// The caller must hold e.mu.
+func (e *EndpointsTranslator) recomputeExtensionsFor(name string) {
+	for ext, assignments := range e.extensions {
+		for _, a := range assignments {
+			if a.name == name {
+				e.recomputeExtension(ext)
+				break
+			}
+		}
+	}
 }
 
 func (e *EndpointsTranslator) OnAdd(obj interface{}) {
@@ -135,6 +266,7 @@ func (e *EndpointsTranslator) Add(a *v2.ClusterLoadAssignment) {
 	}
 	e.entries[a.ClusterName] = a
 	e.Notify(a.ClusterName)
+	e.recomputeExtensionsFor(a.ClusterName)
 }
 
 // Remove removes the named entry from the cache. If the entry
@@ -145,6 +277,7 @@ func (e *EndpointsTranslator) Remove(name string) {
 
 	delete(e.entries, name)
 	e.Notify(name)
+	e.recomputeExtensionsFor(name)
 }
 
 // recomputeClusterLoadAssignment recomputes the EDS cache taking into account old and new endpoints.
@@ -175,8 +308,13 @@ func recomputeClusterLoadAssignment(e *EndpointsTranslator, oldep, newep *v1.End
 			continue
 		}
 		for _, p := range s.Ports {
-			if p.Protocol != "TCP" {
-				// skip non TCP ports
+			socketAddress := envoy.SocketAddress
+			switch p.Protocol {
+			case "TCP":
+			case "UDP":
+				socketAddress = envoy.UDPSocketAddress
+			default:
+				// skip non TCP or UDP ports
 				continue
 			}
 
@@ -185,7 +323,7 @@ func recomputeClusterLoadAssignment(e *EndpointsTranslator, oldep, newep *v1.End
 
 			lbendpoints := make([]*envoy_api_v2_endpoint.LbEndpoint, 0, len(addresses))
 			for _, a := range addresses {
-				addr := envoy.SocketAddress(a.IP, int(p.Port))
+				addr := socketAddress(a.IP, int(p.Port))
 				lbendpoints = append(lbendpoints, envoy.LBEndpoint(addr))
 			}
 
@@ -220,6 +220,26 @@ func TestEndpointsTranslatorAddEndpoints(t *testing.T) {
 				),
 			},
 		},
+		"tcp and udp ports": {
+			ep: endpoints("kube-system", "coredns", v1.EndpointSubset{
+				Addresses: addresses(
+					"10.10.1.1",
+				),
+				Ports: ports(
+					port("dns-tcp", 53),
+					v1.EndpointPort{Name: "dns", Port: 53, Protocol: "UDP"},
+					v1.EndpointPort{Name: "sctp", Port: 9000, Protocol: "SCTP"},
+				),
+			}),
+			want: []proto.Message{
+				envoy.ClusterLoadAssignment("kube-system/coredns/dns",
+					envoy.UDPSocketAddress("10.10.1.1", 53),
+				),
+				envoy.ClusterLoadAssignment("kube-system/coredns/dns-tcp",
+					envoy.SocketAddress("10.10.1.1", 53),
+				),
+			},
+		},
 	}
 
 	log := testLogger(t)
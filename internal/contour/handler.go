@@ -27,6 +27,7 @@ import (
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	serviceapis "sigs.k8s.io/service-apis/api/v1alpha1"
 )
 
 // EventHandler implements cache.ResourceEventHandler, filters k8s events towards
@@ -179,6 +180,9 @@ func (e *EventHandler) onUpdate(op interface{}) bool {
 	case opUpdate:
 		if cmp.Equal(op.oldObj, op.newObj,
 			cmpopts.IgnoreFields(projcontour.HTTPProxy{}, "Status"),
+			cmpopts.IgnoreFields(serviceapis.GatewayClass{}, "Status"),
+			cmpopts.IgnoreFields(serviceapis.Gateway{}, "Status"),
+			cmpopts.IgnoreFields(serviceapis.HTTPRoute{}, "Status"),
 			cmpopts.IgnoreFields(metav1.ObjectMeta{}, "ResourceVersion")) {
 			e.WithField("op", "update").Debugf("%T skipping update, only status has changed", op.newObj)
 			return false
@@ -216,6 +220,7 @@ func (e *EventHandler) rebuildDAG() {
 	case <-e.IsLeader:
 		// We're the leader, update resource status.
 		e.setStatus(latestDAG.Statuses())
+		e.setServiceAPIStatus(latestDAG.ServiceAPIStatuses())
 	default:
 		e.Debug("skipping metrics and CRD status update, not leader")
 	}
@@ -226,7 +231,7 @@ func (e *EventHandler) setStatus(statuses map[types.NamespacedName]dag.Status) {
 	for _, st := range statuses {
 		switch obj := st.Object.(type) {
 		case *projcontour.HTTPProxy:
-			err := e.StatusClient.SetStatus(st.Status, st.Description, obj)
+			err := e.StatusClient.SetStatus(st.Status, st.Description, st.Errors, obj)
 			if err != nil {
 				e.WithError(err).
 					WithField("status", st.Status).
@@ -242,3 +247,16 @@ func (e *EventHandler) setStatus(statuses map[types.NamespacedName]dag.Status) {
 		}
 	}
 }
+
+// setServiceAPIStatus updates the status of Service APIs objects.
+func (e *EventHandler) setServiceAPIStatus(objs []k8s.Object) {
+	for _, obj := range objs {
+		if err := e.StatusClient.SetServiceAPIStatus(obj); err != nil {
+			e.WithError(err).
+				WithField("kind", k8s.KindOf(obj)).
+				WithField("name", obj.GetObjectMeta().GetName()).
+				WithField("namespace", obj.GetObjectMeta().GetNamespace()).
+				Error("failed to set status")
+		}
+	}
+}
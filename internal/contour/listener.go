@@ -14,6 +14,7 @@
 package contour
 
 import (
+	"fmt"
 	"path"
 	"sort"
 	"sync"
@@ -22,6 +23,7 @@ import (
 	envoy_api_v2_auth "github.com/envoyproxy/go-control-plane/envoy/api/v2/auth"
 	envoy_api_v2_listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
 	envoy_api_v2_accesslog "github.com/envoyproxy/go-control-plane/envoy/config/filter/accesslog/v2"
+	http "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
 	resource "github.com/envoyproxy/go-control-plane/pkg/resource/v2"
 	"github.com/golang/protobuf/proto"
 	"github.com/projectcontour/contour/internal/dag"
@@ -35,6 +37,8 @@ const (
 	ENVOY_HTTP_LISTENER            = "ingress_http"
 	ENVOY_FALLBACK_ROUTECONFIG     = "ingress_fallbackcert"
 	ENVOY_HTTPS_LISTENER           = "ingress_https"
+	ENVOY_TCP_LISTENER_PREFIX      = "ingress_tcp"
+	ENVOY_UDP_LISTENER_PREFIX      = "ingress_udp"
 	DEFAULT_HTTP_ACCESS_LOG        = "/dev/stdout"
 	DEFAULT_HTTP_LISTENER_ADDRESS  = "0.0.0.0"
 	DEFAULT_HTTP_LISTENER_PORT     = 8080
@@ -78,6 +82,18 @@ type ListenerConfig struct {
 	// MinimumTLSVersion defines the minimum TLS protocol version the proxy should accept.
 	MinimumTLSVersion envoy_api_v2_auth.TlsParameters_TlsProtocol
 
+	// MaximumTLSVersion defines the maximum TLS protocol version the proxy should accept.
+	// If not set, defaults to TLS 1.3.
+	MaximumTLSVersion envoy_api_v2_auth.TlsParameters_TlsProtocol
+
+	// CipherSuites defines the TLS 1.2 cipher suites the proxy should accept.
+	// If not set, defaults to the Contour default cipher suites.
+	CipherSuites []string
+
+	// ECDHCurves defines the ECDH curves the proxy should accept.
+	// If not set, defaults to the Envoy default curves.
+	ECDHCurves []string
+
 	// DefaultHTTPVersions defines the default set of HTTP
 	// versions the proxy should accept. If not specified, all
 	// supported versions are accepted. This is applied to both
@@ -85,8 +101,9 @@ type ListenerConfig struct {
 	// HTTPS, because we don't support h2c.
 	DefaultHTTPVersions []envoy.HTTPVersionType
 
-	// AccessLogType defines if Envoy logs should be output as Envoy's default or JSON.
-	// Valid values: 'envoy', 'json'
+	// AccessLogType defines if Envoy logs should be output as Envoy's default or JSON,
+	// or streamed to the gRPC access log service.
+	// Valid values: 'envoy', 'json', 'grpc'
 	// If not set, defaults to 'envoy'
 	AccessLogType string
 
@@ -185,8 +202,16 @@ func (lvc *ListenerConfig) accesslogFields() []string {
 	return envoy.DefaultFields
 }
 
-func (lvc *ListenerConfig) newInsecureAccessLog() []*envoy_api_v2_accesslog.AccessLog {
+// newInsecureAccessLog returns the access log of the HTTP (non TLS)
+// listener. If the access log type is "grpc" but the access log
+// service could not be resolved, logs are written in Envoy's format.
+func (lvc *ListenerConfig) newInsecureAccessLog(als *dag.AccessLogService) []*envoy_api_v2_accesslog.AccessLog {
 	switch lvc.accesslogType() {
+	case "grpc":
+		if als != nil {
+			return envoy.GRPCAccessLogHTTP(als)
+		}
+		return envoy.FileAccessLogEnvoy(lvc.httpAccessLog())
 	case "json":
 		return envoy.FileAccessLogJSON(lvc.httpAccessLog(), lvc.accesslogFields())
 	default:
@@ -194,8 +219,15 @@ func (lvc *ListenerConfig) newInsecureAccessLog() []*envoy_api_v2_accesslog.Acce
 	}
 }
 
-func (lvc *ListenerConfig) newSecureAccessLog() []*envoy_api_v2_accesslog.AccessLog {
+// newSecureAccessLog returns the access log of the HTTPS (TLS)
+// listener's HTTP connection managers.
+func (lvc *ListenerConfig) newSecureAccessLog(als *dag.AccessLogService) []*envoy_api_v2_accesslog.AccessLog {
 	switch lvc.accesslogType() {
+	case "grpc":
+		if als != nil {
+			return envoy.GRPCAccessLogHTTP(als)
+		}
+		return envoy.FileAccessLogEnvoy(lvc.httpsAccessLog())
 	case "json":
 		return envoy.FileAccessLogJSON(lvc.httpsAccessLog(), lvc.accesslogFields())
 	default:
@@ -203,6 +235,24 @@ func (lvc *ListenerConfig) newSecureAccessLog() []*envoy_api_v2_accesslog.Access
 	}
 }
 
+// newInsecureTCPAccessLog returns the access log of the
+// TCP proxies on dedicated listener ports.
+func (lvc *ListenerConfig) newInsecureTCPAccessLog(als *dag.AccessLogService) []*envoy_api_v2_accesslog.AccessLog {
+	if lvc.accesslogType() == "grpc" && als != nil {
+		return envoy.GRPCAccessLogTCP(als)
+	}
+	return lvc.newInsecureAccessLog(als)
+}
+
+// newSecureTCPAccessLog returns the access log of the HTTPS (TLS)
+// listener's TCP proxies.
+func (lvc *ListenerConfig) newSecureTCPAccessLog(als *dag.AccessLogService) []*envoy_api_v2_accesslog.AccessLog {
+	if lvc.accesslogType() == "grpc" && als != nil {
+		return envoy.GRPCAccessLogTCP(als)
+	}
+	return lvc.newSecureAccessLog(als)
+}
+
 // minTLSVersion returns the requested minimum TLS protocol
 // version or envoy_api_v2_auth.TlsParameters_TLSv1_1 if not configured.
 func (lvc *ListenerConfig) minTLSVersion() envoy_api_v2_auth.TlsParameters_TlsProtocol {
@@ -212,6 +262,15 @@ func (lvc *ListenerConfig) minTLSVersion() envoy_api_v2_auth.TlsParameters_TlsPr
 	return envoy_api_v2_auth.TlsParameters_TLSv1_1
 }
 
+// maxTLSVersion returns the requested maximum TLS protocol
+// version or envoy_api_v2_auth.TlsParameters_TLSv1_3 if not configured.
+func (lvc *ListenerConfig) maxTLSVersion() envoy_api_v2_auth.TlsParameters_TlsProtocol {
+	if lvc.MaximumTLSVersion != envoy_api_v2_auth.TlsParameters_TLS_AUTO {
+		return lvc.MaximumTLSVersion
+	}
+	return envoy_api_v2_auth.TlsParameters_TLSv1_3
+}
+
 // ListenerCache manages the contents of the gRPC LDS cache.
 type ListenerCache struct {
 	mu           sync.Mutex
@@ -294,6 +353,17 @@ type listenerVisitor struct {
 
 	listeners map[string]*v2.Listener
 	http      bool // at least one dag.VirtualHost encountered
+
+	// rateLimitFilters are added to every HTTP connection manager.
+	rateLimitFilters []*http.HttpFilter
+
+	// tracing is the tracing configuration of every
+	// HTTP connection manager, or nil if disabled.
+	tracing *http.HttpConnectionManager_Tracing
+
+	// accessLogService is the gRPC access log service,
+	// or nil if not configured.
+	accessLogService *dag.AccessLogService
 }
 
 func visitListeners(root dag.Vertex, lvc *ListenerConfig) map[string]*v2.Listener {
@@ -308,30 +378,38 @@ func visitListeners(root dag.Vertex, lvc *ListenerConfig) map[string]*v2.Listene
 			),
 		},
 	}
+	lv.rateLimitFilters = rateLimitFilters(root)
+	lv.tracing = envoy.TracingConfig(tracing(root))
+	lv.accessLogService = accessLogService(root)
 
 	lv.visit(root)
 
 	if lv.http {
 		// Add a listener if there are vhosts bound to http.
 		cm := envoy.HTTPConnectionManagerBuilder().
-			Codec(envoy.CodecForVersions(lv.DefaultHTTPVersions...)).
-			DefaultFilters().
+			Codec(envoy.CodecForVersions(lv.DefaultHTTPVersions...))
+
+		for _, f := range lv.rateLimitFilters {
+			cm = cm.AddFilter(f)
+		}
+
+		cm = cm.DefaultFilters().
 			RouteConfigName(ENVOY_HTTP_LISTENER).
 			MetricsPrefix(ENVOY_HTTP_LISTENER).
-			AccessLoggers(lvc.newInsecureAccessLog()).
+			AccessLoggers(lvc.newInsecureAccessLog(lv.accessLogService)).
 			RequestTimeout(lvc.RequestTimeout).
 			ConnectionIdleTimeout(lvc.ConnectionIdleTimeout).
 			StreamIdleTimeout(lvc.StreamIdleTimeout).
 			MaxConnectionDuration(lvc.MaxConnectionDuration).
 			ConnectionShutdownGracePeriod(lvc.ConnectionShutdownGracePeriod).
-			Get()
+			Tracing(lv.tracing)
 
 		lv.listeners[ENVOY_HTTP_LISTENER] = envoy.Listener(
 			ENVOY_HTTP_LISTENER,
 			lvc.httpAddress(),
 			lvc.httpPort(),
 			proxyProtocol(lvc.UseProxyProto),
-			cm,
+			cm.Get(),
 		)
 	}
 
@@ -347,6 +425,72 @@ func visitListeners(root dag.Vertex, lvc *ListenerConfig) map[string]*v2.Listene
 	return lv.listeners
 }
 
+// rateLimitFilters returns the rate limit HTTP filters that the
+// DAG needs. The local rate limit filter requires a newer Envoy than
+// the rest of the configuration, so it is only added when a virtual
+// host or route has a local rate limit policy.
+func rateLimitFilters(root dag.Vertex) []*http.HttpFilter {
+	var local bool
+	var rls *dag.RateLimitService
+
+	hasLocal := func(policy *dag.RateLimitPolicy) bool {
+		return policy != nil && policy.Local != nil
+	}
+
+	var visit func(dag.Vertex)
+	visit = func(vertex dag.Vertex) {
+		switch v := vertex.(type) {
+		case *dag.RateLimitService:
+			rls = v
+		case *dag.VirtualHost:
+			local = local || hasLocal(v.RateLimitPolicy)
+		case *dag.SecureVirtualHost:
+			local = local || hasLocal(v.RateLimitPolicy)
+		case *dag.Route:
+			local = local || hasLocal(v.RateLimitPolicy)
+		}
+		vertex.Visit(visit)
+	}
+	root.Visit(visit)
+
+	var filters []*http.HttpFilter
+	if local {
+		filters = append(filters, envoy.FilterLocalRateLimit())
+	}
+	if rls != nil {
+		filters = append(filters, envoy.FilterGlobalRateLimit(rls))
+	}
+	return filters
+}
+
+// tracing returns the tracing configuration of the DAG,
+// or nil if tracing is not configured.
+func tracing(root dag.Vertex) *dag.Tracing {
+	var t *dag.Tracing
+
+	root.Visit(func(vertex dag.Vertex) {
+		if v, ok := vertex.(*dag.Tracing); ok {
+			t = v
+		}
+	})
+
+	return t
+}
+
+// accessLogService returns the gRPC access log service of
+// the DAG, or nil if the access log service is not configured.
+func accessLogService(root dag.Vertex) *dag.AccessLogService {
+	var als *dag.AccessLogService
+
+	root.Visit(func(vertex dag.Vertex) {
+		if v, ok := vertex.(*dag.AccessLogService); ok {
+			als = v
+		}
+	})
+
+	return als
+}
+
 func proxyProtocol(useProxy bool) []*envoy_api_v2_listener.ListenerFilter {
 	if useProxy {
 		return envoy.ListenerFilters(
@@ -386,19 +530,36 @@ func (v *listenerVisitor) visit(vertex dag.Vertex) {
 			// metrics prefix to keep compatibility with previous
 			// Contour versions since the metrics prefix will be
 			// coded into monitoring dashboards.
+			cm := envoy.HTTPConnectionManagerBuilder().
+				Codec(envoy.CodecForVersions(v.DefaultHTTPVersions...)).
+				AddFilter(envoy.FilterMisdirectedRequests(vh.VirtualHost.Name, vh.VirtualHost.Aliases...))
+
+			// The authorization filter has to run before the
+			// default filters, since they include the router.
+			if vh.AuthorizationService != nil {
+				cm = cm.AddFilter(envoy.FilterExternalAuthz(
+					vh.AuthorizationService.Name,
+					vh.AuthorizationFailOpen,
+					vh.AuthorizationResponseTimeout,
+				))
+			}
+
+			for _, f := range v.rateLimitFilters {
+				cm = cm.AddFilter(f)
+			}
+
 			filters = envoy.Filters(
-				envoy.HTTPConnectionManagerBuilder().
-					Codec(envoy.CodecForVersions(v.DefaultHTTPVersions...)).
-					AddFilter(envoy.FilterMisdirectedRequests(vh.VirtualHost.Name)).
-					DefaultFilters().
+				cm.DefaultFilters().
 					RouteConfigName(path.Join("https", vh.VirtualHost.Name)).
 					MetricsPrefix(ENVOY_HTTPS_LISTENER).
-					AccessLoggers(v.ListenerConfig.newSecureAccessLog()).
+					AccessLoggers(v.ListenerConfig.newSecureAccessLog(v.accessLogService)).
 					RequestTimeout(v.ListenerConfig.RequestTimeout).
 					ConnectionIdleTimeout(v.ListenerConfig.ConnectionIdleTimeout).
 					StreamIdleTimeout(v.ListenerConfig.StreamIdleTimeout).
 					MaxConnectionDuration(v.ListenerConfig.MaxConnectionDuration).
 					ConnectionShutdownGracePeriod(v.ListenerConfig.ConnectionShutdownGracePeriod).
+					Tracing(v.tracing).
+					ForwardClientCertificate(vh.ForwardClientCertificate).
 					Get(),
 			)
 
@@ -407,7 +568,7 @@ func (v *listenerVisitor) visit(vertex dag.Vertex) {
 			filters = envoy.Filters(
 				envoy.TCPProxy(ENVOY_HTTPS_LISTENER,
 					vh.TCPProxy,
-					v.ListenerConfig.newSecureAccessLog()),
+					v.ListenerConfig.newSecureTCPAccessLog(v.accessLogService)),
 			)
 
 			// Do not offer ALPN for TCP proxying, since
@@ -422,15 +583,36 @@ func (v *listenerVisitor) visit(vertex dag.Vertex) {
 			// Choose the higher of the configured or requested TLS version.
 			vers := max(v.ListenerConfig.minTLSVersion(), vh.MinTLSVersion)
 
+			// The requested maximum TLS version overrides the
+			// configured one, but can't be lower than the minimum.
+			maxVers := v.ListenerConfig.maxTLSVersion()
+			if vh.MaxTLSVersion != envoy_api_v2_auth.TlsParameters_TLS_AUTO {
+				maxVers = vh.MaxTLSVersion
+			}
+			maxVers = max(vers, maxVers)
+
+			cipherSuites := v.ListenerConfig.CipherSuites
+			if len(vh.CipherSuites) > 0 {
+				cipherSuites = vh.CipherSuites
+			}
+
+			ecdhCurves := v.ListenerConfig.ECDHCurves
+			if len(vh.ECDHCurves) > 0 {
+				ecdhCurves = vh.ECDHCurves
+			}
+
 			downstreamTLS = envoy.DownstreamTLSContext(
 				vh.Secret,
 				vers,
+				maxVers,
+				cipherSuites,
+				ecdhCurves,
 				vh.DownstreamValidation,
 				alpnProtos...)
 		}
 
 		v.listeners[ENVOY_HTTPS_LISTENER].FilterChains = append(v.listeners[ENVOY_HTTPS_LISTENER].FilterChains,
-			envoy.FilterChainTLS(vh.VirtualHost.Name, downstreamTLS, filters))
+			envoy.FilterChainTLS(vh.VirtualHost.Name, downstreamTLS, filters, vh.VirtualHost.Aliases...))
 
 		// If this VirtualHost has enabled the fallback certificate then set a default
 		// FilterChain which will allow routes with this vhost to accept non-SNI TLS requests.
@@ -443,21 +625,30 @@ func (v *listenerVisitor) visit(vertex dag.Vertex) {
 			downstreamTLS = envoy.DownstreamTLSContext(
 				vh.FallbackCertificate,
 				v.ListenerConfig.minTLSVersion(),
+				max(v.ListenerConfig.minTLSVersion(), v.ListenerConfig.maxTLSVersion()),
+				v.ListenerConfig.CipherSuites,
+				v.ListenerConfig.ECDHCurves,
 				vh.DownstreamValidation,
 				alpnProtos...)
 
 			// Default filter chain
+			cm := envoy.HTTPConnectionManagerBuilder()
+
+			for _, f := range v.rateLimitFilters {
+				cm = cm.AddFilter(f)
+			}
+
 			filters = envoy.Filters(
-				envoy.HTTPConnectionManagerBuilder().
-					DefaultFilters().
+				cm.DefaultFilters().
 					RouteConfigName(ENVOY_FALLBACK_ROUTECONFIG).
 					MetricsPrefix(ENVOY_HTTPS_LISTENER).
-					AccessLoggers(v.ListenerConfig.newSecureAccessLog()).
+					AccessLoggers(v.ListenerConfig.newSecureAccessLog(v.accessLogService)).
 					RequestTimeout(v.ListenerConfig.RequestTimeout).
 					ConnectionIdleTimeout(v.ListenerConfig.ConnectionIdleTimeout).
 					StreamIdleTimeout(v.ListenerConfig.StreamIdleTimeout).
 					MaxConnectionDuration(v.ListenerConfig.MaxConnectionDuration).
 					ConnectionShutdownGracePeriod(v.ListenerConfig.ConnectionShutdownGracePeriod).
+					Tracing(v.tracing).
 					Get(),
 			)
 
@@ -465,6 +656,31 @@ func (v *listenerVisitor) visit(vertex dag.Vertex) {
 				envoy.FilterChainTLSFallback(downstreamTLS, filters))
 		}
 
+	case *dag.TCPListener:
+		// Dedicated listeners are named after their port,
+		// which is also the stat prefix of their TCP proxy.
+		name := fmt.Sprintf("%s_%d", ENVOY_TCP_LISTENER_PREFIX, vh.Port)
+		v.listeners[name] = envoy.Listener(
+			name,
+			v.ListenerConfig.httpAddress(),
+			vh.Port,
+			proxyProtocol(v.ListenerConfig.UseProxyProto),
+			envoy.TCPProxy(name,
+				vh.TCPProxy,
+				v.ListenerConfig.newInsecureTCPAccessLog(v.accessLogService)),
+		)
+
+	case *dag.UDPListener:
+		// The udp_proxy filter of this Envoy API version has
+		// no access log, and proxy protocol does not apply.
+		name := fmt.Sprintf("%s_%d", ENVOY_UDP_LISTENER_PREFIX, vh.Port)
+		v.listeners[name] = envoy.UDPListener(
+			name,
+			v.ListenerConfig.httpAddress(),
+			vh.Port,
+			envoy.UDPProxy(name, vh.Cluster),
+		)
+
 	default:
 		// recurse
 		vertex.Visit(v.visit)
@@ -2015,7 +2015,7 @@ func transportSocket(secretname string, tlsMinProtoVersion envoy_api_v2_auth.Tls
 		},
 	}
 	return envoy.DownstreamTLSTransportSocket(
-		envoy.DownstreamTLSContext(secret, tlsMinProtoVersion, nil, alpnprotos...),
+		envoy.DownstreamTLSContext(secret, tlsMinProtoVersion, envoy_api_v2_auth.TlsParameters_TLSv1_3, nil, nil, nil, alpnprotos...),
 	)
 }
 
@@ -21,7 +21,9 @@ import (
 	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
 	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
 	resource "github.com/envoyproxy/go-control-plane/pkg/resource/v2"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/any"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/envoy"
 	"github.com/projectcontour/contour/internal/protobuf"
@@ -136,9 +138,9 @@ func (v *routeVisitor) onVirtualHost(vh *dag.VirtualHost) {
 			})
 		} else {
 			rt := &envoy_api_v2_route.Route{
-				Match:  envoy.RouteMatch(route),
-				Action: envoy.RouteRoute(route),
+				Match: envoy.RouteMatch(route),
 			}
+			setRouteAction(rt, route)
 			if route.RequestHeadersPolicy != nil {
 				rt.RequestHeadersToAdd = envoy.HeaderValueList(route.RequestHeadersPolicy.Set, false)
 				rt.RequestHeadersToRemove = route.RequestHeadersPolicy.Remove
@@ -147,6 +149,18 @@ func (v *routeVisitor) onVirtualHost(vh *dag.VirtualHost) {
 				rt.ResponseHeadersToAdd = envoy.HeaderValueList(route.ResponseHeadersPolicy.Set, false)
 				rt.ResponseHeadersToRemove = route.ResponseHeadersPolicy.Remove
 			}
+			rt.Tracing = envoy.RouteTracing(route.TracingPolicy)
+			if local := localRateLimitPolicy(route.RateLimitPolicy); local != nil {
+				rt.TypedPerFilterConfig = map[string]*any.Any{
+					envoy.LocalRateLimitFilterName: envoy.LocalRateLimitConfig(local),
+				}
+			}
+			if route.FaultInjectionPolicy != nil {
+				if rt.TypedPerFilterConfig == nil {
+					rt.TypedPerFilterConfig = map[string]*any.Any{}
+				}
+				rt.TypedPerFilterConfig[wellknown.Fault] = envoy.FaultConfig(route.FaultInjectionPolicy)
+			}
 			routes = append(routes, rt)
 		}
 	})
@@ -155,7 +169,7 @@ func (v *routeVisitor) onVirtualHost(vh *dag.VirtualHost) {
 		sortRoutes(routes)
 
 		v.routes[ENVOY_HTTP_LISTENER].VirtualHosts = append(v.routes[ENVOY_HTTP_LISTENER].VirtualHosts,
-			envoy.VirtualHost(vh.Name, routes...))
+			virtualHost(vh, routes))
 	}
 }
 
@@ -169,9 +183,9 @@ func (v *routeVisitor) onSecureVirtualHost(svh *dag.SecureVirtualHost) {
 		}
 
 		rt := &envoy_api_v2_route.Route{
-			Match:  envoy.RouteMatch(route),
-			Action: envoy.RouteRoute(route),
+			Match: envoy.RouteMatch(route),
 		}
+		setRouteAction(rt, route)
 		if route.RequestHeadersPolicy != nil {
 			rt.RequestHeadersToAdd = envoy.HeaderValueList(route.RequestHeadersPolicy.Set, false)
 			rt.RequestHeadersToRemove = route.RequestHeadersPolicy.Remove
@@ -180,6 +194,27 @@ func (v *routeVisitor) onSecureVirtualHost(svh *dag.SecureVirtualHost) {
 			rt.ResponseHeadersToAdd = envoy.HeaderValueList(route.ResponseHeadersPolicy.Set, false)
 			rt.ResponseHeadersToRemove = route.ResponseHeadersPolicy.Remove
 		}
+		rt.Tracing = envoy.RouteTracing(route.TracingPolicy)
+
+		// If authorization is enabled on this host, we may need to set per-route filter overrides.
+		if svh.AuthorizationService != nil {
+			rt.TypedPerFilterConfig = envoy.RouteAuthzTypedConfig(route)
+		}
+
+		if local := localRateLimitPolicy(route.RateLimitPolicy); local != nil {
+			if rt.TypedPerFilterConfig == nil {
+				rt.TypedPerFilterConfig = map[string]*any.Any{}
+			}
+			rt.TypedPerFilterConfig[envoy.LocalRateLimitFilterName] = envoy.LocalRateLimitConfig(local)
+		}
+
+		if route.FaultInjectionPolicy != nil {
+			if rt.TypedPerFilterConfig == nil {
+				rt.TypedPerFilterConfig = map[string]*any.Any{}
+			}
+			rt.TypedPerFilterConfig[wellknown.Fault] = envoy.FaultConfig(route.FaultInjectionPolicy)
+		}
+
 		routes = append(routes, rt)
 	})
 
@@ -193,7 +228,7 @@ func (v *routeVisitor) onSecureVirtualHost(svh *dag.SecureVirtualHost) {
 		}
 
 		v.routes[name].VirtualHosts = append(v.routes[name].VirtualHosts,
-			envoy.VirtualHost(svh.VirtualHost.Name, routes...))
+			virtualHost(&svh.VirtualHost, routes))
 
 		// A fallback route configuration contains routes for all the vhosts that have the fallback certificate enabled.
 		// When a request is received, the default TLS filterchain will accept the connection,
@@ -205,7 +240,7 @@ func (v *routeVisitor) onSecureVirtualHost(svh *dag.SecureVirtualHost) {
 			}
 
 			v.routes[ENVOY_FALLBACK_ROUTECONFIG].VirtualHosts = append(v.routes[ENVOY_FALLBACK_ROUTECONFIG].VirtualHosts,
-				envoy.VirtualHost(svh.Name, routes...))
+				virtualHost(&svh.VirtualHost, routes))
 		}
 	}
 }
@@ -230,13 +265,59 @@ func (v *routeVisitor) visit(vertex dag.Vertex) {
 	}
 }
 
+// setRouteAction sets the action of the Envoy route rt. A route
+// either redirects, responds directly, or forwards to its clusters.
+func setRouteAction(rt *envoy_api_v2_route.Route, route *dag.Route) {
+	switch {
+	case route.Redirect != nil:
+		rt.Action = envoy.RouteRedirect(route.Redirect)
+	case route.DirectResponse != nil:
+		rt.Action = envoy.RouteDirectResponse(route.DirectResponse)
+	default:
+		rt.Action = envoy.RouteRoute(route)
+	}
+}
+
+// virtualHost returns the Envoy virtual host for the given
+// dag.VirtualHost, applying the virtual host rate limit policy.
+func virtualHost(vh *dag.VirtualHost, routes []*envoy_api_v2_route.Route) *envoy_api_v2_route.VirtualHost {
+	evh := envoy.VirtualHost(vh.Name, routes...)
+	for _, alias := range vh.Aliases {
+		evh.Domains = append(evh.Domains, envoy.Domains(alias)...)
+	}
+	evh.Cors = envoy.CORSPolicy(vh.CORSPolicy)
+
+	if vh.RateLimitPolicy != nil {
+		evh.RateLimits = envoy.GlobalRateLimits(vh.RateLimitPolicy.Global)
+
+		if local := vh.RateLimitPolicy.Local; local != nil {
+			evh.TypedPerFilterConfig = map[string]*any.Any{
+				envoy.LocalRateLimitFilterName: envoy.LocalRateLimitConfig(local),
+			}
+		}
+	}
+
+	return evh
+}
+
+// localRateLimitPolicy returns the local rate limit policy of
+// the given rate limit policy, if any.
+func localRateLimitPolicy(policy *dag.RateLimitPolicy) *dag.LocalRateLimitPolicy {
+	if policy == nil {
+		return nil
+	}
+	return policy.Local
+}
+
 // sortRoutes sorts the given Route slice in place. Routes are ordered
-// first by longest prefix (or regex), then by the length of the
-// HeaderMatch slice (if any). The HeaderMatch slice is also ordered
-// by the matching header name.
+// first by exact path, longest regex or longest prefix, then by the
+// length of the HeaderMatch slice (if any), then by the length of the
+// QueryParameterMatch slice (if any). The HeaderMatch and
+// QueryParameterMatch slices are also ordered by the matching name.
 func sortRoutes(routes []*envoy_api_v2_route.Route) {
 	for _, r := range routes {
 		sort.Stable(sorter.For(r.Match.Headers))
+		sort.Stable(sorter.For(r.Match.QueryParameters))
 	}
 
 	sort.Stable(sorter.For(routes))
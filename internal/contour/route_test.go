@@ -2878,6 +2878,21 @@ func TestSortLongestRouteFirst(t *testing.T) {
 			}},
 		},
 
+		// The path and the length of query parameter condition list
+		// are equal, so we should order lexicographically by name.
+		"query parameters sort stably by name": {
+			routes: []*envoy_api_v2_route.Route{{
+				Match: routeQueryParameters("/", "zzz-2", "zzz-1"),
+			}, {
+				Match: routeQueryParameters("/", "aaa-2", "aaa-1"),
+			}},
+			want: []*envoy_api_v2_route.Route{{
+				Match: routeQueryParameters("/", "aaa-1", "aaa-2"),
+			}, {
+				Match: routeQueryParameters("/", "zzz-1", "zzz-2"),
+			}},
+		},
+
 		// If we have multiple conditions on the same header, ensure
 		// that we order on the match type too.
 		"headers order by match type": {
@@ -3015,3 +3030,18 @@ func withMirrorPolicy(route *envoy_api_v2_route.Route_Route, mirror string) *env
 	}}
 	return route
 }
+
+// routeQueryParameters returns a prefix match with a present
+// match for each of the named query parameters.
+func routeQueryParameters(prefix string, names ...string) *envoy_api_v2_route.RouteMatch {
+	match := routePrefix(prefix)
+	for _, name := range names {
+		match.QueryParameters = append(match.QueryParameters, &envoy_api_v2_route.QueryParameterMatcher{
+			Name: name,
+			QueryParameterMatchSpecifier: &envoy_api_v2_route.QueryParameterMatcher_PresentMatch{
+				PresentMatch: true,
+			},
+		})
+	}
+	return match
+}
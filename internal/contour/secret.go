@@ -98,14 +98,30 @@ func (v *secretVisitor) addSecret(s *dag.Secret) {
 	}
 }
 
+func (v *secretVisitor) addCRL(s *dag.Secret) {
+	name := envoy.CRLSecretname(s)
+	if _, ok := v.secrets[name]; !ok {
+		v.secrets[name] = envoy.CRLSecret(s)
+	}
+}
+
 func (v *secretVisitor) visit(vertex dag.Vertex) {
-	switch svh := vertex.(type) {
+	switch vertex := vertex.(type) {
 	case *dag.SecureVirtualHost:
-		if svh.Secret != nil {
-			v.addSecret(svh.Secret)
+		if vertex.Secret != nil {
+			v.addSecret(vertex.Secret)
 		}
-		if svh.FallbackCertificate != nil {
-			v.addSecret(svh.FallbackCertificate)
+		if vertex.FallbackCertificate != nil {
+			v.addSecret(vertex.FallbackCertificate)
+		}
+		if crl := vertex.DownstreamValidation.GetCRL(); crl != nil {
+			v.addCRL(crl)
+		}
+		// Visit the clusters of the vhost for client certificates.
+		vertex.Visit(v.visit)
+	case *dag.Cluster:
+		if vertex.ClientCertificate != nil {
+			v.addSecret(vertex.ClientCertificate)
 		}
 	default:
 		vertex.Visit(v.visit)
@@ -16,6 +16,10 @@ package dag
 import (
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -25,10 +29,13 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
+	envoy_api_v2_auth "github.com/envoyproxy/go-control-plane/envoy/api/v2/auth"
 	"github.com/google/go-cmp/cmp"
 	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	projcontourv1alpha1 "github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
 	"github.com/projectcontour/contour/internal/annotation"
 	"github.com/projectcontour/contour/internal/k8s"
+	"github.com/projectcontour/contour/internal/timeout"
 )
 
 // Builder builds a DAG.
@@ -42,31 +49,187 @@ type Builder struct {
 	// permitInsecure field in HTTPProxy.
 	DisablePermitInsecure bool
 
-	services map[servicemeta]*Service
-	secrets  map[types.NamespacedName]*Secret
+	services   map[servicemeta]*Service
+	secrets    map[types.NamespacedName]*Secret
+	extensions map[types.NamespacedName]*ExtensionCluster
 
 	virtualhosts       map[string]*VirtualHost
 	securevirtualhosts map[string]*SecureVirtualHost
 
+	// proxyHosts maps the hostnames owned by HTTPProxies
+	// to their owner. Ingresses can't use these hostnames.
+	proxyHosts map[string]*projcontour.HTTPProxy
+
+	// ingressHosts maps each Ingress host to its oldest
+	// Ingress. The default host of an Ingress is keyed by "*".
+	ingressHosts map[string]*v1beta1.Ingress
+
+	// ListenerPortRange is the range of ports that HTTPProxies
+	// may bind dedicated TCP and UDP listeners to. If nil,
+	// dedicated listeners are disabled.
+	ListenerPortRange *PortRange
+
+	tcplisteners map[int]*TCPListener
+	udplisteners map[int]*UDPListener
+
+	// listenerPorts maps the dedicated listener ports
+	// to the HTTPProxy that owns them.
+	listenerPorts map[listenerPort]*projcontour.HTTPProxy
+
 	orphaned map[types.NamespacedName]bool
 
 	FallbackCertificate *types.NamespacedName
 
+	// ClientCertificate is the default secret that Envoy presents
+	// to TLS upstream services that request a client certificate.
+	ClientCertificate *types.NamespacedName
+
+	// RateLimitService configures the global rate limit
+	// service. If nil, global rate limiting is disabled.
+	RateLimitService *RateLimitServiceConfig
+
+	rateLimitService    *RateLimitService
+	rateLimitServiceErr error
+
+	// Tracing configures distributed tracing. If nil,
+	// tracing is disabled.
+	Tracing *TracingConfig
+
+	tracing *Tracing
+
+	// AccessLogService configures the gRPC access log
+	// service. If nil, access logs are written to files.
+	AccessLogService *AccessLogServiceConfig
+
+	accessLogService *AccessLogService
+
+	// GatewayController is the controller name of the GatewayClasses
+	// served by this builder. If empty, DefaultGatewayController is used.
+	GatewayController string
+
+	// GatewayHTTPPort and GatewayHTTPSPort are the ports of
+	// the Envoy HTTP and HTTPS listeners, which are the only
+	// ports that Gateway listeners may use. If zero, the
+	// default Envoy listener ports are used.
+	GatewayHTTPPort  int
+	GatewayHTTPSPort int
+
+	// gatewayRoutes maps the host and match conditions of each
+	// route attached to a Gateway listener to the oldest HTTPRoute
+	// that uses them. Newer HTTPRoutes can't replace these routes.
+	gatewayRoutes map[gatewayRouteKey]types.NamespacedName
+
+	// gatewayTLSHosts maps each host served by an HTTPS Gateway
+	// listener to the first listener that serves it.
+	gatewayTLSHosts map[string]gatewayTLSHost
+
+	serviceAPIStatuses map[serviceAPIKey]k8s.Object
+
 	StatusWriter
 }
 
+// PortRange is an inclusive range of ports.
+type PortRange struct {
+	Min int
+	Max int
+}
+
+// Contains returns true if port is within the range.
+func (r *PortRange) Contains(port int) bool {
+	return port >= r.Min && port <= r.Max
+}
+
+// listenerPort is the port and protocol of a dedicated listener.
+type listenerPort struct {
+	port     int
+	protocol v1.Protocol
+}
+
+// RateLimitServiceConfig configures the global rate limit service.
+type RateLimitServiceConfig struct {
+	// ExtensionService names the ExtensionService that
+	// implements the Envoy rate limit service.
+	ExtensionService types.NamespacedName
+
+	// Domain is passed to the rate limit service.
+	Domain string
+
+	// FailOpen allows requests to proceed when the rate
+	// limit service fails to respond with a decision.
+	FailOpen bool
+}
+
+// TracingConfig configures distributed tracing.
+type TracingConfig struct {
+	// Provider is the tracer that Envoy uses.
+	// One of "zipkin" or "opentelemetry".
+	Provider string
+
+	// Service names the Kubernetes Service of the
+	// trace collector, and Port the Service port.
+	Service types.NamespacedName
+	Port    int
+
+	// CollectorEndpoint is the API path of a
+	// Zipkin collector that spans are sent to.
+	CollectorEndpoint string
+
+	// SamplingRate is the percentage of requests
+	// that are traced, between 0 and 100.
+	SamplingRate float64
+
+	// CustomTags are added to every span.
+	CustomTags []TracingCustomTag
+}
+
+// TracingCustomTag adds a tag to a span. The tag value is
+// either a literal or the value of a request header.
+type TracingCustomTag struct {
+	TagName       string
+	Literal       string
+	RequestHeader string
+}
+
+// AccessLogServiceConfig configures the gRPC access log service.
+// Exactly one of ExtensionService or Service is set.
+type AccessLogServiceConfig struct {
+	// ExtensionService names the ExtensionService that
+	// implements the Envoy access log service.
+	ExtensionService types.NamespacedName
+
+	// Service names the Kubernetes Service that implements
+	// the Envoy access log service, and Port the Service port.
+	Service types.NamespacedName
+	Port    int
+
+	// LogName identifies this Envoy's access log stream
+	// to the access log service.
+	LogName string
+}
+
 // Build builds a new DAG.
 func (b *Builder) Build() *DAG {
 	b.reset()
 
+	// resolve the owners of conflicting hostnames before any
+	// virtual hosts are created.
+	proxies := b.validHTTPProxies()
+
 	// setup secure vhosts if there is a matching secret
 	// we do this first so that the set of active secure vhosts is stable
 	// during computeIngresses.
 	b.computeSecureVirtualhosts()
 
+	b.computeRateLimitService()
+
+	b.computeTracing()
+	b.computeAccessLogService()
+
 	b.computeIngresses()
 
-	b.computeHTTPProxies()
+	b.computeHTTPProxies(proxies)
+
+	b.computeGateways()
 
 	return b.buildDAG()
 }
@@ -75,12 +238,122 @@ func (b *Builder) Build() *DAG {
 func (b *Builder) reset() {
 	b.services = make(map[servicemeta]*Service, len(b.services))
 	b.secrets = make(map[types.NamespacedName]*Secret, len(b.secrets))
+	b.extensions = make(map[types.NamespacedName]*ExtensionCluster, len(b.extensions))
 	b.orphaned = make(map[types.NamespacedName]bool, len(b.orphaned))
 
 	b.virtualhosts = make(map[string]*VirtualHost)
 	b.securevirtualhosts = make(map[string]*SecureVirtualHost)
+	b.proxyHosts = make(map[string]*projcontour.HTTPProxy)
+	b.ingressHosts = make(map[string]*v1beta1.Ingress)
+	b.tcplisteners = make(map[int]*TCPListener)
+	b.udplisteners = make(map[int]*UDPListener)
+	b.listenerPorts = make(map[listenerPort]*projcontour.HTTPProxy)
 
 	b.statuses = make(map[types.NamespacedName]Status, len(b.statuses))
+	b.gatewayRoutes = make(map[gatewayRouteKey]types.NamespacedName)
+	b.gatewayTLSHosts = make(map[string]gatewayTLSHost)
+	b.serviceAPIStatuses = make(map[serviceAPIKey]k8s.Object, len(b.serviceAPIStatuses))
+
+	b.rateLimitService = nil
+	b.rateLimitServiceErr = nil
+	b.tracing = nil
+	b.accessLogService = nil
+}
+
+// computeRateLimitService resolves the ExtensionService of the
+// global rate limit service, if one is configured.
+func (b *Builder) computeRateLimitService() {
+	if b.RateLimitService == nil {
+		b.rateLimitServiceErr = errors.New("no global rate limit service is configured")
+		return
+	}
+
+	ext, err := b.lookupExtensionCluster(b.RateLimitService.ExtensionService)
+	if err != nil {
+		b.rateLimitServiceErr = fmt.Errorf("global rate limit service is invalid: %s", err)
+		return
+	}
+
+	b.rateLimitService = &RateLimitService{
+		Cluster:  ext,
+		Domain:   b.RateLimitService.Domain,
+		FailOpen: b.RateLimitService.FailOpen,
+		Timeout:  ext.TimeoutPolicy.ResponseTimeout,
+	}
+}
+
+// computeTracing resolves the collector Service of the tracing
+// configuration, if one is configured. Tracing stays disabled
+// until the collector Service exists.
+func (b *Builder) computeTracing() {
+	if b.Tracing == nil {
+		return
+	}
+
+	s, err := b.lookupService(b.Tracing.Service, intstr.FromInt(b.Tracing.Port))
+	if err != nil {
+		b.Source.WithField("name", b.Tracing.Service.Name).
+			WithField("namespace", b.Tracing.Service.Namespace).
+			WithField("error", err.Error()).
+			Errorf("invalid tracing collector Service %q, tracing is disabled", b.Tracing.Service)
+		return
+	}
+
+	c := &Cluster{
+		Upstream: s,
+	}
+	if b.Tracing.Provider == "opentelemetry" {
+		// The OpenCensus exporter speaks gRPC.
+		c.Protocol = "h2c"
+	}
+
+	b.tracing = &Tracing{
+		Provider:          b.Tracing.Provider,
+		Cluster:           c,
+		CollectorEndpoint: b.Tracing.CollectorEndpoint,
+		SamplingRate:      b.Tracing.SamplingRate,
+		CustomTags:        b.Tracing.CustomTags,
+	}
+}
+
+// computeAccessLogService resolves the ExtensionService or Service
+// of the access log service configuration, if one is configured.
+func (b *Builder) computeAccessLogService() {
+	if b.AccessLogService == nil {
+		return
+	}
+
+	als := &AccessLogService{
+		LogName: b.AccessLogService.LogName,
+	}
+
+	if b.AccessLogService.ExtensionService.Name != "" {
+		ext, err := b.lookupExtensionCluster(b.AccessLogService.ExtensionService)
+		if err != nil {
+			b.Source.WithField("name", b.AccessLogService.ExtensionService.Name).
+				WithField("namespace", b.AccessLogService.ExtensionService.Namespace).
+				WithField("error", err.Error()).
+				Errorf("invalid access log ExtensionService %q, gRPC access logging is disabled", b.AccessLogService.ExtensionService)
+			return
+		}
+		als.ExtensionCluster = ext
+	} else {
+		s, err := b.lookupService(b.AccessLogService.Service, intstr.FromInt(b.AccessLogService.Port))
+		if err != nil {
+			b.Source.WithField("name", b.AccessLogService.Service.Name).
+				WithField("namespace", b.AccessLogService.Service.Namespace).
+				WithField("error", err.Error()).
+				Errorf("invalid access log Service %q, gRPC access logging is disabled", b.AccessLogService.Service)
+			return
+		}
+		// The access log service speaks gRPC.
+		als.Cluster = &Cluster{
+			Upstream: s,
+			Protocol: "h2c",
+		}
+	}
+
+	b.accessLogService = als
 }
 
 // lookupService returns a Service that matches the Meta and Port of the Kubernetes' Service,
@@ -119,8 +392,30 @@ func (b *Builder) lookupService(m types.NamespacedName, port intstr.IntOrString)
 	return nil, fmt.Errorf("port %q on service %q not matched", port.String(), m)
 }
 
+// lookupUDPService returns the Service of the given UDP port of
+// a Kubernetes Service. UDP Services are not cached, since their
+// port number can be shared with a TCP port of the same Service.
+func (b *Builder) lookupUDPService(m types.NamespacedName, port int) (*Service, error) {
+	svc, ok := b.Source.services[m]
+	if !ok {
+		return nil, fmt.Errorf("service %q not found", m)
+	}
+	for _, p := range svc.Spec.Ports {
+		if int(p.Port) == port && p.Protocol == v1.ProtocolUDP {
+			return newService(svc, p), nil
+		}
+	}
+	return nil, fmt.Errorf("UDP port %d on service %q not matched", port, m)
+}
+
 func (b *Builder) addService(svc *v1.Service, port v1.ServicePort) *Service {
-	s := &Service{
+	s := newService(svc, port)
+	b.services[s.ToFullName()] = s
+	return s
+}
+
+func newService(svc *v1.Service, port v1.ServicePort) *Service {
+	return &Service{
 		Name:        svc.Name,
 		Namespace:   svc.Namespace,
 		ServicePort: port,
@@ -132,8 +427,6 @@ func (b *Builder) addService(svc *v1.Service, port v1.ServicePort) *Service {
 		MaxRetries:         annotation.MaxRetries(svc),
 		ExternalName:       externalName(svc),
 	}
-	b.services[s.ToFullName()] = s
-	return s
 }
 
 func upstreamProtocol(svc *v1.Service, port v1.ServicePort) string {
@@ -145,6 +438,94 @@ func upstreamProtocol(svc *v1.Service, port v1.ServicePort) string {
 	return protocol
 }
 
+// lookupExtensionCluster returns the ExtensionCluster for the
+// named ExtensionService, or an error if the ExtensionService
+// can't be located or is not valid.
+func (b *Builder) lookupExtensionCluster(m types.NamespacedName) (*ExtensionCluster, error) {
+	if ext, ok := b.extensions[m]; ok {
+		return ext, nil
+	}
+
+	es, ok := b.Source.extensions[m]
+	if !ok {
+		return nil, fmt.Errorf("extension service %q not found", m)
+	}
+
+	switch es.Spec.ProtocolVersion {
+	case "", projcontourv1alpha1.SupportProtocolVersion2:
+	default:
+		return nil, fmt.Errorf("extension service %q: unsupported protocol version %q", m, es.Spec.ProtocolVersion)
+	}
+
+	lbPolicy := loadBalancerPolicy(es.Spec.LoadBalancerPolicy)
+	switch lbPolicy {
+	case "Cookie", "RequestHash", "Maglev":
+		return nil, fmt.Errorf("extension service %q: load balancer policy %q is not supported", m, lbPolicy)
+	}
+
+	ext := &ExtensionCluster{
+		Name:               extensionClusterName(m),
+		LoadBalancerPolicy: lbPolicy,
+		TimeoutPolicy:      timeoutPolicy(es.Spec.TimeoutPolicy),
+	}
+
+	for i, service := range es.Spec.Services {
+		if service.Port < 1 || service.Port > 65535 {
+			return nil, fmt.Errorf("extension service %q: service %q: port must be in the range 1-65535", m, service.Name)
+		}
+
+		s, err := b.lookupService(types.NamespacedName{Name: service.Name, Namespace: es.Namespace}, intstr.FromInt(service.Port))
+		if err != nil {
+			return nil, fmt.Errorf("extension service %q: unresolved service reference: %s", m, err)
+		}
+
+		// GRPC requires HTTP/2, so the protocol defaults to
+		// cleartext HTTP/2 and TLS is only h2.
+		protocol, err := getProtocol(service, s)
+		if err != nil {
+			return nil, fmt.Errorf("extension service %q: %s", m, err)
+		}
+		switch protocol {
+		case "":
+			protocol = "h2c"
+		case "h2", "h2c":
+		default:
+			return nil, fmt.Errorf("extension service %q: service %q: protocol %q is not supported for GRPC extensions", m, service.Name, protocol)
+		}
+
+		var uv *PeerValidationContext
+		if protocol == "h2" {
+			uv, err = b.lookupUpstreamValidation(service.UpstreamValidation, es.Namespace)
+			if err != nil {
+				return nil, fmt.Errorf("extension service %q: service %q: TLS upstream validation policy error: %s", m, service.Name, err)
+			}
+		}
+
+		// All the Services in an ExtensionService share a single
+		// Envoy cluster, so they have to be reached the same way.
+		if i == 0 {
+			ext.Protocol = protocol
+			ext.UpstreamValidation = uv
+		} else if ext.Protocol != protocol || !cmp.Equal(ext.UpstreamValidation, uv) {
+			return nil, fmt.Errorf("extension service %q: all services must use the same protocol and upstream validation", m)
+		}
+
+		ext.Upstreams = append(ext.Upstreams, WeightedService{
+			Weight:  uint32(service.Weight),
+			Service: s,
+		})
+	}
+
+	b.extensions[m] = ext
+	return ext, nil
+}
+
+// extensionClusterName returns the name of the Envoy cluster
+// for the named ExtensionService.
+func extensionClusterName(m types.NamespacedName) string {
+	return strings.Join([]string{"extension", m.Namespace, m.Name}, "/")
+}
+
 // lookupSecret returns a Secret if present or nil if the underlying kubernetes
 // secret fails validation or is missing.
 func (b *Builder) lookupSecret(m types.NamespacedName, validate func(*v1.Secret) error) (*Secret, error) {
@@ -194,40 +575,128 @@ func (b *Builder) lookupSecureVirtualHost(name string) *SecureVirtualHost {
 // validHTTPProxies returns a slice of *projcontour.HTTPProxy objects.
 // invalid HTTPProxy objects are excluded from the slice and their status
 // updated accordingly.
+//
+// Each fqdn and alias is owned by the oldest HTTPProxy or Ingress that
+// uses it, so that a newer object can't take a hostname away from an
+// existing one. Newer HTTPProxies that reuse a hostname are invalid,
+// and the hostnames owned by HTTPProxies are recorded in b.proxyHosts
+// so that Ingresses skip them.
 func (b *Builder) validHTTPProxies() []*projcontour.HTTPProxy {
-	// ensure that a given fqdn is only referenced in a single HTTPProxy resource
+	for _, ing := range b.Source.ingresses {
+		for _, host := range ingressHostNames(ing) {
+			if other, ok := b.ingressHosts[host]; !ok || olderThan(ing, other) {
+				b.ingressHosts[host] = ing
+			}
+		}
+	}
+
 	var valid []*projcontour.HTTPProxy
-	fqdnHTTPProxies := make(map[string][]*projcontour.HTTPProxy)
+	var roots []*projcontour.HTTPProxy
 	for _, proxy := range b.Source.httpproxies {
-		if proxy.Spec.VirtualHost == nil {
+		// Proxies with a dedicated listener claim a port
+		// rather than hostnames.
+		if proxy.Spec.VirtualHost == nil || proxy.Spec.Listener != nil {
 			valid = append(valid, proxy)
 			continue
 		}
-		fqdnHTTPProxies[proxy.Spec.VirtualHost.Fqdn] = append(fqdnHTTPProxies[proxy.Spec.VirtualHost.Fqdn], proxy)
+		roots = append(roots, proxy)
 	}
 
-	for fqdn, proxies := range fqdnHTTPProxies {
-		switch len(proxies) {
-		case 1:
-			valid = append(valid, proxies[0])
-		default:
-			// multiple irs use the same fqdn. mark them as invalid.
-			var conflicting []string
-			for _, proxy := range proxies {
-				conflicting = append(conflicting, proxy.Namespace+"/"+proxy.Name)
-			}
-			sort.Strings(conflicting) // sort for test stability
-			msg := fmt.Sprintf("fqdn %q is used in multiple HTTPProxies: %s", fqdn, strings.Join(conflicting, ", "))
-			for _, proxy := range proxies {
-				sw, commit := b.WithObject(proxy)
-				sw.WithValue("vhost", fqdn).SetInvalid(msg)
-				commit()
+	// Visit the roots from oldest to newest so that the oldest
+	// claims each hostname.
+	sort.Slice(roots, func(i, j int) bool {
+		return olderThan(roots[i], roots[j])
+	})
+
+	for _, proxy := range roots {
+		// Proxies outside the root namespaces are invalid,
+		// so they don't claim any hostnames.
+		if !b.rootAllowed(proxy.Namespace) {
+			valid = append(valid, proxy)
+			continue
+		}
+
+		names := virtualHostNames(proxy.Spec.VirtualHost)
+		if msg := b.hostConflict(proxy, names); msg != "" {
+			sw, commit := b.WithObject(proxy)
+			sw.WithValue("vhost", proxy.Spec.VirtualHost.Fqdn).SetInvalid(msg)
+			commit()
+			continue
+		}
+
+		for _, fqdn := range names {
+			if !isBlank(fqdn) {
+				b.proxyHosts[fqdn] = proxy
 			}
 		}
+		valid = append(valid, proxy)
 	}
 	return valid
 }
 
+// hostConflict returns a description of the first of the names that
+// is already owned by an older HTTPProxy or Ingress, or "" if there
+// is no conflict.
+func (b *Builder) hostConflict(proxy *projcontour.HTTPProxy, names []string) string {
+	for _, fqdn := range names {
+		if owner, ok := b.proxyHosts[fqdn]; ok {
+			return fmt.Sprintf("fqdn %q is already used by HTTPProxy %s/%s", fqdn, owner.Namespace, owner.Name)
+		}
+		if ing, ok := b.ingressHosts[fqdn]; ok && olderThan(ing, proxy) {
+			return fmt.Sprintf("fqdn %q is already used by Ingress %s/%s", fqdn, ing.Namespace, ing.Name)
+		}
+	}
+	return ""
+}
+
+// ingressHostNames returns the hostnames of the rules and TLS
+// configuration of ing. The default host is returned as "*".
+func ingressHostNames(ing *v1beta1.Ingress) []string {
+	var hosts []string
+	if ing.Spec.Backend != nil {
+		hosts = append(hosts, "*")
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.Host != "" {
+			hosts = append(hosts, rule.Host)
+		} else {
+			hosts = append(hosts, "*")
+		}
+	}
+	for _, tls := range ing.Spec.TLS {
+		hosts = append(hosts, tls.Hosts...)
+	}
+	return hosts
+}
+
+// olderThan returns true if a was created before b. Objects created
+// at the same time are ordered by namespace and name, so that the
+// result is deterministic.
+func olderThan(a, b k8s.Object) bool {
+	ma, mb := a.GetObjectMeta(), b.GetObjectMeta()
+	ta, tb := ma.GetCreationTimestamp(), mb.GetCreationTimestamp()
+	if !ta.Equal(&tb) {
+		return ta.Before(&tb)
+	}
+	if ma.GetNamespace() != mb.GetNamespace() {
+		return ma.GetNamespace() < mb.GetNamespace()
+	}
+	return ma.GetName() < mb.GetName()
+}
+
+// virtualHostNames returns the distinct fqdn and aliases of vhost.
+func virtualHostNames(vhost *projcontour.VirtualHost) []string {
+	names := []string{vhost.Fqdn}
+	seen := map[string]bool{vhost.Fqdn: true}
+	for _, alias := range vhost.Aliases {
+		if !seen[alias] {
+			seen[alias] = true
+			names = append(names, alias)
+		}
+	}
+	return names
+}
+
 // computeSecureVirtualhosts populates tls parameters of
 // secure virtual hosts.
 func (b *Builder) computeSecureVirtualhosts() {
@@ -255,6 +724,9 @@ func (b *Builder) computeSecureVirtualhosts() {
 			// ahead and create the SecureVirtualHost for this
 			// Ingress.
 			for _, host := range tls.Hosts {
+				if !b.ingressHostPermitted(ing, host) {
+					continue
+				}
 				svhost := b.lookupSecureVirtualHost(host)
 				svhost.Secret = sec
 				svhost.MinTLSVersion = annotation.MinTLSVersion(
@@ -319,6 +791,9 @@ func (b *Builder) computeIngressRule(ing *v1beta1.Ingress, rule v1beta1.IngressR
 		// if host name is blank, rewrite to Envoy's * default host.
 		host = "*"
 	}
+	if !b.ingressHostPermitted(ing, host) {
+		return
+	}
 	for _, httppath := range httppaths(rule) {
 		path := stringOrDefault(httppath.Path, "/")
 		be := httppath.Backend
@@ -345,10 +820,123 @@ func (b *Builder) computeIngressRule(ing *v1beta1.Ingress, rule v1beta1.IngressR
 	}
 }
 
-func (b *Builder) computeHTTPProxies() {
-	for _, proxy := range b.validHTTPProxies() {
+// ingressHostPermitted returns false, and logs the conflict, if
+// host is owned by an older HTTPProxy.
+func (b *Builder) ingressHostPermitted(ing *v1beta1.Ingress, host string) bool {
+	owner, ok := b.proxyHosts[host]
+	if !ok {
+		return true
+	}
+	b.Source.WithField("name", ing.GetName()).
+		WithField("namespace", ing.GetNamespace()).
+		Errorf("host %q is already used by HTTPProxy %s/%s", host, owner.Namespace, owner.Name)
+	return false
+}
+
+func (b *Builder) computeHTTPProxies(proxies []*projcontour.HTTPProxy) {
+	var listeners []*projcontour.HTTPProxy
+	for _, proxy := range proxies {
+		if proxy.Spec.Listener != nil {
+			listeners = append(listeners, proxy)
+			continue
+		}
 		b.computeHTTPProxy(proxy)
 	}
+
+	// Visit the listener proxies from oldest to newest so
+	// that the oldest claims each port.
+	sort.Slice(listeners, func(i, j int) bool {
+		return olderThan(listeners[i], listeners[j])
+	})
+	for _, proxy := range listeners {
+		b.computeListener(proxy)
+	}
+}
+
+// computeListener builds the dedicated TCP or UDP
+// listener of an HTTPProxy that has a Spec.Listener.
+func (b *Builder) computeListener(proxy *projcontour.HTTPProxy) {
+	sw, commit := b.WithObject(proxy)
+	defer commit()
+
+	if !b.rootAllowed(proxy.Namespace) {
+		sw.SetInvalid("root HTTPProxy cannot be defined in this namespace")
+		return
+	}
+
+	switch {
+	case proxy.Spec.VirtualHost != nil:
+		sw.SetInvalid("Spec.Listener cannot be combined with Spec.VirtualHost")
+		return
+	case len(proxy.Spec.Routes) > 0 || len(proxy.Spec.Includes) > 0:
+		sw.SetInvalid("Spec.Listener cannot be combined with Spec.Routes or Spec.Includes")
+		return
+	case proxy.Spec.TCPProxy != nil && proxy.Spec.UDPProxy != nil:
+		sw.SetInvalid("Spec.TCPProxy cannot be combined with Spec.UDPProxy")
+		return
+	case proxy.Spec.TCPProxy == nil && proxy.Spec.UDPProxy == nil:
+		sw.SetInvalid("Spec.Listener requires that either Spec.TCPProxy or Spec.UDPProxy be set")
+		return
+	}
+
+	lp := listenerPort{port: proxy.Spec.Listener.Port, protocol: v1.ProtocolTCP}
+	if proxy.Spec.UDPProxy != nil {
+		lp.protocol = v1.ProtocolUDP
+	}
+
+	if b.ListenerPortRange == nil {
+		sw.SetInvalid("Spec.Listener: dedicated listener ports are not enabled")
+		return
+	}
+	if !b.ListenerPortRange.Contains(lp.port) {
+		sw.SetInvalid("Spec.Listener port %d is outside the permitted range %d-%d",
+			lp.port, b.ListenerPortRange.Min, b.ListenerPortRange.Max)
+		return
+	}
+	if owner, ok := b.listenerPorts[lp]; ok {
+		sw.SetInvalid("Spec.Listener %s port %d is already used by HTTPProxy %s/%s", lp.protocol, lp.port, owner.Namespace, owner.Name)
+		return
+	}
+
+	if lp.protocol == v1.ProtocolUDP {
+		cluster, err := b.udpProxyCluster(proxy)
+		if err != nil {
+			sw.SetInvalid("Spec.UDPProxy: %s", err)
+			return
+		}
+		b.udplisteners[lp.port] = &UDPListener{
+			Port:    lp.port,
+			Cluster: cluster,
+		}
+	} else {
+		tcpproxy, ok := b.processHTTPProxyTCPProxy(sw, proxy, nil)
+		if !ok {
+			return
+		}
+		b.tcplisteners[lp.port] = &TCPListener{
+			Port:     lp.port,
+			TCPProxy: tcpproxy,
+		}
+	}
+
+	b.listenerPorts[lp] = proxy
+	sw.SetValid()
+}
+
+// udpProxyCluster returns the Cluster of the UDP
+// Service port that an HTTPProxy forwards datagrams to.
+func (b *Builder) udpProxyCluster(proxy *projcontour.HTTPProxy) (*Cluster, error) {
+	udpproxy := proxy.Spec.UDPProxy
+	m := types.NamespacedName{Name: udpproxy.Service.Name, Namespace: proxy.Namespace}
+	s, err := b.lookupUDPService(m, udpproxy.Service.Port)
+	if err != nil {
+		return nil, fmt.Errorf("unresolved service reference: %s", err)
+	}
+
+	return &Cluster{
+		Upstream:           s,
+		LoadBalancerPolicy: loadBalancerPolicy(udpproxy.LoadBalancerPolicy),
+	}, nil
 }
 
 func (b *Builder) computeHTTPProxy(proxy *projcontour.HTTPProxy) {
@@ -367,14 +955,25 @@ func (b *Builder) computeHTTPProxy(proxy *projcontour.HTTPProxy) {
 		return
 	}
 
+	if proxy.Spec.UDPProxy != nil {
+		sw.SetInvalid("Spec.UDPProxy requires that Spec.Listener be set")
+		return
+	}
+
 	host := proxy.Spec.VirtualHost.Fqdn
 	if isBlank(host) {
 		sw.SetInvalid("Spec.VirtualHost.Fqdn must be specified")
 		return
 	}
 	sw = sw.WithValue("vhost", host)
-	if strings.Contains(host, "*") {
-		sw.SetInvalid("Spec.VirtualHost.Fqdn %q cannot use wildcards", host)
+	if !validFqdnWildcard(host) {
+		sw.SetInvalid("Spec.VirtualHost.Fqdn %q has an invalid wildcard", host)
+		return
+	}
+
+	aliases := proxy.Spec.VirtualHost.Aliases
+	if err := validAliases(host, aliases); err != nil {
+		sw.SetInvalid("Spec.VirtualHost.Aliases is invalid: %s", err)
 		return
 	}
 
@@ -404,9 +1003,37 @@ func (b *Builder) computeHTTPProxy(proxy *projcontour.HTTPProxy) {
 				return
 			}
 
+			// The certificate is shared by all the names of the
+			// vhost, so it must be valid for each of them.
+			if len(aliases) > 0 {
+				if err := validateCertificateNames(sec.Cert(), append([]string{host}, aliases...)); err != nil {
+					sw.SetInvalid("Spec.VirtualHost.TLS Secret %q is invalid: %s", tls.SecretName, err)
+					return
+				}
+			}
+
 			svhost := b.lookupSecureVirtualHost(host)
 			svhost.Secret = sec
 			svhost.MinTLSVersion = annotation.MinTLSVersion(tls.MinimumProtocolVersion)
+			svhost.MaxTLSVersion = annotation.MaxTLSVersion(tls.MaximumProtocolVersion)
+
+			if svhost.MaxTLSVersion != envoy_api_v2_auth.TlsParameters_TLS_AUTO && svhost.MaxTLSVersion < svhost.MinTLSVersion {
+				sw.SetInvalid("Spec.VirtualHost.TLS maximum protocol version %q is lower than the minimum protocol version %q",
+					tls.MaximumProtocolVersion, tls.MinimumProtocolVersion)
+				return
+			}
+
+			if err := ValidateCipherSuites(tls.CipherSuites); err != nil {
+				sw.SetInvalid("Spec.VirtualHost.TLS.CipherSuites is invalid: %s", err)
+				return
+			}
+			svhost.CipherSuites = tls.CipherSuites
+
+			if err := ValidateECDHCurves(tls.ECDHCurves); err != nil {
+				sw.SetInvalid("Spec.VirtualHost.TLS.ECDHCurves is invalid: %s", err)
+				return
+			}
+			svhost.ECDHCurves = tls.ECDHCurves
 
 			// Check if FallbackCertificate && ClientValidation are both enabled in the same vhost
 			if tls.EnableFallbackCertificate && tls.ClientValidation != nil {
@@ -444,9 +1071,26 @@ func (b *Builder) computeHTTPProxy(proxy *projcontour.HTTPProxy) {
 				}
 				svhost.DownstreamValidation = dv
 			}
+
+			if tls.ForwardClientCertificate != nil {
+				if proxy.Spec.TCPProxy != nil {
+					sw.SetInvalid("Spec.VirtualHost.TLS.ForwardClientCertificate cannot be combined with Spec.TCPProxy")
+					return
+				}
+
+				fcc, err := forwardClientCertificate(tls.ForwardClientCertificate, tls.ClientValidation != nil)
+				if err != nil {
+					sw.SetInvalid("Spec.VirtualHost.TLS.ForwardClientCertificate is invalid: %s", err)
+					return
+				}
+				svhost.ForwardClientCertificate = fcc
+			}
 		} else if tls.ClientValidation != nil {
 			sw.SetInvalid("Spec.VirtualHost.TLS passthrough cannot be combined with tls.clientValidation")
 			return
+		} else if tls.ForwardClientCertificate != nil {
+			sw.SetInvalid("Spec.VirtualHost.TLS passthrough cannot be combined with tls.forwardClientCertificate")
+			return
 		}
 	}
 
@@ -455,19 +1099,98 @@ func (b *Builder) computeHTTPProxy(proxy *projcontour.HTTPProxy) {
 			sw.SetInvalid("Spec.TCPProxy requires that either Spec.TLS.Passthrough or Spec.TLS.SecretName be set")
 			return
 		}
-		if !b.processHTTPProxyTCPProxy(sw, proxy, nil, host) {
+		tcpproxy, ok := b.processHTTPProxyTCPProxy(sw, proxy, nil)
+		if !ok {
 			return
 		}
+		svhost := b.lookupSecureVirtualHost(host)
+		svhost.TCPProxy = tcpproxy
+		svhost.Aliases = aliases
 	}
 
-	routes := b.computeRoutes(sw, proxy, nil, nil, tlsEnabled)
+	if auth := proxy.Spec.VirtualHost.Authorization; auth != nil {
+		tls := proxy.Spec.VirtualHost.TLS
+		if tls == nil || isBlank(tls.SecretName) {
+			sw.SetInvalid("Spec.VirtualHost.Authorization requires that Spec.VirtualHost.TLS.SecretName be set")
+			return
+		}
+		if tls.EnableFallbackCertificate {
+			sw.SetInvalid("Spec.VirtualHost.Authorization cannot be combined with Spec.VirtualHost.TLS.EnableFallbackCertificate")
+			return
+		}
+		if proxy.Spec.TCPProxy != nil {
+			sw.SetInvalid("Spec.VirtualHost.Authorization cannot be combined with Spec.TCPProxy")
+			return
+		}
+
+		ref := auth.ExtensionServiceRef
+		if ref.APIVersion != "" && ref.APIVersion != projcontourv1alpha1.GroupVersion.String() {
+			sw.SetInvalid("Spec.VirtualHost.Authorization.ExtensionServiceRef: unsupported API version %q", ref.APIVersion)
+			return
+		}
+
+		ext, err := b.lookupExtensionCluster(types.NamespacedName{
+			Namespace: stringOrDefault(ref.Namespace, proxy.Namespace),
+			Name:      ref.Name,
+		})
+		if err != nil {
+			sw.SetInvalid("Spec.VirtualHost.Authorization.ExtensionServiceRef is invalid: %s", err)
+			return
+		}
+
+		svhost := b.lookupSecureVirtualHost(host)
+		svhost.AuthorizationService = ext
+		svhost.AuthorizationResponseTimeout = timeout.Parse(auth.ResponseTimeout)
+		if svhost.AuthorizationResponseTimeout.UseDefault() {
+			// Fall back to the response timeout of the extension service.
+			svhost.AuthorizationResponseTimeout = ext.TimeoutPolicy.ResponseTimeout
+		}
+		svhost.AuthorizationFailOpen = auth.FailOpen
+	}
+
+	rlp, err := rateLimitPolicy(proxy.Spec.VirtualHost.RateLimitPolicy)
+	if err != nil {
+		sw.SetInvalid("Spec.VirtualHost.RateLimitPolicy is invalid: %s", err)
+		return
+	}
+	if rlp != nil && rlp.Global != nil && b.rateLimitServiceErr != nil {
+		sw.SetInvalid("Spec.VirtualHost.RateLimitPolicy.Global: %s", b.rateLimitServiceErr)
+		return
+	}
+
+	cp, err := corsPolicy(proxy.Spec.VirtualHost.CORSPolicy)
+	if err != nil {
+		sw.SetInvalidWithError(projcontour.ConditionTypeCORSError, "PolicyDidNotParse",
+			"Spec.VirtualHost.CORSPolicy: %s", err)
+		return
+	}
+
+	routes := b.computeRoutes(sw, proxy, proxy, nil, nil, tlsEnabled)
+
+	// Routes that permit insecure requests would bypass the
+	// authorization server, since it is only attached to the
+	// secure listener.
+	if proxy.Spec.VirtualHost.Authorization != nil {
+		for _, r := range routes {
+			if !r.HTTPSUpgrade && !r.AuthDisabled {
+				sw.SetInvalid("Spec.VirtualHost.Authorization: route %q permits insecure requests but does not disable authorization", r.PathMatchCondition)
+				return
+			}
+		}
+	}
 	insecure := b.lookupVirtualHost(host)
+	insecure.Aliases = aliases
+	insecure.RateLimitPolicy = rlp
+	insecure.CORSPolicy = cp
 	addRoutes(insecure, routes)
 
 	// if TLS is enabled for this virtual host and there is no tcp proxy defined,
 	// then add routes to the secure virtualhost definition.
 	if tlsEnabled && proxy.Spec.TCPProxy == nil {
 		secure := b.lookupSecureVirtualHost(host)
+		secure.Aliases = aliases
+		secure.RateLimitPolicy = rlp
+		secure.CORSPolicy = cp
 		addRoutes(secure, routes)
 	}
 }
@@ -508,6 +1231,7 @@ func expandPrefixMatches(routes []*Route) []*Route {
 		// If there is no path prefix, we won't do any expansion, so skip it.
 		if !r.HasPathPrefix() {
 			expandedRoutes = append(expandedRoutes, r)
+			continue
 		}
 
 		routingPrefix := r.PathMatchCondition.(*PrefixMatchCondition).Prefix
@@ -591,7 +1315,7 @@ func getProtocol(service projcontour.Service, s *Service) (string, error) {
 	return protocol, nil
 }
 
-func (b *Builder) computeRoutes(sw *ObjectStatusWriter, proxy *projcontour.HTTPProxy, conditions []projcontour.MatchCondition, visited []*projcontour.HTTPProxy, enforceTLS bool) []*Route {
+func (b *Builder) computeRoutes(sw *ObjectStatusWriter, rootProxy *projcontour.HTTPProxy, proxy *projcontour.HTTPProxy, conditions []projcontour.MatchCondition, visited []*projcontour.HTTPProxy, enforceTLS bool) []*Route {
 	for _, v := range visited {
 		// ensure we are not following an edge that produces a cycle
 		var path []string
@@ -614,6 +1338,18 @@ func (b *Builder) computeRoutes(sw *ObjectStatusWriter, proxy *projcontour.HTTPP
 		return nil
 	}
 
+	// Included proxies without a tracing policy use the
+	// policy of the root proxy.
+	policy := proxy.Spec.TracingPolicy
+	if policy == nil {
+		policy = rootProxy.Spec.TracingPolicy
+	}
+	tp, err := tracingPolicy(policy)
+	if err != nil {
+		sw.SetInvalid("%s", err)
+		return nil
+	}
+
 	// Loop over and process all includes
 	for _, include := range proxy.Spec.Includes {
 		namespace := include.Namespace
@@ -631,13 +1367,13 @@ func (b *Builder) computeRoutes(sw *ObjectStatusWriter, proxy *projcontour.HTTPP
 			return nil
 		}
 
-		if err := pathMatchConditionsValid(include.Conditions); err != nil {
+		if err := includeMatchConditionsValid(include.Conditions); err != nil {
 			sw.SetInvalid("include: %s", err)
 			return nil
 		}
 
 		sw, commit := b.WithObject(delegate)
-		routes = append(routes, b.computeRoutes(sw, delegate, append(conditions, include.Conditions...), visited, enforceTLS)...)
+		routes = append(routes, b.computeRoutes(sw, rootProxy, delegate, append(conditions, include.Conditions...), visited, enforceTLS)...)
 		commit()
 
 		// dest is not an orphaned httpproxy, as there is an httpproxy that points to it
@@ -654,37 +1390,113 @@ func (b *Builder) computeRoutes(sw *ObjectStatusWriter, proxy *projcontour.HTTPP
 
 		// Look for invalid header conditions on this route
 		if err := headerMatchConditionsValid(conds); err != nil {
-			sw.SetInvalid(err.Error())
+			sw.SetInvalid("%s", err)
+			return nil
+		}
+
+		// Look for invalid query parameter conditions on this route
+		if err := queryParamMatchConditionsValid(conds); err != nil {
+			sw.SetInvalid("%s", err)
 			return nil
 		}
 
 		reqHP, err := headersPolicy(route.RequestHeadersPolicy, true /* allow Host */)
 		if err != nil {
-			sw.SetInvalid(err.Error())
+			sw.SetInvalid("%s", err)
 			return nil
 		}
 
 		respHP, err := headersPolicy(route.ResponseHeadersPolicy, false /* disallow Host */)
 		if err != nil {
-			sw.SetInvalid(err.Error())
+			sw.SetInvalid("%s", err)
+			return nil
+		}
+
+		rlp, err := rateLimitPolicy(route.RateLimitPolicy)
+		if err != nil {
+			sw.SetInvalid("route.rateLimitPolicy is invalid: %s", err)
+			return nil
+		}
+		if rlp != nil && rlp.Global != nil && b.rateLimitServiceErr != nil {
+			sw.SetInvalid("route.rateLimitPolicy.global: %s", b.rateLimitServiceErr)
+			return nil
+		}
+
+		redirect, err := redirectPolicy(route.RequestRedirectPolicy)
+		if err != nil {
+			sw.SetInvalid("route.requestRedirectPolicy is invalid: %s", err)
 			return nil
 		}
 
-		if len(route.Services) < 1 {
+		directResponse, err := directResponsePolicy(route.DirectResponsePolicy)
+		if err != nil {
+			sw.SetInvalid("route.directResponsePolicy is invalid: %s", err)
+			return nil
+		}
+
+		switch {
+		case redirect != nil && directResponse != nil:
+			sw.SetInvalid("route.requestRedirectPolicy and route.directResponsePolicy cannot both be set")
+			return nil
+		case redirect != nil && len(route.Services) > 0:
+			sw.SetInvalid("route.services cannot be set when route.requestRedirectPolicy is set")
+			return nil
+		case directResponse != nil && len(route.Services) > 0:
+			sw.SetInvalid("route.services cannot be set when route.directResponsePolicy is set")
+			return nil
+		case redirect == nil && directResponse == nil && len(route.Services) < 1:
 			sw.SetInvalid("route.services must have at least one entry")
 			return nil
 		}
 
+		hashPolicies, err := requestHashPolicies(route.LoadBalancerPolicy)
+		if err != nil {
+			sw.SetInvalid("%s", err)
+			return nil
+		}
+
 		r := &Route{
-			PathMatchCondition:    mergePathMatchConditions(conds),
-			HeaderMatchConditions: mergeHeaderMatchConditions(conds),
-			Websocket:             route.EnableWebsockets,
-			HTTPSUpgrade:          routeEnforceTLS(enforceTLS, route.PermitInsecure && !b.DisablePermitInsecure),
-			TimeoutPolicy:         timeoutPolicy(route.TimeoutPolicy),
-			RetryPolicy:           retryPolicy(route.RetryPolicy),
-			RequestHeadersPolicy:  reqHP,
-			ResponseHeadersPolicy: respHP,
+			PathMatchCondition:        mergePathMatchConditions(conds),
+			HeaderMatchConditions:     mergeHeaderMatchConditions(conds),
+			QueryParamMatchConditions: mergeQueryParamMatchConditions(conds),
+			Websocket:                 route.EnableWebsockets,
+			HTTPSUpgrade:              routeEnforceTLS(enforceTLS, route.PermitInsecure && !b.DisablePermitInsecure),
+			TimeoutPolicy:             timeoutPolicy(route.TimeoutPolicy),
+			RetryPolicy:               retryPolicy(route.RetryPolicy),
+			RequestHeadersPolicy:      reqHP,
+			ResponseHeadersPolicy:     respHP,
+			RateLimitPolicy:           rlp,
+			RequestHashPolicies:       hashPolicies,
+			TracingPolicy:             tp,
+			Redirect:                  redirect,
+			DirectResponse:            directResponse,
+		}
+
+		if redirect != nil && redirect.Prefix != "" && !r.HasPathPrefix() {
+			sw.SetInvalid("route.requestRedirectPolicy.prefix requires a prefix condition")
+			return nil
+		}
+
+		// If the enclosing root proxy enabled authorization,
+		// take the defaults from the virtual host and let the
+		// route policy override them.
+		if auth := rootProxy.Spec.VirtualHost.Authorization; auth != nil {
+			r.AuthDisabled, r.AuthContext = authorizationPolicy(auth.AuthPolicy, route.AuthPolicy)
+		}
+
+		rr, err := regexRewritePolicy(route.PathRewritePolicy)
+		if err != nil {
+			sw.SetInvalid("%s", err)
+			return nil
 		}
+		r.RegexRewrite = rr
+
+		fip, err := faultInjectionPolicy(route.FaultInjectionPolicy)
+		if err != nil {
+			sw.SetInvalid("%s", err)
+			return nil
+		}
+		r.FaultInjectionPolicy = fip
 
 		if len(route.GetPrefixReplacements()) > 0 {
 			if !r.HasPathPrefix() {
@@ -693,7 +1505,7 @@ func (b *Builder) computeRoutes(sw *ObjectStatusWriter, proxy *projcontour.HTTPP
 			}
 
 			if err := prefixReplacementsAreValid(route.GetPrefixReplacements()); err != nil {
-				sw.SetInvalid(err.Error())
+				sw.SetInvalid("%s", err)
 				return nil
 			}
 
@@ -738,7 +1550,7 @@ func (b *Builder) computeRoutes(sw *ObjectStatusWriter, proxy *projcontour.HTTPP
 			// Determine the protocol to use to speak to this Cluster.
 			protocol, err := getProtocol(service, s)
 			if err != nil {
-				sw.SetInvalid(err.Error())
+				sw.SetInvalid("%s", err)
 				return nil
 			}
 
@@ -753,28 +1565,52 @@ func (b *Builder) computeRoutes(sw *ObjectStatusWriter, proxy *projcontour.HTTPP
 				}
 			}
 
+			var clientCert *Secret
+			if protocol == "tls" || protocol == "h2" {
+				// we can only present client certificates to services that talk TLS
+				clientCert, err = b.lookupClientCertificate(service.ClientCertificate, proxy.Namespace)
+				if err != nil {
+					sw.SetInvalid("Service [%s:%d] TLS client certificate error: %s",
+						service.Name, service.Port, err)
+					return nil
+				}
+			}
+
 			reqHP, err := headersPolicy(service.RequestHeadersPolicy, true /* allow Host */)
 			if err != nil {
-				sw.SetInvalid(err.Error())
+				sw.SetInvalid("%s", err)
 				return nil
 			}
 
 			respHP, err := headersPolicy(service.ResponseHeadersPolicy, false /* disallow Host */)
 			if err != nil {
-				sw.SetInvalid(err.Error())
+				sw.SetInvalid("%s", err)
+				return nil
+			}
+
+			odp, err := outlierDetectionPolicy(service.OutlierDetectionPolicy)
+			if err != nil {
+				sw.SetInvalid("service %q: %s", service.Name, err)
 				return nil
 			}
 
 			c := &Cluster{
-				Upstream:              s,
-				LoadBalancerPolicy:    loadBalancerPolicy(route.LoadBalancerPolicy),
-				Weight:                uint32(service.Weight),
-				HTTPHealthCheckPolicy: httpHealthCheckPolicy(route.HealthCheckPolicy),
-				UpstreamValidation:    uv,
-				RequestHeadersPolicy:  reqHP,
-				ResponseHeadersPolicy: respHP,
-				Protocol:              protocol,
-				SNI:                   determineSNI(r.RequestHeadersPolicy, reqHP, s),
+				Upstream:               s,
+				LoadBalancerPolicy:     loadBalancerPolicy(route.LoadBalancerPolicy),
+				Weight:                 uint32(service.Weight),
+				HTTPHealthCheckPolicy:  httpHealthCheckPolicy(route.HealthCheckPolicy),
+				UpstreamValidation:     uv,
+				ClientCertificate:      clientCert,
+				RequestHeadersPolicy:   reqHP,
+				ResponseHeadersPolicy:  respHP,
+				OutlierDetectionPolicy: odp,
+				CircuitBreakerPolicy:   circuitBreakerPolicy(service.CircuitBreakerPolicy, s),
+				Protocol:               protocol,
+				SNI:                    determineSNI(r.RequestHeadersPolicy, reqHP, s),
+			}
+			if cp := service.ConnectionPoolPolicy; cp != nil {
+				c.MaxRequestsPerConnection = cp.MaxRequestsPerConnection
+				c.HTTP2MaxConcurrentStreams = cp.HTTP2MaxConcurrentStreams
 			}
 			if service.Mirror && r.MirrorPolicy != nil {
 				sw.SetInvalid("only one service per route may be nominated as mirror")
@@ -825,13 +1661,78 @@ func escapeHeaderValue(value string) string {
 	return strings.Replace(value, "%", "%%", -1)
 }
 
+// dynamicHeaderVariables are the Envoy variables that may be used
+// in a dynamic header value.
+var dynamicHeaderVariables = map[string]bool{
+	"DOWNSTREAM_REMOTE_ADDRESS":              true,
+	"DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT": true,
+	"DOWNSTREAM_PEER_SUBJECT":                true,
+	"DOWNSTREAM_PEER_ISSUER":                 true,
+	"DOWNSTREAM_PEER_URI_SAN":                true,
+	"DOWNSTREAM_PEER_FINGERPRINT_256":        true,
+	"DOWNSTREAM_TLS_VERSION":                 true,
+	"HOSTNAME":                               true,
+}
+
+// dynamicRequestHeaders are the request headers that may be copied
+// into a dynamic header value with %REQ(header)%. Credentials, such
+// as the Authorization and Cookie headers, are deliberately excluded.
+var dynamicRequestHeaders = map[string]bool{
+	"Accept":            true,
+	"Accept-Language":   true,
+	"Content-Type":      true,
+	"Referer":           true,
+	"User-Agent":        true,
+	"X-Forwarded-For":   true,
+	"X-Forwarded-Proto": true,
+	"X-Request-Id":      true,
+}
+
+// headerVariable matches an Envoy variable, such as %HOSTNAME%
+// or %REQ(X-Request-Id)%, at the start of a string.
+var headerVariable = regexp.MustCompile(`^%([A-Z0-9_]+)(\(([^()%]*)\))?%`)
+
+// dynamicHeaderValue validates the Envoy variables in a dynamic header
+// value. Variables must be in dynamicHeaderVariables, or be %REQ(header)%
+// variables that name one of dynamicRequestHeaders. A % that does not
+// start a variable is escaped, so that it is set literally. See:
+// https://www.envoyproxy.io/docs/envoy/latest/configuration/http/http_conn_man/headers#custom-request-response-headers
+func dynamicHeaderValue(value string) (string, error) {
+	var sb strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' {
+			sb.WriteByte(value[i])
+			continue
+		}
+
+		m := headerVariable.FindStringSubmatch(value[i:])
+		switch {
+		case m == nil:
+			sb.WriteString("%%")
+			continue
+		case m[1] == "REQ" && m[2] != "":
+			if !dynamicRequestHeaders[http.CanonicalHeaderKey(m[3])] {
+				return "", fmt.Errorf("request header %q cannot be used in %q", m[3], m[0])
+			}
+		case m[2] != "" || !dynamicHeaderVariables[m[1]]:
+			return "", fmt.Errorf("unsupported variable %q", m[0])
+		}
+
+		sb.WriteString(m[0])
+		i += len(m[0]) - 1
+	}
+
+	return sb.String(), nil
+}
+
 func includeMatchConditionsIdentical(includes []projcontour.Include) bool {
 	j := 0
 	for i := 1; i < len(includes); i++ {
 		// Now compare each include's set of conditions
 		for _, cA := range includes[i].Conditions {
 			for _, cB := range includes[j].Conditions {
-				if (cA.Prefix == cB.Prefix) && cmp.Equal(cA.Header, cB.Header) {
+				if (cA.Prefix == cB.Prefix) && cmp.Equal(cA.Header, cB.Header) && cmp.Equal(cA.QueryParameter, cB.QueryParameter) {
 					return true
 				}
 			}
@@ -855,6 +1756,21 @@ func (b *Builder) buildDAG() *DAG {
 		dag.roots = append(dag.roots, https)
 	}
 
+	dag.roots = append(dag.roots, b.buildTCPListeners()...)
+	dag.roots = append(dag.roots, b.buildUDPListeners()...)
+
+	if b.rateLimitService != nil {
+		dag.roots = append(dag.roots, b.rateLimitService)
+	}
+
+	if b.tracing != nil {
+		dag.roots = append(dag.roots, b.tracing)
+	}
+
+	if b.accessLogService != nil {
+		dag.roots = append(dag.roots, b.accessLogService)
+	}
+
 	for meta := range b.orphaned {
 		proxy, ok := b.Source.httpproxies[meta]
 		if ok {
@@ -865,6 +1781,7 @@ func (b *Builder) buildDAG() *DAG {
 		}
 	}
 	dag.statuses = b.statuses
+	dag.serviceAPIStatuses = sortedServiceAPIStatuses(b.serviceAPIStatuses)
 	return &dag
 }
 
@@ -907,6 +1824,30 @@ func (b *Builder) buildHTTPSListener() *Listener {
 	}
 }
 
+// buildTCPListeners returns the dedicated TCP listeners sorted by port.
+func (b *Builder) buildTCPListeners() []Vertex {
+	var listeners = make([]Vertex, 0, len(b.tcplisteners))
+	for _, l := range b.tcplisteners {
+		listeners = append(listeners, l)
+	}
+	sort.Slice(listeners, func(i, j int) bool {
+		return listeners[i].(*TCPListener).Port < listeners[j].(*TCPListener).Port
+	})
+	return listeners
+}
+
+// buildUDPListeners returns the dedicated UDP listeners sorted by port.
+func (b *Builder) buildUDPListeners() []Vertex {
+	var listeners = make([]Vertex, 0, len(b.udplisteners))
+	for _, l := range b.udplisteners {
+		listeners = append(listeners, l)
+	}
+	sort.Slice(listeners, func(i, j int) bool {
+		return listeners[i].(*UDPListener).Port < listeners[j].(*UDPListener).Port
+	})
+	return listeners
+}
+
 // setOrphaned records an HTTPProxy resource as orphaned.
 func (b *Builder) setOrphaned(obj k8s.Object) {
 	m := types.NamespacedName{
@@ -942,17 +1883,133 @@ func (b *Builder) lookupUpstreamValidation(uv *projcontour.UpstreamValidation, n
 		return nil, fmt.Errorf("invalid CA Secret %q: %s", secretName, err)
 	}
 
-	if uv.SubjectName == "" {
+	if uv.SubjectName == "" && len(uv.SubjectAltNames) == 0 {
 		// UpstreamValidation is requested, but SAN is not provided
 		return nil, errors.New("missing subject alternative name")
 	}
 
+	sans, err := subjectAltNameMatches(uv.SubjectAltNames)
+	if err != nil {
+		return nil, err
+	}
+
 	return &PeerValidationContext{
-		CACertificate: cacert,
-		SubjectName:   uv.SubjectName,
+		CACertificate:   cacert,
+		SubjectName:     uv.SubjectName,
+		SubjectAltNames: sans,
 	}, nil
 }
 
+// subjectAltNameMatches validates the subject alternative name matchers
+// of an upstream validation.
+func subjectAltNameMatches(matches []projcontour.SubjectAltNameMatch) ([]SubjectAltNameMatch, error) {
+	var sans []SubjectAltNameMatch
+
+	for i, m := range matches {
+		var san SubjectAltNameMatch
+		var n int
+
+		if m.Exact != "" {
+			san.MatchType, san.Value = "exact", m.Exact
+			n++
+		}
+		if m.Prefix != "" {
+			san.MatchType, san.Value = "prefix", m.Prefix
+			n++
+		}
+		if m.Suffix != "" {
+			san.MatchType, san.Value = "suffix", m.Suffix
+			n++
+		}
+		if m.Regex != "" {
+			if _, err := regexp.Compile(m.Regex); err != nil {
+				return nil, fmt.Errorf("subject alternative name %d: invalid regex %q", i, m.Regex)
+			}
+			san.MatchType, san.Value = "regex", m.Regex
+			n++
+		}
+		if n != 1 {
+			return nil, fmt.Errorf("subject alternative name %d: must specify exactly one of exact, prefix, suffix or regex", i)
+		}
+
+		// Envoy matches every subject alternative name of the
+		// certificate, whatever its type, so the type is only
+		// used to check that an exact value is well formed.
+		if m.Type != "" && m.Exact == "" {
+			return nil, fmt.Errorf("subject alternative name %d: type can only be used with exact", i)
+		}
+		if m.Exact != "" && !validSubjectAltName(m.Type, m.Exact) {
+			return nil, fmt.Errorf("subject alternative name %d: %q is not a valid %s name", i, m.Exact, m.Type)
+		}
+
+		sans = append(sans, san)
+	}
+
+	return sans, nil
+}
+
+// validSubjectAltName returns true if name is a well formed
+// subject alternative name of the given type.
+func validSubjectAltName(sanType, name string) bool {
+	switch sanType {
+	case "URI":
+		u, err := url.Parse(name)
+		return err == nil && u.Scheme != ""
+	case "IP":
+		return net.ParseIP(name) != nil
+	case "Email":
+		return strings.Contains(name, "@")
+	case "DNS":
+		return !strings.ContainsAny(name, ":/@")
+	default:
+		return true
+	}
+}
+
+// lookupClientCertificate returns the client certificate that Envoy
+// presents to an upstream service. If the service does not name a
+// secret, the default client certificate, if any, is used.
+//
+// The default client certificate is configured by the operator, so it
+// is not subject to certificate delegation, and an invalid default
+// certificate doesn't invalidate the services that would present it.
+func (b *Builder) lookupClientCertificate(name string, namespace string) (*Secret, error) {
+	if isBlank(name) {
+		return b.lookupDefaultClientCertificate(), nil
+	}
+
+	secretName := k8s.NamespacedNameFrom(name, k8s.DefaultNamespace(namespace))
+	sec, err := b.lookupSecret(secretName, validSecret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Secret %q: %s", secretName, err)
+	}
+
+	if !b.delegationPermitted(secretName, namespace) {
+		return nil, fmt.Errorf("Secret %q certificate delegation not permitted", secretName)
+	}
+
+	return sec, nil
+}
+
+// lookupDefaultClientCertificate returns the default client certificate,
+// or nil if it is not configured or invalid.
+func (b *Builder) lookupDefaultClientCertificate() *Secret {
+	if b.ClientCertificate == nil {
+		// no client certificate requested, nothing to do
+		return nil
+	}
+
+	sec, err := b.lookupSecret(*b.ClientCertificate, validSecret)
+	if err != nil {
+		b.Source.WithField("name", b.ClientCertificate.Name).
+			WithField("namespace", b.ClientCertificate.Namespace).
+			WithField("error", err.Error()).
+			Errorf("invalid default client certificate Secret %q", *b.ClientCertificate)
+		return nil
+	}
+	return sec
+}
+
 func (b *Builder) lookupDownstreamValidation(vc *projcontour.DownstreamValidation, namespace string) (*PeerValidationContext, error) {
 	secretName := types.NamespacedName{Name: vc.CACertificate, Namespace: namespace}
 	cacert, err := b.lookupSecret(secretName, validCA)
@@ -961,20 +2018,32 @@ func (b *Builder) lookupDownstreamValidation(vc *projcontour.DownstreamValidatio
 		return nil, fmt.Errorf("invalid CA Secret %q: %s", secretName, err)
 	}
 
-	return &PeerValidationContext{
+	pvc := &PeerValidationContext{
 		CACertificate: cacert,
-	}, nil
+	}
+
+	if vc.CertificateRevocationList != "" {
+		secretName := types.NamespacedName{Name: vc.CertificateRevocationList, Namespace: namespace}
+		crl, err := b.lookupSecret(secretName, validCRL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CRL Secret %q: %s", secretName, err)
+		}
+		pvc.CRL = crl
+	}
+
+	return pvc, nil
 }
 
 // processHTTPProxyTCPProxy processes the spec.tcpproxy stanza in a HTTPProxy document
-// following the chain of spec.tcpproxy.include references. It returns true if processing
-// was successful, otherwise false if an error was encountered. The details of the error
-// will be recorded on the status of the relevant HTTPProxy object,
-func (b *Builder) processHTTPProxyTCPProxy(sw *ObjectStatusWriter, httpproxy *projcontour.HTTPProxy, visited []*projcontour.HTTPProxy, host string) bool {
+// following the chain of spec.tcpproxy.include references. It returns the resulting
+// TCPProxy and true if processing was successful, otherwise false if an error was
+// encountered. The details of the error will be recorded on the status of the relevant
+// HTTPProxy object,
+func (b *Builder) processHTTPProxyTCPProxy(sw *ObjectStatusWriter, httpproxy *projcontour.HTTPProxy, visited []*projcontour.HTTPProxy) (*TCPProxy, bool) {
 	tcpproxy := httpproxy.Spec.TCPProxy
 	if tcpproxy == nil {
 		// nothing to do
-		return true
+		return nil, true
 	}
 
 	visited = append(visited, httpproxy)
@@ -988,7 +2057,7 @@ func (b *Builder) processHTTPProxyTCPProxy(sw *ObjectStatusWriter, httpproxy *pr
 
 	if len(tcpproxy.Services) > 0 && tcpProxyInclude != nil {
 		sw.SetInvalid("tcpproxy: cannot specify services and include in the same httpproxy")
-		return false
+		return nil, false
 	}
 
 	if len(tcpproxy.Services) > 0 {
@@ -998,23 +2067,37 @@ func (b *Builder) processHTTPProxyTCPProxy(sw *ObjectStatusWriter, httpproxy *pr
 			s, err := b.lookupService(m, intstr.FromInt(service.Port))
 			if err != nil {
 				sw.SetInvalid("Spec.TCPProxy unresolved service reference: %s", err)
-				return false
+				return nil, false
+			}
+
+			// A service outlier detection policy takes precedence
+			// over the policy of the tcpproxy.
+			policy := tcpproxy.OutlierDetectionPolicy
+			if service.OutlierDetectionPolicy != nil {
+				policy = service.OutlierDetectionPolicy
 			}
+			odp, err := outlierDetectionPolicy(policy)
+			if err != nil {
+				sw.SetInvalid("Spec.TCPProxy service %q: %s", service.Name, err)
+				return nil, false
+			}
+
 			proxy.Clusters = append(proxy.Clusters, &Cluster{
-				Upstream:             s,
-				Protocol:             s.Protocol,
-				LoadBalancerPolicy:   loadBalancerPolicy(tcpproxy.LoadBalancerPolicy),
-				TCPHealthCheckPolicy: tcpHealthCheckPolicy(tcpproxy.HealthCheckPolicy),
+				Upstream:               s,
+				Protocol:               s.Protocol,
+				LoadBalancerPolicy:     loadBalancerPolicy(tcpproxy.LoadBalancerPolicy),
+				TCPHealthCheckPolicy:   tcpHealthCheckPolicy(tcpproxy.HealthCheckPolicy),
+				OutlierDetectionPolicy: odp,
+				CircuitBreakerPolicy:   circuitBreakerPolicy(service.CircuitBreakerPolicy, s),
 			})
 		}
-		b.lookupSecureVirtualHost(host).TCPProxy = &proxy
-		return true
+		return &proxy, true
 	}
 
 	if tcpProxyInclude == nil {
 		// We don't allow an empty TCPProxy object.
 		sw.SetInvalid("tcpproxy: either services or inclusion must be specified")
-		return false
+		return nil, false
 	}
 
 	namespace := tcpProxyInclude.Namespace
@@ -1027,12 +2110,12 @@ func (b *Builder) processHTTPProxyTCPProxy(sw *ObjectStatusWriter, httpproxy *pr
 	dest, ok := b.Source.httpproxies[m]
 	if !ok {
 		sw.SetInvalid("tcpproxy: include %s/%s not found", m.Namespace, m.Name)
-		return false
+		return nil, false
 	}
 
-	if dest.Spec.VirtualHost != nil {
+	if dest.Spec.VirtualHost != nil || dest.Spec.Listener != nil {
 		sw.SetInvalid("root httpproxy cannot delegate to another root httpproxy")
-		return false
+		return nil, false
 	}
 
 	// dest is no longer an orphan
@@ -1047,18 +2130,18 @@ func (b *Builder) processHTTPProxyTCPProxy(sw *ObjectStatusWriter, httpproxy *pr
 		if dest.Name == hp.Name && dest.Namespace == hp.Namespace {
 			path = append(path, fmt.Sprintf("%s/%s", dest.Namespace, dest.Name))
 			sw.SetInvalid("tcpproxy include creates a cycle: %s", strings.Join(path, " -> "))
-			return false
+			return nil, false
 		}
 	}
 
 	// follow the link and process the target tcpproxy
 	sw, commit := sw.WithObject(dest)
 	defer commit()
-	ok = b.processHTTPProxyTCPProxy(sw, dest, visited, host)
+	proxy, ok := b.processHTTPProxyTCPProxy(sw, dest, visited)
 	if ok {
 		sw.SetValid()
 	}
-	return ok
+	return proxy, ok
 }
 
 func externalName(svc *v1.Service) string {
@@ -1092,6 +2175,39 @@ func route(ingress *v1beta1.Ingress, path string, service *Service) *Route {
 	return r
 }
 
+// validAliases returns an error if an alias is not a valid
+// fqdn, or repeats the fqdn or another alias. Conflicts with
+// other objects are resolved by validHTTPProxies.
+func validAliases(fqdn string, aliases []string) error {
+	seen := map[string]bool{fqdn: true}
+	for _, alias := range aliases {
+		switch {
+		case isBlank(alias):
+			return errors.New("alias must not be blank")
+		case !validFqdnWildcard(alias):
+			return fmt.Errorf("alias %q has an invalid wildcard", alias)
+		case seen[alias]:
+			return fmt.Errorf("alias %q is duplicated", alias)
+		}
+		seen[alias] = true
+	}
+	return nil
+}
+
+// validFqdnWildcard returns true if host does not contain any
+// wildcards, or if the wildcard is the whole leftmost label of a
+// host with at least two more labels, e.g. "*.example.com".
+func validFqdnWildcard(host string) bool {
+	if !strings.Contains(host, "*") {
+		return true
+	}
+	if !strings.HasPrefix(host, "*.") {
+		return false
+	}
+	domain := strings.TrimPrefix(host, "*.")
+	return !strings.Contains(domain, "*") && strings.Contains(domain, ".")
+}
+
 // isBlank indicates if a string contains nothing but blank characters.
 func isBlank(s string) bool {
 	return len(strings.TrimSpace(s)) == 0
@@ -1156,6 +2272,14 @@ func validCA(s *v1.Secret) error {
 	return nil
 }
 
+func validCRL(s *v1.Secret) error {
+	if len(s.Data[CRLKey]) == 0 {
+		return fmt.Errorf("empty %q key", CRLKey)
+	}
+
+	return nil
+}
+
 // routeEnforceTLS determines if the route should redirect the user to a secure TLS listener
 func routeEnforceTLS(enforceTLS, permitInsecure bool) bool {
 	return enforceTLS && !permitInsecure
@@ -21,7 +21,10 @@ import (
 	envoy_api_v2_auth "github.com/envoyproxy/go-control-plane/envoy/api/v2/auth"
 	"github.com/google/go-cmp/cmp"
 	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/assert"
 	"github.com/projectcontour/contour/internal/timeout"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -5170,11 +5173,74 @@ func TestDAGInsert(t *testing.T) {
 					},
 				},
 			},
+			// The Ingress and the HTTPProxy were created at the same
+			// time and have the same namespace and name, so neither
+			// is older. The HTTPProxy keeps example.com and the Ingress
+			// is skipped.
+			want: listeners(
+				&Listener{
+					Port: 443,
+					VirtualHosts: virtualhosts(
+						&SecureVirtualHost{
+							VirtualHost: VirtualHost{
+								Name: "example.com",
+							},
+							MinTLSVersion: envoy_api_v2_auth.TlsParameters_TLSv1_1,
+							Secret:        secret(sec1),
+							TCPProxy: &TCPProxy{
+								Clusters: clusters(service(s9)),
+							},
+						},
+					),
+				},
+			),
+		},
+		"service shared across ingress and httpproxy tcpproxy with different hosts": {
+			objs: []interface{}{
+				sec1,
+				s9,
+				&v1beta1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "nginx",
+						Namespace: "default",
+					},
+					Spec: v1beta1.IngressSpec{
+						TLS: []v1beta1.IngressTLS{{
+							Hosts:      []string{"www.example.com"},
+							SecretName: s1.Name,
+						}},
+						Rules: []v1beta1.IngressRule{{
+							Host:             "www.example.com",
+							IngressRuleValue: ingressrulevalue(backend(s9.Name, intstr.FromInt(80))),
+						}},
+					},
+				},
+				&projcontour.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "nginx",
+						Namespace: "default",
+					},
+					Spec: projcontour.HTTPProxySpec{
+						VirtualHost: &projcontour.VirtualHost{
+							Fqdn: "example.com",
+							TLS: &projcontour.TLS{
+								SecretName: sec1.Name,
+							},
+						},
+						TCPProxy: &projcontour.TCPProxy{
+							Services: []projcontour.Service{{
+								Name: s9.Name,
+								Port: 80,
+							}},
+						},
+					},
+				},
+			},
 			want: listeners(
 				&Listener{
 					Port: 80,
 					VirtualHosts: virtualhosts(
-						virtualhost("example.com", prefixroute("/", service(s9))),
+						virtualhost("www.example.com", prefixroute("/", service(s9))),
 					),
 				},
 				&Listener{
@@ -5194,6 +5260,57 @@ func TestDAGInsert(t *testing.T) {
 				},
 			),
 		},
+		"httpproxy keeps fqdn used by newer ingress": {
+			objs: []interface{}{
+				s9,
+				&v1beta1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "nginx",
+						Namespace:         "default",
+						CreationTimestamp: metav1.NewTime(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)),
+					},
+					Spec: v1beta1.IngressSpec{
+						Rules: []v1beta1.IngressRule{{
+							Host: "example.com",
+							IngressRuleValue: v1beta1.IngressRuleValue{
+								HTTP: &v1beta1.HTTPIngressRuleValue{
+									Paths: []v1beta1.HTTPIngressPath{{
+										Path:    "/ingress",
+										Backend: *backend(s9.Name, intstr.FromInt(80)),
+									}},
+								},
+							},
+						}},
+					},
+				},
+				&projcontour.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "nginx",
+						Namespace:         "default",
+						CreationTimestamp: metav1.NewTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+					},
+					Spec: projcontour.HTTPProxySpec{
+						VirtualHost: &projcontour.VirtualHost{
+							Fqdn: "example.com",
+						},
+						Routes: []projcontour.Route{{
+							Services: []projcontour.Service{{
+								Name: s9.Name,
+								Port: 80,
+							}},
+						}},
+					},
+				},
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("example.com", prefixroute("/", service(s9))),
+					),
+				},
+			),
+		},
 		// issue 1954
 		"httpproxy tcpproxy + permitinsecure": {
 			objs: []interface{}{
@@ -6060,6 +6177,59 @@ func ingressrulevalue(backend *v1beta1.IngressBackend) v1beta1.IngressRuleValue
 	}
 }
 
+func TestBuilderAccessLogServiceNotFound(t *testing.T) {
+	tests := map[string]struct {
+		config  AccessLogServiceConfig
+		wantMsg string
+		wantErr string
+	}{
+		"extension service not found": {
+			config: AccessLogServiceConfig{
+				ExtensionService: types.NamespacedName{Name: "als", Namespace: "projectcontour"},
+			},
+			wantMsg: `invalid access log ExtensionService "projectcontour/als", gRPC access logging is disabled`,
+			wantErr: `extension service "projectcontour/als" not found`,
+		},
+		"service not found": {
+			config: AccessLogServiceConfig{
+				Service: types.NamespacedName{Name: "als", Namespace: "projectcontour"},
+				Port:    9001,
+			},
+			wantMsg: `invalid access log Service "projectcontour/als", gRPC access logging is disabled`,
+			wantErr: `service "projectcontour/als" not found`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			log, hook := logtest.NewNullLogger()
+			b := Builder{
+				AccessLogService: &tc.config,
+				Source: KubernetesCache{
+					FieldLogger: log,
+				},
+			}
+			b.Build()
+
+			if b.accessLogService != nil {
+				t.Fatalf("expected no access log service, got %v", b.accessLogService)
+			}
+
+			entry := hook.LastEntry()
+			if entry == nil {
+				t.Fatal("expected an error to be logged")
+			}
+			assert.Equal(t, logrus.ErrorLevel, entry.Level)
+			assert.Equal(t, tc.wantMsg, entry.Message)
+			assert.Equal(t, logrus.Fields{
+				"name":      "als",
+				"namespace": "projectcontour",
+				"error":     tc.wantErr,
+			}, entry.Data)
+		})
+	}
+}
+
 func TestBuilderLookupService(t *testing.T) {
 	s1 := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -6281,6 +6451,356 @@ func TestDAGRootNamespaces(t *testing.T) {
 	}
 }
 
+func TestDAGTCPListeners(t *testing.T) {
+	s1 := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "postgres",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{
+				Name:     "postgres",
+				Protocol: "TCP",
+				Port:     5432,
+			}},
+		},
+	}
+
+	listenerProxy := func(name string, port int, created time.Time) *projcontour.HTTPProxy {
+		return &projcontour.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         s1.Namespace,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: projcontour.HTTPProxySpec{
+				Listener: &projcontour.Listener{
+					Port: port,
+				},
+				TCPProxy: &projcontour.TCPProxy{
+					Services: []projcontour.Service{{
+						Name: s1.Name,
+						Port: 5432,
+					}},
+				},
+			},
+		}
+	}
+
+	now := time.Now()
+	proxy1 := listenerProxy("postgres", 5432, now)
+	proxy2 := listenerProxy("postgres-replica", 5433, now)
+
+	// proxy3 is newer than proxy1 and uses the same port.
+	proxy3 := listenerProxy("postgres-copy", 5432, now.Add(time.Minute))
+
+	// proxy4 is outside the permitted port range.
+	proxy4 := listenerProxy("postgres-high", 15432, now)
+
+	// proxy5 also has a virtual host.
+	proxy5 := listenerProxy("postgres-vhost", 5434, now)
+	proxy5.Spec.VirtualHost = &projcontour.VirtualHost{
+		Fqdn: "postgres.example.com",
+	}
+
+	// proxy6 has no tcpproxy.
+	proxy6 := listenerProxy("postgres-empty", 5435, now)
+	proxy6.Spec.TCPProxy = nil
+
+	// proxy7 includes the tcpproxy of a child.
+	proxy7 := listenerProxy("postgres-parent", 5436, now)
+	proxy7.Spec.TCPProxy = &projcontour.TCPProxy{
+		Include: &projcontour.TCPProxyInclude{
+			Name: "postgres-child",
+		},
+	}
+	proxy7child := &projcontour.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "postgres-child",
+			Namespace: s1.Namespace,
+		},
+		Spec: projcontour.HTTPProxySpec{
+			TCPProxy: &projcontour.TCPProxy{
+				Services: []projcontour.Service{{
+					Name: s1.Name,
+					Port: 5432,
+				}},
+			},
+		},
+	}
+
+	tcplistener := func(port int) *TCPListener {
+		return &TCPListener{
+			Port: port,
+			TCPProxy: &TCPProxy{
+				Clusters: clusters(service(s1)),
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		portRange  *PortRange
+		objs       []interface{}
+		want       []*TCPListener
+		wantStatus map[string]string
+	}{
+		"listeners sorted by port": {
+			portRange: &PortRange{Min: 5000, Max: 6000},
+			objs:      []interface{}{proxy2, proxy1, s1},
+			want:      []*TCPListener{tcplistener(5432), tcplistener(5433)},
+			wantStatus: map[string]string{
+				"postgres":         "valid HTTPProxy",
+				"postgres-replica": "valid HTTPProxy",
+			},
+		},
+		"dedicated listeners disabled": {
+			objs: []interface{}{proxy1, s1},
+			wantStatus: map[string]string{
+				"postgres": "Spec.Listener: dedicated listener ports are not enabled",
+			},
+		},
+		"port outside permitted range": {
+			portRange: &PortRange{Min: 5000, Max: 6000},
+			objs:      []interface{}{proxy4, s1},
+			wantStatus: map[string]string{
+				"postgres-high": "Spec.Listener port 15432 is outside the permitted range 5000-6000",
+			},
+		},
+		"older proxy keeps a reused port": {
+			portRange: &PortRange{Min: 5000, Max: 6000},
+			objs:      []interface{}{proxy3, proxy1, s1},
+			want:      []*TCPListener{tcplistener(5432)},
+			wantStatus: map[string]string{
+				"postgres":      "valid HTTPProxy",
+				"postgres-copy": "Spec.Listener TCP port 5432 is already used by HTTPProxy default/postgres",
+			},
+		},
+		"listener with virtual host": {
+			portRange: &PortRange{Min: 5000, Max: 6000},
+			objs:      []interface{}{proxy5, s1},
+			wantStatus: map[string]string{
+				"postgres-vhost": "Spec.Listener cannot be combined with Spec.VirtualHost",
+			},
+		},
+		"listener without tcpproxy": {
+			portRange: &PortRange{Min: 5000, Max: 6000},
+			objs:      []interface{}{proxy6, s1},
+			wantStatus: map[string]string{
+				"postgres-empty": "Spec.Listener requires that either Spec.TCPProxy or Spec.UDPProxy be set",
+			},
+		},
+		"listener with included tcpproxy": {
+			portRange: &PortRange{Min: 5000, Max: 6000},
+			objs:      []interface{}{proxy7, proxy7child, s1},
+			want:      []*TCPListener{tcplistener(5436)},
+			wantStatus: map[string]string{
+				"postgres-parent": "valid HTTPProxy",
+				"postgres-child":  "valid HTTPProxy",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := Builder{
+				ListenerPortRange: tc.portRange,
+				Source: KubernetesCache{
+					FieldLogger: testLogger(t),
+				},
+			}
+			for _, o := range tc.objs {
+				builder.Source.Insert(o)
+			}
+			dag := builder.Build()
+
+			var got []*TCPListener
+			dag.Visit(func(v Vertex) {
+				if l, ok := v.(*TCPListener); ok {
+					got = append(got, l)
+				}
+			})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+
+			gotStatus := make(map[string]string)
+			for _, s := range dag.Statuses() {
+				gotStatus[s.Object.GetObjectMeta().GetName()] = s.Description
+			}
+			if diff := cmp.Diff(tc.wantStatus, gotStatus); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestDAGUDPListeners(t *testing.T) {
+	// s1 serves DNS over both TCP and UDP on port 53.
+	s1 := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "coredns",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{
+				Name:     "dns-tcp",
+				Protocol: "TCP",
+				Port:     53,
+			}, {
+				Name:     "dns",
+				Protocol: "UDP",
+				Port:     53,
+			}},
+		},
+	}
+
+	udpProxy := func(name string, port, servicePort int) *projcontour.HTTPProxy {
+		return &projcontour.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: s1.Namespace,
+			},
+			Spec: projcontour.HTTPProxySpec{
+				Listener: &projcontour.Listener{
+					Port: port,
+				},
+				UDPProxy: &projcontour.UDPProxy{
+					Service: projcontour.UDPProxyService{
+						Name: s1.Name,
+						Port: servicePort,
+					},
+				},
+			},
+		}
+	}
+
+	proxy1 := udpProxy("dns", 5353, 53)
+
+	// proxy2 uses the same port number as proxy1 for TCP.
+	proxy2 := &projcontour.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dns-tcp",
+			Namespace: s1.Namespace,
+		},
+		Spec: projcontour.HTTPProxySpec{
+			Listener: &projcontour.Listener{
+				Port: 5353,
+			},
+			TCPProxy: &projcontour.TCPProxy{
+				Services: []projcontour.Service{{
+					Name: s1.Name,
+					Port: 53,
+				}},
+			},
+		},
+	}
+
+	// proxy3 references a port that is not a UDP port.
+	proxy3 := udpProxy("dns-missing", 5354, 54)
+
+	// proxy4 has both a udpproxy and a tcpproxy.
+	proxy4 := udpProxy("dns-both", 5355, 53)
+	proxy4.Spec.TCPProxy = proxy2.Spec.TCPProxy
+
+	// proxy5 has a udpproxy without a listener.
+	proxy5 := udpProxy("dns-vhost", 0, 53)
+	proxy5.Spec.Listener = nil
+	proxy5.Spec.VirtualHost = &projcontour.VirtualHost{
+		Fqdn: "dns.example.com",
+	}
+
+	tests := map[string]struct {
+		objs       []interface{}
+		want       []*UDPListener
+		wantStatus map[string]string
+	}{
+		"udp listener": {
+			objs: []interface{}{proxy1, s1},
+			want: []*UDPListener{{
+				Port: 5353,
+				Cluster: &Cluster{
+					Upstream: &Service{
+						Name:        s1.Name,
+						Namespace:   s1.Namespace,
+						ServicePort: s1.Spec.Ports[1],
+					},
+				},
+			}},
+			wantStatus: map[string]string{
+				"dns": "valid HTTPProxy",
+			},
+		},
+		"udp and tcp listeners share a port number": {
+			objs: []interface{}{proxy1, proxy2, s1},
+			want: []*UDPListener{{
+				Port: 5353,
+				Cluster: &Cluster{
+					Upstream: &Service{
+						Name:        s1.Name,
+						Namespace:   s1.Namespace,
+						ServicePort: s1.Spec.Ports[1],
+					},
+				},
+			}},
+			wantStatus: map[string]string{
+				"dns":     "valid HTTPProxy",
+				"dns-tcp": "valid HTTPProxy",
+			},
+		},
+		"udp service port not found": {
+			objs: []interface{}{proxy3, s1},
+			wantStatus: map[string]string{
+				"dns-missing": `Spec.UDPProxy: unresolved service reference: UDP port 54 on service "default/coredns" not matched`,
+			},
+		},
+		"udpproxy and tcpproxy": {
+			objs: []interface{}{proxy4, s1},
+			wantStatus: map[string]string{
+				"dns-both": "Spec.TCPProxy cannot be combined with Spec.UDPProxy",
+			},
+		},
+		"udpproxy without listener": {
+			objs: []interface{}{proxy5, s1},
+			wantStatus: map[string]string{
+				"dns-vhost": "Spec.UDPProxy requires that Spec.Listener be set",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := Builder{
+				ListenerPortRange: &PortRange{Min: 5000, Max: 6000},
+				Source: KubernetesCache{
+					FieldLogger: testLogger(t),
+				},
+			}
+			for _, o := range tc.objs {
+				builder.Source.Insert(o)
+			}
+			dag := builder.Build()
+
+			var got []*UDPListener
+			dag.Visit(func(v Vertex) {
+				if l, ok := v.(*UDPListener); ok {
+					got = append(got, l)
+				}
+			})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+
+			gotStatus := make(map[string]string)
+			for _, s := range dag.Statuses() {
+				gotStatus[s.Object.GetObjectMeta().GetName()] = s.Description
+			}
+			if diff := cmp.Diff(tc.wantStatus, gotStatus); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
 func TestMatchesPathPrefix(t *testing.T) {
 	tests := map[string]struct {
 		path    string
@@ -6546,6 +7066,139 @@ func TestEnforceRoute(t *testing.T) {
 	}
 }
 
+func TestValidFqdnWildcard(t *testing.T) {
+	tests := map[string]bool{
+		"www.example.com":   true,
+		"*.example.com":     true,
+		"*.www.example.com": true,
+		"*":                 false,
+		"*.com":             false,
+		"*example.com":      false,
+		"www.*.example.com": false,
+		"*.*.example.com":   false,
+		"www.example.*":     false,
+	}
+
+	for host, want := range tests {
+		t.Run(host, func(t *testing.T) {
+			got := validFqdnWildcard(host)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func TestSubjectAltNameMatches(t *testing.T) {
+	tests := map[string]struct {
+		in      []projcontour.SubjectAltNameMatch
+		want    []SubjectAltNameMatch
+		wantErr string
+	}{
+		"nil": {
+			in:   nil,
+			want: nil,
+		},
+		"spiffe id and dns suffix": {
+			in: []projcontour.SubjectAltNameMatch{{
+				Type:  "URI",
+				Exact: "spiffe://cluster/ns/foo/sa/bar",
+			}, {
+				Suffix: ".foo.svc.cluster.local",
+			}, {
+				Regex: "backend-[0-9]+",
+			}},
+			want: []SubjectAltNameMatch{{
+				MatchType: "exact",
+				Value:     "spiffe://cluster/ns/foo/sa/bar",
+			}, {
+				MatchType: "suffix",
+				Value:     ".foo.svc.cluster.local",
+			}, {
+				MatchType: "regex",
+				Value:     "backend-[0-9]+",
+			}},
+		},
+		"ip and email": {
+			in: []projcontour.SubjectAltNameMatch{{
+				Type:  "IP",
+				Exact: "10.0.0.1",
+			}, {
+				Type:  "Email",
+				Exact: "admin@example.com",
+			}},
+			want: []SubjectAltNameMatch{{
+				MatchType: "exact",
+				Value:     "10.0.0.1",
+			}, {
+				MatchType: "exact",
+				Value:     "admin@example.com",
+			}},
+		},
+		"type with a suffix match": {
+			in: []projcontour.SubjectAltNameMatch{{
+				Type:   "DNS",
+				Suffix: ".foo.svc.cluster.local",
+			}},
+			wantErr: "subject alternative name 0: type can only be used with exact",
+		},
+		"no match": {
+			in: []projcontour.SubjectAltNameMatch{{
+				Type: "DNS",
+			}},
+			wantErr: "subject alternative name 0: must specify exactly one of exact, prefix, suffix or regex",
+		},
+		"multiple matches": {
+			in: []projcontour.SubjectAltNameMatch{{
+				Exact:  "example.com",
+				Suffix: ".com",
+			}},
+			wantErr: "subject alternative name 0: must specify exactly one of exact, prefix, suffix or regex",
+		},
+		"invalid regex": {
+			in: []projcontour.SubjectAltNameMatch{{
+				Regex: "^(",
+			}},
+			wantErr: `subject alternative name 0: invalid regex "^("`,
+		},
+		"invalid uri": {
+			in: []projcontour.SubjectAltNameMatch{{
+				Type:  "DNS",
+				Exact: "example.com",
+			}, {
+				Type:  "URI",
+				Exact: "example.com",
+			}},
+			wantErr: `subject alternative name 1: "example.com" is not a valid URI name`,
+		},
+		"invalid ip": {
+			in: []projcontour.SubjectAltNameMatch{{
+				Type:  "IP",
+				Exact: "example.com",
+			}},
+			wantErr: `subject alternative name 0: "example.com" is not a valid IP name`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := subjectAltNameMatches(tc.in)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
 func TestValidateHeaderAlteration(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -6636,6 +7289,54 @@ func TestValidateHeaderAlteration(t *testing.T) {
 				"Lot-Of-Percents": "%%%%%%%%%%",
 			},
 		},
+	}, {
+		name: "dynamic values are not escaped",
+		in: &projcontour.HeadersPolicy{
+			Set: []projcontour.HeaderValue{{
+				Name:    "X-Real-IP",
+				Value:   "%DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT%",
+				Dynamic: true,
+			}, {
+				Name:    "X-Client-Cert-Subject",
+				Value:   "%DOWNSTREAM_PEER_SUBJECT%",
+				Dynamic: true,
+			}, {
+				Name:    "X-Request-Trace",
+				Value:   "%REQ(X-Request-Id)% on %HOSTNAME% at 100%",
+				Dynamic: true,
+			}, {
+				Name:  "X-Literal",
+				Value: "%HOSTNAME%",
+			}},
+		},
+		want: &HeadersPolicy{
+			Set: map[string]string{
+				"X-Real-Ip":             "%DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT%",
+				"X-Client-Cert-Subject": "%DOWNSTREAM_PEER_SUBJECT%",
+				"X-Request-Trace":       "%REQ(X-Request-Id)% on %HOSTNAME% at 100%%",
+				"X-Literal":             "%%HOSTNAME%%",
+			},
+		},
+	}, {
+		name: "unknown dynamic variable",
+		in: &projcontour.HeadersPolicy{
+			Set: []projcontour.HeaderValue{{
+				Name:    "K-Foo",
+				Value:   "%UNKNOWN_VARIABLE%",
+				Dynamic: true,
+			}},
+		},
+		wantErr: errors.New(`invalid set header "K-Foo": unsupported variable "%UNKNOWN_VARIABLE%"`),
+	}, {
+		name: "dynamic request header not in the allow list",
+		in: &projcontour.HeadersPolicy{
+			Set: []projcontour.HeaderValue{{
+				Name:    "K-Foo",
+				Value:   "%REQ(Authorization)%",
+				Dynamic: true,
+			}},
+		},
+		wantErr: errors.New(`invalid set header "K-Foo": request header "Authorization" cannot be used in "%REQ(Authorization)%"`),
 	}}
 
 	for _, test := range tests {
@@ -40,6 +40,16 @@ type KubernetesCache struct {
 	// If not set, defaults to DEFAULT_INGRESS_CLASS.
 	IngressClass string
 
+	// ConfiguredServices are the Services referenced by Contour's
+	// configuration, such as the trace collector. Changes to them
+	// always trigger a rebuild.
+	ConfiguredServices []types.NamespacedName
+
+	// ConfiguredSecrets are the Secrets referenced by Contour's
+	// configuration, such as the default client certificate.
+	// Changes to them always trigger a rebuild.
+	ConfiguredSecrets []types.NamespacedName
+
 	ingresses            map[types.NamespacedName]*v1beta1.Ingress
 	httpproxies          map[types.NamespacedName]*projectcontour.HTTPProxy
 	secrets              map[types.NamespacedName]*v1.Secret
@@ -153,31 +163,15 @@ func (kc *KubernetesCache) Insert(obj interface{}) bool {
 		kc.httpproxydelegations[k8s.NamespacedNameOf(obj)] = obj
 		return true
 	case *serviceapis.GatewayClass:
-		m := k8s.NamespacedNameOf(obj)
-		// TODO(youngnick): Remove this once service-apis actually have behavior
-		// other than being added to the cache.
-		kc.WithField("experimental", "service-apis").WithField("name", m.Name).WithField("namespace", m.Namespace).Debug("Adding GatewayClass")
 		kc.gatewayclasses[k8s.NamespacedNameOf(obj)] = obj
 		return true
 	case *serviceapis.Gateway:
-		m := k8s.NamespacedNameOf(obj)
-		// TODO(youngnick): Remove this once service-apis actually have behavior
-		// other than being added to the cache.
-		kc.WithField("experimental", "service-apis").WithField("name", m.Name).WithField("namespace", m.Namespace).Debug("Adding Gateway")
 		kc.gateways[k8s.NamespacedNameOf(obj)] = obj
 		return true
 	case *serviceapis.HTTPRoute:
-		m := k8s.NamespacedNameOf(obj)
-		// TODO(youngnick): Remove this once service-apis actually have behavior
-		// other than being added to the cache.
-		kc.WithField("experimental", "service-apis").WithField("name", m.Name).WithField("namespace", m.Namespace).Debug("Adding HTTPRoute")
 		kc.httproutes[k8s.NamespacedNameOf(obj)] = obj
 		return true
 	case *serviceapis.TcpRoute:
-		m := k8s.NamespacedNameOf(obj)
-		// TODO(youngnick): Remove this once service-apis actually have behavior
-		// other than being added to the cache.
-		kc.WithField("experimental", "service-apis").WithField("name", m.Name).WithField("namespace", m.Namespace).Debug("Adding TcpRoute")
 		kc.tcproutes[k8s.NamespacedNameOf(obj)] = obj
 		return true
 	case *projectcontourv1alpha1.ExtensionService:
@@ -236,33 +230,21 @@ func (kc *KubernetesCache) remove(obj interface{}) bool {
 	case *serviceapis.GatewayClass:
 		m := k8s.NamespacedNameOf(obj)
 		_, ok := kc.gatewayclasses[m]
-		// TODO(youngnick): Remove this once service-apis actually have behavior
-		// other than being removed from the cache.
-		kc.WithField("experimental", "service-apis").WithField("name", m.Name).WithField("namespace", m.Namespace).Debug("Removing GatewayClass")
 		delete(kc.gatewayclasses, m)
 		return ok
 	case *serviceapis.Gateway:
 		m := k8s.NamespacedNameOf(obj)
 		_, ok := kc.gateways[m]
-		// TODO(youngnick): Remove this once service-apis actually have behavior
-		// other than being removed from the cache.
-		kc.WithField("experimental", "service-apis").WithField("name", m.Name).WithField("namespace", m.Namespace).Debug("Removing Gateway")
 		delete(kc.gateways, m)
 		return ok
 	case *serviceapis.HTTPRoute:
 		m := k8s.NamespacedNameOf(obj)
 		_, ok := kc.httproutes[m]
-		// TODO(youngnick): Remove this once service-apis actually have behavior
-		// other than being removed from the cache.
-		kc.WithField("experimental", "service-apis").WithField("name", m.Name).WithField("namespace", m.Namespace).Debug("Removing HTTPRoute")
 		delete(kc.httproutes, m)
 		return ok
 	case *serviceapis.TcpRoute:
 		m := k8s.NamespacedNameOf(obj)
 		_, ok := kc.tcproutes[m]
-		// TODO(youngnick): Remove this once service-apis actually have behavior
-		// other than being removed from the cache.
-		kc.WithField("experimental", "service-apis").WithField("name", m.Name).WithField("namespace", m.Namespace).Debug("Removing TcpRoute")
 		delete(kc.tcproutes, m)
 		return ok
 	case *projectcontourv1alpha1.ExtensionService:
@@ -281,6 +263,12 @@ func (kc *KubernetesCache) remove(obj interface{}) bool {
 // serviceTriggersRebuild returns true if this service is referenced
 // by an Ingress or HTTPProxy in this cache.
 func (kc *KubernetesCache) serviceTriggersRebuild(service *v1.Service) bool {
+	for _, m := range kc.ConfiguredServices {
+		if m == k8s.NamespacedNameOf(service) {
+			return true
+		}
+	}
+
 	for _, ingress := range kc.ingresses {
 		if ingress.Namespace != service.Namespace {
 			continue
@@ -324,6 +312,37 @@ func (kc *KubernetesCache) serviceTriggersRebuild(service *v1.Service) bool {
 		}
 	}
 
+	for _, ext := range kc.extensions {
+		if ext.Namespace != service.Namespace {
+			continue
+		}
+		for _, s := range ext.Spec.Services {
+			if s.Name == service.Name {
+				return true
+			}
+		}
+	}
+
+	for _, route := range kc.httproutes {
+		if route.Namespace != service.Namespace {
+			continue
+		}
+		hosts := route.Spec.Hosts
+		if route.Spec.Default != nil {
+			hosts = append(hosts, *route.Spec.Default)
+		}
+		for _, host := range hosts {
+			for _, rule := range host.Rules {
+				if rule.Action == nil || rule.Action.ForwardTo == nil {
+					continue
+				}
+				if rule.Action.ForwardTo.Name == service.Name {
+					return true
+				}
+			}
+		}
+	}
+
 	return false
 }
 
@@ -331,6 +350,12 @@ func (kc *KubernetesCache) serviceTriggersRebuild(service *v1.Service) bool {
 // or HTTPProxy object in this cache. If the secret is not in the same namespace
 // it must be mentioned by a TLSCertificateDelegation.
 func (kc *KubernetesCache) secretTriggersRebuild(secret *v1.Secret) bool {
+	for _, m := range kc.ConfiguredSecrets {
+		if m == k8s.NamespacedNameOf(secret) {
+			return true
+		}
+	}
+
 	if _, isCA := secret.Data[CACertificateKey]; isCA {
 		// locating a secret validation usage involves traversing each
 		// proxy object, determining if there is a valid delegation,
@@ -340,6 +365,12 @@ func (kc *KubernetesCache) secretTriggersRebuild(secret *v1.Secret) bool {
 		return true
 	}
 
+	if _, isCRL := secret.Data[CRLKey]; isCRL {
+		// As with CA secrets, assume that any change to a CRL
+		// secret will trigger a rebuild.
+		return true
+	}
+
 	delegations := make(map[string]bool) // targetnamespace/secretname to bool
 
 	// TODO(youngnick): Check if this is required.
@@ -403,5 +434,21 @@ func (kc *KubernetesCache) secretTriggersRebuild(secret *v1.Secret) bool {
 		}
 	}
 
+	for _, gw := range kc.gateways {
+		if gw.Namespace != secret.Namespace {
+			continue
+		}
+		for _, l := range gw.Spec.Listeners {
+			if l.TLS == nil {
+				continue
+			}
+			for _, cert := range l.TLS.Certificates {
+				if cert.Kind == "Secret" && cert.Name == secret.Name {
+					return true
+				}
+			}
+		}
+	}
+
 	return false
 }
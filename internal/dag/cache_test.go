@@ -24,6 +24,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	serviceapis "sigs.k8s.io/service-apis/api/v1alpha1"
 )
 
@@ -59,6 +60,19 @@ func TestKubernetesCacheInsert(t *testing.T) {
 			},
 			want: true,
 		},
+		"insert CRL secret": {
+			obj: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "crl",
+					Namespace: "default",
+				},
+				Type: v1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					CRLKey: []byte(CRL),
+				},
+			},
+			want: true,
+		},
 		"insert CA secret w/ explanatory text": {
 			obj: &v1.Secret{
 				ObjectMeta: metav1.ObjectMeta{
@@ -976,3 +990,57 @@ func (t *testWriter) Write(buf []byte) (int, error) {
 	t.Logf("%s", buf)
 	return len(buf), nil
 }
+
+func TestKubernetesCacheInsertConfiguredService(t *testing.T) {
+	cache := KubernetesCache{
+		ConfiguredServices: []types.NamespacedName{{
+			Name:      "collector",
+			Namespace: "tracing",
+		}},
+		FieldLogger: testLogger(t),
+	}
+
+	service := func(namespace, name string) *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+		}
+	}
+
+	if !cache.Insert(service("tracing", "collector")) {
+		t.Fatalf("Insert(tracing/collector): expected true, got false")
+	}
+	if cache.Insert(service("default", "collector")) {
+		t.Fatalf("Insert(default/collector): expected false, got true")
+	}
+}
+
+func TestKubernetesCacheInsertConfiguredSecret(t *testing.T) {
+	cache := KubernetesCache{
+		ConfiguredSecrets: []types.NamespacedName{{
+			Name:      "envoy-client",
+			Namespace: "projectcontour",
+		}},
+		FieldLogger: testLogger(t),
+	}
+
+	secret := func(namespace, name string) *v1.Secret {
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Type: v1.SecretTypeTLS,
+			Data: secretdata(CERTIFICATE, RSA_PRIVATE_KEY),
+		}
+	}
+
+	if !cache.Insert(secret("projectcontour", "envoy-client")) {
+		t.Fatalf("Insert(projectcontour/envoy-client): expected true, got false")
+	}
+	if cache.Insert(secret("default", "envoy-client")) {
+		t.Fatalf("Insert(default/envoy-client): expected false, got true")
+	}
+}
@@ -22,17 +22,43 @@ import (
 	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
 )
 
-// mergePathMatchConditions merges the given slice of prefix MatchConditions into a single
-// prefix Condition.
-// pathMatchConditionsValid guarantees that if a prefix is present, it will start with a
-// / character, so we can simply concatenate.
+// mergePathMatchConditions merges the given slice of path MatchConditions into a single
+// path Condition. Prefixes are concatenated, and an exact or regex condition, if present,
+// is appended to the merged prefix.
+// pathMatchConditionsValid guarantees that if a prefix or exact path is present, it will
+// start with a / character, so we can simply concatenate.
 func mergePathMatchConditions(conds []projcontour.MatchCondition) MatchCondition {
 	prefix := ""
+	exact := ""
+	regex := ""
 	for _, cond := range conds {
-		prefix = prefix + cond.Prefix
+		switch {
+		case cond.Exact != "":
+			exact = cond.Exact
+		case cond.Regex != "":
+			regex = cond.Regex
+		default:
+			prefix = prefix + cond.Prefix
+		}
 	}
 
 	re := regexp.MustCompile(`//+`)
+
+	switch {
+	case exact != "":
+		return &ExactMatchCondition{
+			Path: re.ReplaceAllString(prefix+exact, `/`),
+		}
+	case regex != "":
+		// The merged prefix is matched literally. Since regex
+		// conditions start with a /, drop any trailing / from
+		// the prefix so that the two are joined by a single /.
+		prefix = strings.TrimRight(re.ReplaceAllString(prefix, `/`), "/")
+		return &RegexMatchCondition{
+			Regex: regexp.QuoteMeta(prefix) + regex,
+		}
+	}
+
 	prefix = re.ReplaceAllString(prefix, `/`)
 
 	// After the merge operation is done, if the string is still empty, then
@@ -50,20 +76,64 @@ func mergePathMatchConditions(conds []projcontour.MatchCondition) MatchCondition
 }
 
 // pathMatchConditionsValid validates a slice of MatchConditions can be correctly merged.
-// It encodes the business rules about what is allowed for prefix MatchConditions.
+// It encodes the business rules about what is allowed for path MatchConditions.
 func pathMatchConditionsValid(conds []projcontour.MatchCondition) error {
 	prefixCount := 0
+	pathCount := 0
 
 	for _, cond := range conds {
+		n := 0
 		if cond.Prefix != "" {
+			n++
 			prefixCount++
 			if cond.Prefix[0] != '/' {
 				return fmt.Errorf("prefix conditions must start with /, %s was supplied", cond.Prefix)
 			}
 		}
+		if cond.Exact != "" {
+			n++
+			if cond.Exact[0] != '/' {
+				return fmt.Errorf("exact conditions must start with /, %s was supplied", cond.Exact)
+			}
+		}
+		if cond.Regex != "" {
+			n++
+			if cond.Regex[0] != '/' {
+				return fmt.Errorf("regex conditions must start with /, %s was supplied", cond.Regex)
+			}
+			if _, err := regexp.Compile(cond.Regex); err != nil {
+				return fmt.Errorf("invalid regex condition %q: %s", cond.Regex, err)
+			}
+		}
+		if n > 1 {
+			return errors.New("only one of prefix, exact or regex may be set in a condition")
+		}
+
+		pathCount += n
 		if prefixCount > 1 {
 			return errors.New("more than one prefix is not allowed in a condition block")
 		}
+		if pathCount > 1 {
+			return errors.New("more than one of prefix, exact or regex is not allowed in a condition block")
+		}
+	}
+
+	return nil
+}
+
+// includeMatchConditionsValid validates the MatchConditions of an include.
+// In addition to the path rules enforced by pathMatchConditionsValid, exact
+// and regex conditions are not allowed, since nothing could be appended to
+// them by the routes of the included HTTPProxy.
+func includeMatchConditionsValid(conds []projcontour.MatchCondition) error {
+	if err := pathMatchConditionsValid(conds); err != nil {
+		return err
+	}
+
+	for _, cond := range conds {
+		if cond.Exact != "" || cond.Regex != "" {
+			return errors.New("exact and regex conditions are not allowed on includes")
+		}
 	}
 
 	return nil
@@ -111,6 +181,93 @@ func mergeHeaderMatchConditions(conds []projcontour.MatchCondition) []HeaderMatc
 	return hc
 }
 
+func mergeQueryParamMatchConditions(conds []projcontour.MatchCondition) []QueryParamMatchCondition {
+	var qc []QueryParamMatchCondition
+	for _, cond := range conds {
+		switch {
+		case cond.QueryParameter == nil:
+			// skip it
+		case cond.QueryParameter.Present:
+			qc = append(qc, QueryParamMatchCondition{
+				Name:      cond.QueryParameter.Name,
+				MatchType: "present",
+			})
+		case cond.QueryParameter.Exact != "":
+			qc = append(qc, QueryParamMatchCondition{
+				Name:      cond.QueryParameter.Name,
+				Value:     cond.QueryParameter.Exact,
+				MatchType: "exact",
+			})
+		case cond.QueryParameter.Prefix != "":
+			qc = append(qc, QueryParamMatchCondition{
+				Name:      cond.QueryParameter.Name,
+				Value:     cond.QueryParameter.Prefix,
+				MatchType: "prefix",
+			})
+		case cond.QueryParameter.Contains != "":
+			qc = append(qc, QueryParamMatchCondition{
+				Name:      cond.QueryParameter.Name,
+				Value:     cond.QueryParameter.Contains,
+				MatchType: "contains",
+			})
+		case cond.QueryParameter.Regex != "":
+			qc = append(qc, QueryParamMatchCondition{
+				Name:      cond.QueryParameter.Name,
+				Value:     cond.QueryParameter.Regex,
+				MatchType: "regex",
+			})
+		}
+	}
+	return qc
+}
+
+// queryParamMatchConditionsValid validates that the query parameter conditions
+// within a slice of MatchConditions are valid. Specifically, it returns an error
+// for any of the following scenarios:
+//	- a condition without a name
+//	- a condition that does not set exactly one match operator
+//	- a regex that does not compile
+//	- more than 1 'exact' condition for the same query parameter
+func queryParamMatchConditionsValid(conditions []projcontour.MatchCondition) error {
+	queryParamsWithExactMatch := map[string]bool{}
+
+	for _, v := range conditions {
+		qp := v.QueryParameter
+		if qp == nil {
+			continue
+		}
+
+		if qp.Name == "" {
+			return errors.New("query parameter conditions must have a name")
+		}
+
+		n := 0
+		for _, set := range []bool{qp.Present, qp.Exact != "", qp.Prefix != "", qp.Contains != "", qp.Regex != ""} {
+			if set {
+				n++
+			}
+		}
+		if n != 1 {
+			return fmt.Errorf("query parameter condition %q must set exactly one of present, exact, prefix, contains or regex", qp.Name)
+		}
+
+		if qp.Regex != "" {
+			if _, err := regexp.Compile(qp.Regex); err != nil {
+				return fmt.Errorf("invalid regex for query parameter condition %q: %s", qp.Name, err)
+			}
+		}
+
+		if qp.Exact != "" {
+			if queryParamsWithExactMatch[qp.Name] {
+				return errors.New("cannot specify duplicate query parameter 'exact match' conditions in the same route")
+			}
+			queryParamsWithExactMatch[qp.Name] = true
+		}
+	}
+
+	return nil
+}
+
 // headerMatchConditionsValid validates that the header conditions within a
 // slice of MatchConditions are valid. Specifically, it returns an error for
 // any of the following scenarios:
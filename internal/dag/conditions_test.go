@@ -82,6 +82,44 @@ func TestPathMatchCondition(t *testing.T) {
 			}},
 			want: &PrefixMatchCondition{Prefix: "/"},
 		},
+		"exact condition": {
+			matchconditions: []projcontour.MatchCondition{{
+				Exact: "/healthz",
+			}},
+			want: &ExactMatchCondition{Path: "/healthz"},
+		},
+		"prefix and exact condition": {
+			matchconditions: []projcontour.MatchCondition{{
+				Prefix: "/a/",
+			}, {
+				Exact: "/healthz",
+			}},
+			want: &ExactMatchCondition{Path: "/a/healthz"},
+		},
+		"regex condition": {
+			matchconditions: []projcontour.MatchCondition{{
+				Regex: "/api/v[0-9]+/users",
+			}},
+			want: &RegexMatchCondition{Regex: "/api/v[0-9]+/users"},
+		},
+		"prefix and regex condition": {
+			matchconditions: []projcontour.MatchCondition{{
+				Prefix: "/a.b/",
+			}, {
+				Prefix: "/c",
+			}, {
+				Regex: "/api/v[0-9]+/users",
+			}},
+			want: &RegexMatchCondition{Regex: `/a\.b/c/api/v[0-9]+/users`},
+		},
+		"slash prefix and regex condition": {
+			matchconditions: []projcontour.MatchCondition{{
+				Prefix: "/",
+			}, {
+				Regex: "/api/.*",
+			}},
+			want: &RegexMatchCondition{Regex: "/api/.*"},
+		},
 	}
 
 	for name, tc := range tests {
@@ -304,6 +342,52 @@ func TestPrefixMatchConditionsValid(t *testing.T) {
 			}},
 			want: false,
 		},
+		"valid exact condition": {
+			matchconditions: []projcontour.MatchCondition{{
+				Exact: "/healthz",
+			}},
+			want: true,
+		},
+		"invalid exact condition": {
+			matchconditions: []projcontour.MatchCondition{{
+				Exact: "healthz",
+			}},
+			want: false,
+		},
+		"valid regex condition": {
+			matchconditions: []projcontour.MatchCondition{{
+				Regex: "/api/v[0-9]+/users",
+			}},
+			want: true,
+		},
+		"regex condition that does not compile": {
+			matchconditions: []projcontour.MatchCondition{{
+				Regex: "/api/v[0-9+/users",
+			}},
+			want: false,
+		},
+		"regex condition with backreference": {
+			// RE2 does not support backreferences.
+			matchconditions: []projcontour.MatchCondition{{
+				Regex: `/(a)\1`,
+			}},
+			want: false,
+		},
+		"prefix and exact in one condition": {
+			matchconditions: []projcontour.MatchCondition{{
+				Prefix: "/api",
+				Exact:  "/api",
+			}},
+			want: false,
+		},
+		"prefix and regex matchconditions": {
+			matchconditions: []projcontour.MatchCondition{{
+				Prefix: "/api",
+			}, {
+				Regex: "/v[0-9]+",
+			}},
+			want: false,
+		},
 	}
 
 	for name, tc := range tests {
@@ -314,6 +398,39 @@ func TestPrefixMatchConditionsValid(t *testing.T) {
 	}
 }
 
+func TestIncludeMatchConditionsValid(t *testing.T) {
+	tests := map[string]struct {
+		matchconditions []projcontour.MatchCondition
+		want            bool
+	}{
+		"prefix condition": {
+			matchconditions: []projcontour.MatchCondition{{
+				Prefix: "/api",
+			}},
+			want: true,
+		},
+		"exact condition": {
+			matchconditions: []projcontour.MatchCondition{{
+				Exact: "/api",
+			}},
+			want: false,
+		},
+		"regex condition": {
+			matchconditions: []projcontour.MatchCondition{{
+				Regex: "/api/.*",
+			}},
+			want: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := includeMatchConditionsValid(tc.matchconditions)
+			assert.Equal(t, tc.want, err == nil)
+		})
+	}
+}
+
 func TestValidateHeaderMatchConditions(t *testing.T) {
 	tests := map[string]struct {
 		matchconditions []projcontour.MatchCondition
@@ -503,3 +620,170 @@ func TestValidateHeaderMatchConditions(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryParamMatchConditions(t *testing.T) {
+	tests := map[string]struct {
+		matchconditions []projcontour.MatchCondition
+		want            []QueryParamMatchCondition
+	}{
+		"empty condition list": {
+			matchconditions: nil,
+			want:            nil,
+		},
+		"prefix": {
+			matchconditions: []projcontour.MatchCondition{{
+				Prefix: "/",
+			}},
+			want: nil,
+		},
+		"query parameter present": {
+			matchconditions: []projcontour.MatchCondition{{
+				QueryParameter: &projcontour.QueryParameterMatchCondition{
+					Name:    "debug",
+					Present: true,
+				},
+			}},
+			want: []QueryParamMatchCondition{{
+				Name:      "debug",
+				MatchType: "present",
+			}},
+		},
+		"query parameter exact, prefix, contains and regex": {
+			matchconditions: []projcontour.MatchCondition{{
+				QueryParameter: &projcontour.QueryParameterMatchCondition{
+					Name:  "a",
+					Exact: "1",
+				},
+			}, {
+				QueryParameter: &projcontour.QueryParameterMatchCondition{
+					Name:   "b",
+					Prefix: "2",
+				},
+			}, {
+				QueryParameter: &projcontour.QueryParameterMatchCondition{
+					Name:     "c",
+					Contains: "3",
+				},
+			}, {
+				QueryParameter: &projcontour.QueryParameterMatchCondition{
+					Name:  "d",
+					Regex: "[4-5]",
+				},
+			}},
+			want: []QueryParamMatchCondition{{
+				Name:      "a",
+				Value:     "1",
+				MatchType: "exact",
+			}, {
+				Name:      "b",
+				Value:     "2",
+				MatchType: "prefix",
+			}, {
+				Name:      "c",
+				Value:     "3",
+				MatchType: "contains",
+			}, {
+				Name:      "d",
+				Value:     "[4-5]",
+				MatchType: "regex",
+			}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := mergeQueryParamMatchConditions(tc.matchconditions)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestValidateQueryParamMatchConditions(t *testing.T) {
+	tests := map[string]struct {
+		matchconditions []projcontour.MatchCondition
+		wantErr         bool
+	}{
+		"empty condition list": {
+			matchconditions: nil,
+			wantErr:         false,
+		},
+		"valid matchconditions": {
+			matchconditions: []projcontour.MatchCondition{{
+				Prefix: "/blog",
+			}, {
+				QueryParameter: &projcontour.QueryParameterMatchCondition{
+					Name:  "debug",
+					Exact: "true",
+				},
+			}, {
+				QueryParameter: &projcontour.QueryParameterMatchCondition{
+					Name:  "version",
+					Regex: "v[0-9]+",
+				},
+			}},
+			wantErr: false,
+		},
+		"missing name": {
+			matchconditions: []projcontour.MatchCondition{{
+				QueryParameter: &projcontour.QueryParameterMatchCondition{
+					Exact: "true",
+				},
+			}},
+			wantErr: true,
+		},
+		"no match operator": {
+			matchconditions: []projcontour.MatchCondition{{
+				QueryParameter: &projcontour.QueryParameterMatchCondition{
+					Name: "debug",
+				},
+			}},
+			wantErr: true,
+		},
+		"more than one match operator": {
+			matchconditions: []projcontour.MatchCondition{{
+				QueryParameter: &projcontour.QueryParameterMatchCondition{
+					Name:    "debug",
+					Exact:   "true",
+					Present: true,
+				},
+			}},
+			wantErr: true,
+		},
+		"invalid regex": {
+			matchconditions: []projcontour.MatchCondition{{
+				QueryParameter: &projcontour.QueryParameterMatchCondition{
+					Name:  "debug",
+					Regex: "[",
+				},
+			}},
+			wantErr: true,
+		},
+		"multiple 'exact' matchconditions for the same query parameter are invalid": {
+			matchconditions: []projcontour.MatchCondition{{
+				QueryParameter: &projcontour.QueryParameterMatchCondition{
+					Name:  "debug",
+					Exact: "true",
+				},
+			}, {
+				QueryParameter: &projcontour.QueryParameterMatchCondition{
+					Name:  "debug",
+					Exact: "false",
+				},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotErr := queryParamMatchConditionsValid(tc.matchconditions)
+
+			if !tc.wantErr && gotErr != nil {
+				t.Fatalf("Expected no error, got (%v)", gotErr)
+			}
+			if tc.wantErr && gotErr == nil {
+				t.Fatalf("Expected error, got none")
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	envoy_api_v2_auth "github.com/envoyproxy/go-control-plane/envoy/api/v2/auth"
+	"github.com/projectcontour/contour/internal/k8s"
 	"github.com/projectcontour/contour/internal/timeout"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -67,6 +68,10 @@ type DAG struct {
 
 	// status computed while building this dag.
 	statuses map[types.NamespacedName]Status
+
+	// serviceAPIStatuses are copies of the Service APIs objects
+	// processed while building this dag, with their status updated.
+	serviceAPIStatuses []k8s.Object
 }
 
 // Visit calls fn on each root of this DAG.
@@ -82,6 +87,12 @@ func (d *DAG) Statuses() map[types.NamespacedName]Status {
 	return d.statuses
 }
 
+// ServiceAPIStatuses returns the Service APIs objects whose status
+// was computed while building this DAG.
+func (d *DAG) ServiceAPIStatuses() []k8s.Object {
+	return d.serviceAPIStatuses
+}
+
 type MatchCondition interface {
 	fmt.Stringer
 }
@@ -95,6 +106,15 @@ func (pc *PrefixMatchCondition) String() string {
 	return "prefix: " + pc.Prefix
 }
 
+// ExactMatchCondition matches the whole path of a URL.
+type ExactMatchCondition struct {
+	Path string
+}
+
+func (ec *ExactMatchCondition) String() string {
+	return "exact: " + ec.Path
+}
+
 // RegexMatchCondition matches the URL by regular expression.
 type RegexMatchCondition struct {
 	Regex string
@@ -123,6 +143,23 @@ func (hc *HeaderMatchCondition) String() string {
 	return "header: " + details
 }
 
+// QueryParamMatchCondition matches request query parameters by MatchType
+type QueryParamMatchCondition struct {
+	Name      string
+	Value     string
+	MatchType string
+}
+
+func (qc *QueryParamMatchCondition) String() string {
+	details := strings.Join([]string{
+		"name=" + qc.Name,
+		"value=" + qc.Value,
+		"matchtype=" + qc.MatchType,
+	}, "&")
+
+	return "queryparam: " + details
+}
+
 // Route defines the properties of a route to a Cluster.
 type Route struct {
 
@@ -134,6 +171,10 @@ type Route struct {
 	// match on the request headers.
 	HeaderMatchConditions []HeaderMatchCondition
 
+	// QueryParamMatchConditions specifies a set of additional Conditions to
+	// match on the request query parameters.
+	QueryParamMatchConditions []QueryParamMatchCondition
+
 	Clusters []*Cluster
 
 	// Should this route generate a 301 upgrade if accessed
@@ -153,14 +194,49 @@ type Route struct {
 	// Indicates that during forwarding, the matched prefix (or path) should be swapped with this value
 	PrefixRewrite string
 
+	// RegexRewrite rewrites the path by a regular expression
+	// substitution during forwarding.
+	RegexRewrite *RegexRewrite
+
+	// FaultInjectionPolicy injects delays and aborts into
+	// requests that match this route.
+	FaultInjectionPolicy *FaultInjectionPolicy
+
 	// Mirror Policy defines the mirroring policy for this Route.
 	MirrorPolicy *MirrorPolicy
 
+	// RequestHashPolicies is a list of policies for configuring hashes on
+	// request attributes when a consistent hashing load balancer is used.
+	RequestHashPolicies []RequestHashPolicy
+
 	// RequestHeadersPolicy defines how headers are managed during forwarding
 	RequestHeadersPolicy *HeadersPolicy
 
 	// ResponseHeadersPolicy defines how headers are managed during forwarding
 	ResponseHeadersPolicy *HeadersPolicy
+
+	// AuthDisabled is set if authorization should be disabled
+	// for this route. If authorization is disabled, the AuthContext
+	// field has no effect.
+	AuthDisabled bool
+
+	// AuthContext sets the authorization context (if authorization is enabled).
+	AuthContext map[string]string
+
+	// RateLimitPolicy defines if/how requests for the route are rate limited.
+	RateLimitPolicy *RateLimitPolicy
+
+	// Redirect, if set, redirects requests instead of forwarding
+	// them to the route's Clusters.
+	Redirect *Redirect
+
+	// DirectResponse, if set, responds to requests directly
+	// instead of forwarding them to the route's Clusters.
+	DirectResponse *DirectResponse
+
+	// TracingPolicy, if set, overrides the global tracing
+	// configuration for this route.
+	TracingPolicy *TracingPolicy
 }
 
 // HasPathPrefix returns whether this route has a PrefixPathCondition.
@@ -169,6 +245,12 @@ func (r *Route) HasPathPrefix() bool {
 	return ok
 }
 
+// HasPathExact returns whether this route has an ExactMatchCondition.
+func (r *Route) HasPathExact() bool {
+	_, ok := r.PathMatchCondition.(*ExactMatchCondition)
+	return ok
+}
+
 // HasPathRegex returns whether this route has a RegexPathCondition.
 func (r *Route) HasPathRegex() bool {
 	_, ok := r.PathMatchCondition.(*RegexMatchCondition)
@@ -203,11 +285,148 @@ type RetryPolicy struct {
 	PerTryTimeout timeout.Setting
 }
 
+// FaultInjectionPolicy defines the faults that are
+// injected into requests.
+type FaultInjectionPolicy struct {
+	// Delay, if set, delays requests.
+	Delay *FaultDelay
+
+	// Abort, if set, aborts requests.
+	Abort *FaultAbort
+
+	// Headers restricts the faults to requests
+	// that match all of the header conditions.
+	Headers []HeaderMatchCondition
+}
+
+// FaultDelay delays a percentage of requests.
+type FaultDelay struct {
+	// FixedDelay is how long requests are delayed.
+	FixedDelay time.Duration
+
+	// Percentage is the percentage of requests
+	// that are delayed, between 0 and 100.
+	Percentage float64
+}
+
+// FaultAbort aborts a percentage of requests. Exactly
+// one of HTTPStatus or GRPCStatus is set.
+type FaultAbort struct {
+	// HTTPStatus is the HTTP status code of the response.
+	HTTPStatus uint32
+
+	// GRPCStatus is the gRPC status code of the response.
+	GRPCStatus uint32
+
+	// Percentage is the percentage of requests
+	// that are aborted, between 0 and 100.
+	Percentage float64
+}
+
+// ForwardClientCertificate defines how the X-Forwarded-Client-Cert
+// header is handled and which client certificate details it carries.
+type ForwardClientCertificate struct {
+	// Mode is one of "sanitize", "forward-only", "append-forward"
+	// or "sanitize-set".
+	Mode string
+
+	// Subject, Cert, Chain, DNS and URI select the details of
+	// the client certificate that are set in the header.
+	Subject bool
+	Cert    bool
+	Chain   bool
+	DNS     bool
+	URI     bool
+}
+
+// RegexRewrite is a regular expression substitution of the path.
+type RegexRewrite struct {
+	// Pattern is the regular expression matched against the path.
+	Pattern string
+
+	// Substitution replaces every match of Pattern.
+	Substitution string
+}
+
+// TracingPolicy overrides the global tracing configuration.
+type TracingPolicy struct {
+	// SamplingRate is the percentage of requests
+	// that are traced, between 0 and 100.
+	SamplingRate float64
+}
+
 // MirrorPolicy defines the mirroring policy for a route.
 type MirrorPolicy struct {
 	Cluster *Cluster
 }
 
+// RequestHashPolicy holds the request attribute which is hashed to
+// select an upstream host. Exactly one of the hash options is set.
+type RequestHashPolicy struct {
+	// Terminal stops the evaluation of further hash policies
+	// if this policy produced a hash.
+	Terminal bool
+
+	HeaderHashOptions         *HeaderHashOptions
+	CookieHashOptions         *CookieHashOptions
+	QueryParameterHashOptions *QueryParameterHashOptions
+
+	// HashSourceIP hashes the downstream connection's source IP.
+	HashSourceIP bool
+}
+
+// HeaderHashOptions hashes the value of a request header.
+type HeaderHashOptions struct {
+	HeaderName string
+}
+
+// CookieHashOptions hashes the value of a request cookie.
+type CookieHashOptions struct {
+	Name string
+	Path string
+
+	// TTL is the lifetime of the cookie Envoy generates if
+	// the request does not carry it. If TTL is nil, Envoy
+	// never generates the cookie.
+	TTL *time.Duration
+}
+
+// QueryParameterHashOptions hashes the value of a request query parameter.
+type QueryParameterHashOptions struct {
+	ParameterName string
+}
+
+// Redirect defines an HTTP redirect. Empty fields keep the
+// corresponding value of the original request.
+type Redirect struct {
+	// Scheme is the scheme of the redirect location.
+	Scheme string
+
+	// Hostname is the hostname of the redirect location.
+	Hostname string
+
+	// Port is the port of the redirect location.
+	Port uint32
+
+	// Path replaces the whole path of the request.
+	Path string
+
+	// Prefix replaces the matched prefix of the request path.
+	Prefix string
+
+	// StatusCode is the HTTP status code of the redirect.
+	StatusCode int
+}
+
+// DirectResponse defines a fixed response to a request.
+type DirectResponse struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode uint32
+
+	// Body is the body of the response, if any.
+	Body string
+}
+
 // HeadersPolicy defines how headers are managed during forwarding
 type HeadersPolicy struct {
 	// HostRewrite defines if a host should be rewritten on upstream requests
@@ -217,6 +436,84 @@ type HeadersPolicy struct {
 	Remove []string
 }
 
+// RateLimitPolicy holds rate limiting parameters.
+type RateLimitPolicy struct {
+	Local  *LocalRateLimitPolicy
+	Global *GlobalRateLimitPolicy
+}
+
+// LocalRateLimitPolicy holds local rate limiting parameters,
+// expressed as an Envoy token bucket.
+type LocalRateLimitPolicy struct {
+	MaxTokens            uint32
+	TokensPerFill        uint32
+	FillInterval         time.Duration
+	ResponseStatusCode   uint32
+	ResponseHeadersToAdd map[string]string
+}
+
+// GlobalRateLimitPolicy holds the descriptors that are sent
+// to the global rate limit service.
+type GlobalRateLimitPolicy struct {
+	Descriptors []*RateLimitDescriptor
+}
+
+// RateLimitDescriptor is a list of rate limit descriptor entries.
+type RateLimitDescriptor struct {
+	Entries []RateLimitDescriptorEntry
+}
+
+// RateLimitDescriptorEntry is an entry in a rate limit descriptor.
+// Exactly one field should be non-nil.
+type RateLimitDescriptorEntry struct {
+	GenericKey    *GenericKeyDescriptorEntry
+	HeaderMatch   *HeaderMatchDescriptorEntry
+	RemoteAddress *RemoteAddressDescriptorEntry
+}
+
+// GenericKeyDescriptorEntry configures a descriptor entry
+// with a key of "generic_key" and a static value.
+type GenericKeyDescriptorEntry struct {
+	Value string
+}
+
+// HeaderMatchDescriptorEntry configures a descriptor entry
+// that's populated from the value of a request header.
+type HeaderMatchDescriptorEntry struct {
+	HeaderName string
+	Key        string
+}
+
+// RemoteAddressDescriptorEntry configures a descriptor entry
+// that contains the remote address (i.e. client IP).
+type RemoteAddressDescriptorEntry struct{}
+
+// CORSPolicy allows setting the CORS policy
+type CORSPolicy struct {
+	// Specifies whether the resource allows credentials.
+	AllowCredentials bool
+	// AllowOrigin specifies the origins that will be allowed to do CORS requests.
+	AllowOrigin []CORSAllowOriginMatch
+	// AllowMethods specifies the content for the *access-control-allow-methods* header.
+	AllowMethods []string
+	// AllowHeaders specifies the content for the *access-control-allow-headers* header.
+	AllowHeaders []string
+	// ExposeHeaders Specifies the content for the *access-control-expose-headers* header.
+	ExposeHeaders []string
+	// MaxAge specifies the content for the *access-control-max-age* header.
+	MaxAge timeout.Setting
+}
+
+// CORSAllowOriginMatch matches the origin of a cross-origin request.
+// Exactly one of Exact or Regex is set.
+type CORSAllowOriginMatch struct {
+	// Exact is an origin that must match the request origin exactly.
+	// The value "*" matches any origin.
+	Exact string
+	// Regex is a regular expression that must match the request origin.
+	Regex string
+}
+
 type HeaderValue struct {
 	// Name represents a key of a header
 	Key string
@@ -232,6 +529,12 @@ type PeerValidationContext struct {
 	// SubjectName holds an optional subject name which Envoy will check against the
 	// certificate presented by the upstream.
 	SubjectName string
+	// SubjectAltNames holds optional matchers which Envoy will check against the
+	// subject alternative names of the certificate presented by the upstream.
+	SubjectAltNames []SubjectAltNameMatch
+	// CRL holds an optional reference to the Secret containing the certificate
+	// revocation lists used to verify downstream client certificates.
+	CRL *Secret
 }
 
 // GetCACertificate returns the CA certificate from PeerValidationContext.
@@ -243,6 +546,32 @@ func (pvc *PeerValidationContext) GetCACertificate() []byte {
 	return pvc.CACertificate.Object.Data[CACertificateKey]
 }
 
+// GetCRL returns the CRL Secret from PeerValidationContext.
+func (pvc *PeerValidationContext) GetCRL() *Secret {
+	if pvc == nil {
+		return nil
+	}
+	return pvc.CRL
+}
+
+// GetSubjectAltNames returns the SubjectAltNames from PeerValidationContext.
+func (pvc *PeerValidationContext) GetSubjectAltNames() []SubjectAltNameMatch {
+	if pvc == nil {
+		return nil
+	}
+	return pvc.SubjectAltNames
+}
+
+// SubjectAltNameMatch matches a subject alternative name of a
+// certificate. It matches subject alternative names of any type.
+type SubjectAltNameMatch struct {
+	// MatchType is one of "exact", "prefix", "suffix" or "regex".
+	MatchType string
+
+	// Value is the string or regular expression to match.
+	Value string
+}
+
 // GetSubjectName returns the SubjectName from PeerValidationContext.
 func (pvc *PeerValidationContext) GetSubjectName() string {
 	if pvc == nil {
@@ -269,6 +598,17 @@ type VirtualHost struct {
 	// as defined by RFC 3986.
 	Name string
 
+	// Aliases are additional fully qualified domain names
+	// that are served by this virtual host.
+	Aliases []string
+
+	// RateLimitPolicy defines if/how requests for the virtual host
+	// are rate limited.
+	RateLimitPolicy *RateLimitPolicy
+
+	// CORSPolicy is the cross-origin policy to apply to the VirtualHost.
+	CORSPolicy *CORSPolicy
+
 	routes map[string]*Route
 }
 
@@ -284,6 +624,9 @@ func conditionsToString(r *Route) string {
 	for _, cond := range r.HeaderMatchConditions {
 		s = append(s, cond.String())
 	}
+	for _, cond := range r.QueryParamMatchConditions {
+		s = append(s, cond.String())
+	}
 	return strings.Join(s, ",")
 }
 
@@ -305,6 +648,18 @@ type SecureVirtualHost struct {
 	// TLS minimum protocol version. Defaults to envoy_api_v2_auth.TlsParameters_TLS_AUTO
 	MinTLSVersion envoy_api_v2_auth.TlsParameters_TlsProtocol
 
+	// TLS maximum protocol version. Defaults to envoy_api_v2_auth.TlsParameters_TLS_AUTO,
+	// in which case the listener default is used.
+	MaxTLSVersion envoy_api_v2_auth.TlsParameters_TlsProtocol
+
+	// CipherSuites is the list of TLS 1.2 cipher suites for this host.
+	// If empty, the listener default is used.
+	CipherSuites []string
+
+	// ECDHCurves is the list of ECDH curves for this host.
+	// If empty, the listener default is used.
+	ECDHCurves []string
+
 	// The cert and key for this host.
 	Secret *Secret
 
@@ -316,6 +671,26 @@ type SecureVirtualHost struct {
 
 	// DownstreamValidation defines how to verify the client's certificate.
 	DownstreamValidation *PeerValidationContext
+
+	// ForwardClientCertificate defines how details of the client's
+	// certificate are forwarded to backends. If nil, the
+	// X-Forwarded-Client-Cert header is sanitized.
+	ForwardClientCertificate *ForwardClientCertificate
+
+	// AuthorizationService points to the extension that client
+	// requests are forwarded to for authorization. If nil, no
+	// authorization is enabled for this host.
+	AuthorizationService *ExtensionCluster
+
+	// AuthorizationResponseTimeout sets how long the proxy should wait
+	// for authorization server responses.
+	AuthorizationResponseTimeout timeout.Setting
+
+	// AuthorizationFailOpen sets whether authorization server
+	// failures should cause the client request to also fail. The
+	// only reason to set this to `true` is when you are migrating
+	// from internal to external authorization.
+	AuthorizationFailOpen bool
 }
 
 func (s *SecureVirtualHost) Visit(f func(Vertex)) {
@@ -326,6 +701,9 @@ func (s *SecureVirtualHost) Visit(f func(Vertex)) {
 	if s.Secret != nil {
 		f(s.Secret) // secret is not required if vhost is using tls passthrough
 	}
+	if s.AuthorizationService != nil {
+		f(s.AuthorizationService)
+	}
 }
 
 func (s *SecureVirtualHost) Valid() bool {
@@ -355,6 +733,34 @@ func (l *Listener) Visit(f func(Vertex)) {
 	}
 }
 
+// TCPListener is a dedicated port that proxies
+// plain TCP connections to a TCPProxy.
+type TCPListener struct {
+
+	// Port is the TCP port to listen on.
+	Port int
+
+	TCPProxy *TCPProxy
+}
+
+func (l *TCPListener) Visit(f func(Vertex)) {
+	f(l.TCPProxy)
+}
+
+// UDPListener is a dedicated port that proxies
+// UDP datagrams to a Cluster.
+type UDPListener struct {
+
+	// Port is the UDP port to listen on.
+	Port int
+
+	Cluster *Cluster
+}
+
+func (l *UDPListener) Visit(f func(Vertex)) {
+	f(l.Cluster)
+}
+
 // TCPProxy represents a cluster of TCP endpoints.
 type TCPProxy struct {
 
@@ -436,6 +842,10 @@ type Cluster struct {
 	// UpstreamValidation defines how to verify the backend service's certificate
 	UpstreamValidation *PeerValidationContext
 
+	// ClientCertificate is the certificate that Envoy presents to
+	// the backend service when it requests a client certificate.
+	ClientCertificate *Secret
+
 	// The load balancer type to use when picking a host in the cluster.
 	// See https://www.envoyproxy.io/docs/envoy/latest/api-v2/api/v2/cds.proto#envoy-api-enum-cluster-lbpolicy
 	LoadBalancerPolicy string
@@ -446,6 +856,23 @@ type Cluster struct {
 	// Cluster tcp health check policy
 	*TCPHealthCheckPolicy
 
+	// OutlierDetectionPolicy defines how unhealthy endpoints are
+	// ejected from the cluster.
+	OutlierDetectionPolicy *OutlierDetectionPolicy
+
+	// CircuitBreakerPolicy overrides the circuit breaker thresholds
+	// of the Upstream service. If nil, the thresholds of the
+	// service annotations are used.
+	CircuitBreakerPolicy *CircuitBreakerPolicy
+
+	// MaxRequestsPerConnection is the maximum number of requests
+	// sent on a single upstream connection. Zero is unlimited.
+	MaxRequestsPerConnection uint32
+
+	// HTTP2MaxConcurrentStreams is the maximum number of concurrent
+	// streams on a single upstream HTTP/2 connection.
+	HTTP2MaxConcurrentStreams uint32
+
 	// RequestHeadersPolicy defines how headers are managed during forwarding
 	RequestHeadersPolicy *HeadersPolicy
 
@@ -464,6 +891,117 @@ func (c Cluster) Visit(f func(Vertex)) {
 	f(c.Upstream)
 }
 
+// WeightedService represents the relative weight of a Service
+// amongst its siblings.
+type WeightedService struct {
+	// Weight is the integral load balancing weight.
+	Weight uint32
+
+	// Service is the Kubernetes Service port traffic is sent to.
+	Service *Service
+}
+
+// ExtensionCluster generates an Envoy cluster for an ExtensionService resource.
+type ExtensionCluster struct {
+	// Name is the (globally unique) name of the corresponding Envoy cluster resource.
+	Name string
+
+	// Upstreams are the weighted Kubernetes Services whose
+	// endpoints receive the extension requests.
+	Upstreams []WeightedService
+
+	// The protocol to use to speak to this cluster.
+	// One of "h2" or "h2c".
+	Protocol string
+
+	// UpstreamValidation defines how to verify the backend service's certificate
+	UpstreamValidation *PeerValidationContext
+
+	// The load balancer type to use when picking a host in the cluster.
+	// See https://www.envoyproxy.io/docs/envoy/latest/api-v2/api/v2/cds.proto#envoy-api-enum-cluster-lbpolicy
+	LoadBalancerPolicy string
+
+	// TimeoutPolicy specifies how to handle timeouts to this extension.
+	TimeoutPolicy TimeoutPolicy
+}
+
+func (e *ExtensionCluster) Visit(func(Vertex)) {
+	// ExtensionClusters are leaves in the DAG.
+}
+
+// RateLimitService is the global rate limit service that
+// Envoy asks for rate limit decisions.
+type RateLimitService struct {
+	// Cluster is the extension cluster that implements the
+	// Envoy rate limit service.
+	Cluster *ExtensionCluster
+
+	// Domain is passed to the rate limit service to scope
+	// the descriptors it is sent.
+	Domain string
+
+	// FailOpen allows requests to proceed when the rate limit
+	// service cannot be reached or does not respond in time.
+	FailOpen bool
+
+	// Timeout is how long to wait for a rate limit decision.
+	Timeout timeout.Setting
+}
+
+func (r *RateLimitService) Visit(f func(Vertex)) {
+	f(r.Cluster)
+}
+
+// Tracing is the distributed tracing configuration
+// of Envoy's HTTP connection managers.
+type Tracing struct {
+	// Provider is the tracer that Envoy uses.
+	// One of "zipkin" or "opentelemetry".
+	Provider string
+
+	// Cluster is the cluster of the trace collector.
+	Cluster *Cluster
+
+	// CollectorEndpoint is the API path of a
+	// Zipkin collector that spans are sent to.
+	CollectorEndpoint string
+
+	// SamplingRate is the percentage of requests
+	// that are traced, between 0 and 100.
+	SamplingRate float64
+
+	// CustomTags are added to every span.
+	CustomTags []TracingCustomTag
+}
+
+func (t *Tracing) Visit(f func(Vertex)) {
+	f(t.Cluster)
+}
+
+// AccessLogService is the gRPC access log service that
+// Envoy streams access logs to.
+type AccessLogService struct {
+	// ExtensionCluster is the extension cluster that implements
+	// the access log service, if it is an ExtensionService.
+	ExtensionCluster *ExtensionCluster
+
+	// Cluster is the cluster that implements the access
+	// log service, if it is a Kubernetes Service.
+	Cluster *Cluster
+
+	// LogName identifies this Envoy's access log stream.
+	LogName string
+}
+
+func (a *AccessLogService) Visit(f func(Vertex)) {
+	if a.ExtensionCluster != nil {
+		f(a.ExtensionCluster)
+	}
+	if a.Cluster != nil {
+		f(a.Cluster)
+	}
+}
+
 // Secret represents a K8s Secret for TLS usage as a DAG Vertex. A Secret is
 // a leaf in the DAG.
 type Secret struct {
@@ -506,3 +1044,36 @@ type TCPHealthCheckPolicy struct {
 	UnhealthyThreshold uint32
 	HealthyThreshold   uint32
 }
+
+// CircuitBreakerPolicy defines the circuit breaker thresholds of
+// a cluster. Zero values use the Envoy defaults.
+type CircuitBreakerPolicy struct {
+	MaxConnections     uint32
+	MaxPendingRequests uint32
+	MaxRequests        uint32
+	MaxRetries         uint32
+}
+
+// OutlierDetectionPolicy defines passive health checking of the
+// endpoints of a cluster. Zero values use the Envoy defaults.
+type OutlierDetectionPolicy struct {
+	// Consecutive5xxErrors is the number of consecutive 5xx
+	// responses before an endpoint is ejected.
+	Consecutive5xxErrors uint32
+
+	// ConsecutiveGatewayErrors is the number of consecutive
+	// 502, 503 or 504 responses before an endpoint is ejected.
+	// Zero disables ejection on gateway errors.
+	ConsecutiveGatewayErrors uint32
+
+	// Interval is the time between ejection sweeps.
+	Interval time.Duration
+
+	// BaseEjectionTime is the base time an endpoint is ejected for.
+	BaseEjectionTime time.Duration
+
+	// MaxEjectionPercent is the maximum percentage of endpoints
+	// that can be ejected at the same time. Nil selects the
+	// Envoy default.
+	MaxEjectionPercent *uint32
+}
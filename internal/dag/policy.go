@@ -14,8 +14,13 @@
 package dag
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -80,13 +85,24 @@ func headersPolicy(policy *projcontour.HeadersPolicy, allowHostRewrite bool) (*H
 			if !allowHostRewrite {
 				return nil, fmt.Errorf("rewriting %q header is not supported", key)
 			}
+			if entry.Dynamic {
+				return nil, fmt.Errorf("dynamic values are not supported for the %q header", key)
+			}
 			hostRewrite = entry.Value
 			continue
 		}
 		if msgs := validation.IsHTTPHeaderName(key); len(msgs) != 0 {
 			return nil, fmt.Errorf("invalid set header %q: %v", key, msgs)
 		}
-		set[key] = escapeHeaderValue(entry.Value)
+		if !entry.Dynamic {
+			set[key] = escapeHeaderValue(entry.Value)
+			continue
+		}
+		value, err := dynamicHeaderValue(entry.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid set header %q: %v", key, err)
+		}
+		set[key] = value
 	}
 
 	remove := sets.NewString()
@@ -195,8 +211,80 @@ func tcpHealthCheckPolicy(hc *projcontour.TCPHealthCheckPolicy) *TCPHealthCheckP
 	}
 }
 
+// circuitBreakerPolicy merges the circuit breaker policy with the
+// thresholds of the service annotations. Thresholds set by the policy
+// take precedence over the annotations.
+func circuitBreakerPolicy(policy *projcontour.CircuitBreakerPolicy, s *Service) *CircuitBreakerPolicy {
+	if policy == nil {
+		return nil
+	}
+
+	thresholdOrDefault := func(threshold, annotation uint32) uint32 {
+		if threshold > 0 {
+			return threshold
+		}
+		return annotation
+	}
+
+	return &CircuitBreakerPolicy{
+		MaxConnections:     thresholdOrDefault(policy.MaxConnections, s.MaxConnections),
+		MaxPendingRequests: thresholdOrDefault(policy.MaxPendingRequests, s.MaxPendingRequests),
+		MaxRequests:        thresholdOrDefault(policy.MaxRequests, s.MaxRequests),
+		MaxRetries:         thresholdOrDefault(policy.MaxRetries, s.MaxRetries),
+	}
+}
+
+// outlierDetectionPolicy validates the outlier detection policy
+// and returns the equivalent DAG policy.
+func outlierDetectionPolicy(policy *projcontour.OutlierDetectionPolicy) (*OutlierDetectionPolicy, error) {
+	if policy == nil {
+		return nil, nil
+	}
+
+	if p := policy.MaxEjectionPercent; p != nil && *p > 100 {
+		return nil, fmt.Errorf("outlier detection policy: invalid max ejection percent %d", *p)
+	}
+
+	interval, err := outlierDetectionDuration(policy.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("outlier detection policy: invalid interval %q", policy.Interval)
+	}
+
+	baseEjectionTime, err := outlierDetectionDuration(policy.BaseEjectionTime)
+	if err != nil {
+		return nil, fmt.Errorf("outlier detection policy: invalid base ejection time %q", policy.BaseEjectionTime)
+	}
+
+	return &OutlierDetectionPolicy{
+		Consecutive5xxErrors:     policy.Consecutive5xxErrors,
+		ConsecutiveGatewayErrors: policy.ConsecutiveGatewayErrors,
+		Interval:                 interval,
+		BaseEjectionTime:         baseEjectionTime,
+		MaxEjectionPercent:       policy.MaxEjectionPercent,
+	}, nil
+}
+
+// outlierDetectionDuration parses an outlier detection duration.
+// An empty string is zero, which selects the Envoy default.
+func outlierDetectionDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, errors.New("duration must be positive")
+	}
+
+	return d, nil
+}
+
 // loadBalancerPolicy returns the load balancer strategy or
-// blank if no valid strategy is supplied.
+// blank if no valid strategy is supplied. The hashing strategies
+// return "Maglev" if the Maglev hash algorithm is selected.
 func loadBalancerPolicy(lbp *projcontour.LoadBalancerPolicy) string {
 	if lbp == nil {
 		return ""
@@ -206,13 +294,450 @@ func loadBalancerPolicy(lbp *projcontour.LoadBalancerPolicy) string {
 		return "WeightedLeastRequest"
 	case "Random":
 		return "Random"
-	case "Cookie":
-		return "Cookie"
+	case "Cookie", "RequestHash":
+		if lbp.HashAlgorithm == "Maglev" {
+			return "Maglev"
+		}
+		return lbp.Strategy
 	default:
 		return ""
 	}
 }
 
+// requestHashPolicies returns the request hash policies for the
+// supplied load balancer policy. The Cookie strategy hashes on a
+// session affinity cookie generated by Envoy, the RequestHash
+// strategy on the policies supplied by the user.
+func requestHashPolicies(lbp *projcontour.LoadBalancerPolicy) ([]RequestHashPolicy, error) {
+	if lbp == nil {
+		return nil, nil
+	}
+
+	switch lbp.Strategy {
+	case "Cookie", "RequestHash":
+	default:
+		if lbp.HashAlgorithm != "" {
+			return nil, fmt.Errorf("hash algorithm %q requires the Cookie or RequestHash load balancer strategy", lbp.HashAlgorithm)
+		}
+	}
+
+	if lbp.Strategy != "RequestHash" && len(lbp.RequestHashPolicies) > 0 {
+		return nil, errors.New("request hash policies require the RequestHash load balancer strategy")
+	}
+
+	switch lbp.Strategy {
+	case "Cookie":
+		ttl := time.Duration(0)
+		return []RequestHashPolicy{{
+			CookieHashOptions: &CookieHashOptions{
+				Name: "X-Contour-Session-Affinity",
+				Path: "/",
+				TTL:  &ttl,
+			},
+		}}, nil
+	case "RequestHash":
+		if len(lbp.RequestHashPolicies) == 0 {
+			return nil, errors.New("the RequestHash load balancer strategy requires at least one request hash policy")
+		}
+	default:
+		return nil, nil
+	}
+
+	var policies []RequestHashPolicy
+	for i, p := range lbp.RequestHashPolicies {
+		n := 0
+		for _, set := range []bool{p.HeaderHashOptions != nil, p.CookieHashOptions != nil, p.QueryParameterHashOptions != nil, p.HashSourceIP} {
+			if set {
+				n++
+			}
+		}
+		if n != 1 {
+			return nil, fmt.Errorf("request hash policy %d must set exactly one of headerHashOptions, cookieHashOptions, queryParameterHashOptions or hashSourceIP", i)
+		}
+
+		policy := RequestHashPolicy{
+			Terminal:     p.Terminal,
+			HashSourceIP: p.HashSourceIP,
+		}
+
+		switch {
+		case p.HeaderHashOptions != nil:
+			if p.HeaderHashOptions.HeaderName == "" {
+				return nil, fmt.Errorf("request hash policy %d: header name must be set", i)
+			}
+			policy.HeaderHashOptions = &HeaderHashOptions{
+				HeaderName: http.CanonicalHeaderKey(p.HeaderHashOptions.HeaderName),
+			}
+		case p.CookieHashOptions != nil:
+			if p.CookieHashOptions.Name == "" {
+				return nil, fmt.Errorf("request hash policy %d: cookie name must be set", i)
+			}
+			cookie := &CookieHashOptions{
+				Name: p.CookieHashOptions.Name,
+				Path: p.CookieHashOptions.Path,
+			}
+			if p.CookieHashOptions.TTL != "" {
+				ttl, err := time.ParseDuration(p.CookieHashOptions.TTL)
+				if err != nil {
+					return nil, fmt.Errorf("request hash policy %d: invalid cookie TTL %q: %s", i, p.CookieHashOptions.TTL, err)
+				}
+				if ttl < 0 {
+					return nil, fmt.Errorf("request hash policy %d: cookie TTL %q must not be negative", i, p.CookieHashOptions.TTL)
+				}
+				cookie.TTL = &ttl
+			}
+			policy.CookieHashOptions = cookie
+		case p.QueryParameterHashOptions != nil:
+			if p.QueryParameterHashOptions.ParameterName == "" {
+				return nil, fmt.Errorf("request hash policy %d: query parameter name must be set", i)
+			}
+			policy.QueryParameterHashOptions = &QueryParameterHashOptions{
+				ParameterName: p.QueryParameterHashOptions.ParameterName,
+			}
+		}
+
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+// tracingPolicy parses the sampling rate of the supplied
+// tracing policy.
+func tracingPolicy(tp *projcontour.TracingPolicy) (*TracingPolicy, error) {
+	if tp == nil {
+		return nil, nil
+	}
+
+	rate, err := strconv.ParseFloat(tp.SamplingRate, 64)
+	if err != nil || rate < 0 || rate > 100 {
+		return nil, fmt.Errorf("tracing policy: invalid sampling rate %q: must be a number between 0 and 100", tp.SamplingRate)
+	}
+
+	return &TracingPolicy{
+		SamplingRate: rate,
+	}, nil
+}
+
+// authorizationPolicy merges the route authorization policy over the
+// default policy of the virtual host. It returns whether authorization
+// is disabled and the merged authorization context.
+func authorizationPolicy(vhost, route *projcontour.AuthorizationPolicy) (bool, map[string]string) {
+	var disabled bool
+	context := map[string]string{}
+
+	for _, p := range []*projcontour.AuthorizationPolicy{vhost, route} {
+		if p == nil {
+			continue
+		}
+		disabled = p.Disabled
+		for k, v := range p.Context {
+			context[k] = v
+		}
+	}
+
+	if len(context) == 0 {
+		context = nil
+	}
+
+	return disabled, context
+}
+
+// rateLimitPolicy builds a RateLimitPolicy from the HTTPProxy rate
+// limit policy, or returns an error if the policy is not valid.
+func rateLimitPolicy(in *projcontour.RateLimitPolicy) (*RateLimitPolicy, error) {
+	if in == nil || (in.Local == nil && in.Global == nil) {
+		return nil, nil
+	}
+
+	local, err := localRateLimitPolicy(in.Local)
+	if err != nil {
+		return nil, err
+	}
+
+	global, err := globalRateLimitPolicy(in.Global)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RateLimitPolicy{
+		Local:  local,
+		Global: global,
+	}, nil
+}
+
+func localRateLimitPolicy(in *projcontour.LocalRateLimitPolicy) (*LocalRateLimitPolicy, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	if in.Requests == 0 {
+		return nil, fmt.Errorf("local.requests must be greater than 0")
+	}
+
+	var fillInterval time.Duration
+	switch in.Unit {
+	case "second":
+		fillInterval = time.Second
+	case "minute":
+		fillInterval = time.Minute
+	case "hour":
+		fillInterval = time.Hour
+	default:
+		return nil, fmt.Errorf("local.unit must be one of 'second', 'minute', or 'hour'")
+	}
+
+	if in.ResponseStatusCode > 0 && (in.ResponseStatusCode < 400 || in.ResponseStatusCode > 599) {
+		return nil, fmt.Errorf("local.responseStatusCode must be in the 400-599 range")
+	}
+
+	responseHeaders, err := headersPolicy(&projcontour.HeadersPolicy{Set: in.ResponseHeadersToAdd}, false)
+	if err != nil {
+		return nil, fmt.Errorf("local.responseHeadersToAdd: %s", err)
+	}
+
+	return &LocalRateLimitPolicy{
+		MaxTokens:            in.Requests + in.Burst,
+		TokensPerFill:        in.Requests,
+		FillInterval:         fillInterval,
+		ResponseStatusCode:   in.ResponseStatusCode,
+		ResponseHeadersToAdd: responseHeaders.Set,
+	}, nil
+}
+
+func globalRateLimitPolicy(in *projcontour.GlobalRateLimitPolicy) (*GlobalRateLimitPolicy, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	if len(in.Descriptors) == 0 {
+		return nil, fmt.Errorf("global.descriptors must not be empty")
+	}
+
+	var descriptors []*RateLimitDescriptor
+	for i, d := range in.Descriptors {
+		if len(d.Entries) == 0 {
+			return nil, fmt.Errorf("global.descriptors[%d]: entries must not be empty", i)
+		}
+
+		var rld RateLimitDescriptor
+		for j, entry := range d.Entries {
+			set := 0
+
+			if entry.GenericKey != nil {
+				set++
+				if entry.GenericKey.Value == "" {
+					return nil, fmt.Errorf("global.descriptors[%d].entries[%d]: genericKey.value must be set", i, j)
+				}
+				rld.Entries = append(rld.Entries, RateLimitDescriptorEntry{
+					GenericKey: &GenericKeyDescriptorEntry{
+						Value: entry.GenericKey.Value,
+					},
+				})
+			}
+
+			if entry.RequestHeader != nil {
+				set++
+				if msgs := validation.IsHTTPHeaderName(entry.RequestHeader.HeaderName); len(msgs) != 0 {
+					return nil, fmt.Errorf("global.descriptors[%d].entries[%d]: invalid header name %q: %v", i, j, entry.RequestHeader.HeaderName, msgs)
+				}
+				if entry.RequestHeader.DescriptorKey == "" {
+					return nil, fmt.Errorf("global.descriptors[%d].entries[%d]: requestHeader.descriptorKey must be set", i, j)
+				}
+				rld.Entries = append(rld.Entries, RateLimitDescriptorEntry{
+					HeaderMatch: &HeaderMatchDescriptorEntry{
+						HeaderName: entry.RequestHeader.HeaderName,
+						Key:        entry.RequestHeader.DescriptorKey,
+					},
+				})
+			}
+
+			if entry.RemoteAddress != nil {
+				set++
+				rld.Entries = append(rld.Entries, RateLimitDescriptorEntry{
+					RemoteAddress: &RemoteAddressDescriptorEntry{},
+				})
+			}
+
+			if set != 1 {
+				return nil, fmt.Errorf("global.descriptors[%d].entries[%d]: exactly one descriptor entry type must be set", i, j)
+			}
+		}
+
+		descriptors = append(descriptors, &rld)
+	}
+
+	return &GlobalRateLimitPolicy{
+		Descriptors: descriptors,
+	}, nil
+}
+
+// redirectPolicy builds a Redirect from the HTTPProxy request
+// redirect policy, or returns an error if the policy is not valid.
+func redirectPolicy(in *projcontour.HTTPRequestRedirectPolicy) (*Redirect, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	switch in.Scheme {
+	case "", "http", "https":
+	default:
+		return nil, fmt.Errorf("scheme must be one of 'http' or 'https'")
+	}
+
+	if in.Port < 0 || in.Port > 65535 {
+		return nil, fmt.Errorf("port must be in the range 1-65535")
+	}
+
+	if in.Path != "" && in.Prefix != "" {
+		return nil, fmt.Errorf("only one of path or prefix may be set")
+	}
+	if in.Path != "" && !strings.HasPrefix(in.Path, "/") {
+		return nil, fmt.Errorf("path must start with '/'")
+	}
+	if in.Prefix != "" && !strings.HasPrefix(in.Prefix, "/") {
+		return nil, fmt.Errorf("prefix must start with '/'")
+	}
+
+	statusCode := in.StatusCode
+	switch statusCode {
+	case 0:
+		statusCode = http.StatusFound
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return nil, fmt.Errorf("statusCode must be one of 301, 302, 307 or 308")
+	}
+
+	return &Redirect{
+		Scheme:     in.Scheme,
+		Hostname:   in.Hostname,
+		Port:       uint32(in.Port),
+		Path:       in.Path,
+		Prefix:     in.Prefix,
+		StatusCode: statusCode,
+	}, nil
+}
+
+// directResponsePolicy builds a DirectResponse from the HTTPProxy direct
+// response policy, or returns an error if the policy is not valid.
+func directResponsePolicy(in *projcontour.HTTPDirectResponsePolicy) (*DirectResponse, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	if in.StatusCode < 200 || in.StatusCode > 599 {
+		return nil, fmt.Errorf("statusCode must be in the 200-599 range")
+	}
+
+	// Envoy rejects direct response bodies larger than 4KiB.
+	if len(in.Body) > 4096 {
+		return nil, fmt.Errorf("body must be at most 4096 bytes")
+	}
+
+	return &DirectResponse{
+		StatusCode: uint32(in.StatusCode),
+		Body:       in.Body,
+	}, nil
+}
+
+// corsPolicy builds a CORSPolicy from the HTTPProxy CORS policy,
+// or returns an error if the policy is not valid.
+func corsPolicy(in *projcontour.CORSPolicy) (*CORSPolicy, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	allowOrigin, err := corsAllowOrigin(in.AllowOrigin)
+	if err != nil {
+		return nil, err
+	}
+
+	maxAge, err := corsMaxAge(in.MaxAge)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CORSPolicy{
+		AllowCredentials: in.AllowCredentials,
+		AllowOrigin:      allowOrigin,
+		AllowMethods:     corsHeaderValues(in.AllowMethods),
+		AllowHeaders:     corsHeaderValues(in.AllowHeaders),
+		ExposeHeaders:    corsHeaderValues(in.ExposeHeaders),
+		MaxAge:           maxAge,
+	}, nil
+}
+
+// corsAllowOrigin converts the allowed origins of a CORS policy to origin
+// matches. The wildcard "*" and origins of the form "scheme://host[:port]"
+// are matched exactly. Anything else is treated as a regular expression.
+func corsAllowOrigin(origins []string) ([]CORSAllowOriginMatch, error) {
+	if len(origins) == 0 {
+		return nil, errors.New("allowOrigin must not be empty")
+	}
+
+	var matches []CORSAllowOriginMatch
+	for _, origin := range origins {
+		if origin == "*" || isExactOrigin(origin) {
+			matches = append(matches, CORSAllowOriginMatch{Exact: origin})
+			continue
+		}
+
+		if _, err := regexp.Compile(origin); err != nil {
+			return nil, fmt.Errorf("invalid allowOrigin %q: %s", origin, err)
+		}
+		matches = append(matches, CORSAllowOriginMatch{Regex: origin})
+	}
+
+	return matches, nil
+}
+
+// isExactOrigin returns true if origin is a serialized origin, i.e. a
+// scheme and a hostname or IP address with an optional port, but no
+// path, query or fragment.
+func isExactOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	if u.Scheme == "" || origin != u.Scheme+"://"+u.Host {
+		return false
+	}
+
+	host := u.Hostname()
+	return net.ParseIP(host) != nil || len(validation.IsDNS1123Subdomain(strings.ToLower(host))) == 0
+}
+
+// corsMaxAge parses the max age of a CORS policy. An empty value uses
+// the Envoy default, and zero disables caching of preflight requests.
+func corsMaxAge(maxAge string) (timeout.Setting, error) {
+	if maxAge == "" {
+		return timeout.DefaultSetting(), nil
+	}
+
+	d, err := time.ParseDuration(maxAge)
+	if err != nil {
+		return timeout.Setting{}, fmt.Errorf("invalid maxAge %q: %s", maxAge, err)
+	}
+
+	switch {
+	case d < 0:
+		return timeout.Setting{}, fmt.Errorf("invalid maxAge %q: must not be negative", maxAge)
+	case d == 0:
+		return timeout.DisabledSetting(), nil
+	default:
+		return timeout.DurationSetting(d), nil
+	}
+}
+
+func corsHeaderValues(values []projcontour.CORSHeaderValue) []string {
+	var out []string
+	for _, v := range values {
+		out = append(out, string(v))
+	}
+	return out
+}
+
 func max(a, b uint32) uint32 {
 	if a > b {
 		return a
@@ -238,3 +763,156 @@ func prefixReplacementsAreValid(replacements []projcontour.ReplacePrefix) error
 
 	return nil
 }
+
+// regexRewritePolicy returns the regular expression substitution of
+// the path rewrite policy, or nil if it only replaces path prefixes.
+// A full path replacement is a substitution of the whole path.
+func regexRewritePolicy(policy *projcontour.PathRewritePolicy) (*RegexRewrite, error) {
+	if policy == nil {
+		return nil, nil
+	}
+
+	set := 0
+	if len(policy.ReplacePrefix) > 0 {
+		set++
+	}
+	if policy.RegexRewrite != nil {
+		set++
+	}
+	if policy.ReplaceFullPath != "" {
+		set++
+	}
+	if set > 1 {
+		return nil, errors.New("path rewrite policy must specify only one of replacePrefix, regexRewrite or replaceFullPath")
+	}
+
+	switch {
+	case policy.RegexRewrite != nil:
+		if _, err := regexp.Compile(policy.RegexRewrite.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid regex rewrite pattern %q: %v", policy.RegexRewrite.Pattern, err)
+		}
+		return &RegexRewrite{
+			Pattern:      policy.RegexRewrite.Pattern,
+			Substitution: policy.RegexRewrite.Substitution,
+		}, nil
+	case policy.ReplaceFullPath != "":
+		if !strings.HasPrefix(policy.ReplaceFullPath, "/") {
+			return nil, fmt.Errorf("invalid full path replacement %q: must begin with '/'", policy.ReplaceFullPath)
+		}
+		return &RegexRewrite{
+			Pattern:      "^.*$",
+			Substitution: policy.ReplaceFullPath,
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// forwardClientCertificate validates the client certificate forwarding
+// policy of a virtual host. Client certificate details can only be set
+// when client certificates are validated.
+func forwardClientCertificate(fcc *projcontour.ForwardClientCertificate, clientValidation bool) (*ForwardClientCertificate, error) {
+	if fcc == nil {
+		return nil, nil
+	}
+
+	mode := stringOrDefault(fcc.Mode, "sanitize")
+	switch mode {
+	case "sanitize":
+	case "forward-only", "append-forward", "sanitize-set":
+		if !clientValidation {
+			return nil, fmt.Errorf("mode %q requires client validation", mode)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported mode %q", mode)
+	}
+
+	return &ForwardClientCertificate{
+		Mode:    mode,
+		Subject: fcc.Subject,
+		Cert:    fcc.Cert,
+		Chain:   fcc.Chain,
+		DNS:     fcc.DNS,
+		URI:     fcc.URI,
+	}, nil
+}
+
+// faultInjectionPolicy validates the fault injection policy and
+// returns the faults that it injects.
+func faultInjectionPolicy(policy *projcontour.FaultInjectionPolicy) (*FaultInjectionPolicy, error) {
+	if policy == nil {
+		return nil, nil
+	}
+
+	if policy.Delay == nil && policy.Abort == nil {
+		return nil, errors.New("fault injection policy must specify a delay or an abort")
+	}
+
+	var fp FaultInjectionPolicy
+
+	if d := policy.Delay; d != nil {
+		delay, err := time.ParseDuration(d.FixedDelay)
+		if err != nil || delay <= 0 {
+			return nil, fmt.Errorf("fault injection policy: invalid fixed delay %q", d.FixedDelay)
+		}
+
+		percentage, err := faultPercentage(d.Percentage)
+		if err != nil {
+			return nil, err
+		}
+
+		fp.Delay = &FaultDelay{
+			FixedDelay: delay,
+			Percentage: percentage,
+		}
+	}
+
+	if a := policy.Abort; a != nil {
+		if (a.HTTPStatus == 0) == (a.GRPCStatus == 0) {
+			return nil, errors.New("fault injection policy: abort must specify exactly one of httpStatus or grpcStatus")
+		}
+		if a.HTTPStatus != 0 && (a.HTTPStatus < 200 || a.HTTPStatus > 599) {
+			return nil, fmt.Errorf("fault injection policy: invalid abort HTTP status %d", a.HTTPStatus)
+		}
+		if a.GRPCStatus > 16 {
+			return nil, fmt.Errorf("fault injection policy: invalid abort gRPC status %d", a.GRPCStatus)
+		}
+
+		percentage, err := faultPercentage(a.Percentage)
+		if err != nil {
+			return nil, err
+		}
+
+		fp.Abort = &FaultAbort{
+			HTTPStatus: a.HTTPStatus,
+			GRPCStatus: a.GRPCStatus,
+			Percentage: percentage,
+		}
+	}
+
+	var conds []projcontour.MatchCondition
+	for i := range policy.Headers {
+		conds = append(conds, projcontour.MatchCondition{Header: &policy.Headers[i]})
+	}
+	if err := headerMatchConditionsValid(conds); err != nil {
+		return nil, fmt.Errorf("fault injection policy: %v", err)
+	}
+	fp.Headers = mergeHeaderMatchConditions(conds)
+
+	return &fp, nil
+}
+
+// faultPercentage parses the percentage of requests that a
+// fault is injected into. An empty string is 100 percent.
+func faultPercentage(s string) (float64, error) {
+	if s == "" {
+		return 100, nil
+	}
+
+	percentage, err := strconv.ParseFloat(s, 64)
+	if err != nil || percentage < 0 || percentage > 100 {
+		return 0, fmt.Errorf("fault injection policy: invalid percentage %q: must be a number between 0 and 100", s)
+	}
+
+	return percentage, nil
+}
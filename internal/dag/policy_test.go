@@ -14,6 +14,7 @@
 package dag
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -335,6 +336,26 @@ func TestLoadBalancerPolicy(t *testing.T) {
 			},
 			want: "Cookie",
 		},
+		"RequestHash": {
+			lbp: &projcontour.LoadBalancerPolicy{
+				Strategy: "RequestHash",
+			},
+			want: "RequestHash",
+		},
+		"Cookie with Maglev": {
+			lbp: &projcontour.LoadBalancerPolicy{
+				Strategy:      "Cookie",
+				HashAlgorithm: "Maglev",
+			},
+			want: "Maglev",
+		},
+		"RequestHash with RingHash": {
+			lbp: &projcontour.LoadBalancerPolicy{
+				Strategy:      "RequestHash",
+				HashAlgorithm: "RingHash",
+			},
+			want: "RequestHash",
+		},
 		"unknown": {
 			lbp: &projcontour.LoadBalancerPolicy{
 				Strategy: "please",
@@ -350,3 +371,1008 @@ func TestLoadBalancerPolicy(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestHashPolicies(t *testing.T) {
+	sessionTTL := time.Duration(0)
+	hourTTL := time.Hour
+
+	tests := map[string]struct {
+		lbp     *projcontour.LoadBalancerPolicy
+		want    []RequestHashPolicy
+		wantErr string
+	}{
+		"nil": {
+			lbp:  nil,
+			want: nil,
+		},
+		"RoundRobin": {
+			lbp: &projcontour.LoadBalancerPolicy{
+				Strategy: "RoundRobin",
+			},
+			want: nil,
+		},
+		"Cookie": {
+			lbp: &projcontour.LoadBalancerPolicy{
+				Strategy: "Cookie",
+			},
+			want: []RequestHashPolicy{{
+				CookieHashOptions: &CookieHashOptions{
+					Name: "X-Contour-Session-Affinity",
+					Path: "/",
+					TTL:  &sessionTTL,
+				},
+			}},
+		},
+		"RequestHash": {
+			lbp: &projcontour.LoadBalancerPolicy{
+				Strategy: "RequestHash",
+				RequestHashPolicies: []projcontour.RequestHashPolicy{{
+					Terminal: true,
+					CookieHashOptions: &projcontour.CookieHashOptions{
+						Name: "JSESSIONID",
+					},
+				}, {
+					CookieHashOptions: &projcontour.CookieHashOptions{
+						Name: "affinity",
+						Path: "/app",
+						TTL:  "1h",
+					},
+				}, {
+					HeaderHashOptions: &projcontour.HeaderHashOptions{
+						HeaderName: "x-tenant",
+					},
+				}, {
+					QueryParameterHashOptions: &projcontour.QueryParameterHashOptions{
+						ParameterName: "user",
+					},
+				}, {
+					HashSourceIP: true,
+				}},
+			},
+			want: []RequestHashPolicy{{
+				Terminal: true,
+				CookieHashOptions: &CookieHashOptions{
+					Name: "JSESSIONID",
+				},
+			}, {
+				CookieHashOptions: &CookieHashOptions{
+					Name: "affinity",
+					Path: "/app",
+					TTL:  &hourTTL,
+				},
+			}, {
+				HeaderHashOptions: &HeaderHashOptions{
+					HeaderName: "X-Tenant",
+				},
+			}, {
+				QueryParameterHashOptions: &QueryParameterHashOptions{
+					ParameterName: "user",
+				},
+			}, {
+				HashSourceIP: true,
+			}},
+		},
+		"RequestHash without policies": {
+			lbp: &projcontour.LoadBalancerPolicy{
+				Strategy: "RequestHash",
+			},
+			wantErr: "the RequestHash load balancer strategy requires at least one request hash policy",
+		},
+		"policies without RequestHash": {
+			lbp: &projcontour.LoadBalancerPolicy{
+				Strategy: "Cookie",
+				RequestHashPolicies: []projcontour.RequestHashPolicy{{
+					HashSourceIP: true,
+				}},
+			},
+			wantErr: "request hash policies require the RequestHash load balancer strategy",
+		},
+		"hash algorithm without hashing strategy": {
+			lbp: &projcontour.LoadBalancerPolicy{
+				Strategy:      "Random",
+				HashAlgorithm: "Maglev",
+			},
+			wantErr: `hash algorithm "Maglev" requires the Cookie or RequestHash load balancer strategy`,
+		},
+		"more than one hash option": {
+			lbp: &projcontour.LoadBalancerPolicy{
+				Strategy: "RequestHash",
+				RequestHashPolicies: []projcontour.RequestHashPolicy{{
+					HeaderHashOptions: &projcontour.HeaderHashOptions{
+						HeaderName: "x-tenant",
+					},
+					HashSourceIP: true,
+				}},
+			},
+			wantErr: "request hash policy 0 must set exactly one of headerHashOptions, cookieHashOptions, queryParameterHashOptions or hashSourceIP",
+		},
+		"invalid cookie TTL": {
+			lbp: &projcontour.LoadBalancerPolicy{
+				Strategy: "RequestHash",
+				RequestHashPolicies: []projcontour.RequestHashPolicy{{
+					CookieHashOptions: &projcontour.CookieHashOptions{
+						Name: "affinity",
+						TTL:  "forever",
+					},
+				}},
+			},
+			wantErr: `request hash policy 0: invalid cookie TTL "forever": time: invalid duration "forever"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := requestHashPolicies(tc.lbp)
+			if tc.wantErr != "" {
+				assert.Equal(t, tc.wantErr, err.Error())
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestCircuitBreakerPolicy(t *testing.T) {
+	svc := &Service{
+		MaxConnections:     9000,
+		MaxPendingRequests: 4096,
+		MaxRetries:         7,
+	}
+
+	tests := map[string]struct {
+		in   *projcontour.CircuitBreakerPolicy
+		want *CircuitBreakerPolicy
+	}{
+		"nil": {
+			in:   nil,
+			want: nil,
+		},
+		"annotation defaults": {
+			in: &projcontour.CircuitBreakerPolicy{},
+			want: &CircuitBreakerPolicy{
+				MaxConnections:     9000,
+				MaxPendingRequests: 4096,
+				MaxRetries:         7,
+			},
+		},
+		"policy overrides annotations": {
+			in: &projcontour.CircuitBreakerPolicy{
+				MaxConnections: 100,
+				MaxRequests:    50,
+			},
+			want: &CircuitBreakerPolicy{
+				MaxConnections:     100,
+				MaxPendingRequests: 4096,
+				MaxRequests:        50,
+				MaxRetries:         7,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := circuitBreakerPolicy(tc.in, svc)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestOutlierDetectionPolicy(t *testing.T) {
+	tests := map[string]struct {
+		in      *projcontour.OutlierDetectionPolicy
+		want    *OutlierDetectionPolicy
+		wantErr string
+	}{
+		"nil": {
+			in:   nil,
+			want: nil,
+		},
+		"defaults": {
+			in:   &projcontour.OutlierDetectionPolicy{},
+			want: &OutlierDetectionPolicy{},
+		},
+		"all fields": {
+			in: &projcontour.OutlierDetectionPolicy{
+				Consecutive5xxErrors:     3,
+				ConsecutiveGatewayErrors: 2,
+				Interval:                 "5s",
+				BaseEjectionTime:         "1m",
+				MaxEjectionPercent:       uint32Ptr(50),
+			},
+			want: &OutlierDetectionPolicy{
+				Consecutive5xxErrors:     3,
+				ConsecutiveGatewayErrors: 2,
+				Interval:                 5 * time.Second,
+				BaseEjectionTime:         time.Minute,
+				MaxEjectionPercent:       uint32Ptr(50),
+			},
+		},
+		"zero max ejection percent": {
+			in: &projcontour.OutlierDetectionPolicy{
+				MaxEjectionPercent: uint32Ptr(0),
+			},
+			want: &OutlierDetectionPolicy{
+				MaxEjectionPercent: uint32Ptr(0),
+			},
+		},
+		"invalid interval": {
+			in: &projcontour.OutlierDetectionPolicy{
+				Interval: "often",
+			},
+			wantErr: "outlier detection policy: invalid interval \"often\"",
+		},
+		"negative base ejection time": {
+			in: &projcontour.OutlierDetectionPolicy{
+				BaseEjectionTime: "-5s",
+			},
+			wantErr: "outlier detection policy: invalid base ejection time \"-5s\"",
+		},
+		"max ejection percent too large": {
+			in: &projcontour.OutlierDetectionPolicy{
+				MaxEjectionPercent: uint32Ptr(101),
+			},
+			wantErr: "outlier detection policy: invalid max ejection percent 101",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := outlierDetectionPolicy(tc.in)
+			if tc.wantErr != "" {
+				assert.Equal(t, tc.wantErr, err.Error())
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestAuthorizationPolicy(t *testing.T) {
+	type result struct {
+		disabled bool
+		context  map[string]string
+	}
+
+	tests := map[string]struct {
+		vhost *projcontour.AuthorizationPolicy
+		route *projcontour.AuthorizationPolicy
+		want  result
+	}{
+		"nil": {
+			want: result{},
+		},
+		"vhost disabled": {
+			vhost: &projcontour.AuthorizationPolicy{Disabled: true},
+			want:  result{disabled: true},
+		},
+		"route enables vhost disabled": {
+			vhost: &projcontour.AuthorizationPolicy{Disabled: true},
+			route: &projcontour.AuthorizationPolicy{Disabled: false},
+			want:  result{disabled: false},
+		},
+		"route disabled": {
+			route: &projcontour.AuthorizationPolicy{Disabled: true},
+			want:  result{disabled: true},
+		},
+		"route context overrides vhost context": {
+			vhost: &projcontour.AuthorizationPolicy{
+				Context: map[string]string{"k1": "vhost", "k2": "vhost"},
+			},
+			route: &projcontour.AuthorizationPolicy{
+				Context: map[string]string{"k2": "route", "k3": "route"},
+			},
+			want: result{
+				context: map[string]string{"k1": "vhost", "k2": "route", "k3": "route"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			disabled, context := authorizationPolicy(tc.vhost, tc.route)
+			assert.Equal(t, tc.want, result{disabled: disabled, context: context})
+		})
+	}
+}
+
+func TestRateLimitPolicy(t *testing.T) {
+	tests := map[string]struct {
+		in      *projcontour.RateLimitPolicy
+		want    *RateLimitPolicy
+		wantErr string
+	}{
+		"nil input": {
+			in:   nil,
+			want: nil,
+		},
+		"nil local and global": {
+			in:   &projcontour.RateLimitPolicy{},
+			want: nil,
+		},
+		"local - no burst": {
+			in: &projcontour.RateLimitPolicy{
+				Local: &projcontour.LocalRateLimitPolicy{
+					Requests: 3,
+					Unit:     "second",
+				},
+			},
+			want: &RateLimitPolicy{
+				Local: &LocalRateLimitPolicy{
+					MaxTokens:     3,
+					TokensPerFill: 3,
+					FillInterval:  time.Second,
+				},
+			},
+		},
+		"local - burst, status and headers": {
+			in: &projcontour.RateLimitPolicy{
+				Local: &projcontour.LocalRateLimitPolicy{
+					Requests:           10,
+					Unit:               "minute",
+					Burst:              4,
+					ResponseStatusCode: 503,
+					ResponseHeadersToAdd: []projcontour.HeaderValue{{
+						Name:  "x-rate-limited",
+						Value: "true",
+					}},
+				},
+			},
+			want: &RateLimitPolicy{
+				Local: &LocalRateLimitPolicy{
+					MaxTokens:          14,
+					TokensPerFill:      10,
+					FillInterval:       time.Minute,
+					ResponseStatusCode: 503,
+					ResponseHeadersToAdd: map[string]string{
+						"X-Rate-Limited": "true",
+					},
+				},
+			},
+		},
+		"local - invalid unit": {
+			in: &projcontour.RateLimitPolicy{
+				Local: &projcontour.LocalRateLimitPolicy{
+					Requests: 10,
+					Unit:     "day",
+				},
+			},
+			wantErr: "local.unit must be one of 'second', 'minute', or 'hour'",
+		},
+		"local - zero requests": {
+			in: &projcontour.RateLimitPolicy{
+				Local: &projcontour.LocalRateLimitPolicy{
+					Unit: "hour",
+				},
+			},
+			wantErr: "local.requests must be greater than 0",
+		},
+		"local - invalid status code": {
+			in: &projcontour.RateLimitPolicy{
+				Local: &projcontour.LocalRateLimitPolicy{
+					Requests:           1,
+					Unit:               "hour",
+					ResponseStatusCode: 200,
+				},
+			},
+			wantErr: "local.responseStatusCode must be in the 400-599 range",
+		},
+		"global - all descriptor entry types": {
+			in: &projcontour.RateLimitPolicy{
+				Global: &projcontour.GlobalRateLimitPolicy{
+					Descriptors: []projcontour.RateLimitDescriptor{{
+						Entries: []projcontour.RateLimitDescriptorEntry{
+							{GenericKey: &projcontour.GenericKeyDescriptor{Value: "generic-value"}},
+							{RequestHeader: &projcontour.RequestHeaderDescriptor{HeaderName: "X-Header", DescriptorKey: "header-key"}},
+						},
+					}, {
+						Entries: []projcontour.RateLimitDescriptorEntry{
+							{RemoteAddress: &projcontour.RemoteAddressDescriptor{}},
+						},
+					}},
+				},
+			},
+			want: &RateLimitPolicy{
+				Global: &GlobalRateLimitPolicy{
+					Descriptors: []*RateLimitDescriptor{{
+						Entries: []RateLimitDescriptorEntry{
+							{GenericKey: &GenericKeyDescriptorEntry{Value: "generic-value"}},
+							{HeaderMatch: &HeaderMatchDescriptorEntry{HeaderName: "X-Header", Key: "header-key"}},
+						},
+					}, {
+						Entries: []RateLimitDescriptorEntry{
+							{RemoteAddress: &RemoteAddressDescriptorEntry{}},
+						},
+					}},
+				},
+			},
+		},
+		"global - empty descriptor": {
+			in: &projcontour.RateLimitPolicy{
+				Global: &projcontour.GlobalRateLimitPolicy{
+					Descriptors: []projcontour.RateLimitDescriptor{{}},
+				},
+			},
+			wantErr: "global.descriptors[0]: entries must not be empty",
+		},
+		"global - multiple entry types set": {
+			in: &projcontour.RateLimitPolicy{
+				Global: &projcontour.GlobalRateLimitPolicy{
+					Descriptors: []projcontour.RateLimitDescriptor{{
+						Entries: []projcontour.RateLimitDescriptorEntry{{
+							GenericKey:    &projcontour.GenericKeyDescriptor{Value: "generic-value"},
+							RemoteAddress: &projcontour.RemoteAddressDescriptor{},
+						}},
+					}},
+				},
+			},
+			wantErr: "global.descriptors[0].entries[0]: exactly one descriptor entry type must be set",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := rateLimitPolicy(tc.in)
+			if tc.wantErr != "" {
+				assert.Equal(t, tc.wantErr, err.Error())
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestTracingPolicy(t *testing.T) {
+	tests := map[string]struct {
+		in      *projcontour.TracingPolicy
+		want    *TracingPolicy
+		wantErr string
+	}{
+		"nil": {
+			in:   nil,
+			want: nil,
+		},
+		"whole percentage": {
+			in:   &projcontour.TracingPolicy{SamplingRate: "100"},
+			want: &TracingPolicy{SamplingRate: 100},
+		},
+		"fractional percentage": {
+			in:   &projcontour.TracingPolicy{SamplingRate: "0.5"},
+			want: &TracingPolicy{SamplingRate: 0.5},
+		},
+		"greater than 100": {
+			in:      &projcontour.TracingPolicy{SamplingRate: "100.1"},
+			wantErr: `tracing policy: invalid sampling rate "100.1": must be a number between 0 and 100`,
+		},
+		"not a number": {
+			in:      &projcontour.TracingPolicy{SamplingRate: "all"},
+			wantErr: `tracing policy: invalid sampling rate "all": must be a number between 0 and 100`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tracingPolicy(tc.in)
+			if tc.wantErr != "" {
+				assert.Equal(t, tc.wantErr, err.Error())
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRegexRewritePolicy(t *testing.T) {
+	tests := map[string]struct {
+		in      *projcontour.PathRewritePolicy
+		want    *RegexRewrite
+		wantErr string
+	}{
+		"nil": {
+			in:   nil,
+			want: nil,
+		},
+		"replace prefix": {
+			in: &projcontour.PathRewritePolicy{
+				ReplacePrefix: []projcontour.ReplacePrefix{{Replacement: "/api"}},
+			},
+			want: nil,
+		},
+		"regex rewrite": {
+			in: &projcontour.PathRewritePolicy{
+				RegexRewrite: &projcontour.RegexRewrite{
+					Pattern:      "^/v1/users/([0-9]+)/profile$",
+					Substitution: "/profile?id=\\1",
+				},
+			},
+			want: &RegexRewrite{
+				Pattern:      "^/v1/users/([0-9]+)/profile$",
+				Substitution: "/profile?id=\\1",
+			},
+		},
+		"replace full path": {
+			in: &projcontour.PathRewritePolicy{
+				ReplaceFullPath: "/healthz",
+			},
+			want: &RegexRewrite{
+				Pattern:      "^.*$",
+				Substitution: "/healthz",
+			},
+		},
+		"invalid regex": {
+			in: &projcontour.PathRewritePolicy{
+				RegexRewrite: &projcontour.RegexRewrite{
+					Pattern: "^/v1/(users",
+				},
+			},
+			wantErr: "invalid regex rewrite pattern \"^/v1/(users\": error parsing regexp: missing closing ): `^/v1/(users`",
+		},
+		"full path without leading slash": {
+			in: &projcontour.PathRewritePolicy{
+				ReplaceFullPath: "healthz",
+			},
+			wantErr: "invalid full path replacement \"healthz\": must begin with '/'",
+		},
+		"multiple rewrites": {
+			in: &projcontour.PathRewritePolicy{
+				ReplacePrefix:   []projcontour.ReplacePrefix{{Replacement: "/api"}},
+				ReplaceFullPath: "/healthz",
+			},
+			wantErr: "path rewrite policy must specify only one of replacePrefix, regexRewrite or replaceFullPath",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := regexRewritePolicy(tc.in)
+			if tc.wantErr != "" {
+				assert.Equal(t, tc.wantErr, err.Error())
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestFaultInjectionPolicy(t *testing.T) {
+	tests := map[string]struct {
+		in      *projcontour.FaultInjectionPolicy
+		want    *FaultInjectionPolicy
+		wantErr string
+	}{
+		"nil": {
+			in:   nil,
+			want: nil,
+		},
+		"delay with default percentage": {
+			in: &projcontour.FaultInjectionPolicy{
+				Delay: &projcontour.FaultDelay{
+					FixedDelay: "500ms",
+				},
+			},
+			want: &FaultInjectionPolicy{
+				Delay: &FaultDelay{
+					FixedDelay: 500 * time.Millisecond,
+					Percentage: 100,
+				},
+			},
+		},
+		"http abort with headers": {
+			in: &projcontour.FaultInjectionPolicy{
+				Abort: &projcontour.FaultAbort{
+					HTTPStatus: 503,
+					Percentage: "2.5",
+				},
+				Headers: []projcontour.HeaderMatchCondition{{
+					Name:  "x-fault",
+					Exact: "abort",
+				}},
+			},
+			want: &FaultInjectionPolicy{
+				Abort: &FaultAbort{
+					HTTPStatus: 503,
+					Percentage: 2.5,
+				},
+				Headers: []HeaderMatchCondition{{
+					Name:      "x-fault",
+					Value:     "abort",
+					MatchType: "exact",
+				}},
+			},
+		},
+		"grpc abort": {
+			in: &projcontour.FaultInjectionPolicy{
+				Abort: &projcontour.FaultAbort{
+					GRPCStatus: 14,
+					Percentage: "10",
+				},
+			},
+			want: &FaultInjectionPolicy{
+				Abort: &FaultAbort{
+					GRPCStatus: 14,
+					Percentage: 10,
+				},
+			},
+		},
+		"no faults": {
+			in:      &projcontour.FaultInjectionPolicy{},
+			wantErr: "fault injection policy must specify a delay or an abort",
+		},
+		"invalid fixed delay": {
+			in: &projcontour.FaultInjectionPolicy{
+				Delay: &projcontour.FaultDelay{
+					FixedDelay: "forever",
+				},
+			},
+			wantErr: "fault injection policy: invalid fixed delay \"forever\"",
+		},
+		"invalid percentage": {
+			in: &projcontour.FaultInjectionPolicy{
+				Delay: &projcontour.FaultDelay{
+					FixedDelay: "1s",
+					Percentage: "101",
+				},
+			},
+			wantErr: "fault injection policy: invalid percentage \"101\": must be a number between 0 and 100",
+		},
+		"abort without status": {
+			in: &projcontour.FaultInjectionPolicy{
+				Abort: &projcontour.FaultAbort{},
+			},
+			wantErr: "fault injection policy: abort must specify exactly one of httpStatus or grpcStatus",
+		},
+		"abort with both statuses": {
+			in: &projcontour.FaultInjectionPolicy{
+				Abort: &projcontour.FaultAbort{
+					HTTPStatus: 503,
+					GRPCStatus: 14,
+				},
+			},
+			wantErr: "fault injection policy: abort must specify exactly one of httpStatus or grpcStatus",
+		},
+		"invalid http status": {
+			in: &projcontour.FaultInjectionPolicy{
+				Abort: &projcontour.FaultAbort{
+					HTTPStatus: 99,
+				},
+			},
+			wantErr: "fault injection policy: invalid abort HTTP status 99",
+		},
+		"invalid grpc status": {
+			in: &projcontour.FaultInjectionPolicy{
+				Abort: &projcontour.FaultAbort{
+					GRPCStatus: 17,
+				},
+			},
+			wantErr: "fault injection policy: invalid abort gRPC status 17",
+		},
+		"duplicate exact headers": {
+			in: &projcontour.FaultInjectionPolicy{
+				Abort: &projcontour.FaultAbort{
+					HTTPStatus: 503,
+				},
+				Headers: []projcontour.HeaderMatchCondition{{
+					Name:  "x-fault",
+					Exact: "a",
+				}, {
+					Name:  "x-fault",
+					Exact: "b",
+				}},
+			},
+			wantErr: "fault injection policy: cannot specify duplicate header 'exact match' conditions in the same route",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := faultInjectionPolicy(tc.in)
+			if tc.wantErr != "" {
+				assert.Equal(t, tc.wantErr, err.Error())
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestForwardClientCertificate(t *testing.T) {
+	tests := map[string]struct {
+		in               *projcontour.ForwardClientCertificate
+		clientValidation bool
+		want             *ForwardClientCertificate
+		wantErr          string
+	}{
+		"nil": {
+			in:   nil,
+			want: nil,
+		},
+		"default mode": {
+			in:   &projcontour.ForwardClientCertificate{},
+			want: &ForwardClientCertificate{Mode: "sanitize"},
+		},
+		"forward only with client validation": {
+			in: &projcontour.ForwardClientCertificate{
+				Mode: "forward-only",
+			},
+			clientValidation: true,
+			want:             &ForwardClientCertificate{Mode: "forward-only"},
+		},
+		"forward only without client validation": {
+			in: &projcontour.ForwardClientCertificate{
+				Mode: "forward-only",
+			},
+			wantErr: `mode "forward-only" requires client validation`,
+		},
+		"sanitize set uri": {
+			in: &projcontour.ForwardClientCertificate{
+				Mode:    "sanitize-set",
+				Subject: true,
+				URI:     true,
+			},
+			clientValidation: true,
+			want: &ForwardClientCertificate{
+				Mode:    "sanitize-set",
+				Subject: true,
+				URI:     true,
+			},
+		},
+		"append forward without client validation": {
+			in: &projcontour.ForwardClientCertificate{
+				Mode: "append-forward",
+				Cert: true,
+			},
+			wantErr: `mode "append-forward" requires client validation`,
+		},
+		"unsupported mode": {
+			in: &projcontour.ForwardClientCertificate{
+				Mode: "always",
+			},
+			clientValidation: true,
+			wantErr:          `unsupported mode "always"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := forwardClientCertificate(tc.in, tc.clientValidation)
+			if tc.wantErr != "" {
+				assert.Equal(t, tc.wantErr, err.Error())
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestCORSPolicy(t *testing.T) {
+	tests := map[string]struct {
+		in      *projcontour.CORSPolicy
+		want    *CORSPolicy
+		wantErr string
+	}{
+		"nil input": {
+			in:   nil,
+			want: nil,
+		},
+		"exact, wildcard and regex origins": {
+			in: &projcontour.CORSPolicy{
+				AllowCredentials: true,
+				AllowOrigin: []string{
+					"*",
+					"https://example.com",
+					"http://example.com:8080",
+					`https://.*\.example\.com`,
+				},
+				AllowMethods:  []projcontour.CORSHeaderValue{"GET", "POST"},
+				AllowHeaders:  []projcontour.CORSHeaderValue{"authorization"},
+				ExposeHeaders: []projcontour.CORSHeaderValue{"x-request-id"},
+				MaxAge:        "10m",
+			},
+			want: &CORSPolicy{
+				AllowCredentials: true,
+				AllowOrigin: []CORSAllowOriginMatch{
+					{Exact: "*"},
+					{Exact: "https://example.com"},
+					{Exact: "http://example.com:8080"},
+					{Regex: `https://.*\.example\.com`},
+				},
+				AllowMethods:  []string{"GET", "POST"},
+				AllowHeaders:  []string{"authorization"},
+				ExposeHeaders: []string{"x-request-id"},
+				MaxAge:        timeout.DurationSetting(10 * time.Minute),
+			},
+		},
+		"zero max age disables caching": {
+			in: &projcontour.CORSPolicy{
+				AllowOrigin:  []string{"*"},
+				AllowMethods: []projcontour.CORSHeaderValue{"GET"},
+				MaxAge:       "0s",
+			},
+			want: &CORSPolicy{
+				AllowOrigin:  []CORSAllowOriginMatch{{Exact: "*"}},
+				AllowMethods: []string{"GET"},
+				MaxAge:       timeout.DisabledSetting(),
+			},
+		},
+		"no origins": {
+			in: &projcontour.CORSPolicy{
+				AllowMethods: []projcontour.CORSHeaderValue{"GET"},
+			},
+			wantErr: "allowOrigin must not be empty",
+		},
+		"invalid origin regex": {
+			in: &projcontour.CORSPolicy{
+				AllowOrigin:  []string{"https://example.com", "(invalid"},
+				AllowMethods: []projcontour.CORSHeaderValue{"GET"},
+			},
+			wantErr: "invalid allowOrigin \"(invalid\": error parsing regexp: missing closing ): `(invalid`",
+		},
+		"invalid max age": {
+			in: &projcontour.CORSPolicy{
+				AllowOrigin:  []string{"*"},
+				AllowMethods: []projcontour.CORSHeaderValue{"GET"},
+				MaxAge:       "forever",
+			},
+			wantErr: "invalid maxAge \"forever\": time: invalid duration \"forever\"",
+		},
+		"negative max age": {
+			in: &projcontour.CORSPolicy{
+				AllowOrigin:  []string{"*"},
+				AllowMethods: []projcontour.CORSHeaderValue{"GET"},
+				MaxAge:       "-1s",
+			},
+			wantErr: "invalid maxAge \"-1s\": must not be negative",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := corsPolicy(tc.in)
+			if tc.wantErr != "" {
+				assert.Equal(t, tc.wantErr, err.Error())
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRedirectPolicy(t *testing.T) {
+	tests := map[string]struct {
+		in      *projcontour.HTTPRequestRedirectPolicy
+		want    *Redirect
+		wantErr string
+	}{
+		"nil input": {
+			in:   nil,
+			want: nil,
+		},
+		"default status code": {
+			in: &projcontour.HTTPRequestRedirectPolicy{
+				Hostname: "example.com",
+			},
+			want: &Redirect{
+				Hostname:   "example.com",
+				StatusCode: 302,
+			},
+		},
+		"all fields": {
+			in: &projcontour.HTTPRequestRedirectPolicy{
+				Scheme:     "https",
+				Hostname:   "example.com",
+				Port:       8443,
+				Prefix:     "/v2",
+				StatusCode: 308,
+			},
+			want: &Redirect{
+				Scheme:     "https",
+				Hostname:   "example.com",
+				Port:       8443,
+				Prefix:     "/v2",
+				StatusCode: 308,
+			},
+		},
+		"invalid scheme": {
+			in: &projcontour.HTTPRequestRedirectPolicy{
+				Scheme: "ftp",
+			},
+			wantErr: "scheme must be one of 'http' or 'https'",
+		},
+		"invalid port": {
+			in: &projcontour.HTTPRequestRedirectPolicy{
+				Port: 65536,
+			},
+			wantErr: "port must be in the range 1-65535",
+		},
+		"path and prefix": {
+			in: &projcontour.HTTPRequestRedirectPolicy{
+				Path:   "/foo",
+				Prefix: "/bar",
+			},
+			wantErr: "only one of path or prefix may be set",
+		},
+		"relative path": {
+			in: &projcontour.HTTPRequestRedirectPolicy{
+				Path: "foo",
+			},
+			wantErr: "path must start with '/'",
+		},
+		"invalid status code": {
+			in: &projcontour.HTTPRequestRedirectPolicy{
+				StatusCode: 303,
+			},
+			wantErr: "statusCode must be one of 301, 302, 307 or 308",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := redirectPolicy(tc.in)
+			if tc.wantErr != "" {
+				assert.Equal(t, tc.wantErr, err.Error())
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestDirectResponsePolicy(t *testing.T) {
+	tests := map[string]struct {
+		in      *projcontour.HTTPDirectResponsePolicy
+		want    *DirectResponse
+		wantErr string
+	}{
+		"nil input": {
+			in:   nil,
+			want: nil,
+		},
+		"status and body": {
+			in: &projcontour.HTTPDirectResponsePolicy{
+				StatusCode: 503,
+				Body:       "down for maintenance",
+			},
+			want: &DirectResponse{
+				StatusCode: 503,
+				Body:       "down for maintenance",
+			},
+		},
+		"invalid status code": {
+			in: &projcontour.HTTPDirectResponsePolicy{
+				StatusCode: 600,
+			},
+			wantErr: "statusCode must be in the 200-599 range",
+		},
+		"body too large": {
+			in: &projcontour.HTTPDirectResponsePolicy{
+				StatusCode: 200,
+				Body:       strings.Repeat("a", 4097),
+			},
+			wantErr: "body must be at most 4096 bytes",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := directResponsePolicy(tc.in)
+			if tc.wantErr != "" {
+				assert.Equal(t, tc.wantErr, err.Error())
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func uint32Ptr(v uint32) *uint32 {
+	return &v
+}
@@ -27,6 +27,9 @@ import (
 // CACertificateKey is the key name for accessing TLS CA certificate bundles in Kubernetes Secrets.
 const CACertificateKey = "ca.crt"
 
+// CRLKey is the key name for accessing certificate revocation lists in Kubernetes Secrets.
+const CRLKey = "crl.pem"
+
 // isValidSecret returns true if the secret is interesting and well
 // formed. TLS certificate/key pairs must be secrets of type
 // "kubernetes.io/tls". Certificate bundles may be "kubernetes.io/tls"
@@ -53,7 +56,7 @@ func isValidSecret(secret *v1.Secret) (bool, error) {
 			return false, fmt.Errorf("invalid TLS private key: %v", err)
 		}
 
-	// Generic secrets may have a 'ca.crt' only.
+	// Generic secrets may have a 'ca.crt' or a 'crl.pem' only.
 	case v1.SecretTypeOpaque, "":
 		if _, ok := secret.Data[v1.TLSCertKey]; ok {
 			return false, nil
@@ -63,7 +66,7 @@ func isValidSecret(secret *v1.Secret) (bool, error) {
 			return false, nil
 		}
 
-		if data := secret.Data[CACertificateKey]; len(data) == 0 {
+		if len(secret.Data[CACertificateKey]) == 0 && len(secret.Data[CRLKey]) == 0 {
 			return false, nil
 		}
 
@@ -82,6 +85,14 @@ func isValidSecret(secret *v1.Secret) (bool, error) {
 		}
 	}
 
+	// If the secret has a CRL key, validate that it
+	// is PEM certificate revocation list(s).
+	if data := secret.Data[CRLKey]; len(data) > 0 {
+		if err := validateCRL(data); err != nil {
+			return false, fmt.Errorf("invalid CRL: %v", err)
+		}
+	}
+
 	return true, nil
 }
 
@@ -145,6 +156,77 @@ func validateCertificate(data []byte) error {
 	return nil
 }
 
+func validateCRL(data []byte) error {
+	var exists bool
+
+	for containsPEMHeader(data) {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return errors.New("failed to parse PEM block")
+		}
+		if block.Type != "X509 CRL" {
+			return fmt.Errorf("unexpected block type '%s'", block.Type)
+		}
+		if _, err := x509.ParseDERCRL(block.Bytes); err != nil {
+			return err
+		}
+
+		exists = true
+	}
+
+	if !exists {
+		return errors.New("failed to locate CRL")
+	}
+
+	return nil
+}
+
+// validateCertificateNames returns an error if the first certificate
+// in data is not valid for each of the names. Certificates without DNS
+// subject alternative names are matched against their common name.
+func validateCertificateNames(data []byte, names []string) error {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return errors.New("failed to locate certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return err
+	}
+
+	patterns := cert.DNSNames
+	if len(patterns) == 0 {
+		patterns = []string{cert.Subject.CommonName}
+	}
+
+	for _, name := range names {
+		if !matchesAnyHostname(patterns, name) {
+			return fmt.Errorf("certificate is not valid for %q", name)
+		}
+	}
+	return nil
+}
+
+// matchesAnyHostname returns true if host matches one of the
+// certificate hostname patterns. A wildcard pattern matches
+// exactly one leftmost label, or the same wildcard host.
+func matchesAnyHostname(patterns []string, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSuffix(p, "."))
+		if p == host {
+			return true
+		}
+		if strings.HasPrefix(p, "*.") {
+			if i := strings.IndexByte(host, '.'); i > 0 && host[i:] == p[1:] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func hasCommonName(c *x509.Certificate) bool {
 	return strings.TrimSpace(c.Subject.CommonName) != ""
 }
@@ -69,6 +69,125 @@ func TestIsValidSecret(t *testing.T) {
 	}
 }
 
+func TestIsValidGenericSecret(t *testing.T) {
+	tests := map[string]struct {
+		data  map[string][]byte
+		valid bool
+		err   error
+	}{
+		"CA bundle": {
+			data:  map[string][]byte{CACertificateKey: []byte(CERTIFICATE)},
+			valid: true,
+		},
+		"CRL": {
+			data:  map[string][]byte{CRLKey: []byte(CRL)},
+			valid: true,
+		},
+		"CA bundle and CRL": {
+			data: map[string][]byte{
+				CACertificateKey: []byte(CERTIFICATE),
+				CRLKey:           []byte(CRL + "\n" + CRL),
+			},
+			valid: true,
+		},
+		"CRL is a certificate": {
+			data:  map[string][]byte{CRLKey: []byte(CERTIFICATE)},
+			valid: false,
+			err:   errors.New("invalid CRL: unexpected block type 'CERTIFICATE'"),
+		},
+		"CRL is not PEM": {
+			data:  map[string][]byte{CRLKey: []byte("not a crl")},
+			valid: false,
+			err:   errors.New("invalid CRL: failed to locate CRL"),
+		},
+		"no interesting keys": {
+			data:  map[string][]byte{"foo": []byte("bar")},
+			valid: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			type Result struct {
+				Valid bool
+				Err   error
+			}
+
+			want := Result{Valid: tc.valid, Err: tc.err}
+
+			valid, err := isValidSecret(&v1.Secret{
+				Type: v1.SecretTypeOpaque,
+				Data: tc.data,
+			})
+			got := Result{Valid: valid, Err: err}
+
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestValidateCertificateNames(t *testing.T) {
+	tests := map[string]struct {
+		cert  string
+		names []string
+		err   error
+	}{
+		"subject alt names": {
+			cert:  EC_CERTIFICATE,
+			names: []string{"www.example.com", "EXAMPLE.com"},
+		},
+		"subject alt name missing": {
+			cert:  EC_CERTIFICATE,
+			names: []string{"www.example.com", "api.example.com"},
+			err:   errors.New(`certificate is not valid for "api.example.com"`),
+		},
+		"common name": {
+			cert:  CERTIFICATE,
+			names: []string{"boring-wozniak.example.com"},
+		},
+		"common name missing": {
+			cert:  CERTIFICATE,
+			names: []string{"www.example.com"},
+			err:   errors.New(`certificate is not valid for "www.example.com"`),
+		},
+		"no certificate": {
+			cert:  "",
+			names: []string{"www.example.com"},
+			err:   errors.New("failed to locate certificate"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateCertificateNames([]byte(tc.cert), tc.names)
+			assert.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestMatchesAnyHostname(t *testing.T) {
+	tests := map[string]struct {
+		patterns []string
+		host     string
+		want     bool
+	}{
+		"exact":                   {[]string{"www.example.com"}, "www.example.com", true},
+		"case insensitive":        {[]string{"WWW.example.com"}, "www.EXAMPLE.com", true},
+		"wildcard":                {[]string{"*.example.com"}, "www.example.com", true},
+		"wildcard one label only": {[]string{"*.example.com"}, "a.b.example.com", false},
+		"wildcard apex":           {[]string{"*.example.com"}, "example.com", false},
+		"wildcard host":           {[]string{"*.example.com"}, "*.example.com", true},
+		"wildcard host mismatch":  {[]string{"www.example.com"}, "*.example.com", false},
+		"no match":                {[]string{"example.com", "www.example.com"}, "api.example.com", false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, matchesAnyHostname(tc.patterns, tc.host))
+		})
+	}
+}
+
 const (
 	// generated by https://www.selfsignedcertificate.com
 	CERTIFICATE = `-----BEGIN CERTIFICATE-----
@@ -202,6 +321,20 @@ dsipT/w+MFInBLLiJcqLAfh0647vhjCFNGG4MZ6YGVwiIdvjZ1dH2Ujsdo8e0+Wi
 b5qYn0JNERfPYdLwXNV1HCM9
 -----END PRIVATE KEY-----
 `
+
+	// CRL is a certificate revocation list issued by CERTIFICATE,
+	// generated with "openssl ca -gencrl".
+	CRL = `-----BEGIN X509 CRL-----
+MIIBfTBnAgEBMA0GCSqGSIb3DQEBCwUAMCUxIzAhBgNVBAMMGmJvcmluZy13b3pu
+aWFrLmV4YW1wbGUuY29tFw0yNjEwMTgxMjUyNDRaFw0zNjEwMTUxMjUyNDRaoA4w
+DDAKBgNVHRQEAwIBATANBgkqhkiG9w0BAQsFAAOCAQEAih35aGwSJVXIPLAeVTkK
+XhCeI1yMgIqFkL85H8mFdIlsrdFJRkr9r2uP7WLiGsoDHmCc6P2SRwANlZZtUeT/
+/BI/Nmx7IoDRCXJ6lFOazLlCCtD3gQpre8Ad/PA7F1ZO0ycOCZebhBpVJiwzpziO
+UJ3mIItB2F22oN5CRYfNWJKmRFDN7SlAAqE7OEjDszBgghsxBW7sZ67gymIvhPMe
+W8Av14S1CyNnhkASr2Ql9qzQ9N8a8l8uwY/qcv4Ynvn/+QFTMjdTwyChLHuGNo0k
++LJ0cOEjyY+EZ9zUF5uTNQkQbcUsPuMtA5cLE9sXgZl4Dg16zUE+YtqIMu8L63gZ
+1Q==
+-----END X509 CRL-----`
 )
 
 func secretdata(cert, key string) map[string][]byte {
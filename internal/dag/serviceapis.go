@@ -0,0 +1,726 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dag
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/annotation"
+	"github.com/projectcontour/contour/internal/k8s"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	serviceapis "sigs.k8s.io/service-apis/api/v1alpha1"
+)
+
+// DefaultGatewayController is the controller name of the
+// GatewayClasses that Contour serves by default.
+const DefaultGatewayController = "projectcontour.io/contour"
+
+// serviceAPIKey identifies a Service APIs object whose status is
+// computed while building the DAG.
+type serviceAPIKey struct {
+	kind string
+	types.NamespacedName
+}
+
+// gatewayListener is a Gateway listener that routes can be attached to.
+type gatewayListener struct {
+	name string
+
+	// secret is the TLS certificate of an HTTPS listener.
+	// It is nil for HTTP listeners.
+	secret *Secret
+
+	minTLSVersion string
+}
+
+// gatewayTLSHost is the Gateway listener that first serves an HTTPS host.
+type gatewayTLSHost struct {
+	gateway  types.NamespacedName
+	listener gatewayListener
+}
+
+// gatewayRouteKey identifies a route of a Gateway listener by its
+// host and match conditions.
+type gatewayRouteKey struct {
+	secure     bool
+	host       string
+	conditions string
+}
+
+// gatewayRouteRef is a route referenced by a Gateway.
+type gatewayRouteRef struct {
+	gw        *serviceapis.Gateway
+	ref       v1.TypedLocalObjectReference
+	listeners []gatewayListener
+
+	// err is the reason the route could not be attached.
+	err error
+}
+
+// gatewayUpdate is the updated status of a Gateway whose routes
+// are yet to be attached.
+type gatewayUpdate struct {
+	updated *serviceapis.Gateway
+	refs    []*gatewayRouteRef
+}
+
+// gatewayController returns the controller name of the GatewayClasses
+// that this builder serves.
+func (b *Builder) gatewayController() string {
+	if b.GatewayController == "" {
+		return DefaultGatewayController
+	}
+	return b.GatewayController
+}
+
+// gatewayHTTPPort returns the port of the Envoy HTTP listener.
+func (b *Builder) gatewayHTTPPort() int {
+	if b.GatewayHTTPPort == 0 {
+		return 8080
+	}
+	return b.GatewayHTTPPort
+}
+
+// gatewayHTTPSPort returns the port of the Envoy HTTPS listener.
+func (b *Builder) gatewayHTTPSPort() int {
+	if b.GatewayHTTPSPort == 0 {
+		return 8443
+	}
+	return b.GatewayHTTPSPort
+}
+
+// computeGateways builds virtual hosts and routes from the Gateways whose
+// GatewayClass is served by Contour, and computes the status of the
+// GatewayClasses, Gateways and HTTPRoutes involved.
+func (b *Builder) computeGateways() {
+	for _, class := range b.Source.gatewayclasses {
+		if class.Spec.Controller != b.gatewayController() {
+			continue
+		}
+		b.computeGatewayClass(class)
+	}
+
+	// Rebuild the status of every HTTPRoute, so that an HTTPRoute
+	// that is no longer attached to a Gateway reports so.
+	for _, route := range b.Source.httproutes {
+		b.resetHTTPRouteStatus(route)
+	}
+
+	var gateways []*serviceapis.Gateway
+	for _, gw := range b.Source.gateways {
+		if b.isContourGateway(gw) {
+			gateways = append(gateways, gw)
+		}
+	}
+	sort.Slice(gateways, func(i, j int) bool {
+		return olderThan(gateways[i], gateways[j])
+	})
+
+	var updates []*gatewayUpdate
+	var refs []*gatewayRouteRef
+	for _, gw := range gateways {
+		if u := b.computeGateway(gw); u != nil {
+			updates = append(updates, u)
+			refs = append(refs, u.refs...)
+		}
+	}
+
+	// Attach the oldest HTTPRoutes first, so that if HTTPRoutes
+	// use the same host and match conditions, or serve the same
+	// HTTPS host differently, the oldest one wins.
+	sort.SliceStable(refs, func(i, j int) bool {
+		ri, rj := b.gatewayHTTPRoute(refs[i]), b.gatewayHTTPRoute(refs[j])
+		switch {
+		case ri == nil:
+			return false
+		case rj == nil:
+			return true
+		default:
+			return olderThan(ri, rj)
+		}
+	})
+	for _, ref := range refs {
+		ref.err = b.computeGatewayRoute(ref.gw, ref.ref, ref.listeners)
+	}
+
+	for _, u := range updates {
+		var routeErrors []string
+		for _, ref := range u.refs {
+			if ref.err != nil {
+				routeErrors = append(routeErrors, fmt.Sprintf("%s %q: %s", ref.ref.Kind, ref.ref.Name, ref.err))
+			}
+		}
+		u.updated.Status.Conditions = append(u.updated.Status.Conditions,
+			gatewayCondition(serviceapis.ConditionInvalidRoutes, strings.Join(routeErrors, "; ")))
+		b.setServiceAPIStatus("Gateway", u.updated)
+	}
+}
+
+// isContourGateway returns true if the Gateway's GatewayClass is served
+// by Contour, or doesn't exist, in which case Contour reports so.
+func (b *Builder) isContourGateway(gw *serviceapis.Gateway) bool {
+	// GatewayClasses are cluster scoped, and so are
+	// cached in the default namespace.
+	class, ok := b.Source.gatewayclasses[types.NamespacedName{
+		Name:      gw.Spec.Class,
+		Namespace: metav1.NamespaceDefault,
+	}]
+	return !ok || class.Spec.Controller == b.gatewayController()
+}
+
+// gatewayHTTPRoute returns the HTTPRoute referenced by a Gateway,
+// or nil if the reference is not to an existing HTTPRoute.
+func (b *Builder) gatewayHTTPRoute(ref *gatewayRouteRef) *serviceapis.HTTPRoute {
+	if ref.ref.Kind != "HTTPRoute" {
+		return nil
+	}
+	return b.Source.httproutes[types.NamespacedName{Name: ref.ref.Name, Namespace: ref.gw.Namespace}]
+}
+
+func (b *Builder) computeGatewayClass(class *serviceapis.GatewayClass) {
+	condition := serviceapis.GatewayClassCondition{
+		Type:   serviceapis.GatewayClassConditionStatusInvalidParameters,
+		Status: v1.ConditionFalse,
+	}
+
+	if class.Spec.ParametersRef != nil {
+		condition.Status = v1.ConditionTrue
+		condition.Reason = stringPtr("ParametersNotSupported")
+		condition.Message = stringPtr("Contour does not support GatewayClass parameters")
+	}
+
+	updated := class.DeepCopy()
+	updated.Status.Conditions = []serviceapis.GatewayClassCondition{condition}
+	b.setServiceAPIStatus("GatewayClass", updated)
+}
+
+// computeGateway validates the listeners of the Gateway. It returns the
+// updated Gateway and its routes, or nil if the Gateway's GatewayClass
+// doesn't exist, in which case the Gateway's status is already recorded.
+func (b *Builder) computeGateway(gw *serviceapis.Gateway) *gatewayUpdate {
+	updated := gw.DeepCopy()
+	updated.Status = serviceapis.GatewayStatus{}
+
+	if _, ok := b.Source.gatewayclasses[types.NamespacedName{Name: gw.Spec.Class, Namespace: metav1.NamespaceDefault}]; !ok {
+		updated.Status.Conditions = append(updated.Status.Conditions,
+			gatewayCondition(serviceapis.ConditionNoSuchGatewayClass, fmt.Sprintf("GatewayClass %q not found", gw.Spec.Class)))
+		b.setServiceAPIStatus("Gateway", updated)
+		return nil
+	}
+	updated.Status.Conditions = append(updated.Status.Conditions,
+		gatewayCondition(serviceapis.ConditionNoSuchGatewayClass, ""))
+
+	var listeners []gatewayListener
+	var listenerErrors []string
+	for _, l := range gw.Spec.Listeners {
+		listener, err := b.gatewayListener(gw, l)
+
+		status := serviceapis.ListenerStatus{
+			Name:    l.Name,
+			Address: l.Address,
+		}
+		condition := serviceapis.ListenerCondition{
+			Type:   serviceapis.ConditionInvalidListener,
+			Status: v1.ConditionFalse,
+		}
+		if err != nil {
+			condition.Status = v1.ConditionTrue
+			condition.Reason = "Invalid"
+			condition.Message = err.Error()
+			listenerErrors = append(listenerErrors, fmt.Sprintf("listener %q: %s", l.Name, err))
+		} else {
+			listeners = append(listeners, *listener)
+		}
+		status.Conditions = []serviceapis.ListenerCondition{condition}
+		updated.Status.Listeners = append(updated.Status.Listeners, status)
+	}
+	updated.Status.Conditions = append(updated.Status.Conditions,
+		gatewayCondition(serviceapis.ConditionInvalidListeners, strings.Join(listenerErrors, "; ")))
+
+	u := &gatewayUpdate{updated: updated}
+	for _, ref := range gw.Spec.Routes {
+		u.refs = append(u.refs, &gatewayRouteRef{
+			gw:        gw,
+			ref:       ref,
+			listeners: listeners,
+		})
+	}
+	return u
+}
+
+// gatewayListener validates the given listener of a Gateway.
+func (b *Builder) gatewayListener(gw *serviceapis.Gateway, l serviceapis.Listener) (*gatewayListener, error) {
+	protocol := serviceapis.HTTPProcotol
+	if l.Protocol != nil {
+		protocol = *l.Protocol
+	}
+
+	if l.Address != nil {
+		return nil, fmt.Errorf("listener addresses are not supported")
+	}
+
+	switch protocol {
+	case serviceapis.HTTPProcotol:
+		if err := checkGatewayPort(l, protocol, b.gatewayHTTPPort()); err != nil {
+			return nil, err
+		}
+		if l.TLS != nil {
+			return nil, fmt.Errorf("TLS cannot be configured on %s listeners", protocol)
+		}
+		return &gatewayListener{name: l.Name}, nil
+	case serviceapis.HTTPSProcotol:
+		if err := checkGatewayPort(l, protocol, b.gatewayHTTPSPort()); err != nil {
+			return nil, err
+		}
+		if l.TLS == nil || len(l.TLS.Certificates) == 0 {
+			return nil, fmt.Errorf("%s listeners must have a TLS certificate", protocol)
+		}
+		if len(l.TLS.Certificates) > 1 {
+			return nil, fmt.Errorf("only one TLS certificate is supported")
+		}
+
+		ref := l.TLS.Certificates[0]
+		if !isCoreKind(ref, "Secret") {
+			return nil, fmt.Errorf("TLS certificate %q must be a Secret", ref.Name)
+		}
+
+		m := types.NamespacedName{Name: ref.Name, Namespace: gw.Namespace}
+		sec, err := b.lookupSecret(m, validSecret)
+		if err != nil {
+			return nil, fmt.Errorf("TLS Secret %q is invalid: %s", m, err)
+		}
+
+		listener := &gatewayListener{
+			name:   l.Name,
+			secret: sec,
+		}
+		if v := l.TLS.MinimumVersion; v != nil {
+			switch *v {
+			case serviceapis.TLS1_3:
+				listener.minTLSVersion = "1.3"
+			case serviceapis.TLS1_2:
+				listener.minTLSVersion = "1.2"
+			}
+		}
+		return listener, nil
+	default:
+		return nil, fmt.Errorf("protocol %q is not supported", protocol)
+	}
+}
+
+// checkGatewayPort returns an error if the listener requests a port
+// other than port, the port of the Envoy listener for the protocol.
+func checkGatewayPort(l serviceapis.Listener, protocol string, port int) error {
+	if l.Port != nil && int(*l.Port) != port {
+		return fmt.Errorf("%s listeners must use port %d", protocol, port)
+	}
+	return nil
+}
+
+// computeGatewayRoute attaches the route referenced by a Gateway to the
+// Gateway's listeners.
+func (b *Builder) computeGatewayRoute(gw *serviceapis.Gateway, ref v1.TypedLocalObjectReference, listeners []gatewayListener) error {
+	group := ""
+	if ref.APIGroup != nil {
+		group = *ref.APIGroup
+	}
+	if group != serviceapis.GroupVersion.Group {
+		return fmt.Errorf("API group %q is not supported", group)
+	}
+
+	m := types.NamespacedName{Name: ref.Name, Namespace: gw.Namespace}
+
+	switch ref.Kind {
+	case "HTTPRoute":
+		route, ok := b.Source.httproutes[m]
+		if !ok {
+			return fmt.Errorf("not found")
+		}
+
+		vhosts, err := b.computeHTTPRoute(route)
+		if err != nil {
+			return err
+		}
+
+		var hosts []string
+		for host := range vhosts {
+			hosts = append(hosts, host)
+		}
+		sort.Strings(hosts)
+
+		// Check every host before adding any routes, so that
+		// an invalid HTTPRoute is not partially attached.
+		claimed := make(map[string]gatewayTLSHost)
+		for _, listener := range listeners {
+			for _, host := range hosts {
+				if err := b.checkGatewayHost(gw, route, listener, host, vhosts[host], claimed); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, listener := range listeners {
+			for _, host := range hosts {
+				b.addGatewayRoutes(route, listener, host, vhosts[host])
+			}
+		}
+		for host, owner := range claimed {
+			b.gatewayTLSHosts[host] = owner
+		}
+
+		b.addHTTPRouteGateway(route, gw)
+		return nil
+	case "TcpRoute":
+		if _, ok := b.Source.tcproutes[m]; !ok {
+			return fmt.Errorf("not found")
+		}
+		// The TcpRoute type of this version of the Service
+		// APIs has no fields, so there is nothing to route,
+		// and no status to record on the TcpRoute itself.
+		return fmt.Errorf("TcpRoutes are not supported")
+	default:
+		return fmt.Errorf("kind %q is not supported", ref.Kind)
+	}
+}
+
+// checkGatewayHost returns an error if the listener cannot serve the
+// given routes of the HTTPRoute for the given host. Hosts owned by
+// HTTPProxies or Ingresses can't be used, so that an HTTPRoute can't
+// replace their routes or TLS configuration. Likewise, an HTTPRoute
+// can't replace the routes of an older HTTPRoute, nor can a listener
+// change the TLS configuration of a host served by another listener.
+// HTTPS hosts not yet served by any listener are recorded in claimed.
+func (b *Builder) checkGatewayHost(gw *serviceapis.Gateway, route *serviceapis.HTTPRoute, listener gatewayListener, host string, routes []*Route, claimed map[string]gatewayTLSHost) error {
+	if owner, ok := b.proxyHosts[host]; ok {
+		return fmt.Errorf("host %q is already used by HTTPProxy %s/%s", host, owner.Namespace, owner.Name)
+	}
+	if ing, ok := b.ingressHosts[host]; ok {
+		return fmt.Errorf("host %q is already used by Ingress %s/%s", host, ing.Namespace, ing.Name)
+	}
+
+	for _, r := range routes {
+		key := gatewayRouteKeyOf(listener, host, r)
+		if owner, ok := b.gatewayRoutes[key]; ok && owner != k8s.NamespacedNameOf(route) {
+			return fmt.Errorf("host %q route %q is already used by HTTPRoute %s", host, key.conditions, owner)
+		}
+	}
+
+	if listener.secret == nil {
+		return nil
+	}
+
+	if host == "*" {
+		return fmt.Errorf("the default host cannot be served by %s listener %q", serviceapis.HTTPSProcotol, listener.name)
+	}
+
+	owner, ok := b.gatewayTLSHosts[host]
+	if !ok {
+		owner, ok = claimed[host]
+	}
+	if !ok {
+		claimed[host] = gatewayTLSHost{gateway: k8s.NamespacedNameOf(gw), listener: listener}
+		return nil
+	}
+
+	switch {
+	case k8s.NamespacedNameOf(owner.listener.secret.Object) != k8s.NamespacedNameOf(listener.secret.Object):
+		return fmt.Errorf("host %q is already served by Gateway %s with a different TLS certificate", host, owner.gateway)
+	case owner.listener.minTLSVersion != listener.minTLSVersion:
+		return fmt.Errorf("host %q is already served by Gateway %s with a different minimum TLS version", host, owner.gateway)
+	}
+	return nil
+}
+
+// gatewayRouteKeyOf returns the key of a route of the listener.
+func gatewayRouteKeyOf(listener gatewayListener, host string, r *Route) gatewayRouteKey {
+	return gatewayRouteKey{
+		secure:     listener.secret != nil,
+		host:       host,
+		conditions: conditionsToString(r),
+	}
+}
+
+// addGatewayRoutes adds the routes of the HTTPRoute for the given host
+// to the virtual host of the listener, and records their owner.
+func (b *Builder) addGatewayRoutes(route *serviceapis.HTTPRoute, listener gatewayListener, host string, routes []*Route) {
+	for _, r := range routes {
+		b.gatewayRoutes[gatewayRouteKeyOf(listener, host, r)] = k8s.NamespacedNameOf(route)
+	}
+
+	if listener.secret == nil {
+		addRoutes(b.lookupVirtualHost(host), routes)
+		return
+	}
+
+	svh := b.lookupSecureVirtualHost(host)
+	svh.Secret = listener.secret
+	svh.MinTLSVersion = annotation.MinTLSVersion(listener.minTLSVersion)
+	addRoutes(svh, routes)
+}
+
+// computeHTTPRoute returns the routes of the HTTPRoute, keyed by host name.
+// The default host of the HTTPRoute is keyed by "*".
+func (b *Builder) computeHTTPRoute(route *serviceapis.HTTPRoute) (map[string][]*Route, error) {
+	vhosts := make(map[string][]*Route)
+
+	hosts := route.Spec.Hosts
+	if route.Spec.Default != nil {
+		def := *route.Spec.Default
+		def.Hostnames = []string{"*"}
+		hosts = append(hosts, def)
+	}
+
+	for _, host := range hosts {
+		if host.Extension != nil {
+			return nil, fmt.Errorf("host extensions are not supported")
+		}
+		if len(host.Hostnames) == 0 {
+			return nil, fmt.Errorf("hosts must have at least one hostname")
+		}
+
+		var routes []*Route
+		for i, rule := range host.Rules {
+			r, err := b.httpRouteRule(route.Namespace, rule)
+			if err != nil {
+				return nil, fmt.Errorf("rules[%d]: %s", i, err)
+			}
+			routes = append(routes, r)
+		}
+
+		for _, hostname := range host.Hostnames {
+			if hostname != "*" && strings.Contains(hostname, "*") {
+				return nil, fmt.Errorf("hostname %q cannot use wildcards", hostname)
+			}
+			vhosts[hostname] = append(vhosts[hostname], routes...)
+		}
+	}
+
+	return vhosts, nil
+}
+
+// httpRouteRule builds a Route from a rule of an HTTPRoute.
+func (b *Builder) httpRouteRule(namespace string, rule serviceapis.HTTPRouteRule) (*Route, error) {
+	r := &Route{
+		PathMatchCondition: &PrefixMatchCondition{Prefix: "/"},
+	}
+
+	if match := rule.Match; match != nil {
+		if match.Extension != nil {
+			return nil, fmt.Errorf("match extensions are not supported")
+		}
+
+		path := "/"
+		if match.Path != nil {
+			path = *match.Path
+		}
+
+		switch match.PathType {
+		case "", serviceapis.PathTypePrefix, serviceapis.PathTypeImplementionSpecific:
+			r.PathMatchCondition = &PrefixMatchCondition{Prefix: path}
+		case serviceapis.PathTypeExact:
+			r.PathMatchCondition = &ExactMatchCondition{Path: path}
+		case serviceapis.PathTypeRegularExpression:
+			if _, err := regexp.Compile(path); err != nil {
+				return nil, fmt.Errorf("invalid path regex %q: %s", path, err)
+			}
+			r.PathMatchCondition = &RegexMatchCondition{Regex: path}
+		default:
+			return nil, fmt.Errorf("path type %q is not supported", match.PathType)
+		}
+
+		if match.HeaderType != nil && *match.HeaderType != serviceapis.HeaderTypeExact {
+			return nil, fmt.Errorf("header type %q is not supported", *match.HeaderType)
+		}
+
+		var names []string
+		for name := range match.Header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			r.HeaderMatchConditions = append(r.HeaderMatchConditions, HeaderMatchCondition{
+				Name:      name,
+				Value:     match.Header[name],
+				MatchType: "exact",
+			})
+		}
+	}
+
+	if filter := rule.Filter; filter != nil {
+		if filter.Extension != nil {
+			return nil, fmt.Errorf("filter extensions are not supported")
+		}
+
+		if filter.Headers != nil {
+			hp := &projcontour.HeadersPolicy{
+				Remove: filter.Headers.Remove,
+			}
+			var names []string
+			for name := range filter.Headers.Add {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				hp.Set = append(hp.Set, projcontour.HeaderValue{
+					Name:  name,
+					Value: filter.Headers.Add[name],
+				})
+			}
+
+			reqHP, err := headersPolicy(hp, true /* allow Host */)
+			if err != nil {
+				return nil, err
+			}
+			r.RequestHeadersPolicy = reqHP
+		}
+	}
+
+	action := rule.Action
+	if action == nil || action.ForwardTo == nil {
+		if action != nil && action.Extension != nil {
+			return nil, fmt.Errorf("action extensions are not supported")
+		}
+		return nil, fmt.Errorf("action.forwardTo must be set")
+	}
+
+	ref := action.ForwardTo
+	if !isCoreKind(*ref, "Service") {
+		return nil, fmt.Errorf("action.forwardTo must be a Service")
+	}
+
+	s, err := b.lookupServiceReference(types.NamespacedName{Name: ref.Name, Namespace: namespace})
+	if err != nil {
+		return nil, err
+	}
+
+	r.Clusters = []*Cluster{{
+		Upstream: s,
+		Protocol: s.Protocol,
+		SNI:      determineSNI(r.RequestHeadersPolicy, nil, s),
+	}}
+
+	return r, nil
+}
+
+// lookupServiceReference returns the Service for a reference that has no
+// port. The referenced Service must have exactly one port.
+func (b *Builder) lookupServiceReference(m types.NamespacedName) (*Service, error) {
+	svc, ok := b.Source.services[m]
+	if !ok {
+		return nil, fmt.Errorf("service %q not found", m)
+	}
+	if len(svc.Spec.Ports) != 1 {
+		return nil, fmt.Errorf("service %q must have exactly one port", m)
+	}
+
+	return b.lookupService(m, intstr.FromInt(int(svc.Spec.Ports[0].Port)))
+}
+
+// resetHTTPRouteStatus records the status of the HTTPRoute as attached
+// to no Gateway served by Contour. Gateways served by other controllers
+// are kept, as their controllers own those entries.
+func (b *Builder) resetHTTPRouteStatus(route *serviceapis.HTTPRoute) {
+	updated := route.DeepCopy()
+	updated.Status.Gateways = []v1.ObjectReference{}
+	for _, ref := range route.Status.Gateways {
+		if ref.Kind != "Gateway" {
+			continue
+		}
+		gw, ok := b.Source.gateways[types.NamespacedName{Name: ref.Name, Namespace: ref.Namespace}]
+		if ok && !b.isContourGateway(gw) {
+			updated.Status.Gateways = append(updated.Status.Gateways, ref)
+		}
+	}
+	b.setServiceAPIStatus("HTTPRoute", updated)
+}
+
+// addHTTPRouteGateway records that the HTTPRoute is attached to the Gateway.
+func (b *Builder) addHTTPRouteGateway(route *serviceapis.HTTPRoute, gw *serviceapis.Gateway) {
+	key := serviceAPIKey{kind: "HTTPRoute", NamespacedName: k8s.NamespacedNameOf(route)}
+
+	updated, ok := b.serviceAPIStatuses[key].(*serviceapis.HTTPRoute)
+	if !ok {
+		updated = route.DeepCopy()
+		updated.Status = serviceapis.HTTPRouteStatus{}
+	}
+
+	updated.Status.Gateways = append(updated.Status.Gateways, v1.ObjectReference{
+		APIVersion: serviceapis.GroupVersion.String(),
+		Kind:       "Gateway",
+		Namespace:  gw.Namespace,
+		Name:       gw.Name,
+	})
+	b.setServiceAPIStatus("HTTPRoute", updated)
+}
+
+// setServiceAPIStatus records the updated status of a Service APIs object.
+func (b *Builder) setServiceAPIStatus(kind string, obj k8s.Object) {
+	b.serviceAPIStatuses[serviceAPIKey{kind: kind, NamespacedName: k8s.NamespacedNameOf(obj)}] = obj
+}
+
+// gatewayCondition returns a Gateway condition of the given type. The
+// condition is true, i.e. abnormal, if message is not empty.
+func gatewayCondition(conditionType serviceapis.GatewayConditionType, message string) serviceapis.GatewayCondition {
+	if message == "" {
+		return serviceapis.GatewayCondition{
+			Type:   conditionType,
+			Status: v1.ConditionFalse,
+		}
+	}
+
+	return serviceapis.GatewayCondition{
+		Type:    conditionType,
+		Status:  v1.ConditionTrue,
+		Reason:  string(conditionType),
+		Message: message,
+	}
+}
+
+// isCoreKind returns true if ref refers to an object of the
+// given kind in the core API group.
+func isCoreKind(ref v1.TypedLocalObjectReference, kind string) bool {
+	return (ref.APIGroup == nil || *ref.APIGroup == "") && ref.Kind == kind
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+// sortedServiceAPIStatuses returns the updated Service APIs objects
+// ordered by kind, namespace and name.
+func sortedServiceAPIStatuses(statuses map[serviceAPIKey]k8s.Object) []k8s.Object {
+	var keys []serviceAPIKey
+	for k := range statuses {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].kind != keys[j].kind {
+			return keys[i].kind < keys[j].kind
+		}
+		return keys[i].String() < keys[j].String()
+	})
+
+	var objs []k8s.Object
+	for _, k := range keys {
+		objs = append(objs, statuses[k])
+	}
+	return objs
+}
@@ -0,0 +1,866 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dag
+
+import (
+	"testing"
+	"time"
+
+	envoy_api_v2_auth "github.com/envoyproxy/go-control-plane/envoy/api/v2/auth"
+	"github.com/google/go-cmp/cmp"
+	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/assert"
+	"github.com/projectcontour/contour/internal/k8s"
+	"github.com/projectcontour/contour/internal/timeout"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	serviceapis "sigs.k8s.io/service-apis/api/v1alpha1"
+)
+
+func TestDAGInsertServiceAPIs(t *testing.T) {
+	sec1 := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret",
+			Namespace: "default",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: secretdata(CERTIFICATE, RSA_PRIVATE_KEY),
+	}
+
+	s1 := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kuard",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{
+				Protocol:   "TCP",
+				Port:       8080,
+				TargetPort: intstr.FromInt(8080),
+			}},
+		},
+	}
+
+	// s2 has two ports, so it cannot be used by an HTTPRoute.
+	s2 := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "multiport",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{
+				Name:     "http",
+				Protocol: "TCP",
+				Port:     8080,
+			}, {
+				Name:     "metrics",
+				Protocol: "TCP",
+				Port:     9000,
+			}},
+		},
+	}
+
+	class := &serviceapis.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "contour",
+		},
+		Spec: serviceapis.GatewayClassSpec{
+			Controller: DefaultGatewayController,
+		},
+	}
+
+	otherClass := &serviceapis.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "other",
+		},
+		Spec: serviceapis.GatewayClassSpec{
+			Controller: "example.com/other",
+		},
+	}
+
+	route := &serviceapis.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "route",
+			Namespace: "default",
+		},
+		Spec: serviceapis.HTTPRouteSpec{
+			Hosts: []serviceapis.HTTPRouteHost{{
+				Hostnames: []string{"example.com"},
+				Rules: []serviceapis.HTTPRouteRule{{
+					Match: &serviceapis.HTTPRouteMatch{
+						PathType: serviceapis.PathTypePrefix,
+						Path:     stringPtr("/"),
+					},
+					Action: &serviceapis.HTTPRouteAction{
+						ForwardTo: &v1.TypedLocalObjectReference{
+							Kind: "Service",
+							Name: "kuard",
+						},
+					},
+				}},
+			}},
+		},
+	}
+
+	// s3 is the backend of newerRoute.
+	s3 := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kuard2",
+			Namespace: "default",
+		},
+		Spec: s1.Spec,
+	}
+
+	// newerRoute uses the same host and match as route.
+	newerRoute := route.DeepCopy()
+	newerRoute.Name = "newer"
+	newerRoute.CreationTimestamp = metav1.NewTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	newerRoute.Spec.Hosts[0].Rules[0].Action.ForwardTo.Name = "kuard2"
+
+	routeRef := v1.TypedLocalObjectReference{
+		APIGroup: stringPtr(serviceapis.GroupVersion.Group),
+		Kind:     "HTTPRoute",
+		Name:     "route",
+	}
+
+	newerRouteRef := v1.TypedLocalObjectReference{
+		APIGroup: stringPtr(serviceapis.GroupVersion.Group),
+		Kind:     "HTTPRoute",
+		Name:     "newer",
+	}
+
+	gateway := func(class string, listeners ...serviceapis.Listener) *serviceapis.Gateway {
+		return &serviceapis.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "gateway",
+				Namespace: "default",
+			},
+			Spec: serviceapis.GatewaySpec{
+				Class:     class,
+				Listeners: listeners,
+				Routes:    []v1.TypedLocalObjectReference{routeRef},
+			},
+		}
+	}
+
+	httpListener := serviceapis.Listener{
+		Name:     "http",
+		Protocol: stringPtr(serviceapis.HTTPProcotol),
+	}
+
+	httpsListener := serviceapis.Listener{
+		Name:     "https",
+		Protocol: stringPtr(serviceapis.HTTPSProcotol),
+		TLS: &serviceapis.ListenerTLS{
+			Certificates: []v1.TypedLocalObjectReference{{
+				Kind: "Secret",
+				Name: "secret",
+			}},
+			MinimumVersion: stringPtr(serviceapis.TLS1_2),
+		},
+	}
+
+	tls13Listener := *httpsListener.DeepCopy()
+	tls13Listener.TLS.MinimumVersion = stringPtr(serviceapis.TLS1_3)
+
+	// newerGateway returns a Gateway that is newer than the
+	// Gateway returned by gateway.
+	newerGateway := func(listeners ...serviceapis.Listener) *serviceapis.Gateway {
+		gw := gateway("contour", listeners...)
+		gw.Name = "newer"
+		gw.CreationTimestamp = metav1.NewTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+		return gw
+	}
+
+	tests := map[string]struct {
+		objs []interface{}
+		want []Vertex
+	}{
+		"http listener": {
+			objs: []interface{}{s1, class, route, gateway("contour", httpListener)},
+			want: listeners(
+				&Listener{
+					Port:         80,
+					VirtualHosts: virtualhosts(virtualhost("example.com", prefixroute("/", service(s1)))),
+				},
+			),
+		},
+		"https listener": {
+			objs: []interface{}{s1, sec1, class, route, gateway("contour", httpsListener)},
+			want: listeners(
+				&Listener{
+					Port: 443,
+					VirtualHosts: virtualhosts(
+						&SecureVirtualHost{
+							VirtualHost: VirtualHost{
+								Name:   "example.com",
+								routes: routes(prefixroute("/", service(s1))),
+							},
+							MinTLSVersion: envoy_api_v2_auth.TlsParameters_TLSv1_2,
+							Secret:        secret(sec1),
+						},
+					),
+				},
+			),
+		},
+		"https listener with missing secret": {
+			objs: []interface{}{s1, class, route, gateway("contour", httpsListener)},
+			want: listeners(),
+		},
+		"gateway of another controller": {
+			objs: []interface{}{s1, otherClass, route, gateway("other", httpListener)},
+			want: listeners(),
+		},
+		"gateway with missing class": {
+			objs: []interface{}{s1, route, gateway("contour", httpListener)},
+			want: listeners(),
+		},
+		"default host, exact path and header match": {
+			objs: []interface{}{
+				s1,
+				class,
+				&serviceapis.HTTPRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "route",
+						Namespace: "default",
+					},
+					Spec: serviceapis.HTTPRouteSpec{
+						Default: &serviceapis.HTTPRouteHost{
+							Rules: []serviceapis.HTTPRouteRule{{
+								Match: &serviceapis.HTTPRouteMatch{
+									PathType: serviceapis.PathTypeExact,
+									Path:     stringPtr("/healthz"),
+									Header: map[string]string{
+										"x-b": "2",
+										"x-a": "1",
+									},
+								},
+								Filter: &serviceapis.HTTPRouteFilter{
+									Headers: &serviceapis.HTTPHeaderFilter{
+										Add:    map[string]string{"x-contour": "gateway"},
+										Remove: []string{"x-internal"},
+									},
+								},
+								Action: &serviceapis.HTTPRouteAction{
+									ForwardTo: &v1.TypedLocalObjectReference{
+										Kind: "Service",
+										Name: "kuard",
+									},
+								},
+							}},
+						},
+					},
+				},
+				gateway("contour", httpListener),
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(
+						virtualhost("*", &Route{
+							PathMatchCondition: &ExactMatchCondition{Path: "/healthz"},
+							HeaderMatchConditions: []HeaderMatchCondition{
+								{Name: "x-a", Value: "1", MatchType: "exact"},
+								{Name: "x-b", Value: "2", MatchType: "exact"},
+							},
+							RequestHeadersPolicy: &HeadersPolicy{
+								Set:    map[string]string{"X-Contour": "gateway"},
+								Remove: []string{"X-Internal"},
+							},
+							Clusters: clusters(service(s1)),
+						}),
+					),
+				},
+			),
+		},
+		"listener on another port": {
+			objs: []interface{}{s1, class, route, gateway("contour", serviceapis.Listener{
+				Name:     "http",
+				Protocol: stringPtr(serviceapis.HTTPProcotol),
+				Port:     int32Ptr(9000),
+			})},
+			want: listeners(),
+		},
+		"listener on the envoy http port": {
+			objs: []interface{}{s1, class, route, gateway("contour", serviceapis.Listener{
+				Name:     "http",
+				Protocol: stringPtr(serviceapis.HTTPProcotol),
+				Port:     int32Ptr(8080),
+			})},
+			want: listeners(
+				&Listener{
+					Port:         80,
+					VirtualHosts: virtualhosts(virtualhost("example.com", prefixroute("/", service(s1)))),
+				},
+			),
+		},
+		"listener with an address": {
+			objs: []interface{}{s1, class, route, gateway("contour", serviceapis.Listener{
+				Name:     "http",
+				Protocol: stringPtr(serviceapis.HTTPProcotol),
+				Address: &serviceapis.ListenerAddress{
+					Type:  serviceapis.IPAddress,
+					Value: "10.0.0.1",
+				},
+			})},
+			want: listeners(),
+		},
+		"route on a host owned by an httpproxy": {
+			objs: []interface{}{
+				s1,
+				class,
+				route,
+				&projcontour.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "proxy",
+						Namespace: "other",
+					},
+					Spec: projcontour.HTTPProxySpec{
+						VirtualHost: &projcontour.VirtualHost{
+							Fqdn: "example.com",
+						},
+						Routes: []projcontour.Route{{
+							Conditions: []projcontour.MatchCondition{{
+								Prefix: "/proxy",
+							}},
+							Services: []projcontour.Service{{
+								Name: "kuard",
+								Port: 8080,
+							}},
+						}},
+					},
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "kuard",
+						Namespace: "other",
+					},
+					Spec: s1.Spec,
+				},
+				gateway("contour", httpListener),
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(virtualhost("example.com", prefixroute("/proxy", &Service{
+						Name:      "kuard",
+						Namespace: "other",
+						ServicePort: v1.ServicePort{
+							Protocol:   "TCP",
+							Port:       8080,
+							TargetPort: intstr.FromInt(8080),
+						},
+					}))),
+				},
+			),
+		},
+		"route on an httpproxy alias": {
+			objs: []interface{}{
+				s1,
+				class,
+				route,
+				&projcontour.HTTPProxy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "proxy",
+						Namespace: "default",
+					},
+					Spec: projcontour.HTTPProxySpec{
+						VirtualHost: &projcontour.VirtualHost{
+							Fqdn:    "www.example.com",
+							Aliases: []string{"example.com"},
+						},
+						Routes: []projcontour.Route{{
+							Services: []projcontour.Service{{
+								Name: "kuard",
+								Port: 8080,
+							}},
+						}},
+					},
+				},
+				gateway("contour", httpListener),
+			},
+			want: listeners(
+				&Listener{
+					Port: 80,
+					VirtualHosts: virtualhosts(&VirtualHost{
+						Name:    "www.example.com",
+						Aliases: []string{"example.com"},
+						routes:  routes(prefixroute("/", service(s1))),
+					}),
+				},
+			),
+		},
+		"route on a host owned by an ingress": {
+			objs: []interface{}{
+				s1,
+				class,
+				route,
+				&v1beta1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "ingress",
+						Namespace: "default",
+					},
+					Spec: v1beta1.IngressSpec{
+						Rules: []v1beta1.IngressRule{{
+							Host: "example.com",
+							IngressRuleValue: v1beta1.IngressRuleValue{
+								HTTP: &v1beta1.HTTPIngressRuleValue{
+									Paths: []v1beta1.HTTPIngressPath{{
+										Path:    "/ingress",
+										Backend: *backend("kuard", intstr.FromInt(8080)),
+									}},
+								},
+							},
+						}},
+					},
+				},
+				gateway("contour", httpListener),
+			},
+			want: listeners(
+				&Listener{
+					Port:         80,
+					VirtualHosts: virtualhosts(virtualhost("example.com", prefixroute("/ingress", service(s1)))),
+				},
+			),
+		},
+		"newer route with the same host and match": {
+			objs: []interface{}{
+				s1,
+				s3,
+				class,
+				route,
+				newerRoute,
+				&serviceapis.Gateway{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "gateway",
+						Namespace: "default",
+					},
+					Spec: serviceapis.GatewaySpec{
+						Class:     "contour",
+						Listeners: []serviceapis.Listener{httpListener},
+						Routes:    []v1.TypedLocalObjectReference{routeRef, newerRouteRef},
+					},
+				},
+			},
+			want: listeners(
+				&Listener{
+					Port:         80,
+					VirtualHosts: virtualhosts(virtualhost("example.com", prefixroute("/", service(s1)))),
+				},
+			),
+		},
+		"newer gateway with a different minimum tls version": {
+			objs: []interface{}{
+				s1,
+				sec1,
+				class,
+				route,
+				newerGateway(tls13Listener),
+				gateway("contour", httpsListener),
+			},
+			want: listeners(
+				&Listener{
+					Port: 443,
+					VirtualHosts: virtualhosts(
+						&SecureVirtualHost{
+							VirtualHost: VirtualHost{
+								Name:   "example.com",
+								routes: routes(prefixroute("/", service(s1))),
+							},
+							MinTLSVersion: envoy_api_v2_auth.TlsParameters_TLSv1_2,
+							Secret:        secret(sec1),
+						},
+					),
+				},
+			),
+		},
+		"forwardTo a service with several ports": {
+			objs: []interface{}{
+				s2,
+				class,
+				&serviceapis.HTTPRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "route",
+						Namespace: "default",
+					},
+					Spec: serviceapis.HTTPRouteSpec{
+						Hosts: []serviceapis.HTTPRouteHost{{
+							Hostnames: []string{"example.com"},
+							Rules: []serviceapis.HTTPRouteRule{{
+								Action: &serviceapis.HTTPRouteAction{
+									ForwardTo: &v1.TypedLocalObjectReference{
+										Kind: "Service",
+										Name: "multiport",
+									},
+								},
+							}},
+						}},
+					},
+				},
+				gateway("contour", httpListener),
+			},
+			want: listeners(),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := Builder{
+				Source: KubernetesCache{
+					FieldLogger: testLogger(t),
+				},
+			}
+			for _, o := range tc.objs {
+				builder.Source.Insert(o)
+			}
+			dag := builder.Build()
+
+			got := make(map[int]*Listener)
+			dag.Visit(listenerMap(got).Visit)
+
+			want := make(map[int]*Listener)
+			for _, v := range tc.want {
+				if l, ok := v.(*Listener); ok {
+					want[l.Port] = l
+				}
+			}
+			opts := []cmp.Option{
+				cmp.AllowUnexported(VirtualHost{}),
+				cmp.AllowUnexported(timeout.Setting{}),
+			}
+			if diff := cmp.Diff(want, got, opts...); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestServiceAPIStatus(t *testing.T) {
+	s1 := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kuard",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{
+				Protocol:   "TCP",
+				Port:       8080,
+				TargetPort: intstr.FromInt(8080),
+			}},
+		},
+	}
+
+	class := &serviceapis.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "contour",
+		},
+		Spec: serviceapis.GatewayClassSpec{
+			Controller: DefaultGatewayController,
+			ParametersRef: &v1.ObjectReference{
+				Kind: "ConfigMap",
+				Name: "params",
+			},
+		},
+	}
+
+	route := &serviceapis.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "route",
+			Namespace: "default",
+		},
+		Spec: serviceapis.HTTPRouteSpec{
+			Hosts: []serviceapis.HTTPRouteHost{{
+				Hostnames: []string{"example.com"},
+				Rules: []serviceapis.HTTPRouteRule{{
+					Action: &serviceapis.HTTPRouteAction{
+						ForwardTo: &v1.TypedLocalObjectReference{
+							Kind: "Service",
+							Name: "kuard",
+						},
+					},
+				}},
+			}},
+		},
+	}
+
+	tcproute := &serviceapis.TcpRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tcp",
+			Namespace: "default",
+		},
+	}
+
+	gw := &serviceapis.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "gateway",
+			Namespace: "default",
+		},
+		Spec: serviceapis.GatewaySpec{
+			Class: "contour",
+			Listeners: []serviceapis.Listener{{
+				Name: "http",
+			}, {
+				Name:     "https",
+				Protocol: stringPtr(serviceapis.HTTPSProcotol),
+			}, {
+				Name: "alt",
+				Port: int32Ptr(8081),
+			}},
+			Routes: []v1.TypedLocalObjectReference{{
+				APIGroup: stringPtr(serviceapis.GroupVersion.Group),
+				Kind:     "HTTPRoute",
+				Name:     "route",
+			}, {
+				APIGroup: stringPtr(serviceapis.GroupVersion.Group),
+				Kind:     "TcpRoute",
+				Name:     "tcp",
+			}},
+		},
+	}
+
+	builder := Builder{
+		Source: KubernetesCache{
+			FieldLogger: testLogger(t),
+		},
+	}
+	for _, o := range []interface{}{s1, class, route, tcproute, gw} {
+		builder.Source.Insert(o)
+	}
+
+	got := builder.Build().ServiceAPIStatuses()
+
+	wantClass := class.DeepCopy()
+	wantClass.Status.Conditions = []serviceapis.GatewayClassCondition{{
+		Type:    serviceapis.GatewayClassConditionStatusInvalidParameters,
+		Status:  v1.ConditionTrue,
+		Reason:  stringPtr("ParametersNotSupported"),
+		Message: stringPtr("Contour does not support GatewayClass parameters"),
+	}}
+
+	wantGateway := gw.DeepCopy()
+	wantGateway.Status = serviceapis.GatewayStatus{
+		Conditions: []serviceapis.GatewayCondition{{
+			Type:   serviceapis.ConditionNoSuchGatewayClass,
+			Status: v1.ConditionFalse,
+		}, {
+			Type:    serviceapis.ConditionInvalidListeners,
+			Status:  v1.ConditionTrue,
+			Reason:  string(serviceapis.ConditionInvalidListeners),
+			Message: `listener "https": HTTPS listeners must have a TLS certificate; listener "alt": HTTP listeners must use port 8080`,
+		}, {
+			Type:    serviceapis.ConditionInvalidRoutes,
+			Status:  v1.ConditionTrue,
+			Reason:  string(serviceapis.ConditionInvalidRoutes),
+			Message: `TcpRoute "tcp": TcpRoutes are not supported`,
+		}},
+		Listeners: []serviceapis.ListenerStatus{{
+			Name: "http",
+			Conditions: []serviceapis.ListenerCondition{{
+				Type:   serviceapis.ConditionInvalidListener,
+				Status: v1.ConditionFalse,
+			}},
+		}, {
+			Name: "https",
+			Conditions: []serviceapis.ListenerCondition{{
+				Type:    serviceapis.ConditionInvalidListener,
+				Status:  v1.ConditionTrue,
+				Reason:  "Invalid",
+				Message: "HTTPS listeners must have a TLS certificate",
+			}},
+		}, {
+			Name: "alt",
+			Conditions: []serviceapis.ListenerCondition{{
+				Type:    serviceapis.ConditionInvalidListener,
+				Status:  v1.ConditionTrue,
+				Reason:  "Invalid",
+				Message: "HTTP listeners must use port 8080",
+			}},
+		}},
+	}
+
+	wantRoute := route.DeepCopy()
+	wantRoute.Status.Gateways = []v1.ObjectReference{{
+		APIVersion: serviceapis.GroupVersion.String(),
+		Kind:       "Gateway",
+		Namespace:  "default",
+		Name:       "gateway",
+	}}
+
+	assert.Equal(t, []k8s.Object{wantGateway, wantClass, wantRoute}, got)
+}
+
+func TestHTTPRouteStatus(t *testing.T) {
+	s1 := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kuard",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{
+				Protocol:   "TCP",
+				Port:       8080,
+				TargetPort: intstr.FromInt(8080),
+			}},
+		},
+	}
+
+	class := &serviceapis.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "contour",
+		},
+		Spec: serviceapis.GatewayClassSpec{
+			Controller: DefaultGatewayController,
+		},
+	}
+
+	otherClass := &serviceapis.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "other",
+		},
+		Spec: serviceapis.GatewayClassSpec{
+			Controller: "example.com/other",
+		},
+	}
+
+	gatewayRef := func(name string) v1.ObjectReference {
+		return v1.ObjectReference{
+			APIVersion: serviceapis.GroupVersion.String(),
+			Kind:       "Gateway",
+			Namespace:  "default",
+			Name:       name,
+		}
+	}
+
+	route := &serviceapis.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "route",
+			Namespace: "default",
+		},
+		Spec: serviceapis.HTTPRouteSpec{
+			Hosts: []serviceapis.HTTPRouteHost{{
+				Hostnames: []string{"example.com"},
+				Rules: []serviceapis.HTTPRouteRule{{
+					Action: &serviceapis.HTTPRouteAction{
+						ForwardTo: &v1.TypedLocalObjectReference{
+							Kind: "Service",
+							Name: "kuard",
+						},
+					},
+				}},
+			}},
+		},
+	}
+
+	// newer uses the same host and match as route.
+	newer := route.DeepCopy()
+	newer.Name = "newer"
+	newer.CreationTimestamp = metav1.NewTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer.Status.Gateways = []v1.ObjectReference{gatewayRef("gateway")}
+
+	// detached is not referenced by any Gateway, but was
+	// attached to a deleted Gateway and to the Gateway of
+	// another controller.
+	detached := route.DeepCopy()
+	detached.Name = "detached"
+	detached.Status.Gateways = []v1.ObjectReference{gatewayRef("deleted"), gatewayRef("other")}
+
+	gw := &serviceapis.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "gateway",
+			Namespace: "default",
+		},
+		Spec: serviceapis.GatewaySpec{
+			Class: "contour",
+			Listeners: []serviceapis.Listener{{
+				Name: "http",
+			}},
+			Routes: []v1.TypedLocalObjectReference{{
+				APIGroup: stringPtr(serviceapis.GroupVersion.Group),
+				Kind:     "HTTPRoute",
+				Name:     "newer",
+			}, {
+				APIGroup: stringPtr(serviceapis.GroupVersion.Group),
+				Kind:     "HTTPRoute",
+				Name:     "route",
+			}},
+		},
+	}
+
+	otherGateway := &serviceapis.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "other",
+			Namespace: "default",
+		},
+		Spec: serviceapis.GatewaySpec{
+			Class: "other",
+		},
+	}
+
+	builder := Builder{
+		Source: KubernetesCache{
+			FieldLogger: testLogger(t),
+		},
+	}
+	for _, o := range []interface{}{s1, class, otherClass, route, newer, detached, gw, otherGateway} {
+		builder.Source.Insert(o)
+	}
+
+	got := builder.Build().ServiceAPIStatuses()
+
+	wantGateway := gw.DeepCopy()
+	wantGateway.Status = serviceapis.GatewayStatus{
+		Conditions: []serviceapis.GatewayCondition{{
+			Type:   serviceapis.ConditionNoSuchGatewayClass,
+			Status: v1.ConditionFalse,
+		}, {
+			Type:   serviceapis.ConditionInvalidListeners,
+			Status: v1.ConditionFalse,
+		}, {
+			Type:    serviceapis.ConditionInvalidRoutes,
+			Status:  v1.ConditionTrue,
+			Reason:  string(serviceapis.ConditionInvalidRoutes),
+			Message: `HTTPRoute "newer": host "example.com" route "prefix: /" is already used by HTTPRoute default/route`,
+		}},
+		Listeners: []serviceapis.ListenerStatus{{
+			Name: "http",
+			Conditions: []serviceapis.ListenerCondition{{
+				Type:   serviceapis.ConditionInvalidListener,
+				Status: v1.ConditionFalse,
+			}},
+		}},
+	}
+
+	wantClass := class.DeepCopy()
+	wantClass.Status.Conditions = []serviceapis.GatewayClassCondition{{
+		Type:   serviceapis.GatewayClassConditionStatusInvalidParameters,
+		Status: v1.ConditionFalse,
+	}}
+
+	wantDetached := detached.DeepCopy()
+	wantDetached.Status.Gateways = []v1.ObjectReference{gatewayRef("other")}
+
+	wantNewer := newer.DeepCopy()
+	wantNewer.Status.Gateways = []v1.ObjectReference{}
+
+	wantRoute := route.DeepCopy()
+	wantRoute.Status.Gateways = []v1.ObjectReference{gatewayRef("gateway")}
+
+	assert.Equal(t, []k8s.Object{wantGateway, wantClass, wantDetached, wantNewer, wantRoute}, got)
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
@@ -27,6 +27,10 @@ type Status struct {
 	Status      string
 	Description string
 	Vhost       string
+
+	// Errors are the error subconditions of the
+	// Valid condition of the object.
+	Errors []projcontour.SubCondition
 }
 
 type StatusWriter struct {
@@ -37,6 +41,7 @@ type ObjectStatusWriter struct {
 	sw     *StatusWriter
 	obj    k8s.Object
 	values map[string]string
+	errors []projcontour.SubCondition
 }
 
 // WithObject returns an ObjectStatusWriter that can be used to set the state of
@@ -74,6 +79,7 @@ func (sw *StatusWriter) commit(osw *ObjectStatusWriter) {
 			Status:      osw.values["status"],
 			Description: osw.values["description"],
 			Vhost:       osw.values["vhost"],
+			Errors:      osw.errors,
 		}
 	}
 }
@@ -86,6 +92,19 @@ func (osw *ObjectStatusWriter) SetInvalid(format string, args ...interface{}) {
 	osw.WithValue("description", fmt.Sprintf(format, args...)).WithValue("status", k8s.StatusInvalid)
 }
 
+// SetInvalidWithError sets the object invalid, and adds an error
+// subcondition of the given type and reason to its Valid condition.
+func (osw *ObjectStatusWriter) SetInvalidWithError(errorType, reason, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	osw.SetInvalid("%s", msg)
+	osw.errors = append(osw.errors, projcontour.SubCondition{
+		Type:    errorType,
+		Status:  projcontour.ConditionTrue,
+		Reason:  reason,
+		Message: msg,
+	})
+}
+
 func (osw *ObjectStatusWriter) SetValid() {
 	switch osw.obj.(type) {
 	case *projcontour.HTTPProxy:
@@ -108,6 +127,7 @@ func (osw *ObjectStatusWriter) WithObject(obj k8s.Object) (_ *ObjectStatusWriter
 		sw:     osw.sw,
 		obj:    obj,
 		values: m,
+		errors: append([]projcontour.SubCondition(nil), osw.errors...),
 	}
 	return nosw, func() {
 		osw.sw.commit(nosw)
@@ -16,6 +16,7 @@ package dag
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"github.com/projectcontour/contour/internal/assert"
@@ -704,6 +705,24 @@ func TestDAGStatus(t *testing.T) {
 		},
 	}
 
+	// proxy18Older is proxy18, created before proxy17.
+	proxy18Older := proxy18.DeepCopy()
+	proxy18Older.CreationTimestamp = metav1.NewTime(proxy17.CreationTimestamp.Add(-time.Hour))
+
+	// ingressExampleOlder uses the fqdn of proxy17, and was created before it.
+	ingressExampleOlder := &v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "example-ingress",
+			Namespace:         "roots",
+			CreationTimestamp: metav1.NewTime(proxy17.CreationTimestamp.Add(-time.Hour)),
+		},
+		Spec: v1beta1.IngressSpec{
+			Rules: []v1beta1.IngressRule{{
+				Host: "example.com",
+			}},
+		},
+	}
+
 	proxy19 := &projcontour.HTTPProxy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "app-with-tls-delegation",
@@ -1674,6 +1693,65 @@ func TestDAGStatus(t *testing.T) {
 		},
 	}
 
+	// aliasProxy returns a proxy for www.example.com with the given aliases.
+	aliasProxy := func(name string, aliases ...string) *projcontour.HTTPProxy {
+		return &projcontour.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "roots",
+			},
+			Spec: projcontour.HTTPProxySpec{
+				VirtualHost: &projcontour.VirtualHost{
+					Fqdn:    "www.example.com",
+					Aliases: aliases,
+				},
+				Routes: []projcontour.Route{{
+					Services: []projcontour.Service{{
+						Name: "home",
+						Port: 8080,
+					}},
+				}},
+			},
+		}
+	}
+
+	// proxyAliasDuplicated repeats one of its aliases.
+	proxyAliasDuplicated := aliasProxy("alias-duplicated", "example.com", "example.com")
+
+	// proxyAliasWildcard has an alias with an invalid wildcard.
+	proxyAliasWildcard := aliasProxy("alias-wildcard", "example.*")
+
+	// proxyAliasConflict has an alias that is the fqdn of proxy17.
+	proxyAliasConflict := aliasProxy("alias-conflict", "example.com")
+
+	// proxyAliasTLS has an alias that its certificate is not valid for.
+	proxyAliasTLS := aliasProxy("alias-tls", "example.com")
+	proxyAliasTLS.Spec.VirtualHost.TLS = &projcontour.TLS{
+		SecretName: secretRootsNS.Name,
+	}
+
+	proxyInvalidCORSOrigin := &projcontour.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalid-cors-origin",
+			Namespace: serviceKuard.Namespace,
+		},
+		Spec: projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn: "example.com",
+				CORSPolicy: &projcontour.CORSPolicy{
+					AllowOrigin:  []string{"https://[example.com"},
+					AllowMethods: []projcontour.CORSHeaderValue{"GET"},
+				},
+			},
+			Routes: []projcontour.Route{{
+				Services: []projcontour.Service{{
+					Name: serviceKuard.Name,
+					Port: 8080,
+				}},
+			}},
+		},
+	}
+
 	tests := map[string]struct {
 		objs                []interface{}
 		fallbackCertificate *types.NamespacedName
@@ -1860,7 +1938,7 @@ func TestDAGStatus(t *testing.T) {
 		"proxy invalid FQDN contains wildcard": {
 			objs: []interface{}{proxy15},
 			want: map[types.NamespacedName]Status{
-				{Name: proxy15.Name, Namespace: proxy15.Namespace}: {Object: proxy15, Status: "invalid", Description: `Spec.VirtualHost.Fqdn "example.*.com" cannot use wildcards`, Vhost: "example.*.com"},
+				{Name: proxy15.Name, Namespace: proxy15.Namespace}: {Object: proxy15, Status: "invalid", Description: `Spec.VirtualHost.Fqdn "example.*.com" has an invalid wildcard`, Vhost: "example.*.com"},
 			},
 		},
 		"proxy missing service shows invalid status": {
@@ -1886,35 +1964,113 @@ func TestDAGStatus(t *testing.T) {
 			},
 		},
 		"insert conflicting proxies due to fqdn reuse": {
-			objs: []interface{}{proxy17, proxy18},
+			objs: []interface{}{proxy17, proxy18, serviceKuard},
 			want: map[types.NamespacedName]Status{
 				{Name: proxy17.Name, Namespace: proxy17.Namespace}: {
 					Object:      proxy17,
-					Status:      k8s.StatusInvalid,
-					Description: `fqdn "example.com" is used in multiple HTTPProxies: roots/example-com, roots/other-example`,
+					Status:      k8s.StatusValid,
+					Description: "valid HTTPProxy",
 					Vhost:       "example.com",
 				},
 				{Name: proxy18.Name, Namespace: proxy18.Namespace}: {
 					Object:      proxy18,
 					Status:      k8s.StatusInvalid,
-					Description: `fqdn "example.com" is used in multiple HTTPProxies: roots/example-com, roots/other-example`,
+					Description: `fqdn "example.com" is already used by HTTPProxy roots/example-com`,
 					Vhost:       "example.com",
 				},
 			},
 		},
+		"older proxy keeps a reused fqdn": {
+			objs: []interface{}{proxy17, proxy18Older, serviceKuard},
+			want: map[types.NamespacedName]Status{
+				{Name: proxy17.Name, Namespace: proxy17.Namespace}: {
+					Object:      proxy17,
+					Status:      k8s.StatusInvalid,
+					Description: `fqdn "example.com" is already used by HTTPProxy roots/other-example`,
+					Vhost:       "example.com",
+				},
+				{Name: proxy18Older.Name, Namespace: proxy18Older.Namespace}: {
+					Object:      proxy18Older,
+					Status:      k8s.StatusValid,
+					Description: "valid HTTPProxy",
+					Vhost:       "example.com",
+				},
+			},
+		},
+		"older ingress keeps a reused fqdn": {
+			objs: []interface{}{proxy17, ingressExampleOlder, serviceKuard},
+			want: map[types.NamespacedName]Status{
+				{Name: proxy17.Name, Namespace: proxy17.Namespace}: {
+					Object:      proxy17,
+					Status:      k8s.StatusInvalid,
+					Description: `fqdn "example.com" is already used by Ingress roots/example-ingress`,
+					Vhost:       "example.com",
+				},
+			},
+		},
+		"proxy with duplicated alias": {
+			objs: []interface{}{proxyAliasDuplicated, serviceHome},
+			want: map[types.NamespacedName]Status{
+				{Name: proxyAliasDuplicated.Name, Namespace: proxyAliasDuplicated.Namespace}: {
+					Object:      proxyAliasDuplicated,
+					Status:      k8s.StatusInvalid,
+					Description: `Spec.VirtualHost.Aliases is invalid: alias "example.com" is duplicated`,
+					Vhost:       "www.example.com",
+				},
+			},
+		},
+		"proxy with wildcard alias": {
+			objs: []interface{}{proxyAliasWildcard, serviceHome},
+			want: map[types.NamespacedName]Status{
+				{Name: proxyAliasWildcard.Name, Namespace: proxyAliasWildcard.Namespace}: {
+					Object:      proxyAliasWildcard,
+					Status:      k8s.StatusInvalid,
+					Description: `Spec.VirtualHost.Aliases is invalid: alias "example.*" has an invalid wildcard`,
+					Vhost:       "www.example.com",
+				},
+			},
+		},
+		"insert conflicting proxies due to alias reuse": {
+			objs: []interface{}{proxy17, proxyAliasConflict, serviceKuard, serviceHome},
+			want: map[types.NamespacedName]Status{
+				{Name: proxy17.Name, Namespace: proxy17.Namespace}: {
+					Object:      proxy17,
+					Status:      k8s.StatusInvalid,
+					Description: `fqdn "example.com" is already used by HTTPProxy roots/alias-conflict`,
+					Vhost:       "example.com",
+				},
+				{Name: proxyAliasConflict.Name, Namespace: proxyAliasConflict.Namespace}: {
+					Object:      proxyAliasConflict,
+					Status:      k8s.StatusValid,
+					Description: "valid HTTPProxy",
+					Vhost:       "www.example.com",
+				},
+			},
+		},
+		"proxy with alias not covered by certificate": {
+			objs: []interface{}{proxyAliasTLS, secretRootsNS, serviceHome},
+			want: map[types.NamespacedName]Status{
+				{Name: proxyAliasTLS.Name, Namespace: proxyAliasTLS.Namespace}: {
+					Object:      proxyAliasTLS,
+					Status:      k8s.StatusInvalid,
+					Description: `Spec.VirtualHost.TLS Secret "ssl-cert" is invalid: certificate is not valid for "www.example.com"`,
+					Vhost:       "www.example.com",
+				},
+			},
+		},
 		"root proxy including another root": {
 			objs: []interface{}{proxy20, proxy21},
 			want: map[types.NamespacedName]Status{
 				{Name: proxy20.Name, Namespace: proxy20.Namespace}: {
 					Object:      proxy20,
 					Status:      k8s.StatusInvalid,
-					Description: `fqdn "blog.containersteve.com" is used in multiple HTTPProxies: marketing/blog, roots/root-blog`,
+					Description: `fqdn "blog.containersteve.com" is already used by HTTPProxy marketing/blog`,
 					Vhost:       "blog.containersteve.com",
 				},
 				{Name: proxy21.Name, Namespace: proxy21.Namespace}: {
 					Object:      proxy21,
 					Status:      k8s.StatusInvalid,
-					Description: `fqdn "blog.containersteve.com" is used in multiple HTTPProxies: marketing/blog, roots/root-blog`,
+					Description: `Spec.VirtualHost.TLS Secret "blog-containersteve-com" is invalid: Secret not found`,
 					Vhost:       "blog.containersteve.com",
 				},
 			},
@@ -2272,6 +2428,23 @@ func TestDAGStatus(t *testing.T) {
 				{Name: fallbackCertificate.Name, Namespace: fallbackCertificate.Namespace}: {Object: fallbackCertificate, Status: "invalid", Description: "Spec.Virtualhost.TLS enabled fallback but the fallback Certificate Secret is not configured in Contour configuration file", Vhost: "example.com"},
 			},
 		},
+		"invalid cors origin regex": {
+			objs: []interface{}{proxyInvalidCORSOrigin, serviceKuard},
+			want: map[types.NamespacedName]Status{
+				{Name: proxyInvalidCORSOrigin.Name, Namespace: proxyInvalidCORSOrigin.Namespace}: {
+					Object:      proxyInvalidCORSOrigin,
+					Status:      k8s.StatusInvalid,
+					Description: "Spec.VirtualHost.CORSPolicy: invalid allowOrigin \"https://[example.com\": error parsing regexp: missing closing ]: `[example.com`",
+					Vhost:       "example.com",
+					Errors: []projcontour.SubCondition{{
+						Type:    projcontour.ConditionTypeCORSError,
+						Status:  projcontour.ConditionTrue,
+						Reason:  "PolicyDidNotParse",
+						Message: "Spec.VirtualHost.CORSPolicy: invalid allowOrigin \"https://[example.com\": error parsing regexp: missing closing ]: `[example.com`",
+					}},
+				},
+			},
+		},
 		"fallback certificate requested and clientValidation also configured": {
 			objs: []interface{}{fallbackCertificateWithClientValidation, fallbackSecret, secretRootsNS, serviceHome},
 			want: map[types.NamespacedName]Status{
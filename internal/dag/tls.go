@@ -0,0 +1,89 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// supportedCipherSuites is the set of TLS 1.2 cipher suite
+// names that Envoy supports.
+//
+// See https://www.envoyproxy.io/docs/envoy/latest/api-v2/api/v2/auth/common.proto#envoy-api-field-auth-tlsparameters-cipher-suites
+var supportedCipherSuites = map[string]bool{
+	"ECDHE-ECDSA-AES128-GCM-SHA256": true,
+	"ECDHE-RSA-AES128-GCM-SHA256":   true,
+	"ECDHE-ECDSA-AES256-GCM-SHA384": true,
+	"ECDHE-RSA-AES256-GCM-SHA384":   true,
+	"ECDHE-ECDSA-CHACHA20-POLY1305": true,
+	"ECDHE-RSA-CHACHA20-POLY1305":   true,
+	"ECDHE-PSK-CHACHA20-POLY1305":   true,
+	"ECDHE-ECDSA-AES128-SHA":        true,
+	"ECDHE-RSA-AES128-SHA":          true,
+	"ECDHE-PSK-AES128-CBC-SHA":      true,
+	"ECDHE-ECDSA-AES256-SHA":        true,
+	"ECDHE-RSA-AES256-SHA":          true,
+	"ECDHE-PSK-AES256-CBC-SHA":      true,
+	"AES128-GCM-SHA256":             true,
+	"AES256-GCM-SHA384":             true,
+	"AES128-SHA":                    true,
+	"PSK-AES128-CBC-SHA":            true,
+	"AES256-SHA":                    true,
+	"PSK-AES256-CBC-SHA":            true,
+	"DES-CBC3-SHA":                  true,
+}
+
+// supportedECDHCurves is the set of ECDH curve names that
+// Envoy supports.
+//
+// See https://www.envoyproxy.io/docs/envoy/latest/api-v2/api/v2/auth/common.proto#envoy-api-field-auth-tlsparameters-ecdh-curves
+var supportedECDHCurves = map[string]bool{
+	"X25519": true,
+	"P-256":  true,
+	"P-384":  true,
+	"P-521":  true,
+}
+
+// ValidateCipherSuites returns an error if any of the cipher suites
+// is not supported by Envoy. An entry may be an equal preference
+// group of cipher suites, written as "[A|B]".
+func ValidateCipherSuites(ciphers []string) error {
+	for _, c := range ciphers {
+		names := []string{c}
+		if strings.HasPrefix(c, "[") && strings.HasSuffix(c, "]") {
+			names = strings.Split(strings.Trim(c, "[]"), "|")
+		}
+
+		for _, name := range names {
+			if !supportedCipherSuites[name] {
+				return fmt.Errorf("unsupported cipher suite %q", c)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateECDHCurves returns an error if any of the ECDH
+// curves is not supported by Envoy.
+func ValidateECDHCurves(curves []string) error {
+	for _, c := range curves {
+		if !supportedECDHCurves[c] {
+			return fmt.Errorf("unsupported ECDH curve %q", c)
+		}
+	}
+
+	return nil
+}
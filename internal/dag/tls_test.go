@@ -0,0 +1,81 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dag
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/projectcontour/contour/internal/assert"
+)
+
+func TestValidateCipherSuites(t *testing.T) {
+	tests := map[string]struct {
+		ciphers []string
+		want    error
+	}{
+		"empty": {
+			ciphers: nil,
+			want:    nil,
+		},
+		"supported cipher suites": {
+			ciphers: []string{"ECDHE-ECDSA-AES256-GCM-SHA384", "ECDHE-RSA-AES128-SHA"},
+			want:    nil,
+		},
+		"equal preference group": {
+			ciphers: []string{"[ECDHE-ECDSA-AES128-GCM-SHA256|ECDHE-ECDSA-CHACHA20-POLY1305]"},
+			want:    nil,
+		},
+		"unsupported cipher suite": {
+			ciphers: []string{"ECDHE-RSA-AES128-SHA", "TLS_RSA_WITH_RC4_128_SHA"},
+			want:    errors.New(`unsupported cipher suite "TLS_RSA_WITH_RC4_128_SHA"`),
+		},
+		"unsupported cipher suite in group": {
+			ciphers: []string{"[ECDHE-RSA-AES128-SHA|RC4-SHA]"},
+			want:    errors.New(`unsupported cipher suite "[ECDHE-RSA-AES128-SHA|RC4-SHA]"`),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ValidateCipherSuites(tc.ciphers))
+		})
+	}
+}
+
+func TestValidateECDHCurves(t *testing.T) {
+	tests := map[string]struct {
+		curves []string
+		want   error
+	}{
+		"empty": {
+			curves: nil,
+			want:   nil,
+		},
+		"supported curves": {
+			curves: []string{"X25519", "P-256"},
+			want:   nil,
+		},
+		"unsupported curve": {
+			curves: []string{"P-256", "secp256k1"},
+			want:   errors.New(`unsupported ECDH curve "secp256k1"`),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ValidateECDHCurves(tc.curves))
+		})
+	}
+}
@@ -14,13 +14,18 @@
 package envoy
 
 import (
+	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	accesslogv2 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v2"
 	accesslog "github.com/envoyproxy/go-control-plane/envoy/config/filter/accesslog/v2"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	_struct "github.com/golang/protobuf/ptypes/struct"
+	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/protobuf"
 )
 
+// TCPGRPCAccessLog is the name of Envoy's TCP gRPC access log sink.
+const TCPGRPCAccessLog = "envoy.tcp_grpc_access_log"
+
 //JSONFields is the canonical translation table for JSON fields to Envoy log template formats,
 //used for specifying fields for Envoy to log when JSON logging is enabled.
 //Only fields specified in this map may be used for JSON logging.
@@ -119,6 +124,53 @@ func FileAccessLogJSON(path string, keys []string) []*accesslog.AccessLog {
 	}}
 }
 
+// GRPCAccessLogHTTP returns a new access log filter that streams
+// HTTP access logs to the given gRPC access log service.
+func GRPCAccessLogHTTP(als *dag.AccessLogService) []*accesslog.AccessLog {
+	return []*accesslog.AccessLog{{
+		Name: wellknown.HTTPGRPCAccessLog,
+		ConfigType: &accesslog.AccessLog_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&accesslogv2.HttpGrpcAccessLogConfig{
+				CommonConfig: grpcAccessLogCommonConfig(als),
+			}),
+		},
+	}}
+}
+
+// GRPCAccessLogTCP returns a new access log filter that streams
+// TCP proxy access logs to the given gRPC access log service.
+func GRPCAccessLogTCP(als *dag.AccessLogService) []*accesslog.AccessLog {
+	return []*accesslog.AccessLog{{
+		Name: TCPGRPCAccessLog,
+		ConfigType: &accesslog.AccessLog_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&accesslogv2.TcpGrpcAccessLogConfig{
+				CommonConfig: grpcAccessLogCommonConfig(als),
+			}),
+		},
+	}}
+}
+
+func grpcAccessLogCommonConfig(als *dag.AccessLogService) *accesslogv2.CommonGrpcAccessLogConfig {
+	var cluster string
+	switch {
+	case als.ExtensionCluster != nil:
+		cluster = als.ExtensionCluster.Name
+	default:
+		cluster = Clustername(als.Cluster)
+	}
+
+	return &accesslogv2.CommonGrpcAccessLogConfig{
+		LogName: als.LogName,
+		GrpcService: &envoy_api_v2_core.GrpcService{
+			TargetSpecifier: &envoy_api_v2_core.GrpcService_EnvoyGrpc_{
+				EnvoyGrpc: &envoy_api_v2_core.GrpcService_EnvoyGrpc{
+					ClusterName: cluster,
+				},
+			},
+		},
+	}
+}
+
 func sv(s string) *_struct.Value {
 	return &_struct.Value{
 		Kind: &_struct.Value_StringValue{
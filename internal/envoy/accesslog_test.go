@@ -16,12 +16,15 @@ package envoy
 import (
 	"testing"
 
+	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	accesslog_v2 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v2"
 	envoy_accesslog "github.com/envoyproxy/go-control-plane/envoy/config/filter/accesslog/v2"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	_struct "github.com/golang/protobuf/ptypes/struct"
 	"github.com/projectcontour/contour/internal/assert"
+	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/protobuf"
+	v1 "k8s.io/api/core/v1"
 )
 
 func TestFileAccessLog(t *testing.T) {
@@ -108,3 +111,56 @@ func TestJSONFileAccessLog(t *testing.T) {
 		})
 	}
 }
+
+func TestGRPCAccessLog(t *testing.T) {
+	grpcService := func(cluster string) *envoy_api_v2_core.GrpcService {
+		return &envoy_api_v2_core.GrpcService{
+			TargetSpecifier: &envoy_api_v2_core.GrpcService_EnvoyGrpc_{
+				EnvoyGrpc: &envoy_api_v2_core.GrpcService_EnvoyGrpc{
+					ClusterName: cluster,
+				},
+			},
+		}
+	}
+
+	ext := &dag.AccessLogService{
+		ExtensionCluster: &dag.ExtensionCluster{Name: "extension/projectcontour/als"},
+		LogName:          "contour",
+	}
+
+	assert.Equal(t, []*envoy_accesslog.AccessLog{{
+		Name: wellknown.HTTPGRPCAccessLog,
+		ConfigType: &envoy_accesslog.AccessLog_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&accesslog_v2.HttpGrpcAccessLogConfig{
+				CommonConfig: &accesslog_v2.CommonGrpcAccessLogConfig{
+					LogName:     "contour",
+					GrpcService: grpcService("extension/projectcontour/als"),
+				},
+			}),
+		},
+	}}, GRPCAccessLogHTTP(ext))
+
+	svc := &dag.AccessLogService{
+		Cluster: &dag.Cluster{
+			Upstream: &dag.Service{
+				Name:        "als",
+				Namespace:   "logging",
+				ServicePort: v1.ServicePort{Port: 9001},
+			},
+			Protocol: "h2c",
+		},
+		LogName: "edge",
+	}
+
+	assert.Equal(t, []*envoy_accesslog.AccessLog{{
+		Name: TCPGRPCAccessLog,
+		ConfigType: &envoy_accesslog.AccessLog_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&accesslog_v2.TcpGrpcAccessLogConfig{
+				CommonConfig: &accesslog_v2.CommonGrpcAccessLogConfig{
+					LogName:     "edge",
+					GrpcService: grpcService("logging/als/9001/da39a3ee5e"),
+				},
+			}),
+		},
+	}}, GRPCAccessLogTCP(svc))
+}
@@ -49,8 +49,9 @@ var (
 
 // UpstreamTLSContext creates an envoy_api_v2_auth.UpstreamTlsContext. By default
 // UpstreamTLSContext returns a HTTP/1.1 TLS enabled context. A list of
-// additional ALPN protocols can be provided.
-func UpstreamTLSContext(peerValidationContext *dag.PeerValidationContext, sni string, alpnProtocols ...string) *envoy_api_v2_auth.UpstreamTlsContext {
+// additional ALPN protocols can be provided. If clientSecret is not nil,
+// Envoy presents it as its client certificate, delivered over SDS.
+func UpstreamTLSContext(peerValidationContext *dag.PeerValidationContext, sni string, clientSecret *dag.Secret, alpnProtocols ...string) *envoy_api_v2_auth.UpstreamTlsContext {
 	context := &envoy_api_v2_auth.UpstreamTlsContext{
 		CommonTlsContext: &envoy_api_v2_auth.CommonTlsContext{
 			AlpnProtocols: alpnProtocols,
@@ -58,14 +59,22 @@ func UpstreamTLSContext(peerValidationContext *dag.PeerValidationContext, sni st
 		Sni: sni,
 	}
 
-	if peerValidationContext.GetCACertificate() != nil && len(peerValidationContext.GetSubjectName()) > 0 {
+	if clientSecret != nil {
+		context.CommonTlsContext.TlsCertificateSdsSecretConfigs = []*envoy_api_v2_auth.SdsSecretConfig{{
+			Name:      Secretname(clientSecret),
+			SdsConfig: ConfigSource("contour"),
+		}}
+	}
+
+	if peerValidationContext.GetCACertificate() != nil &&
+		(len(peerValidationContext.GetSubjectName()) > 0 || len(peerValidationContext.GetSubjectAltNames()) > 0) {
 		// We have to explicitly assign the value from validationContext
 		// to context.CommonTlsContext.ValidationContextType because the
 		// latter is an interface. Returning nil from validationContext
 		// directly into this field boxes the nil into the unexported
 		// type of this grpc OneOf field which causes proto marshaling
 		// to explode later on.
-		vc := validationContext(peerValidationContext.GetCACertificate(), peerValidationContext.GetSubjectName())
+		vc := validationContext(peerValidationContext.GetCACertificate(), peerValidationContext.GetSubjectName(), peerValidationContext.GetSubjectAltNames())
 		if vc != nil {
 			context.CommonTlsContext.ValidationContextType = vc
 		}
@@ -74,7 +83,7 @@ func UpstreamTLSContext(peerValidationContext *dag.PeerValidationContext, sni st
 	return context
 }
 
-func validationContext(ca []byte, subjectName string) *envoy_api_v2_auth.CommonTlsContext_ValidationContext {
+func validationContext(ca []byte, subjectName string, subjectAltNames []dag.SubjectAltNameMatch) *envoy_api_v2_auth.CommonTlsContext_ValidationContext {
 	vc := &envoy_api_v2_auth.CommonTlsContext_ValidationContext{
 		ValidationContext: &envoy_api_v2_auth.CertificateValidationContext{
 			TrustedCa: &envoy_api_v2_core.DataSource{
@@ -94,17 +103,65 @@ func validationContext(ca []byte, subjectName string) *envoy_api_v2_auth.CommonT
 		}
 	}
 
+	for _, san := range subjectAltNames {
+		vc.ValidationContext.MatchSubjectAltNames = append(vc.ValidationContext.MatchSubjectAltNames,
+			subjectAltNameMatcher(san))
+	}
+
 	return vc
 }
 
-// DownstreamTLSContext creates a new DownstreamTlsContext.
-func DownstreamTLSContext(serverSecret *dag.Secret, tlsMinProtoVersion envoy_api_v2_auth.TlsParameters_TlsProtocol, peerValidationContext *dag.PeerValidationContext, alpnProtos ...string) *envoy_api_v2_auth.DownstreamTlsContext {
+// subjectAltNameMatcher returns a matcher for a subject alternative
+// name. Envoy matches it against every subject alternative name of
+// the certificate, regardless of type.
+func subjectAltNameMatcher(san dag.SubjectAltNameMatch) *matcher.StringMatcher {
+	switch san.MatchType {
+	case "prefix":
+		return &matcher.StringMatcher{
+			MatchPattern: &matcher.StringMatcher_Prefix{
+				Prefix: san.Value,
+			},
+		}
+	case "suffix":
+		return &matcher.StringMatcher{
+			MatchPattern: &matcher.StringMatcher_Suffix{
+				Suffix: san.Value,
+			},
+		}
+	case "regex":
+		return &matcher.StringMatcher{
+			MatchPattern: &matcher.StringMatcher_SafeRegex{
+				SafeRegex: SafeRegexMatch(san.Value),
+			},
+		}
+	default:
+		return &matcher.StringMatcher{
+			MatchPattern: &matcher.StringMatcher_Exact{
+				Exact: san.Value,
+			},
+		}
+	}
+}
+
+// DownstreamTLSContext creates a new DownstreamTlsContext. If
+// tlsMaxProtoVersion is TLS_AUTO, the maximum version is TLS 1.3.
+// If cipherSuites is empty, the default Contour cipher suites are
+// used, and if ecdhCurves is empty, the Envoy default curves are used.
+func DownstreamTLSContext(serverSecret *dag.Secret, tlsMinProtoVersion, tlsMaxProtoVersion envoy_api_v2_auth.TlsParameters_TlsProtocol, cipherSuites, ecdhCurves []string, peerValidationContext *dag.PeerValidationContext, alpnProtos ...string) *envoy_api_v2_auth.DownstreamTlsContext {
+	if tlsMaxProtoVersion == envoy_api_v2_auth.TlsParameters_TLS_AUTO {
+		tlsMaxProtoVersion = envoy_api_v2_auth.TlsParameters_TLSv1_3
+	}
+	if len(cipherSuites) == 0 {
+		cipherSuites = ciphers
+	}
+
 	context := &envoy_api_v2_auth.DownstreamTlsContext{
 		CommonTlsContext: &envoy_api_v2_auth.CommonTlsContext{
 			TlsParams: &envoy_api_v2_auth.TlsParameters{
 				TlsMinimumProtocolVersion: tlsMinProtoVersion,
-				TlsMaximumProtocolVersion: envoy_api_v2_auth.TlsParameters_TLSv1_3,
-				CipherSuites:              ciphers,
+				TlsMaximumProtocolVersion: tlsMaxProtoVersion,
+				CipherSuites:              cipherSuites,
+				EcdhCurves:                ecdhCurves,
 			},
 			TlsCertificateSdsSecretConfigs: []*envoy_api_v2_auth.SdsSecretConfig{{
 				Name:      Secretname(serverSecret),
@@ -115,11 +172,25 @@ func DownstreamTLSContext(serverSecret *dag.Secret, tlsMinProtoVersion envoy_api
 	}
 
 	if peerValidationContext.GetCACertificate() != nil {
-		vc := validationContext(peerValidationContext.GetCACertificate(), "")
+		vc := validationContext(peerValidationContext.GetCACertificate(), "", nil)
 		if vc != nil {
 			context.CommonTlsContext.ValidationContextType = vc
 			context.RequireClientCertificate = protobuf.Bool(true)
 		}
+
+		// The CRL is delivered over SDS and combined with the
+		// CA, so that updating it does not change the listener.
+		if crl := peerValidationContext.GetCRL(); crl != nil {
+			context.CommonTlsContext.ValidationContextType = &envoy_api_v2_auth.CommonTlsContext_CombinedValidationContext{
+				CombinedValidationContext: &envoy_api_v2_auth.CommonTlsContext_CombinedCertificateValidationContext{
+					DefaultValidationContext: vc.ValidationContext,
+					ValidationContextSdsSecretConfig: &envoy_api_v2_auth.SdsSecretConfig{
+						Name:      CRLSecretname(crl),
+						SdsConfig: ConfigSource("contour"),
+					},
+				},
+			}
+		}
 	}
 
 	return context
@@ -19,7 +19,7 @@ import (
 	envoy_api_v2_auth "github.com/envoyproxy/go-control-plane/envoy/api/v2/auth"
 	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher"
-	"github.com/google/go-cmp/cmp"
+	"github.com/projectcontour/contour/internal/assert"
 	"github.com/projectcontour/contour/internal/dag"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -39,6 +39,7 @@ func TestUpstreamTLSContext(t *testing.T) {
 
 	tests := map[string]struct {
 		validation    *dag.PeerValidationContext
+		clientSecret  *dag.Secret
 		alpnProtocols []string
 		externalName  string
 		want          *envoy_api_v2_auth.UpstreamTlsContext
@@ -96,6 +97,105 @@ func TestUpstreamTLSContext(t *testing.T) {
 				},
 			},
 		},
+		"ca, altname and subject alt names": {
+			validation: &dag.PeerValidationContext{
+				CACertificate: secret,
+				SubjectName:   "www.example.com",
+				SubjectAltNames: []dag.SubjectAltNameMatch{{
+					MatchType: "exact",
+					Value:     "spiffe://cluster/ns/foo/sa/bar",
+				}, {
+					MatchType: "suffix",
+					Value:     ".example.com",
+				}, {
+					MatchType: "prefix",
+					Value:     "spiffe://cluster/ns/foo/",
+				}, {
+					MatchType: "regex",
+					Value:     "backend-[0-9]+",
+				}},
+			},
+			want: &envoy_api_v2_auth.UpstreamTlsContext{
+				CommonTlsContext: &envoy_api_v2_auth.CommonTlsContext{
+					ValidationContextType: &envoy_api_v2_auth.CommonTlsContext_ValidationContext{
+						ValidationContext: &envoy_api_v2_auth.CertificateValidationContext{
+							TrustedCa: &envoy_api_v2_core.DataSource{
+								Specifier: &envoy_api_v2_core.DataSource_InlineBytes{
+									InlineBytes: []byte("ca"),
+								},
+							},
+							MatchSubjectAltNames: []*matcher.StringMatcher{{
+								MatchPattern: &matcher.StringMatcher_Exact{
+									Exact: "www.example.com",
+								},
+							}, {
+								MatchPattern: &matcher.StringMatcher_Exact{
+									Exact: "spiffe://cluster/ns/foo/sa/bar",
+								},
+							}, {
+								MatchPattern: &matcher.StringMatcher_Suffix{
+									Suffix: ".example.com",
+								},
+							}, {
+								MatchPattern: &matcher.StringMatcher_Prefix{
+									Prefix: "spiffe://cluster/ns/foo/",
+								},
+							}, {
+								MatchPattern: &matcher.StringMatcher_SafeRegex{
+									SafeRegex: SafeRegexMatch("backend-[0-9]+"),
+								},
+							}},
+						},
+					},
+				},
+			},
+		},
+		"subject alt names without altname": {
+			validation: &dag.PeerValidationContext{
+				CACertificate: secret,
+				SubjectAltNames: []dag.SubjectAltNameMatch{{
+					MatchType: "exact",
+					Value:     "spiffe://cluster/ns/foo/sa/bar",
+				}},
+			},
+			want: &envoy_api_v2_auth.UpstreamTlsContext{
+				CommonTlsContext: &envoy_api_v2_auth.CommonTlsContext{
+					ValidationContextType: &envoy_api_v2_auth.CommonTlsContext_ValidationContext{
+						ValidationContext: &envoy_api_v2_auth.CertificateValidationContext{
+							TrustedCa: &envoy_api_v2_core.DataSource{
+								Specifier: &envoy_api_v2_core.DataSource_InlineBytes{
+									InlineBytes: []byte("ca"),
+								},
+							},
+							MatchSubjectAltNames: []*matcher.StringMatcher{{
+								MatchPattern: &matcher.StringMatcher_Exact{
+									Exact: "spiffe://cluster/ns/foo/sa/bar",
+								},
+							}},
+						},
+					},
+				},
+			},
+		},
+		"client certificate": {
+			clientSecret: &dag.Secret{
+				Object: &v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "clientcert",
+						Namespace: "default",
+					},
+					Type: v1.SecretTypeTLS,
+				},
+			},
+			want: &envoy_api_v2_auth.UpstreamTlsContext{
+				CommonTlsContext: &envoy_api_v2_auth.CommonTlsContext{
+					TlsCertificateSdsSecretConfigs: []*envoy_api_v2_auth.SdsSecretConfig{{
+						Name:      "default/clientcert/da39a3ee5e",
+						SdsConfig: ConfigSource("contour"),
+					}},
+				},
+			},
+		},
 		"external name sni": {
 			externalName: "projectcontour.local",
 			want: &envoy_api_v2_auth.UpstreamTlsContext{
@@ -107,10 +207,8 @@ func TestUpstreamTLSContext(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			got := UpstreamTLSContext(tc.validation, tc.externalName, tc.alpnProtocols...)
-			if diff := cmp.Diff(tc.want, got); diff != "" {
-				t.Fatal(diff)
-			}
+			got := UpstreamTLSContext(tc.validation, tc.externalName, tc.clientSecret, tc.alpnProtocols...)
+			assert.Equal(t, tc.want, got)
 		})
 	}
 }
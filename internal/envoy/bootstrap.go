@@ -28,6 +28,7 @@ import (
 	clusterv2 "github.com/envoyproxy/go-control-plane/envoy/api/v2/cluster"
 	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	envoy_api_bootstrap "github.com/envoyproxy/go-control-plane/envoy/config/bootstrap/v2"
+	envoy_config_metrics_v2 "github.com/envoyproxy/go-control-plane/envoy/config/metrics/v2"
 	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher"
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
@@ -157,7 +158,7 @@ func bootstrap(c *BootstrapConfig) ([]bootstrapf, error) {
 }
 
 func bootstrapConfig(c *BootstrapConfig) *envoy_api_bootstrap.Bootstrap {
-	return &envoy_api_bootstrap.Bootstrap{
+	b := &envoy_api_bootstrap.Bootstrap{
 		DynamicResources: &envoy_api_bootstrap.Bootstrap_DynamicResources{
 			LdsConfig: ConfigSource("contour"),
 			CdsConfig: ConfigSource("contour"),
@@ -217,6 +218,31 @@ func bootstrapConfig(c *BootstrapConfig) *envoy_api_bootstrap.Bootstrap {
 			Address:       SocketAddress(c.adminAddress(), c.adminPort()),
 		},
 	}
+
+	if c.MetricsService {
+		b.StatsSinks = []*envoy_config_metrics_v2.StatsSink{metricsServiceStatsSink("contour")}
+	}
+
+	return b
+}
+
+// metricsServiceStatsSink returns a stats sink that streams
+// Envoy's statistics to the metrics service of the given cluster.
+func metricsServiceStatsSink(cluster string) *envoy_config_metrics_v2.StatsSink {
+	return &envoy_config_metrics_v2.StatsSink{
+		Name: "envoy.stat_sinks.metrics_service",
+		ConfigType: &envoy_config_metrics_v2.StatsSink_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_config_metrics_v2.MetricsServiceConfig{
+				GrpcService: &envoy_api_v2_core.GrpcService{
+					TargetSpecifier: &envoy_api_v2_core.GrpcService_EnvoyGrpc_{
+						EnvoyGrpc: &envoy_api_v2_core.GrpcService_EnvoyGrpc{
+							ClusterName: cluster,
+						},
+					},
+				},
+			}),
+		},
+	}
 }
 
 func upstreamFileTLSContext(c *BootstrapConfig) *envoy_api_v2_auth.UpstreamTlsContext {
@@ -374,6 +400,11 @@ type BootstrapConfig struct {
 	// referenced in the configuration actually exist. This option is for
 	// testing only.
 	SkipFilePathCheck bool
+
+	// MetricsService streams Envoy's statistics to the metrics
+	// service of the management server, so that Contour can
+	// export the outlier detection ejections of each cluster.
+	MetricsService bool
 }
 
 func (c *BootstrapConfig) xdsAddress() string   { return stringOrDefault(c.XDSAddress, "127.0.0.1") }
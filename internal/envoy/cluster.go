@@ -28,6 +28,7 @@ import (
 	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/protobuf"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -48,6 +49,7 @@ func Cluster(c *dag.Cluster) *v2.Cluster {
 	cluster.AltStatName = altStatName(service)
 	cluster.LbPolicy = lbPolicy(c.LoadBalancerPolicy)
 	cluster.HealthChecks = edshealthcheck(c)
+	cluster.OutlierDetection = outlierDetection(c.OutlierDetectionPolicy)
 
 	switch len(service.ExternalName) {
 	case 0:
@@ -65,36 +67,98 @@ func Cluster(c *dag.Cluster) *v2.Cluster {
 		cluster.DrainConnectionsOnHostRemoval = true
 	}
 
-	if anyPositive(service.MaxConnections, service.MaxPendingRequests, service.MaxRequests, service.MaxRetries) {
+	// Circuit breaker thresholds default to the service annotations.
+	cb := dag.CircuitBreakerPolicy{
+		MaxConnections:     service.MaxConnections,
+		MaxPendingRequests: service.MaxPendingRequests,
+		MaxRequests:        service.MaxRequests,
+		MaxRetries:         service.MaxRetries,
+	}
+	if c.CircuitBreakerPolicy != nil {
+		cb = *c.CircuitBreakerPolicy
+	}
+
+	if anyPositive(cb.MaxConnections, cb.MaxPendingRequests, cb.MaxRequests, cb.MaxRetries) {
 		cluster.CircuitBreakers = &envoy_cluster.CircuitBreakers{
 			Thresholds: []*envoy_cluster.CircuitBreakers_Thresholds{{
-				MaxConnections:     u32nil(service.MaxConnections),
-				MaxPendingRequests: u32nil(service.MaxPendingRequests),
-				MaxRequests:        u32nil(service.MaxRequests),
-				MaxRetries:         u32nil(service.MaxRetries),
+				MaxConnections:     u32nil(cb.MaxConnections),
+				MaxPendingRequests: u32nil(cb.MaxPendingRequests),
+				MaxRequests:        u32nil(cb.MaxRequests),
+				MaxRetries:         u32nil(cb.MaxRetries),
 			}},
 		}
 	}
 
+	cluster.MaxRequestsPerConnection = u32nil(c.MaxRequestsPerConnection)
+
 	switch c.Protocol {
 	case "tls":
 		cluster.TransportSocket = UpstreamTLSTransportSocket(
 			UpstreamTLSContext(
 				c.UpstreamValidation,
 				c.SNI,
+				c.ClientCertificate,
 			),
 		)
 	case "h2":
-		cluster.Http2ProtocolOptions = &envoy_api_v2_core.Http2ProtocolOptions{}
+		cluster.Http2ProtocolOptions = &envoy_api_v2_core.Http2ProtocolOptions{
+			MaxConcurrentStreams: u32nil(c.HTTP2MaxConcurrentStreams),
+		}
 		cluster.TransportSocket = UpstreamTLSTransportSocket(
 			UpstreamTLSContext(
 				c.UpstreamValidation,
 				c.SNI,
+				c.ClientCertificate,
 				"h2",
 			),
 		)
 	case "h2c":
-		cluster.Http2ProtocolOptions = &envoy_api_v2_core.Http2ProtocolOptions{}
+		cluster.Http2ProtocolOptions = &envoy_api_v2_core.Http2ProtocolOptions{
+			MaxConcurrentStreams: u32nil(c.HTTP2MaxConcurrentStreams),
+		}
+	}
+
+	return cluster
+}
+
+// ExtensionCluster builds a v2.Cluster struct for the given extension service.
+func ExtensionCluster(ext *dag.ExtensionCluster) *v2.Cluster {
+	cluster := clusterDefaults()
+
+	cluster.Name = ext.Name
+	cluster.LbPolicy = lbPolicy(ext.LoadBalancerPolicy)
+
+	// The endpoints of all the Services backing the extension are
+	// aggregated into a single ClusterLoadAssignment that has the
+	// same name as the cluster. Each Service is a separate locality,
+	// which is weighted by the Service weight.
+	cluster.ClusterDiscoveryType = ClusterDiscoveryType(v2.Cluster_EDS)
+	cluster.EdsClusterConfig = &v2.Cluster_EdsClusterConfig{
+		EdsConfig:   ConfigSource("contour"),
+		ServiceName: ext.Name,
+	}
+	cluster.CommonLbConfig.LocalityConfigSpecifier = &v2.Cluster_CommonLbConfig_LocalityWeightedLbConfig_{
+		LocalityWeightedLbConfig: &v2.Cluster_CommonLbConfig_LocalityWeightedLbConfig{},
+	}
+
+	// GRPC extensions always speak HTTP/2.
+	cluster.Http2ProtocolOptions = &envoy_api_v2_core.Http2ProtocolOptions{}
+
+	if ext.Protocol == "h2" {
+		cluster.TransportSocket = UpstreamTLSTransportSocket(
+			UpstreamTLSContext(
+				ext.UpstreamValidation,
+				"",
+				nil,
+				"h2",
+			),
+		)
+	}
+
+	if idle := ext.TimeoutPolicy.IdleTimeout; !idle.UseDefault() {
+		cluster.CommonHttpProtocolOptions = &envoy_api_v2_core.HttpProtocolOptions{
+			IdleTimeout: envoyTimeout(idle),
+		}
 	}
 
 	return cluster
@@ -148,8 +212,10 @@ func lbPolicy(strategy string) v2.Cluster_LbPolicy {
 		return v2.Cluster_LEAST_REQUEST
 	case "Random":
 		return v2.Cluster_RANDOM
-	case "Cookie":
+	case "Cookie", "RequestHash":
 		return v2.Cluster_RING_HASH
+	case "Maglev":
+		return v2.Cluster_MAGLEV
 	default:
 		return v2.Cluster_ROUND_ROBIN
 	}
@@ -171,6 +237,37 @@ func edshealthcheck(c *dag.Cluster) []*envoy_api_v2_core.HealthCheck {
 	}
 }
 
+// outlierDetection returns the outlier detection configuration of
+// the policy. Zero values in the policy keep the Envoy defaults.
+func outlierDetection(policy *dag.OutlierDetectionPolicy) *envoy_cluster.OutlierDetection {
+	if policy == nil {
+		return nil
+	}
+
+	od := &envoy_cluster.OutlierDetection{
+		Consecutive_5Xx: u32nil(policy.Consecutive5xxErrors),
+	}
+
+	if p := policy.MaxEjectionPercent; p != nil {
+		od.MaxEjectionPercent = protobuf.UInt32(*p)
+	}
+
+	if policy.Interval > 0 {
+		od.Interval = protobuf.Duration(policy.Interval)
+	}
+	if policy.BaseEjectionTime > 0 {
+		od.BaseEjectionTime = protobuf.Duration(policy.BaseEjectionTime)
+	}
+
+	// Envoy does not enforce gateway error ejection by default.
+	if policy.ConsecutiveGatewayErrors > 0 {
+		od.ConsecutiveGatewayFailure = protobuf.UInt32(policy.ConsecutiveGatewayErrors)
+		od.EnforcingConsecutiveGatewayFailure = protobuf.UInt32(100)
+	}
+
+	return od
+}
+
 // Clustername returns the name of the CDS cluster for this service.
 func Clustername(cluster *dag.Cluster) string {
 	service := cluster.Upstream
@@ -193,6 +290,31 @@ func Clustername(cluster *dag.Cluster) string {
 	if uv := cluster.UpstreamValidation; uv != nil {
 		buf += uv.CACertificate.Object.ObjectMeta.Name
 		buf += uv.SubjectName
+		for _, san := range uv.SubjectAltNames {
+			buf += "san:" + san.MatchType + "/" + san.Value
+		}
+	}
+	if cc := cluster.ClientCertificate; cc != nil {
+		buf += "client:" + cc.Namespace() + "/" + cc.Name()
+	}
+	if cb := cluster.CircuitBreakerPolicy; cb != nil {
+		buf += fmt.Sprintf("cb:%d/%d/%d/%d",
+			cb.MaxConnections, cb.MaxPendingRequests, cb.MaxRequests, cb.MaxRetries)
+	}
+	if cluster.MaxRequestsPerConnection > 0 || cluster.HTTP2MaxConcurrentStreams > 0 {
+		buf += fmt.Sprintf("pool:%d/%d", cluster.MaxRequestsPerConnection, cluster.HTTP2MaxConcurrentStreams)
+	}
+	if od := cluster.OutlierDetectionPolicy; od != nil {
+		buf += fmt.Sprintf("outlier:%d/%d/%s/%s",
+			od.Consecutive5xxErrors, od.ConsecutiveGatewayErrors,
+			od.Interval, od.BaseEjectionTime)
+		if od.MaxEjectionPercent != nil {
+			buf += fmt.Sprintf("/%d", *od.MaxEjectionPercent)
+		}
+	}
+	// A UDP port can have the same number as a TCP port of the Service.
+	if service.ServicePort.Protocol == v1.ProtocolUDP {
+		buf += "udp"
 	}
 
 	// This isn't a crypto hash, we just want a unique name.
@@ -26,6 +26,7 @@ import (
 	"github.com/projectcontour/contour/internal/assert"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/projectcontour/contour/internal/timeout"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -140,7 +141,7 @@ func TestCluster(t *testing.T) {
 					ServiceName: "default/kuard/http",
 				},
 				TransportSocket: UpstreamTLSTransportSocket(
-					UpstreamTLSContext(nil, "", "h2"),
+					UpstreamTLSContext(nil, "", nil, "h2"),
 				),
 				Http2ProtocolOptions: &envoy_api_v2_core.Http2ProtocolOptions{},
 			},
@@ -170,7 +171,7 @@ func TestCluster(t *testing.T) {
 					ServiceName: "default/kuard/http",
 				},
 				TransportSocket: UpstreamTLSTransportSocket(
-					UpstreamTLSContext(nil, ""),
+					UpstreamTLSContext(nil, "", nil),
 				),
 			},
 		},
@@ -186,7 +187,7 @@ func TestCluster(t *testing.T) {
 				ClusterDiscoveryType: ClusterDiscoveryType(v2.Cluster_STRICT_DNS),
 				LoadAssignment:       StaticClusterLoadAssignment(service(svcExternal, "tls")),
 				TransportSocket: UpstreamTLSTransportSocket(
-					UpstreamTLSContext(nil, "projectcontour.local"),
+					UpstreamTLSContext(nil, "projectcontour.local", nil),
 				),
 			},
 		},
@@ -213,7 +214,8 @@ func TestCluster(t *testing.T) {
 							CACertificate: secret,
 							SubjectName:   "foo.bar.io",
 						},
-						""),
+						"",
+						nil),
 				),
 			},
 		},
@@ -309,6 +311,56 @@ func TestCluster(t *testing.T) {
 				},
 			},
 		},
+		"circuit breaker policy overrides annotations": {
+			cluster: &dag.Cluster{
+				Upstream: &dag.Service{
+					Name: s1.Name, Namespace: s1.Namespace,
+					ServicePort:    s1.Spec.Ports[0],
+					MaxConnections: 9000,
+					MaxRetries:     7,
+				},
+				CircuitBreakerPolicy: &dag.CircuitBreakerPolicy{
+					MaxConnections: 100,
+					MaxRequests:    50,
+				},
+			},
+			want: &v2.Cluster{
+				Name:                 "default/kuard/443/1e9f493e5b",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(v2.Cluster_EDS),
+				EdsClusterConfig: &v2.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+				CircuitBreakers: &envoy_cluster.CircuitBreakers{
+					Thresholds: []*envoy_cluster.CircuitBreakers_Thresholds{{
+						MaxConnections: protobuf.UInt32(100),
+						MaxRequests:    protobuf.UInt32(50),
+					}},
+				},
+			},
+		},
+		"h2c upstream with connection pool settings": {
+			cluster: &dag.Cluster{
+				Upstream:                  service(s1, "h2c"),
+				Protocol:                  "h2c",
+				MaxRequestsPerConnection:  1000,
+				HTTP2MaxConcurrentStreams: 64,
+			},
+			want: &v2.Cluster{
+				Name:                 "default/kuard/443/62f33cc221",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(v2.Cluster_EDS),
+				EdsClusterConfig: &v2.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+				MaxRequestsPerConnection: protobuf.UInt32(1000),
+				Http2ProtocolOptions: &envoy_api_v2_core.Http2ProtocolOptions{
+					MaxConcurrentStreams: protobuf.UInt32(64),
+				},
+			},
+		},
 		"cluster with random load balancer policy": {
 			cluster: &dag.Cluster{
 				Upstream:           service(s1),
@@ -341,6 +393,72 @@ func TestCluster(t *testing.T) {
 				LbPolicy: v2.Cluster_RING_HASH,
 			},
 		},
+		"cluster with outlier detection": {
+			cluster: &dag.Cluster{
+				Upstream: service(s1),
+				OutlierDetectionPolicy: &dag.OutlierDetectionPolicy{
+					Consecutive5xxErrors:     3,
+					ConsecutiveGatewayErrors: 2,
+					Interval:                 5 * time.Second,
+					BaseEjectionTime:         time.Minute,
+					MaxEjectionPercent:       uint32Ptr(50),
+				},
+			},
+			want: &v2.Cluster{
+				Name:                 "default/kuard/443/342c74c2da",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(v2.Cluster_EDS),
+				EdsClusterConfig: &v2.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+				OutlierDetection: &envoy_cluster.OutlierDetection{
+					Consecutive_5Xx:                    protobuf.UInt32(3),
+					ConsecutiveGatewayFailure:          protobuf.UInt32(2),
+					EnforcingConsecutiveGatewayFailure: protobuf.UInt32(100),
+					Interval:                           protobuf.Duration(5 * time.Second),
+					BaseEjectionTime:                   protobuf.Duration(time.Minute),
+					MaxEjectionPercent:                 protobuf.UInt32(50),
+				},
+			},
+		},
+		"cluster with default outlier detection": {
+			cluster: &dag.Cluster{
+				Upstream:               service(s1),
+				OutlierDetectionPolicy: &dag.OutlierDetectionPolicy{},
+			},
+			want: &v2.Cluster{
+				Name:                 "default/kuard/443/201b62942f",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(v2.Cluster_EDS),
+				EdsClusterConfig: &v2.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+				OutlierDetection: &envoy_cluster.OutlierDetection{},
+			},
+		},
+
+		"cluster with zero max ejection percent": {
+			cluster: &dag.Cluster{
+				Upstream: service(s1),
+				OutlierDetectionPolicy: &dag.OutlierDetectionPolicy{
+					MaxEjectionPercent: uint32Ptr(0),
+				},
+			},
+			want: &v2.Cluster{
+				Name:                 "default/kuard/443/68548dbaf9",
+				AltStatName:          "default_kuard_443",
+				ClusterDiscoveryType: ClusterDiscoveryType(v2.Cluster_EDS),
+				EdsClusterConfig: &v2.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "default/kuard/http",
+				},
+				OutlierDetection: &envoy_cluster.OutlierDetection{
+					MaxEjectionPercent: protobuf.UInt32(0),
+				},
+			},
+		},
 
 		"tcp service": {
 			cluster: &dag.Cluster{
@@ -400,6 +518,89 @@ func TestCluster(t *testing.T) {
 	}
 }
 
+func TestExtensionCluster(t *testing.T) {
+	service := &dag.Service{
+		Name:      "auth",
+		Namespace: "default",
+		ServicePort: v1.ServicePort{
+			Protocol: "TCP",
+			Port:     9443,
+		},
+	}
+
+	tests := map[string]struct {
+		ext  *dag.ExtensionCluster
+		want *v2.Cluster
+	}{
+		"h2c": {
+			ext: &dag.ExtensionCluster{
+				Name:      "extension/default/auth",
+				Upstreams: []dag.WeightedService{{Weight: 1, Service: service}},
+				Protocol:  "h2c",
+			},
+			want: &v2.Cluster{
+				Name:                 "extension/default/auth",
+				ClusterDiscoveryType: ClusterDiscoveryType(v2.Cluster_EDS),
+				EdsClusterConfig: &v2.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "extension/default/auth",
+				},
+				CommonLbConfig: &v2.Cluster_CommonLbConfig{
+					HealthyPanicThreshold: &envoy_type.Percent{},
+					LocalityConfigSpecifier: &v2.Cluster_CommonLbConfig_LocalityWeightedLbConfig_{
+						LocalityWeightedLbConfig: &v2.Cluster_CommonLbConfig_LocalityWeightedLbConfig{},
+					},
+				},
+				Http2ProtocolOptions: &envoy_api_v2_core.Http2ProtocolOptions{},
+			},
+		},
+		"h2 with idle timeout and lb policy": {
+			ext: &dag.ExtensionCluster{
+				Name:               "extension/default/auth",
+				Upstreams:          []dag.WeightedService{{Weight: 1, Service: service}},
+				Protocol:           "h2",
+				LoadBalancerPolicy: "Random",
+				TimeoutPolicy: dag.TimeoutPolicy{
+					IdleTimeout: timeout.DurationSetting(time.Minute),
+				},
+			},
+			want: &v2.Cluster{
+				Name:                 "extension/default/auth",
+				ClusterDiscoveryType: ClusterDiscoveryType(v2.Cluster_EDS),
+				EdsClusterConfig: &v2.Cluster_EdsClusterConfig{
+					EdsConfig:   ConfigSource("contour"),
+					ServiceName: "extension/default/auth",
+				},
+				LbPolicy: v2.Cluster_RANDOM,
+				CommonLbConfig: &v2.Cluster_CommonLbConfig{
+					HealthyPanicThreshold: &envoy_type.Percent{},
+					LocalityConfigSpecifier: &v2.Cluster_CommonLbConfig_LocalityWeightedLbConfig_{
+						LocalityWeightedLbConfig: &v2.Cluster_CommonLbConfig_LocalityWeightedLbConfig{},
+					},
+				},
+				Http2ProtocolOptions: &envoy_api_v2_core.Http2ProtocolOptions{},
+				TransportSocket: UpstreamTLSTransportSocket(
+					UpstreamTLSContext(nil, "", nil, "h2"),
+				),
+				CommonHttpProtocolOptions: &envoy_api_v2_core.HttpProtocolOptions{
+					IdleTimeout: protobuf.Duration(time.Minute),
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := ExtensionCluster(tc.ext)
+			want := clusterDefaults()
+
+			proto.Merge(want, tc.want)
+
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
 func TestClusterLoadAssignmentName(t *testing.T) {
 	assert.Equal(t, ClusterLoadAssignmentName(types.NamespacedName{Namespace: "ns", Name: "svc"}, "port"), "ns/svc/port")
 	assert.Equal(t, ClusterLoadAssignmentName(types.NamespacedName{Namespace: "ns", Name: "svc"}, ""), "ns/svc")
@@ -426,6 +627,21 @@ func TestClustername(t *testing.T) {
 			},
 			want: "default/backend/80/da39a3ee5e",
 		},
+		"udp port": {
+			cluster: &dag.Cluster{
+				Upstream: &dag.Service{
+					Name:      "backend",
+					Namespace: "default",
+					ServicePort: v1.ServicePort{
+						Name:       "dns",
+						Protocol:   "UDP",
+						Port:       80,
+						TargetPort: intstr.FromInt(6502),
+					},
+				},
+			},
+			want: "default/backend/80/36cf8bee2c",
+		},
 		"far too long": {
 			cluster: &dag.Cluster{
 				Upstream: &dag.Service{
@@ -494,6 +710,44 @@ func TestClustername(t *testing.T) {
 			},
 			want: "default/backend/80/6bf46b7b3a",
 		},
+		"outlier detection params": {
+			cluster: &dag.Cluster{
+				Upstream: &dag.Service{
+					Name:      "backend",
+					Namespace: "default",
+					ServicePort: v1.ServicePort{
+						Name:       "http",
+						Protocol:   "TCP",
+						Port:       80,
+						TargetPort: intstr.FromInt(6502),
+					},
+				},
+				OutlierDetectionPolicy: &dag.OutlierDetectionPolicy{
+					Consecutive5xxErrors: 3,
+					Interval:             5 * time.Second,
+				},
+			},
+			want: "default/backend/80/ad5c465ebe",
+		},
+		"circuit breaker and connection pool params": {
+			cluster: &dag.Cluster{
+				Upstream: &dag.Service{
+					Name:      "backend",
+					Namespace: "default",
+					ServicePort: v1.ServicePort{
+						Name:       "http",
+						Protocol:   "TCP",
+						Port:       80,
+						TargetPort: intstr.FromInt(6502),
+					},
+				},
+				CircuitBreakerPolicy: &dag.CircuitBreakerPolicy{
+					MaxConnections: 100,
+				},
+				MaxRequestsPerConnection: 1000,
+			},
+			want: "default/backend/80/2b2301cbe0",
+		},
 	}
 
 	for name, tc := range tests {
@@ -512,11 +766,13 @@ func TestLBPolicy(t *testing.T) {
 		"":                     v2.Cluster_ROUND_ROBIN,
 		"unknown":              v2.Cluster_ROUND_ROBIN,
 		"Cookie":               v2.Cluster_RING_HASH,
+		"RequestHash":          v2.Cluster_RING_HASH,
+		"Maglev":               v2.Cluster_MAGLEV,
 
-		// RingHash and Maglev were removed as options in 0.13.
+		// RingHash was removed as an option in 0.13. Ring hashing
+		// is selected by the Cookie and RequestHash strategies.
 		// See #1150
 		"RingHash": v2.Cluster_ROUND_ROBIN,
-		"Maglev":   v2.Cluster_ROUND_ROBIN,
 	}
 
 	for policy, want := range tests {
@@ -617,3 +873,7 @@ func service(s *v1.Service, protocols ...string) *dag.Service {
 		Protocol:     protocol,
 	}
 }
+
+func uint32Ptr(v uint32) *uint32 {
+	return &v
+}
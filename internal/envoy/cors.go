@@ -0,0 +1,67 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"fmt"
+	"strings"
+
+	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/protobuf"
+)
+
+// CORSPolicy returns the Envoy CorsPolicy for the given dag.CORSPolicy,
+// or nil if the policy is nil.
+func CORSPolicy(cp *dag.CORSPolicy) *envoy_api_v2_route.CorsPolicy {
+	if cp == nil {
+		return nil
+	}
+
+	ecp := &envoy_api_v2_route.CorsPolicy{
+		AllowCredentials: protobuf.Bool(cp.AllowCredentials),
+		AllowMethods:     strings.Join(cp.AllowMethods, ","),
+		AllowHeaders:     strings.Join(cp.AllowHeaders, ","),
+		ExposeHeaders:    strings.Join(cp.ExposeHeaders, ","),
+	}
+
+	switch {
+	case cp.MaxAge.IsDisabled():
+		ecp.MaxAge = "0"
+	case !cp.MaxAge.UseDefault():
+		ecp.MaxAge = fmt.Sprintf("%.0f", cp.MaxAge.Duration().Seconds())
+	}
+
+	for _, origin := range cp.AllowOrigin {
+		switch {
+		case origin.Regex != "":
+			ecp.AllowOriginStringMatch = append(ecp.AllowOriginStringMatch, &matcher.StringMatcher{
+				MatchPattern: &matcher.StringMatcher_SafeRegex{
+					SafeRegex: SafeRegexMatch(origin.Regex),
+				},
+			})
+		default:
+			// Envoy treats an exact match of "*" as matching any origin.
+			ecp.AllowOriginStringMatch = append(ecp.AllowOriginStringMatch, &matcher.StringMatcher{
+				MatchPattern: &matcher.StringMatcher_Exact{
+					Exact: origin.Exact,
+				},
+				IgnoreCase: true,
+			})
+		}
+	}
+
+	return ecp
+}
@@ -0,0 +1,92 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"testing"
+	"time"
+
+	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher"
+	"github.com/projectcontour/contour/internal/assert"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/projectcontour/contour/internal/timeout"
+)
+
+func TestCORSPolicy(t *testing.T) {
+	tests := map[string]struct {
+		cp   *dag.CORSPolicy
+		want *envoy_api_v2_route.CorsPolicy
+	}{
+		"nil policy": {
+			cp:   nil,
+			want: nil,
+		},
+		"exact and regex origins": {
+			cp: &dag.CORSPolicy{
+				AllowCredentials: true,
+				AllowOrigin: []dag.CORSAllowOriginMatch{
+					{Exact: "*"},
+					{Regex: `https://.*\.example\.com`},
+				},
+				AllowMethods:  []string{"GET", "POST", "OPTIONS"},
+				AllowHeaders:  []string{"authorization", "cache-control"},
+				ExposeHeaders: []string{"x-request-id"},
+				MaxAge:        timeout.DurationSetting(10 * time.Minute),
+			},
+			want: &envoy_api_v2_route.CorsPolicy{
+				AllowOriginStringMatch: []*matcher.StringMatcher{{
+					MatchPattern: &matcher.StringMatcher_Exact{
+						Exact: "*",
+					},
+					IgnoreCase: true,
+				}, {
+					MatchPattern: &matcher.StringMatcher_SafeRegex{
+						SafeRegex: SafeRegexMatch(`https://.*\.example\.com`),
+					},
+				}},
+				AllowCredentials: protobuf.Bool(true),
+				AllowMethods:     "GET,POST,OPTIONS",
+				AllowHeaders:     "authorization,cache-control",
+				ExposeHeaders:    "x-request-id",
+				MaxAge:           "600",
+			},
+		},
+		"max age disabled": {
+			cp: &dag.CORSPolicy{
+				AllowOrigin:  []dag.CORSAllowOriginMatch{{Exact: "https://example.com"}},
+				AllowMethods: []string{"GET"},
+				MaxAge:       timeout.DisabledSetting(),
+			},
+			want: &envoy_api_v2_route.CorsPolicy{
+				AllowOriginStringMatch: []*matcher.StringMatcher{{
+					MatchPattern: &matcher.StringMatcher_Exact{
+						Exact: "https://example.com",
+					},
+					IgnoreCase: true,
+				}},
+				AllowCredentials: protobuf.Bool(false),
+				AllowMethods:     "GET",
+				MaxAge:           "0",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, CORSPolicy(tc.cp))
+		})
+	}
+}
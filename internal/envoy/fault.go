@@ -0,0 +1,107 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/projectcontour/contour/internal/dag"
+)
+
+// faultTypeURL is the type of the per-route fault filter
+// configuration. The v2 API cannot abort requests with a
+// gRPC status, so the configuration is passed as a TypedStruct.
+const faultTypeURL = "type.googleapis.com/envoy.extensions.filters.http.fault.v3.HTTPFault"
+
+// FaultConfig returns the per-filter configuration that injects
+// the faults of the fault injection policy into a route.
+func FaultConfig(policy *dag.FaultInjectionPolicy) *any.Any {
+	if policy == nil {
+		return nil
+	}
+
+	config := map[string]interface{}{}
+
+	if d := policy.Delay; d != nil {
+		config["delay"] = map[string]interface{}{
+			"fixed_delay": jsonDuration(d.FixedDelay),
+			"percentage":  faultPercentage(d.Percentage),
+		}
+	}
+
+	if a := policy.Abort; a != nil {
+		abort := map[string]interface{}{
+			"percentage": faultPercentage(a.Percentage),
+		}
+		switch {
+		case a.GRPCStatus != 0:
+			abort["grpc_status"] = a.GRPCStatus
+		default:
+			abort["http_status"] = a.HTTPStatus
+		}
+		config["abort"] = abort
+	}
+
+	if len(policy.Headers) > 0 {
+		var headers []interface{}
+		for _, h := range policy.Headers {
+			headers = append(headers, faultHeaderMatcher(h))
+		}
+		config["headers"] = headers
+	}
+
+	return typedStruct(faultTypeURL, config)
+}
+
+// faultPercentage returns the JSON FractionalPercent of a
+// percentage. Envoy applies faults in millionths, so the
+// percentage is scaled by 10,000.
+func faultPercentage(percentage float64) map[string]interface{} {
+	return map[string]interface{}{
+		"numerator":   uint32(math.Round(percentage * 10000)),
+		"denominator": "MILLION",
+	}
+}
+
+// faultHeaderMatcher returns the JSON HeaderMatcher of a header
+// condition. It matches headers the same way as headerMatcher.
+func faultHeaderMatcher(h dag.HeaderMatchCondition) map[string]interface{} {
+	header := map[string]interface{}{
+		"name": h.Name,
+	}
+
+	if h.Invert {
+		header["invert_match"] = true
+	}
+
+	switch h.MatchType {
+	case "exact":
+		header["exact_match"] = h.Value
+	case "contains":
+		// See containsMatch for why the substring is wrapped in a regex.
+		header["safe_regex_match"] = map[string]interface{}{
+			"google_re2": map[string]interface{}{
+				"max_program_size": maxRegexProgramSize,
+			},
+			"regex": fmt.Sprintf(".*%s.*", regexp.QuoteMeta(h.Value)),
+		}
+	case "present":
+		header["present_match"] = true
+	}
+
+	return header
+}
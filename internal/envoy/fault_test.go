@@ -0,0 +1,150 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"testing"
+	"time"
+
+	udpa_type_v1 "github.com/cncf/udpa/go/udpa/type/v1"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/projectcontour/contour/internal/assert"
+	"github.com/projectcontour/contour/internal/dag"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestFaultConfig(t *testing.T) {
+	tests := map[string]struct {
+		policy *dag.FaultInjectionPolicy
+		want   map[string]interface{}
+	}{
+		"fixed delay": {
+			policy: &dag.FaultInjectionPolicy{
+				Delay: &dag.FaultDelay{
+					FixedDelay: 1500 * time.Millisecond,
+					Percentage: 100,
+				},
+			},
+			want: map[string]interface{}{
+				"delay": map[string]interface{}{
+					"fixed_delay": "1.5s",
+					"percentage": map[string]interface{}{
+						"numerator":   1000000,
+						"denominator": "MILLION",
+					},
+				},
+			},
+		},
+		"http abort with headers": {
+			policy: &dag.FaultInjectionPolicy{
+				Abort: &dag.FaultAbort{
+					HTTPStatus: 503,
+					Percentage: 12.5,
+				},
+				Headers: []dag.HeaderMatchCondition{{
+					Name:      "x-fault",
+					Value:     "abort",
+					MatchType: "exact",
+				}, {
+					Name:      "x-user",
+					Value:     "a.b",
+					MatchType: "contains",
+					Invert:    true,
+				}, {
+					Name:      "x-debug",
+					MatchType: "present",
+				}},
+			},
+			want: map[string]interface{}{
+				"abort": map[string]interface{}{
+					"http_status": 503,
+					"percentage": map[string]interface{}{
+						"numerator":   125000,
+						"denominator": "MILLION",
+					},
+				},
+				"headers": []interface{}{
+					map[string]interface{}{
+						"name":        "x-fault",
+						"exact_match": "abort",
+					},
+					map[string]interface{}{
+						"name":         "x-user",
+						"invert_match": true,
+						"safe_regex_match": map[string]interface{}{
+							"google_re2": map[string]interface{}{
+								"max_program_size": maxRegexProgramSize,
+							},
+							"regex": `.*a\.b.*`,
+						},
+					},
+					map[string]interface{}{
+						"name":          "x-debug",
+						"present_match": true,
+					},
+				},
+			},
+		},
+		"grpc abort and delay": {
+			policy: &dag.FaultInjectionPolicy{
+				Delay: &dag.FaultDelay{
+					FixedDelay: 2 * time.Second,
+					Percentage: 50,
+				},
+				Abort: &dag.FaultAbort{
+					GRPCStatus: 14,
+					Percentage: 0.5,
+				},
+			},
+			want: map[string]interface{}{
+				"delay": map[string]interface{}{
+					"fixed_delay": "2s",
+					"percentage": map[string]interface{}{
+						"numerator":   500000,
+						"denominator": "MILLION",
+					},
+				},
+				"abort": map[string]interface{}{
+					"grpc_status": 14,
+					"percentage": map[string]interface{}{
+						"numerator":   5000,
+						"denominator": "MILLION",
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got udpa_type_v1.TypedStruct
+			if err := ptypes.UnmarshalAny(FaultConfig(tc.policy), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			want, err := structpb.NewStruct(tc.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, &udpa_type_v1.TypedStruct{
+				TypeUrl: faultTypeURL,
+				Value:   want,
+			}, &got)
+		})
+	}
+
+	assert.Equal(t, (*any.Any)(nil), FaultConfig(nil))
+}
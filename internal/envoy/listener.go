@@ -25,9 +25,12 @@ import (
 	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	envoy_api_v2_listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
 	accesslog "github.com/envoyproxy/go-control-plane/envoy/config/filter/accesslog/v2"
+	envoy_config_filter_http_ext_authz_v2 "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/ext_authz/v2"
 	lua "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/lua/v2"
 	http "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
 	tcp "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/tcp_proxy/v2"
+	udp "github.com/envoyproxy/go-control-plane/envoy/config/filter/udp/udp_proxy/v2alpha"
+	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/protobuf"
@@ -138,6 +141,8 @@ type httpConnectionManagerBuilder struct {
 	connectionShutdownGracePeriod timeout.Setting
 	filters                       []*http.HttpFilter
 	codec                         HTTPVersionType // Note the zero value is AUTO, which is the default we want.
+	tracing                       *http.HttpConnectionManager_Tracing
+	forwardClientCertificate      *dag.ForwardClientCertificate
 }
 
 // RouteConfigName sets the name of the RDS element that contains
@@ -199,6 +204,20 @@ func (b *httpConnectionManagerBuilder) ConnectionShutdownGracePeriod(timeout tim
 	return b
 }
 
+// Tracing sets the tracing configuration for the connection manager.
+// A nil configuration disables tracing.
+func (b *httpConnectionManagerBuilder) Tracing(tracing *http.HttpConnectionManager_Tracing) *httpConnectionManagerBuilder {
+	b.tracing = tracing
+	return b
+}
+
+// ForwardClientCertificate sets how the X-Forwarded-Client-Cert header
+// is handled. A nil configuration sanitizes the header.
+func (b *httpConnectionManagerBuilder) ForwardClientCertificate(fcc *dag.ForwardClientCertificate) *httpConnectionManagerBuilder {
+	b.forwardClientCertificate = fcc
+	return b
+}
+
 func (b *httpConnectionManagerBuilder) DefaultFilters() *httpConnectionManagerBuilder {
 	b.filters = append(b.filters,
 		&http.HttpFilter{
@@ -207,6 +226,12 @@ func (b *httpConnectionManagerBuilder) DefaultFilters() *httpConnectionManagerBu
 		&http.HttpFilter{
 			Name: wellknown.GRPCWeb,
 		},
+		&http.HttpFilter{
+			Name: wellknown.CORS,
+		},
+		&http.HttpFilter{
+			Name: wellknown.Fault,
+		},
 		&http.HttpFilter{
 			Name: wellknown.Router,
 		},
@@ -289,6 +314,21 @@ func (b *httpConnectionManagerBuilder) Get() *envoy_api_v2_listener.Filter {
 		cm.AccessLog = b.accessLoggers
 	}
 
+	if b.tracing != nil {
+		cm.Tracing = b.tracing
+	}
+
+	if fcc := b.forwardClientCertificate; fcc != nil {
+		cm.ForwardClientCertDetails = forwardClientCertDetails(fcc.Mode)
+		cm.SetCurrentClientCertDetails = &http.HttpConnectionManager_SetCurrentClientCertDetails{
+			Subject: protobuf.Bool(fcc.Subject),
+			Cert:    fcc.Cert,
+			Chain:   fcc.Chain,
+			Dns:     fcc.DNS,
+			Uri:     fcc.URI,
+		}
+	}
+
 	// If there's no explicit metrics prefix, default it to the
 	// route config name.
 	if b.metricsPrefix != "" {
@@ -305,6 +345,21 @@ func (b *httpConnectionManagerBuilder) Get() *envoy_api_v2_listener.Filter {
 	}
 }
 
+// forwardClientCertDetails returns the Envoy mode for
+// handling the X-Forwarded-Client-Cert header.
+func forwardClientCertDetails(mode string) http.HttpConnectionManager_ForwardClientCertDetails {
+	switch mode {
+	case "forward-only":
+		return http.HttpConnectionManager_FORWARD_ONLY
+	case "append-forward":
+		return http.HttpConnectionManager_APPEND_FORWARD
+	case "sanitize-set":
+		return http.HttpConnectionManager_SANITIZE_SET
+	default:
+		return http.HttpConnectionManager_SANITIZE
+	}
+}
+
 // HTTPConnectionManager creates a new HTTP Connection Manager filter
 // for the supplied route, access log, and client request timeout.
 func HTTPConnectionManager(routename string, accesslogger []*accesslog.AccessLog, requestTimeout time.Duration) *envoy_api_v2_listener.Filter {
@@ -375,6 +430,42 @@ func TCPProxy(statPrefix string, proxy *dag.TCPProxy, accesslogger []*accesslog.
 	}
 }
 
+// UDPListener returns a new v2.Listener for the supplied address and
+// port that forwards datagrams with the supplied udp_proxy filter.
+func UDPListener(name, address string, port int, proxy *envoy_api_v2_listener.ListenerFilter) *v2.Listener {
+	return &v2.Listener{
+		Name:            name,
+		Address:         UDPSocketAddress(address, port),
+		ListenerFilters: ListenerFilters(proxy),
+		// Without SO_REUSEPORT, a UDP listener is
+		// served by a single Envoy worker thread.
+		ReusePort: true,
+	}
+}
+
+// UDPProxy creates a new udp_proxy listener filter that
+// forwards datagrams to the supplied cluster.
+func UDPProxy(statPrefix string, cluster *dag.Cluster) *envoy_api_v2_listener.ListenerFilter {
+	return &envoy_api_v2_listener.ListenerFilter{
+		Name: "envoy.filters.udp_listener.udp_proxy",
+		ConfigType: &envoy_api_v2_listener.ListenerFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&udp.UdpProxyConfig{
+				StatPrefix: statPrefix,
+				RouteSpecifier: &udp.UdpProxyConfig_Cluster{
+					Cluster: Clustername(cluster),
+				},
+			}),
+		},
+	}
+}
+
+// UDPSocketAddress creates a new UDP envoy_api_v2_core.Address.
+func UDPSocketAddress(address string, port int) *envoy_api_v2_core.Address {
+	addr := SocketAddress(address, port)
+	addr.GetSocketAddress().Protocol = envoy_api_v2_core.SocketAddress_UDP
+	return addr
+}
+
 // SocketAddress creates a new TCP envoy_api_v2_core.Address.
 func SocketAddress(address string, port int) *envoy_api_v2_core.Address {
 	if address == "::" {
@@ -429,26 +520,48 @@ func FilterChains(filters ...*envoy_api_v2_listener.Filter) []*envoy_api_v2_list
 	}
 }
 
-func FilterMisdirectedRequests(fqdn string) *http.HttpFilter {
+// FilterMisdirectedRequests returns a Lua filter that responds with
+// 421 to requests whose Host header matches neither the fqdn nor any
+// of its aliases.
+func FilterMisdirectedRequests(fqdn string, aliases ...string) *http.HttpFilter {
 	// When Envoy matches on the virtual host domain, we configure
 	// it to match any port specifier (see envoy.VirtualHost),
 	// so the Host header (authority) may contain a port that
 	// should be ignored. This means that if we don't have a match,
 	// we should try again after stripping the port specifier.
+	//
+	// A wildcard fqdn such as "*.example.com" matches any host
+	// that ends with ".example.com", the same as Envoy does for
+	// wildcard virtual host domains and SNI server names.
 
 	code := `
+local targets = { %s }
+
+local function matches(host)
+	for _, target in ipairs(targets) do
+		if string.sub(target, 1, 1) == "*" then
+			local suffix = string.sub(target, 2)
+			if #host > #suffix and string.sub(host, -#suffix) == suffix then
+				return true
+			end
+		elseif host == target then
+			return true
+		end
+	end
+	return false
+end
+
 function envoy_on_request(request_handle)
 	local headers = request_handle:headers()
 	local host = string.lower(headers:get(":authority"))
-	local target = "%s"
 
-	if host ~= target then
+	if not matches(host) then
 		s, e = string.find(host, ":", 1, true)
 		if s ~= nil then
 			host = string.sub(host, 1, s - 1)
 		end
 
-		if host ~= target then
+		if not matches(host) then
 			request_handle:respond(
 				{[":status"] = "421"},
 				string.format("misdirected request to %%q", headers:get(":authority"))
@@ -458,22 +571,66 @@ function envoy_on_request(request_handle)
 end
 	`
 
+	var targets []string
+	for _, host := range append([]string{fqdn}, aliases...) {
+		targets = append(targets, fmt.Sprintf("%q", strings.ToLower(host)))
+	}
+
 	return &http.HttpFilter{
 		Name: "envoy.filters.http.lua",
 		ConfigType: &http.HttpFilter_TypedConfig{
 			TypedConfig: protobuf.MustMarshalAny(&lua.Lua{
-				InlineCode: fmt.Sprintf(code, strings.ToLower(fqdn)),
+				InlineCode: fmt.Sprintf(code, strings.Join(targets, ", ")),
 			}),
 		},
 	}
 }
 
-// FilterChainTLS returns a TLS enabled envoy_api_v2_listener.FilterChain.
-func FilterChainTLS(domain string, downstream *envoy_api_v2_auth.DownstreamTlsContext, filters []*envoy_api_v2_listener.Filter) *envoy_api_v2_listener.FilterChain {
+// FilterExternalAuthz returns an `ext_authz` filter configured with the
+// requested parameters.
+func FilterExternalAuthz(authzClusterName string, failOpen bool, timeout timeout.Setting) *http.HttpFilter {
+	authConfig := envoy_config_filter_http_ext_authz_v2.ExtAuthz{
+		Services: &envoy_config_filter_http_ext_authz_v2.ExtAuthz_GrpcService{
+			GrpcService: &envoy_api_v2_core.GrpcService{
+				TargetSpecifier: &envoy_api_v2_core.GrpcService_EnvoyGrpc_{
+					EnvoyGrpc: &envoy_api_v2_core.GrpcService_EnvoyGrpc{
+						ClusterName: authzClusterName,
+					},
+				},
+				Timeout: envoyTimeout(timeout),
+			},
+		},
+		// Clear the route cache so that the authorization
+		// server can affect the routing decision by setting
+		// request headers.
+		ClearRouteCache:  true,
+		FailureModeAllow: failOpen,
+		StatusOnError: &envoy_type.HttpStatus{
+			Code: envoy_type.StatusCode_Forbidden,
+		},
+		// If client certificate validation is enabled on the
+		// vhost, forward the peer certificate to the
+		// authorization server.
+		IncludePeerCertificate: true,
+	}
+
+	return &http.HttpFilter{
+		Name: wellknown.HTTPExternalAuthorization,
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&authConfig),
+		},
+	}
+}
+
+// FilterChainTLS returns a TLS enabled envoy_api_v2_listener.FilterChain
+// that matches the SNI names of the domain and its aliases.
+// The domain may be a wildcard such as "*.example.com"; Envoy prefers
+// filter chains whose server name matches exactly over wildcard ones.
+func FilterChainTLS(domain string, downstream *envoy_api_v2_auth.DownstreamTlsContext, filters []*envoy_api_v2_listener.Filter, aliases ...string) *envoy_api_v2_listener.FilterChain {
 	fc := &envoy_api_v2_listener.FilterChain{
 		Filters: filters,
 		FilterChainMatch: &envoy_api_v2_listener.FilterChainMatch{
-			ServerNames: []string{domain},
+			ServerNames: append([]string{domain}, aliases...),
 		},
 	}
 	// Attach TLS data to this listener if provided.
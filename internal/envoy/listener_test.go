@@ -24,6 +24,7 @@ import (
 	envoy_api_v2_accesslog "github.com/envoyproxy/go-control-plane/envoy/config/filter/accesslog/v2"
 	http "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
 	envoy_config_v2_tcpproxy "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/tcp_proxy/v2"
+	envoy_config_v2alpha_udpproxy "github.com/envoyproxy/go-control-plane/envoy/config/filter/udp/udp_proxy/v2alpha"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/google/go-cmp/cmp"
 	"github.com/projectcontour/contour/internal/assert"
@@ -254,12 +255,53 @@ func TestDownstreamTLSContext(t *testing.T) {
 		SubjectName: subjectName,
 	}
 
+	peerValidationContextWithCRL := &dag.PeerValidationContext{
+		CACertificate: peerValidationContext.CACertificate,
+		CRL: &dag.Secret{
+			Object: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "crl",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					dag.CRLKey: []byte("client-crl"),
+				},
+			},
+		},
+	}
+
 	tests := map[string]struct {
 		got  *envoy_api_v2_auth.DownstreamTlsContext
 		want *envoy_api_v2_auth.DownstreamTlsContext
 	}{
 		"TLS context without client authentication": {
-			DownstreamTLSContext(serverSecret, envoy_api_v2_auth.TlsParameters_TLSv1_1, nil, "h2", "http/1.1"),
+			DownstreamTLSContext(serverSecret, envoy_api_v2_auth.TlsParameters_TLSv1_1, envoy_api_v2_auth.TlsParameters_TLSv1_3, nil, nil, nil, "h2", "http/1.1"),
+			&envoy_api_v2_auth.DownstreamTlsContext{
+				CommonTlsContext: &envoy_api_v2_auth.CommonTlsContext{
+					TlsParams:                      tlsParams,
+					TlsCertificateSdsSecretConfigs: tlsCertificateSdsSecretConfigs,
+					AlpnProtocols:                  alpnProtocols,
+				},
+			},
+		},
+		"TLS context with maximum version, cipher suites and curves": {
+			DownstreamTLSContext(serverSecret, envoy_api_v2_auth.TlsParameters_TLSv1_1, envoy_api_v2_auth.TlsParameters_TLSv1_2,
+				[]string{"ECDHE-RSA-AES256-GCM-SHA384"}, []string{"X25519", "P-256"}, nil, "h2", "http/1.1"),
+			&envoy_api_v2_auth.DownstreamTlsContext{
+				CommonTlsContext: &envoy_api_v2_auth.CommonTlsContext{
+					TlsParams: &envoy_api_v2_auth.TlsParameters{
+						TlsMinimumProtocolVersion: envoy_api_v2_auth.TlsParameters_TLSv1_1,
+						TlsMaximumProtocolVersion: envoy_api_v2_auth.TlsParameters_TLSv1_2,
+						CipherSuites:              []string{"ECDHE-RSA-AES256-GCM-SHA384"},
+						EcdhCurves:                []string{"X25519", "P-256"},
+					},
+					TlsCertificateSdsSecretConfigs: tlsCertificateSdsSecretConfigs,
+					AlpnProtocols:                  alpnProtocols,
+				},
+			},
+		},
+		"TLS context with default maximum version": {
+			DownstreamTLSContext(serverSecret, envoy_api_v2_auth.TlsParameters_TLSv1_1, envoy_api_v2_auth.TlsParameters_TLS_AUTO, nil, nil, nil, "h2", "http/1.1"),
 			&envoy_api_v2_auth.DownstreamTlsContext{
 				CommonTlsContext: &envoy_api_v2_auth.CommonTlsContext{
 					TlsParams:                      tlsParams,
@@ -269,7 +311,7 @@ func TestDownstreamTLSContext(t *testing.T) {
 			},
 		},
 		"TLS context with client authentication": {
-			DownstreamTLSContext(serverSecret, envoy_api_v2_auth.TlsParameters_TLSv1_1, peerValidationContext, "h2", "http/1.1"),
+			DownstreamTLSContext(serverSecret, envoy_api_v2_auth.TlsParameters_TLSv1_1, envoy_api_v2_auth.TlsParameters_TLSv1_3, nil, nil, peerValidationContext, "h2", "http/1.1"),
 			&envoy_api_v2_auth.DownstreamTlsContext{
 				CommonTlsContext: &envoy_api_v2_auth.CommonTlsContext{
 					TlsParams:                      tlsParams,
@@ -280,8 +322,28 @@ func TestDownstreamTLSContext(t *testing.T) {
 				RequireClientCertificate: protobuf.Bool(true),
 			},
 		},
+		"TLS context with client authentication and CRL": {
+			DownstreamTLSContext(serverSecret, envoy_api_v2_auth.TlsParameters_TLSv1_1, envoy_api_v2_auth.TlsParameters_TLSv1_3, nil, nil, peerValidationContextWithCRL, "h2", "http/1.1"),
+			&envoy_api_v2_auth.DownstreamTlsContext{
+				CommonTlsContext: &envoy_api_v2_auth.CommonTlsContext{
+					TlsParams:                      tlsParams,
+					TlsCertificateSdsSecretConfigs: tlsCertificateSdsSecretConfigs,
+					AlpnProtocols:                  alpnProtocols,
+					ValidationContextType: &envoy_api_v2_auth.CommonTlsContext_CombinedValidationContext{
+						CombinedValidationContext: &envoy_api_v2_auth.CommonTlsContext_CombinedCertificateValidationContext{
+							DefaultValidationContext: validationContext.ValidationContext,
+							ValidationContextSdsSecretConfig: &envoy_api_v2_auth.SdsSecretConfig{
+								Name:      "default/crl/crl",
+								SdsConfig: tlsCertificateSdsSecretConfigs[0].SdsConfig,
+							},
+						},
+					},
+				},
+				RequireClientCertificate: protobuf.Bool(true),
+			},
+		},
 		"Downstream validation shall not support subjectName validation": {
-			DownstreamTLSContext(serverSecret, envoy_api_v2_auth.TlsParameters_TLSv1_1, peerValidationContextWithSubjectName, "h2", "http/1.1"),
+			DownstreamTLSContext(serverSecret, envoy_api_v2_auth.TlsParameters_TLSv1_1, envoy_api_v2_auth.TlsParameters_TLSv1_3, nil, nil, peerValidationContextWithSubjectName, "h2", "http/1.1"),
 			&envoy_api_v2_auth.DownstreamTlsContext{
 				CommonTlsContext: &envoy_api_v2_auth.CommonTlsContext{
 					TlsParams:                      tlsParams,
@@ -310,6 +372,7 @@ func TestHTTPConnectionManager(t *testing.T) {
 		streamIdleTimeout             timeout.Setting
 		maxConnectionDuration         timeout.Setting
 		connectionShutdownGracePeriod timeout.Setting
+		forwardClientCertificate      *dag.ForwardClientCertificate
 		want                          *envoy_api_v2_listener.Filter
 	}{
 		"default": {
@@ -343,6 +406,10 @@ func TestHTTPConnectionManager(t *testing.T) {
 							Name: wellknown.Gzip,
 						}, {
 							Name: wellknown.GRPCWeb,
+						}, {
+							Name: wellknown.CORS,
+						}, {
+							Name: wellknown.Fault,
 						}, {
 							Name: wellknown.Router,
 						}},
@@ -393,6 +460,10 @@ func TestHTTPConnectionManager(t *testing.T) {
 							Name: wellknown.Gzip,
 						}, {
 							Name: wellknown.GRPCWeb,
+						}, {
+							Name: wellknown.CORS,
+						}, {
+							Name: wellknown.Fault,
 						}, {
 							Name: wellknown.Router,
 						}},
@@ -444,6 +515,10 @@ func TestHTTPConnectionManager(t *testing.T) {
 							Name: wellknown.Gzip,
 						}, {
 							Name: wellknown.GRPCWeb,
+						}, {
+							Name: wellknown.CORS,
+						}, {
+							Name: wellknown.Fault,
 						}, {
 							Name: wellknown.Router,
 						}},
@@ -496,6 +571,10 @@ func TestHTTPConnectionManager(t *testing.T) {
 							Name: wellknown.Gzip,
 						}, {
 							Name: wellknown.GRPCWeb,
+						}, {
+							Name: wellknown.CORS,
+						}, {
+							Name: wellknown.Fault,
 						}, {
 							Name: wellknown.Router,
 						}},
@@ -547,6 +626,10 @@ func TestHTTPConnectionManager(t *testing.T) {
 							Name: wellknown.Gzip,
 						}, {
 							Name: wellknown.GRPCWeb,
+						}, {
+							Name: wellknown.CORS,
+						}, {
+							Name: wellknown.Fault,
 						}, {
 							Name: wellknown.Router,
 						}},
@@ -599,6 +682,10 @@ func TestHTTPConnectionManager(t *testing.T) {
 							Name: wellknown.Gzip,
 						}, {
 							Name: wellknown.GRPCWeb,
+						}, {
+							Name: wellknown.CORS,
+						}, {
+							Name: wellknown.Fault,
 						}, {
 							Name: wellknown.Router,
 						}},
@@ -649,6 +736,10 @@ func TestHTTPConnectionManager(t *testing.T) {
 							Name: wellknown.Gzip,
 						}, {
 							Name: wellknown.GRPCWeb,
+						}, {
+							Name: wellknown.CORS,
+						}, {
+							Name: wellknown.Fault,
 						}, {
 							Name: wellknown.Router,
 						}},
@@ -668,6 +759,68 @@ func TestHTTPConnectionManager(t *testing.T) {
 				},
 			},
 		},
+		"forward client certificate uri": {
+			routename:    "default/kuard",
+			accesslogger: FileAccessLogEnvoy("/dev/stdout"),
+			forwardClientCertificate: &dag.ForwardClientCertificate{
+				Mode: "sanitize-set",
+				URI:  true,
+			},
+			want: &envoy_api_v2_listener.Filter{
+				Name: wellknown.HTTPConnectionManager,
+				ConfigType: &envoy_api_v2_listener.Filter_TypedConfig{
+					TypedConfig: protobuf.MustMarshalAny(&http.HttpConnectionManager{
+						StatPrefix: "default/kuard",
+						RouteSpecifier: &http.HttpConnectionManager_Rds{
+							Rds: &http.Rds{
+								RouteConfigName: "default/kuard",
+								ConfigSource: &envoy_api_v2_core.ConfigSource{
+									ConfigSourceSpecifier: &envoy_api_v2_core.ConfigSource_ApiConfigSource{
+										ApiConfigSource: &envoy_api_v2_core.ApiConfigSource{
+											ApiType: envoy_api_v2_core.ApiConfigSource_GRPC,
+											GrpcServices: []*envoy_api_v2_core.GrpcService{{
+												TargetSpecifier: &envoy_api_v2_core.GrpcService_EnvoyGrpc_{
+													EnvoyGrpc: &envoy_api_v2_core.GrpcService_EnvoyGrpc{
+														ClusterName: "contour",
+													},
+												},
+											}},
+										},
+									},
+								},
+							},
+						},
+						HttpFilters: []*http.HttpFilter{{
+							Name: wellknown.Gzip,
+						}, {
+							Name: wellknown.GRPCWeb,
+						}, {
+							Name: wellknown.CORS,
+						}, {
+							Name: wellknown.Fault,
+						}, {
+							Name: wellknown.Router,
+						}},
+						HttpProtocolOptions: &envoy_api_v2_core.Http1ProtocolOptions{
+							// Enable support for HTTP/1.0 requests that carry
+							// a Host: header. See #537.
+							AcceptHttp_10: true,
+						},
+						CommonHttpProtocolOptions: &envoy_api_v2_core.HttpProtocolOptions{},
+						AccessLog:                 FileAccessLogEnvoy("/dev/stdout"),
+						UseRemoteAddress:          protobuf.Bool(true),
+						NormalizePath:             protobuf.Bool(true),
+						PreserveExternalRequestId: true,
+						MergeSlashes:              true,
+						ForwardClientCertDetails:  http.HttpConnectionManager_SANITIZE_SET,
+						SetCurrentClientCertDetails: &http.HttpConnectionManager_SetCurrentClientCertDetails{
+							Subject: protobuf.Bool(false),
+							Uri:     true,
+						},
+					}),
+				},
+			},
+		},
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -680,6 +833,7 @@ func TestHTTPConnectionManager(t *testing.T) {
 				StreamIdleTimeout(tc.streamIdleTimeout).
 				MaxConnectionDuration(tc.maxConnectionDuration).
 				ConnectionShutdownGracePeriod(tc.connectionShutdownGracePeriod).
+				ForwardClientCertificate(tc.forwardClientCertificate).
 				DefaultFilters().
 				Get()
 
@@ -776,6 +930,50 @@ func TestTCPProxy(t *testing.T) {
 	}
 }
 
+func TestUDPListener(t *testing.T) {
+	c1 := &dag.Cluster{
+		Upstream: &dag.Service{
+			Name:      "coredns",
+			Namespace: "default",
+			ServicePort: v1.ServicePort{
+				Name:       "dns",
+				Protocol:   "UDP",
+				Port:       53,
+				TargetPort: intstr.FromInt(5353),
+			},
+		},
+	}
+
+	got := UDPListener("ingress_udp_53", "0.0.0.0", 53, UDPProxy("ingress_udp_53", c1))
+	want := &v2.Listener{
+		Name: "ingress_udp_53",
+		Address: &envoy_api_v2_core.Address{
+			Address: &envoy_api_v2_core.Address_SocketAddress{
+				SocketAddress: &envoy_api_v2_core.SocketAddress{
+					Protocol: envoy_api_v2_core.SocketAddress_UDP,
+					Address:  "0.0.0.0",
+					PortSpecifier: &envoy_api_v2_core.SocketAddress_PortValue{
+						PortValue: 53,
+					},
+				},
+			},
+		},
+		ListenerFilters: []*envoy_api_v2_listener.ListenerFilter{{
+			Name: "envoy.filters.udp_listener.udp_proxy",
+			ConfigType: &envoy_api_v2_listener.ListenerFilter_TypedConfig{
+				TypedConfig: protobuf.MustMarshalAny(&envoy_config_v2alpha_udpproxy.UdpProxyConfig{
+					StatPrefix: "ingress_udp_53",
+					RouteSpecifier: &envoy_config_v2alpha_udpproxy.UdpProxyConfig_Cluster{
+						Cluster: Clustername(c1),
+					},
+				}),
+			},
+		}},
+		ReusePort: true,
+	}
+	assert.Equal(t, want, got)
+}
+
 func TestCodecForVersions(t *testing.T) {
 	assert.Equal(t, CodecForVersions(HTTPVersionAuto), HTTPVersionAuto)
 	assert.Equal(t, CodecForVersions(HTTPVersion1, HTTPVersion2), HTTPVersionAuto)
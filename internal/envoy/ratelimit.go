@@ -0,0 +1,208 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	udpa_type_v1 "github.com/cncf/udpa/go/udpa/type/v1"
+	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	envoy_config_filter_http_rate_limit_v2 "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/rate_limit/v2"
+	http "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	envoy_config_ratelimit_v2 "github.com/envoyproxy/go-control-plane/envoy/config/ratelimit/v2"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// LocalRateLimitFilterName is the name of the Envoy HTTP
+	// local rate limit filter.
+	LocalRateLimitFilterName = "envoy.filters.http.local_ratelimit"
+
+	// localRateLimitTypeURL is the type of the local rate limit
+	// filter configuration. The v2 API has no local rate limit
+	// filter, so the configuration is passed as a TypedStruct.
+	localRateLimitTypeURL = "type.googleapis.com/envoy.extensions.filters.http.local_ratelimit.v3.LocalRateLimit"
+
+	// localRateLimitStatPrefix is the stats prefix of the
+	// local rate limit filter.
+	localRateLimitStatPrefix = "http_local_rate_limiter"
+)
+
+// FilterLocalRateLimit returns a local rate limit HTTP filter. The
+// filter has no token bucket of its own, it only enforces the limits
+// configured on virtual hosts and routes.
+//
+// The local rate limit filter requires Envoy 1.16 or later.
+func FilterLocalRateLimit() *http.HttpFilter {
+	return &http.HttpFilter{
+		Name: LocalRateLimitFilterName,
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: typedStruct(localRateLimitTypeURL, map[string]interface{}{
+				"stat_prefix": localRateLimitStatPrefix,
+			}),
+		},
+	}
+}
+
+// LocalRateLimitConfig returns the per-filter configuration that
+// applies the local rate limit policy to a virtual host or route.
+func LocalRateLimitConfig(policy *dag.LocalRateLimitPolicy) *any.Any {
+	if policy == nil {
+		return nil
+	}
+
+	// Enable and enforce the filter for all requests.
+	allRequests := map[string]interface{}{
+		"default_value": map[string]interface{}{
+			"numerator":   100,
+			"denominator": "HUNDRED",
+		},
+	}
+
+	config := map[string]interface{}{
+		"stat_prefix": localRateLimitStatPrefix,
+		"token_bucket": map[string]interface{}{
+			"max_tokens":      policy.MaxTokens,
+			"tokens_per_fill": policy.TokensPerFill,
+			"fill_interval":   jsonDuration(policy.FillInterval),
+		},
+		"filter_enabled":  allRequests,
+		"filter_enforced": allRequests,
+	}
+
+	if policy.ResponseStatusCode > 0 {
+		config["status"] = map[string]interface{}{
+			"code": policy.ResponseStatusCode,
+		}
+	}
+
+	if len(policy.ResponseHeadersToAdd) > 0 {
+		var names []string
+		for name := range policy.ResponseHeadersToAdd {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var headers []interface{}
+		for _, name := range names {
+			headers = append(headers, map[string]interface{}{
+				"header": map[string]interface{}{
+					"key":   name,
+					"value": policy.ResponseHeadersToAdd[name],
+				},
+				"append": false,
+			})
+		}
+		config["response_headers_to_add"] = headers
+	}
+
+	return typedStruct(localRateLimitTypeURL, config)
+}
+
+// FilterGlobalRateLimit returns a rate limit HTTP filter that asks the
+// given rate limit service for a decision on each request.
+func FilterGlobalRateLimit(rls *dag.RateLimitService) *http.HttpFilter {
+	config := envoy_config_filter_http_rate_limit_v2.RateLimit{
+		Domain:          rls.Domain,
+		Timeout:         envoyTimeout(rls.Timeout),
+		FailureModeDeny: !rls.FailOpen,
+		RateLimitService: &envoy_config_ratelimit_v2.RateLimitServiceConfig{
+			GrpcService: &envoy_api_v2_core.GrpcService{
+				TargetSpecifier: &envoy_api_v2_core.GrpcService_EnvoyGrpc_{
+					EnvoyGrpc: &envoy_api_v2_core.GrpcService_EnvoyGrpc{
+						ClusterName: rls.Cluster.Name,
+					},
+				},
+			},
+		},
+	}
+
+	return &http.HttpFilter{
+		Name: wellknown.HTTPRateLimit,
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&config),
+		},
+	}
+}
+
+// GlobalRateLimits returns the rate limit actions that generate the
+// descriptors of the global rate limit policy.
+func GlobalRateLimits(policy *dag.GlobalRateLimitPolicy) []*envoy_api_v2_route.RateLimit {
+	if policy == nil {
+		return nil
+	}
+
+	var rateLimits []*envoy_api_v2_route.RateLimit
+	for _, descriptor := range policy.Descriptors {
+		var rl envoy_api_v2_route.RateLimit
+
+		for _, entry := range descriptor.Entries {
+			switch {
+			case entry.GenericKey != nil:
+				rl.Actions = append(rl.Actions, &envoy_api_v2_route.RateLimit_Action{
+					ActionSpecifier: &envoy_api_v2_route.RateLimit_Action_GenericKey_{
+						GenericKey: &envoy_api_v2_route.RateLimit_Action_GenericKey{
+							DescriptorValue: entry.GenericKey.Value,
+						},
+					},
+				})
+			case entry.HeaderMatch != nil:
+				rl.Actions = append(rl.Actions, &envoy_api_v2_route.RateLimit_Action{
+					ActionSpecifier: &envoy_api_v2_route.RateLimit_Action_RequestHeaders_{
+						RequestHeaders: &envoy_api_v2_route.RateLimit_Action_RequestHeaders{
+							HeaderName:    entry.HeaderMatch.HeaderName,
+							DescriptorKey: entry.HeaderMatch.Key,
+						},
+					},
+				})
+			case entry.RemoteAddress != nil:
+				rl.Actions = append(rl.Actions, &envoy_api_v2_route.RateLimit_Action{
+					ActionSpecifier: &envoy_api_v2_route.RateLimit_Action_RemoteAddress_{
+						RemoteAddress: &envoy_api_v2_route.RateLimit_Action_RemoteAddress{},
+					},
+				})
+			}
+		}
+
+		rateLimits = append(rateLimits, &rl)
+	}
+
+	return rateLimits
+}
+
+// typedStruct returns the given configuration as a TypedStruct
+// of the given type, wrapped in an Any.
+func typedStruct(typeURL string, config map[string]interface{}) *any.Any {
+	value, err := structpb.NewStruct(config)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return protobuf.MustMarshalAny(&udpa_type_v1.TypedStruct{
+		TypeUrl: typeURL,
+		Value:   value,
+	})
+}
+
+// jsonDuration formats d as a JSON protobuf Duration.
+func jsonDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
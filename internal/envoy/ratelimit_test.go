@@ -0,0 +1,209 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"testing"
+	"time"
+
+	udpa_type_v1 "github.com/cncf/udpa/go/udpa/type/v1"
+	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	envoy_config_filter_http_rate_limit_v2 "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/rate_limit/v2"
+	http "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	envoy_config_ratelimit_v2 "github.com/envoyproxy/go-control-plane/envoy/config/ratelimit/v2"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/projectcontour/contour/internal/assert"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/projectcontour/contour/internal/timeout"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestLocalRateLimitConfig(t *testing.T) {
+	tests := map[string]struct {
+		policy *dag.LocalRateLimitPolicy
+		want   map[string]interface{}
+	}{
+		"token bucket only": {
+			policy: &dag.LocalRateLimitPolicy{
+				MaxTokens:     150,
+				TokensPerFill: 100,
+				FillInterval:  time.Minute,
+			},
+			want: map[string]interface{}{
+				"stat_prefix": "http_local_rate_limiter",
+				"token_bucket": map[string]interface{}{
+					"max_tokens":      150,
+					"tokens_per_fill": 100,
+					"fill_interval":   "60s",
+				},
+				"filter_enabled": map[string]interface{}{
+					"default_value": map[string]interface{}{"numerator": 100, "denominator": "HUNDRED"},
+				},
+				"filter_enforced": map[string]interface{}{
+					"default_value": map[string]interface{}{"numerator": 100, "denominator": "HUNDRED"},
+				},
+			},
+		},
+		"status and response headers": {
+			policy: &dag.LocalRateLimitPolicy{
+				MaxTokens:          1,
+				TokensPerFill:      1,
+				FillInterval:       time.Second,
+				ResponseStatusCode: 503,
+				ResponseHeadersToAdd: map[string]string{
+					"X-Header-2": "value-2",
+					"X-Header-1": "value-1",
+				},
+			},
+			want: map[string]interface{}{
+				"stat_prefix": "http_local_rate_limiter",
+				"token_bucket": map[string]interface{}{
+					"max_tokens":      1,
+					"tokens_per_fill": 1,
+					"fill_interval":   "1s",
+				},
+				"filter_enabled": map[string]interface{}{
+					"default_value": map[string]interface{}{"numerator": 100, "denominator": "HUNDRED"},
+				},
+				"filter_enforced": map[string]interface{}{
+					"default_value": map[string]interface{}{"numerator": 100, "denominator": "HUNDRED"},
+				},
+				"status": map[string]interface{}{
+					"code": 503,
+				},
+				"response_headers_to_add": []interface{}{
+					map[string]interface{}{
+						"header": map[string]interface{}{"key": "X-Header-1", "value": "value-1"},
+						"append": false,
+					},
+					map[string]interface{}{
+						"header": map[string]interface{}{"key": "X-Header-2", "value": "value-2"},
+						"append": false,
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got udpa_type_v1.TypedStruct
+			if err := ptypes.UnmarshalAny(LocalRateLimitConfig(tc.policy), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			want, err := structpb.NewStruct(tc.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, &udpa_type_v1.TypedStruct{
+				TypeUrl: "type.googleapis.com/envoy.extensions.filters.http.local_ratelimit.v3.LocalRateLimit",
+				Value:   want,
+			}, &got)
+		})
+	}
+
+	assert.Equal(t, (*any.Any)(nil), LocalRateLimitConfig(nil))
+}
+
+func TestFilterGlobalRateLimit(t *testing.T) {
+	got := FilterGlobalRateLimit(&dag.RateLimitService{
+		Cluster: &dag.ExtensionCluster{
+			Name: "extension/projectcontour/ratelimit",
+		},
+		Domain:  "contour",
+		Timeout: timeout.DurationSetting(100 * time.Millisecond),
+	})
+
+	want := &http.HttpFilter{
+		Name: wellknown.HTTPRateLimit,
+		ConfigType: &http.HttpFilter_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(&envoy_config_filter_http_rate_limit_v2.RateLimit{
+				Domain:          "contour",
+				Timeout:         protobuf.Duration(100 * time.Millisecond),
+				FailureModeDeny: true,
+				RateLimitService: &envoy_config_ratelimit_v2.RateLimitServiceConfig{
+					GrpcService: &envoy_api_v2_core.GrpcService{
+						TargetSpecifier: &envoy_api_v2_core.GrpcService_EnvoyGrpc_{
+							EnvoyGrpc: &envoy_api_v2_core.GrpcService_EnvoyGrpc{
+								ClusterName: "extension/projectcontour/ratelimit",
+							},
+						},
+					},
+				},
+			}),
+		},
+	}
+
+	assert.Equal(t, want, got)
+}
+
+func TestGlobalRateLimits(t *testing.T) {
+	tests := map[string]struct {
+		policy *dag.GlobalRateLimitPolicy
+		want   []*envoy_api_v2_route.RateLimit
+	}{
+		"nil policy": {
+			policy: nil,
+			want:   nil,
+		},
+		"multiple descriptors": {
+			policy: &dag.GlobalRateLimitPolicy{
+				Descriptors: []*dag.RateLimitDescriptor{{
+					Entries: []dag.RateLimitDescriptorEntry{
+						{GenericKey: &dag.GenericKeyDescriptorEntry{Value: "generic-value"}},
+						{HeaderMatch: &dag.HeaderMatchDescriptorEntry{HeaderName: "X-Header", Key: "header-key"}},
+					},
+				}, {
+					Entries: []dag.RateLimitDescriptorEntry{
+						{RemoteAddress: &dag.RemoteAddressDescriptorEntry{}},
+					},
+				}},
+			},
+			want: []*envoy_api_v2_route.RateLimit{{
+				Actions: []*envoy_api_v2_route.RateLimit_Action{{
+					ActionSpecifier: &envoy_api_v2_route.RateLimit_Action_GenericKey_{
+						GenericKey: &envoy_api_v2_route.RateLimit_Action_GenericKey{
+							DescriptorValue: "generic-value",
+						},
+					},
+				}, {
+					ActionSpecifier: &envoy_api_v2_route.RateLimit_Action_RequestHeaders_{
+						RequestHeaders: &envoy_api_v2_route.RateLimit_Action_RequestHeaders{
+							HeaderName:    "X-Header",
+							DescriptorKey: "header-key",
+						},
+					},
+				}},
+			}, {
+				Actions: []*envoy_api_v2_route.RateLimit_Action{{
+					ActionSpecifier: &envoy_api_v2_route.RateLimit_Action_RemoteAddress_{
+						RemoteAddress: &envoy_api_v2_route.RateLimit_Action_RemoteAddress{},
+					},
+				}},
+			}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, GlobalRateLimits(tc.policy))
+		})
+	}
+}
@@ -15,12 +15,18 @@ package envoy
 
 import (
 	"fmt"
+	"net/http"
 	"regexp"
 	"sort"
+	"strings"
 
 	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
 	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	envoy_config_filter_http_ext_authz_v2 "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/ext_authz/v2"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/ptypes/any"
 	"github.com/golang/protobuf/ptypes/duration"
 	wrappers "github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/projectcontour/contour/internal/dag"
@@ -29,28 +35,72 @@ import (
 	"github.com/projectcontour/contour/internal/timeout"
 )
 
+// RouteAuthzDisabled returns a per-route config to disable authorization.
+func RouteAuthzDisabled() *any.Any {
+	return protobuf.MustMarshalAny(
+		&envoy_config_filter_http_ext_authz_v2.ExtAuthzPerRoute{
+			Override: &envoy_config_filter_http_ext_authz_v2.ExtAuthzPerRoute_Disabled{
+				Disabled: true,
+			},
+		},
+	)
+}
+
+// RouteAuthzContext returns a per-route config to pass the given
+// context entries in the check request.
+func RouteAuthzContext(settings map[string]string) *any.Any {
+	return protobuf.MustMarshalAny(
+		&envoy_config_filter_http_ext_authz_v2.ExtAuthzPerRoute{
+			Override: &envoy_config_filter_http_ext_authz_v2.ExtAuthzPerRoute_CheckSettings{
+				CheckSettings: &envoy_config_filter_http_ext_authz_v2.CheckSettings{
+					ContextExtensions: settings,
+				},
+			},
+		},
+	)
+}
+
+// RouteAuthzTypedConfig returns the per-filter configuration that
+// applies the authorization policy of the given route, or nil if
+// the route has no authorization settings.
+func RouteAuthzTypedConfig(r *dag.Route) map[string]*any.Any {
+	switch {
+	case r.AuthDisabled:
+		return map[string]*any.Any{
+			wellknown.HTTPExternalAuthorization: RouteAuthzDisabled(),
+		}
+	case len(r.AuthContext) > 0:
+		return map[string]*any.Any{
+			wellknown.HTTPExternalAuthorization: RouteAuthzContext(r.AuthContext),
+		}
+	default:
+		return nil
+	}
+}
+
 // RouteMatch creates a *envoy_api_v2_route.RouteMatch for the supplied *dag.Route.
 func RouteMatch(route *dag.Route) *envoy_api_v2_route.RouteMatch {
+	match := &envoy_api_v2_route.RouteMatch{
+		Headers:         headerMatcher(route.HeaderMatchConditions),
+		QueryParameters: queryParamMatcher(route.QueryParamMatchConditions),
+	}
+
 	switch c := route.PathMatchCondition.(type) {
 	case *dag.RegexMatchCondition:
-		return &envoy_api_v2_route.RouteMatch{
-			PathSpecifier: &envoy_api_v2_route.RouteMatch_SafeRegex{
-				SafeRegex: SafeRegexMatch(c.Regex),
-			},
-			Headers: headerMatcher(route.HeaderMatchConditions),
+		match.PathSpecifier = &envoy_api_v2_route.RouteMatch_SafeRegex{
+			SafeRegex: SafeRegexMatch(c.Regex),
 		}
-	case *dag.PrefixMatchCondition:
-		return &envoy_api_v2_route.RouteMatch{
-			PathSpecifier: &envoy_api_v2_route.RouteMatch_Prefix{
-				Prefix: c.Prefix,
-			},
-			Headers: headerMatcher(route.HeaderMatchConditions),
+	case *dag.ExactMatchCondition:
+		match.PathSpecifier = &envoy_api_v2_route.RouteMatch_Path{
+			Path: c.Path,
 		}
-	default:
-		return &envoy_api_v2_route.RouteMatch{
-			Headers: headerMatcher(route.HeaderMatchConditions),
+	case *dag.PrefixMatchCondition:
+		match.PathSpecifier = &envoy_api_v2_route.RouteMatch_Prefix{
+			Prefix: c.Prefix,
 		}
 	}
+
+	return match
 }
 
 // RouteRoute creates a *envoy_api_v2_route.Route_Route for the services supplied.
@@ -66,6 +116,17 @@ func RouteRoute(r *dag.Route) *envoy_api_v2_route.Route_Route {
 		RequestMirrorPolicies: mirrorPolicy(r),
 	}
 
+	if r.RegexRewrite != nil {
+		ra.RegexRewrite = &matcher.RegexMatchAndSubstitute{
+			Pattern:      SafeRegexMatch(r.RegexRewrite.Pattern),
+			Substitution: r.RegexRewrite.Substitution,
+		}
+	}
+
+	if r.RateLimitPolicy != nil {
+		ra.RateLimits = GlobalRateLimits(r.RateLimitPolicy.Global)
+	}
+
 	// Check for host header policy and set if found
 	if val := hostReplaceHeader(r.RequestHeadersPolicy); val != "" {
 		ra.HostRewriteSpecifier = &envoy_api_v2_route.RouteAction_HostRewrite{
@@ -95,23 +156,52 @@ func RouteRoute(r *dag.Route) *envoy_api_v2_route.Route_Route {
 	}
 }
 
-// hashPolicy returns a slice of hash policies iff at least one of the route's
-// clusters supplied uses the `Cookie` load balancing strategy.
+// hashPolicy returns the Envoy hash policies of the route's
+// RequestHashPolicies. Policies without a hash source are skipped.
 func hashPolicy(r *dag.Route) []*envoy_api_v2_route.RouteAction_HashPolicy {
-	for _, c := range r.Clusters {
-		if c.LoadBalancerPolicy == "Cookie" {
-			return []*envoy_api_v2_route.RouteAction_HashPolicy{{
-				PolicySpecifier: &envoy_api_v2_route.RouteAction_HashPolicy_Cookie_{
-					Cookie: &envoy_api_v2_route.RouteAction_HashPolicy_Cookie{
-						Name: "X-Contour-Session-Affinity",
-						Ttl:  protobuf.Duration(0),
-						Path: "/",
-					},
+	var policies []*envoy_api_v2_route.RouteAction_HashPolicy
+	for _, rhp := range r.RequestHashPolicies {
+		policy := &envoy_api_v2_route.RouteAction_HashPolicy{
+			Terminal: rhp.Terminal,
+		}
+
+		switch {
+		case rhp.HeaderHashOptions != nil:
+			policy.PolicySpecifier = &envoy_api_v2_route.RouteAction_HashPolicy_Header_{
+				Header: &envoy_api_v2_route.RouteAction_HashPolicy_Header{
+					HeaderName: rhp.HeaderHashOptions.HeaderName,
+				},
+			}
+		case rhp.CookieHashOptions != nil:
+			cookie := &envoy_api_v2_route.RouteAction_HashPolicy_Cookie{
+				Name: rhp.CookieHashOptions.Name,
+				Path: rhp.CookieHashOptions.Path,
+			}
+			if rhp.CookieHashOptions.TTL != nil {
+				cookie.Ttl = protobuf.Duration(*rhp.CookieHashOptions.TTL)
+			}
+			policy.PolicySpecifier = &envoy_api_v2_route.RouteAction_HashPolicy_Cookie_{
+				Cookie: cookie,
+			}
+		case rhp.QueryParameterHashOptions != nil:
+			policy.PolicySpecifier = &envoy_api_v2_route.RouteAction_HashPolicy_QueryParameter_{
+				QueryParameter: &envoy_api_v2_route.RouteAction_HashPolicy_QueryParameter{
+					Name: rhp.QueryParameterHashOptions.ParameterName,
+				},
+			}
+		case rhp.HashSourceIP:
+			policy.PolicySpecifier = &envoy_api_v2_route.RouteAction_HashPolicy_ConnectionProperties_{
+				ConnectionProperties: &envoy_api_v2_route.RouteAction_HashPolicy_ConnectionProperties{
+					SourceIp: true,
 				},
-			}}
+			}
+		default:
+			continue
 		}
+
+		policies = append(policies, policy)
 	}
-	return nil
+	return policies
 }
 
 func mirrorPolicy(r *dag.Route) []*envoy_api_v2_route.RouteAction_RequestMirrorPolicy {
@@ -182,6 +272,74 @@ func UpgradeHTTPS() *envoy_api_v2_route.Route_Redirect {
 	}
 }
 
+// RouteRedirect returns a route Action that redirects the request
+// according to the given redirect.
+func RouteRedirect(r *dag.Redirect) *envoy_api_v2_route.Route_Redirect {
+	ra := &envoy_api_v2_route.RedirectAction{
+		HostRedirect: r.Hostname,
+		PortRedirect: r.Port,
+		ResponseCode: redirectResponseCode(r.StatusCode),
+	}
+
+	if r.Scheme != "" {
+		ra.SchemeRewriteSpecifier = &envoy_api_v2_route.RedirectAction_SchemeRedirect{
+			SchemeRedirect: r.Scheme,
+		}
+	}
+
+	switch {
+	case r.Path != "":
+		ra.PathRewriteSpecifier = &envoy_api_v2_route.RedirectAction_PathRedirect{
+			PathRedirect: r.Path,
+		}
+	case r.Prefix != "":
+		ra.PathRewriteSpecifier = &envoy_api_v2_route.RedirectAction_PrefixRewrite{
+			PrefixRewrite: r.Prefix,
+		}
+	}
+
+	return &envoy_api_v2_route.Route_Redirect{
+		Redirect: ra,
+	}
+}
+
+// redirectResponseCode returns the Envoy response code for the
+// given HTTP redirect status code. Envoy defaults to 301.
+func redirectResponseCode(code int) envoy_api_v2_route.RedirectAction_RedirectResponseCode {
+	switch code {
+	case http.StatusFound:
+		return envoy_api_v2_route.RedirectAction_FOUND
+	case http.StatusSeeOther:
+		return envoy_api_v2_route.RedirectAction_SEE_OTHER
+	case http.StatusTemporaryRedirect:
+		return envoy_api_v2_route.RedirectAction_TEMPORARY_REDIRECT
+	case http.StatusPermanentRedirect:
+		return envoy_api_v2_route.RedirectAction_PERMANENT_REDIRECT
+	default:
+		return envoy_api_v2_route.RedirectAction_MOVED_PERMANENTLY
+	}
+}
+
+// RouteDirectResponse returns a route Action that responds to the
+// request directly with the given response.
+func RouteDirectResponse(r *dag.DirectResponse) *envoy_api_v2_route.Route_DirectResponse {
+	dr := &envoy_api_v2_route.DirectResponseAction{
+		Status: r.StatusCode,
+	}
+
+	if r.Body != "" {
+		dr.Body = &envoy_api_v2_core.DataSource{
+			Specifier: &envoy_api_v2_core.DataSource_InlineString{
+				InlineString: r.Body,
+			},
+		}
+	}
+
+	return &envoy_api_v2_route.Route_DirectResponse{
+		DirectResponse: dr,
+	}
+}
+
 // HeaderValueList creates a list of Envoy HeaderValueOptions from the provided map.
 func HeaderValueList(hvm map[string]string, app bool) []*envoy_api_v2_core.HeaderValueOption {
 	var hvs []*envoy_api_v2_core.HeaderValueOption
@@ -268,21 +426,35 @@ func weightedClusters(clusters []*dag.Cluster) *envoy_api_v2_route.WeightedClust
 	return &wc
 }
 
-// VirtualHost creates a new route.VirtualHost.
+// VirtualHost creates a new route.VirtualHost. The hostname may be
+// a wildcard such as "*.example.com"; Envoy prefers virtual hosts
+// whose domains match exactly over wildcard ones.
 func VirtualHost(hostname string, routes ...*envoy_api_v2_route.Route) *envoy_api_v2_route.VirtualHost {
-	domains := []string{hostname}
-	if hostname != "*" {
-		// NOTE(jpeach) see also envoy.FilterMisdirectedRequests().
-		domains = append(domains, hostname+":*")
-	}
-
 	return &envoy_api_v2_route.VirtualHost{
 		Name:    hashname(60, hostname),
-		Domains: domains,
+		Domains: Domains(hostname),
 		Routes:  routes,
 	}
 }
 
+// Domains returns the route.VirtualHost domains that match hostname.
+func Domains(hostname string) []string {
+	domains := []string{hostname}
+	switch {
+	case hostname == "*":
+	case strings.HasPrefix(hostname, "*"):
+		// Envoy only supports a single wildcard per domain, so
+		// wildcard hostnames can't also match any port specifier.
+		// Match the default HTTP and HTTPS ports instead; a Host
+		// header with any other port is not matched.
+		domains = append(domains, hostname+":80", hostname+":443")
+	default:
+		// NOTE(jpeach) see also envoy.FilterMisdirectedRequests().
+		domains = append(domains, hostname+":*")
+	}
+	return domains
+}
+
 // RouteConfiguration returns a *v2.RouteConfiguration.
 func RouteConfiguration(name string, virtualhosts ...*envoy_api_v2_route.VirtualHost) *v2.RouteConfiguration {
 	return &v2.RouteConfiguration{
@@ -330,6 +502,48 @@ func headerMatcher(headers []dag.HeaderMatchCondition) []*envoy_api_v2_route.Hea
 	return envoyHeaders
 }
 
+func queryParamMatcher(params []dag.QueryParamMatchCondition) []*envoy_api_v2_route.QueryParameterMatcher {
+	var envoyParams []*envoy_api_v2_route.QueryParameterMatcher
+
+	for _, q := range params {
+		param := &envoy_api_v2_route.QueryParameterMatcher{
+			Name: q.Name,
+		}
+
+		switch q.MatchType {
+		case "exact":
+			param.QueryParameterMatchSpecifier = stringMatch(&matcher.StringMatcher{
+				MatchPattern: &matcher.StringMatcher_Exact{Exact: q.Value},
+			})
+		case "prefix":
+			param.QueryParameterMatchSpecifier = stringMatch(&matcher.StringMatcher{
+				MatchPattern: &matcher.StringMatcher_Prefix{Prefix: q.Value},
+			})
+		case "contains":
+			// See containsMatch for why the substring is wrapped in a regex.
+			param.QueryParameterMatchSpecifier = stringMatch(&matcher.StringMatcher{
+				MatchPattern: &matcher.StringMatcher_SafeRegex{
+					SafeRegex: SafeRegexMatch(fmt.Sprintf(".*%s.*", regexp.QuoteMeta(q.Value))),
+				},
+			})
+		case "regex":
+			param.QueryParameterMatchSpecifier = stringMatch(&matcher.StringMatcher{
+				MatchPattern: &matcher.StringMatcher_SafeRegex{
+					SafeRegex: SafeRegexMatch(q.Value),
+				},
+			})
+		case "present":
+			param.QueryParameterMatchSpecifier = &envoy_api_v2_route.QueryParameterMatcher_PresentMatch{PresentMatch: true}
+		}
+		envoyParams = append(envoyParams, param)
+	}
+	return envoyParams
+}
+
+func stringMatch(m *matcher.StringMatcher) *envoy_api_v2_route.QueryParameterMatcher_StringMatch {
+	return &envoy_api_v2_route.QueryParameterMatcher_StringMatch{StringMatch: m}
+}
+
 // containsMatch returns a HeaderMatchSpecifier which will match the
 // supplied substring
 func containsMatch(s string) *envoy_api_v2_route.HeaderMatcher_SafeRegexMatch {
@@ -14,12 +14,14 @@
 package envoy
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
 	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher"
 	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/projectcontour/contour/internal/assert"
 	"github.com/projectcontour/contour/internal/dag"
@@ -48,6 +50,14 @@ func TestRouteRoute(t *testing.T) {
 		},
 		LoadBalancerPolicy: "Cookie",
 	}
+	sessionTTL := time.Duration(0)
+	sessionAffinity := []dag.RequestHashPolicy{{
+		CookieHashOptions: &dag.CookieHashOptions{
+			Name: "X-Contour-Session-Affinity",
+			Path: "/",
+			TTL:  &sessionTTL,
+		},
+	}}
 
 	tests := map[string]struct {
 		route *dag.Route
@@ -65,6 +75,26 @@ func TestRouteRoute(t *testing.T) {
 				},
 			},
 		},
+		"regex rewrite": {
+			route: &dag.Route{
+				Clusters: []*dag.Cluster{c1},
+				RegexRewrite: &dag.RegexRewrite{
+					Pattern:      "^/v1/users/([0-9]+)/profile$",
+					Substitution: "/profile?id=\\1",
+				},
+			},
+			want: &envoy_api_v2_route.Route_Route{
+				Route: &envoy_api_v2_route.RouteAction{
+					ClusterSpecifier: &envoy_api_v2_route.RouteAction_Cluster{
+						Cluster: "default/kuard/8080/da39a3ee5e",
+					},
+					RegexRewrite: &matcher.RegexMatchAndSubstitute{
+						Pattern:      SafeRegexMatch("^/v1/users/([0-9]+)/profile$"),
+						Substitution: "/profile?id=\\1",
+					},
+				},
+			},
+		},
 		"websocket": {
 			route: &dag.Route{
 				Websocket: true,
@@ -351,7 +381,8 @@ func TestRouteRoute(t *testing.T) {
 		},
 		"single service w/ session affinity": {
 			route: &dag.Route{
-				Clusters: []*dag.Cluster{c2},
+				RequestHashPolicies: sessionAffinity,
+				Clusters:            []*dag.Cluster{c2},
 			},
 			want: &envoy_api_v2_route.Route_Route{
 				Route: &envoy_api_v2_route.RouteAction{
@@ -372,7 +403,8 @@ func TestRouteRoute(t *testing.T) {
 		},
 		"multiple service w/ session affinity": {
 			route: &dag.Route{
-				Clusters: []*dag.Cluster{c2, c2},
+				RequestHashPolicies: sessionAffinity,
+				Clusters:            []*dag.Cluster{c2, c2},
 			},
 			want: &envoy_api_v2_route.Route_Route{
 				Route: &envoy_api_v2_route.RouteAction{
@@ -402,7 +434,8 @@ func TestRouteRoute(t *testing.T) {
 		},
 		"mixed service w/ session affinity": {
 			route: &dag.Route{
-				Clusters: []*dag.Cluster{c2, c1},
+				RequestHashPolicies: sessionAffinity,
+				Clusters:            []*dag.Cluster{c2, c1},
 			},
 			want: &envoy_api_v2_route.Route_Route{
 				Route: &envoy_api_v2_route.RouteAction{
@@ -430,6 +463,60 @@ func TestRouteRoute(t *testing.T) {
 				},
 			},
 		},
+		"request hash policies": {
+			route: &dag.Route{
+				RequestHashPolicies: []dag.RequestHashPolicy{{
+					Terminal: true,
+					HeaderHashOptions: &dag.HeaderHashOptions{
+						HeaderName: "X-Tenant",
+					},
+				}, {
+					CookieHashOptions: &dag.CookieHashOptions{
+						Name: "JSESSIONID",
+					},
+				}, {
+					QueryParameterHashOptions: &dag.QueryParameterHashOptions{
+						ParameterName: "user",
+					},
+				}, {
+					HashSourceIP: true,
+				}},
+				Clusters: []*dag.Cluster{c1},
+			},
+			want: &envoy_api_v2_route.Route_Route{
+				Route: &envoy_api_v2_route.RouteAction{
+					ClusterSpecifier: &envoy_api_v2_route.RouteAction_Cluster{
+						Cluster: "default/kuard/8080/da39a3ee5e",
+					},
+					HashPolicy: []*envoy_api_v2_route.RouteAction_HashPolicy{{
+						Terminal: true,
+						PolicySpecifier: &envoy_api_v2_route.RouteAction_HashPolicy_Header_{
+							Header: &envoy_api_v2_route.RouteAction_HashPolicy_Header{
+								HeaderName: "X-Tenant",
+							},
+						},
+					}, {
+						PolicySpecifier: &envoy_api_v2_route.RouteAction_HashPolicy_Cookie_{
+							Cookie: &envoy_api_v2_route.RouteAction_HashPolicy_Cookie{
+								Name: "JSESSIONID",
+							},
+						},
+					}, {
+						PolicySpecifier: &envoy_api_v2_route.RouteAction_HashPolicy_QueryParameter_{
+							QueryParameter: &envoy_api_v2_route.RouteAction_HashPolicy_QueryParameter{
+								Name: "user",
+							},
+						},
+					}, {
+						PolicySpecifier: &envoy_api_v2_route.RouteAction_HashPolicy_ConnectionProperties_{
+							ConnectionProperties: &envoy_api_v2_route.RouteAction_HashPolicy_ConnectionProperties{
+								SourceIp: true,
+							},
+						},
+					}},
+				},
+			},
+		},
 		"host header rewrite": {
 			route: &dag.Route{
 				RequestHeadersPolicy: &dag.HeadersPolicy{
@@ -675,6 +762,14 @@ func TestVirtualHost(t *testing.T) {
 				Domains: []string{"www.example.com", "www.example.com:*"},
 			},
 		},
+		"wildcard hostname": {
+			hostname: "*.example.com",
+			port:     9999,
+			want: &envoy_api_v2_route.VirtualHost{
+				Name:    "*.example.com",
+				Domains: []string{"*.example.com", "*.example.com:80", "*.example.com:443"},
+			},
+		},
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -684,6 +779,47 @@ func TestVirtualHost(t *testing.T) {
 	}
 }
 
+func TestDomainsMatchHost(t *testing.T) {
+	// matches reports whether Envoy matches host to domain. Envoy
+	// supports a single leading or trailing wildcard per domain.
+	matches := func(domain, host string) bool {
+		switch {
+		case domain == "*":
+			return true
+		case strings.HasPrefix(domain, "*"):
+			return len(host) >= len(domain) && strings.HasSuffix(host, domain[1:])
+		case strings.HasSuffix(domain, "*"):
+			return len(host) >= len(domain) && strings.HasPrefix(host, domain[:len(domain)-1])
+		default:
+			return host == domain
+		}
+	}
+
+	tests := map[string]struct {
+		hostname string
+		host     string
+		want     bool
+	}{
+		"exact host":                  {hostname: "www.example.com", host: "www.example.com", want: true},
+		"exact host with port":        {hostname: "www.example.com", host: "www.example.com:8080", want: true},
+		"wildcard host":               {hostname: "*.example.com", host: "www.example.com", want: true},
+		"wildcard host with http":     {hostname: "*.example.com", host: "www.example.com:80", want: true},
+		"wildcard host with https":    {hostname: "*.example.com", host: "www.example.com:443", want: true},
+		"wildcard host with any port": {hostname: "*.example.com", host: "www.example.com:8080", want: false},
+		"wildcard other domain":       {hostname: "*.example.com", host: "www.example.org:80", want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got bool
+			for _, domain := range Domains(tc.hostname) {
+				got = got || matches(domain, tc.host)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
 func TestUpgradeHTTPS(t *testing.T) {
 	got := UpgradeHTTPS()
 	want := &envoy_api_v2_route.Route_Redirect{
@@ -697,6 +833,101 @@ func TestUpgradeHTTPS(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func TestRouteRedirect(t *testing.T) {
+	tests := map[string]struct {
+		redirect *dag.Redirect
+		want     *envoy_api_v2_route.Route_Redirect
+	}{
+		"hostname only": {
+			redirect: &dag.Redirect{
+				Hostname:   "example.com",
+				StatusCode: 302,
+			},
+			want: &envoy_api_v2_route.Route_Redirect{
+				Redirect: &envoy_api_v2_route.RedirectAction{
+					HostRedirect: "example.com",
+					ResponseCode: envoy_api_v2_route.RedirectAction_FOUND,
+				},
+			},
+		},
+		"scheme, port and path": {
+			redirect: &dag.Redirect{
+				Scheme:     "https",
+				Port:       8443,
+				Path:       "/new",
+				StatusCode: 301,
+			},
+			want: &envoy_api_v2_route.Route_Redirect{
+				Redirect: &envoy_api_v2_route.RedirectAction{
+					SchemeRewriteSpecifier: &envoy_api_v2_route.RedirectAction_SchemeRedirect{
+						SchemeRedirect: "https",
+					},
+					PortRedirect: 8443,
+					PathRewriteSpecifier: &envoy_api_v2_route.RedirectAction_PathRedirect{
+						PathRedirect: "/new",
+					},
+					ResponseCode: envoy_api_v2_route.RedirectAction_MOVED_PERMANENTLY,
+				},
+			},
+		},
+		"prefix": {
+			redirect: &dag.Redirect{
+				Prefix:     "/v2",
+				StatusCode: 308,
+			},
+			want: &envoy_api_v2_route.Route_Redirect{
+				Redirect: &envoy_api_v2_route.RedirectAction{
+					PathRewriteSpecifier: &envoy_api_v2_route.RedirectAction_PrefixRewrite{
+						PrefixRewrite: "/v2",
+					},
+					ResponseCode: envoy_api_v2_route.RedirectAction_PERMANENT_REDIRECT,
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, RouteRedirect(tc.redirect))
+		})
+	}
+}
+
+func TestRouteDirectResponse(t *testing.T) {
+	tests := map[string]struct {
+		response *dag.DirectResponse
+		want     *envoy_api_v2_route.Route_DirectResponse
+	}{
+		"no body": {
+			response: &dag.DirectResponse{StatusCode: 404},
+			want: &envoy_api_v2_route.Route_DirectResponse{
+				DirectResponse: &envoy_api_v2_route.DirectResponseAction{
+					Status: 404,
+				},
+			},
+		},
+		"body": {
+			response: &dag.DirectResponse{StatusCode: 503, Body: "maintenance"},
+			want: &envoy_api_v2_route.Route_DirectResponse{
+				DirectResponse: &envoy_api_v2_route.DirectResponseAction{
+					Status: 503,
+					Body: &envoy_api_v2_core.DataSource{
+						Specifier: &envoy_api_v2_core.DataSource_InlineString{
+							InlineString: "maintenance",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, RouteDirectResponse(tc.response))
+		})
+	}
+}
+
 func TestRouteMatch(t *testing.T) {
 	tests := map[string]struct {
 		route *dag.Route
@@ -786,6 +1017,88 @@ func TestRouteMatch(t *testing.T) {
 				},
 			},
 		},
+		"path exact": {
+			route: &dag.Route{
+				PathMatchCondition: &dag.ExactMatchCondition{
+					Path: "/foo",
+				},
+			},
+			want: &envoy_api_v2_route.RouteMatch{
+				PathSpecifier: &envoy_api_v2_route.RouteMatch_Path{
+					Path: "/foo",
+				},
+			},
+		},
+		"query parameters": {
+			route: &dag.Route{
+				PathMatchCondition: &dag.PrefixMatchCondition{
+					Prefix: "/",
+				},
+				QueryParamMatchConditions: []dag.QueryParamMatchCondition{{
+					Name:      "exact",
+					Value:     "1",
+					MatchType: "exact",
+				}, {
+					Name:      "prefix",
+					Value:     "v1.",
+					MatchType: "prefix",
+				}, {
+					Name:      "contains",
+					Value:     "1.2",
+					MatchType: "contains",
+				}, {
+					Name:      "regex",
+					Value:     "v[0-9]+",
+					MatchType: "regex",
+				}, {
+					Name:      "present",
+					MatchType: "present",
+				}},
+			},
+			want: &envoy_api_v2_route.RouteMatch{
+				PathSpecifier: &envoy_api_v2_route.RouteMatch_Prefix{
+					Prefix: "/",
+				},
+				QueryParameters: []*envoy_api_v2_route.QueryParameterMatcher{{
+					Name: "exact",
+					QueryParameterMatchSpecifier: &envoy_api_v2_route.QueryParameterMatcher_StringMatch{
+						StringMatch: &matcher.StringMatcher{
+							MatchPattern: &matcher.StringMatcher_Exact{Exact: "1"},
+						},
+					},
+				}, {
+					Name: "prefix",
+					QueryParameterMatchSpecifier: &envoy_api_v2_route.QueryParameterMatcher_StringMatch{
+						StringMatch: &matcher.StringMatcher{
+							MatchPattern: &matcher.StringMatcher_Prefix{Prefix: "v1."},
+						},
+					},
+				}, {
+					Name: "contains",
+					QueryParameterMatchSpecifier: &envoy_api_v2_route.QueryParameterMatcher_StringMatch{
+						StringMatch: &matcher.StringMatcher{
+							MatchPattern: &matcher.StringMatcher_SafeRegex{
+								SafeRegex: SafeRegexMatch(".*1\\.2.*"),
+							},
+						},
+					},
+				}, {
+					Name: "regex",
+					QueryParameterMatchSpecifier: &envoy_api_v2_route.QueryParameterMatcher_StringMatch{
+						StringMatch: &matcher.StringMatcher{
+							MatchPattern: &matcher.StringMatcher_SafeRegex{
+								SafeRegex: SafeRegexMatch("v[0-9]+"),
+							},
+						},
+					},
+				}, {
+					Name: "present",
+					QueryParameterMatchSpecifier: &envoy_api_v2_route.QueryParameterMatcher_PresentMatch{
+						PresentMatch: true,
+					},
+				}},
+			},
+		},
 	}
 
 	for name, tc := range tests {
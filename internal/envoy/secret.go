@@ -31,6 +31,31 @@ func Secretname(s *dag.Secret) string {
 	return hashname(60, ns, name, fmt.Sprintf("%x", hash[:5]))
 }
 
+// CRLSecretname returns the name of the SDS secret for the
+// certificate revocation lists of this secret. The name does
+// not depend on the CRL contents, so that an updated CRL is
+// delivered over SDS without changing the listener.
+func CRLSecretname(s *dag.Secret) string {
+	return hashname(60, s.Namespace(), s.Name(), "crl")
+}
+
+// CRLSecret creates a new envoy_api_v2_auth.Secret holding
+// the certificate revocation lists of secret.
+func CRLSecret(s *dag.Secret) *envoy_api_v2_auth.Secret {
+	return &envoy_api_v2_auth.Secret{
+		Name: CRLSecretname(s),
+		Type: &envoy_api_v2_auth.Secret_ValidationContext{
+			ValidationContext: &envoy_api_v2_auth.CertificateValidationContext{
+				Crl: &envoy_api_v2_core.DataSource{
+					Specifier: &envoy_api_v2_core.DataSource_InlineBytes{
+						InlineBytes: s.Object.Data[dag.CRLKey],
+					},
+				},
+			},
+		},
+	}
+}
+
 // Secret creates new envoy_api_v2_auth.Secret from secret.
 func Secret(s *dag.Secret) *envoy_api_v2_auth.Secret {
 	return &envoy_api_v2_auth.Secret{
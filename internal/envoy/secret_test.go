@@ -72,6 +72,37 @@ func TestSecret(t *testing.T) {
 	}
 }
 
+func TestCRLSecret(t *testing.T) {
+	secret := &dag.Secret{
+		Object: &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "crl",
+				Namespace: "default",
+			},
+			Data: map[string][]byte{
+				dag.CRLKey: []byte("crl"),
+			},
+		},
+	}
+
+	want := &envoy_api_v2_auth.Secret{
+		Name: "default/crl/crl",
+		Type: &envoy_api_v2_auth.Secret_ValidationContext{
+			ValidationContext: &envoy_api_v2_auth.CertificateValidationContext{
+				Crl: &envoy_api_v2_core.DataSource{
+					Specifier: &envoy_api_v2_core.DataSource_InlineBytes{
+						InlineBytes: []byte("crl"),
+					},
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, CRLSecret(secret)); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
 func TestSecretname(t *testing.T) {
 	tests := map[string]struct {
 		secret *dag.Secret
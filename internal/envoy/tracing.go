@@ -0,0 +1,146 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"math"
+
+	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	http "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	envoy_config_trace_v2 "github.com/envoyproxy/go-control-plane/envoy/config/trace/v2"
+	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type"
+	envoy_type_tracing_v2 "github.com/envoyproxy/go-control-plane/envoy/type/tracing/v2"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/proto"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/protobuf"
+)
+
+// OpenCensusTracerName is the name of Envoy's OpenCensus tracer.
+const OpenCensusTracerName = "envoy.tracers.opencensus"
+
+// DefaultZipkinCollectorEndpoint is the API path that spans are
+// sent to if the Zipkin collector endpoint is not configured.
+const DefaultZipkinCollectorEndpoint = "/api/v2/spans"
+
+// TracingConfig returns the HTTP connection manager tracing
+// configuration that sends spans to the given trace collector.
+func TracingConfig(t *dag.Tracing) *http.HttpConnectionManager_Tracing {
+	if t == nil {
+		return nil
+	}
+
+	return &http.HttpConnectionManager_Tracing{
+		RandomSampling: &envoy_type.Percent{Value: t.SamplingRate},
+		CustomTags:     customTags(t.CustomTags),
+		Provider:       tracingProvider(t),
+	}
+}
+
+func tracingProvider(t *dag.Tracing) *envoy_config_trace_v2.Tracing_Http {
+	var name string
+	var config proto.Message
+
+	switch t.Provider {
+	case "opentelemetry":
+		// Envoy has no native OpenTelemetry tracer in this API
+		// version, so spans are exported to the collector's
+		// OpenCensus receiver, propagating W3C trace context.
+		name = OpenCensusTracerName
+		config = &envoy_config_trace_v2.OpenCensusConfig{
+			OcagentExporterEnabled: true,
+			OcagentGrpcService: &envoy_api_v2_core.GrpcService{
+				TargetSpecifier: &envoy_api_v2_core.GrpcService_EnvoyGrpc_{
+					EnvoyGrpc: &envoy_api_v2_core.GrpcService_EnvoyGrpc{
+						ClusterName: Clustername(t.Cluster),
+					},
+				},
+			},
+			IncomingTraceContext: []envoy_config_trace_v2.OpenCensusConfig_TraceContext{
+				envoy_config_trace_v2.OpenCensusConfig_TRACE_CONTEXT,
+				envoy_config_trace_v2.OpenCensusConfig_B3,
+			},
+			OutgoingTraceContext: []envoy_config_trace_v2.OpenCensusConfig_TraceContext{
+				envoy_config_trace_v2.OpenCensusConfig_TRACE_CONTEXT,
+			},
+		}
+	default:
+		endpoint := t.CollectorEndpoint
+		if endpoint == "" {
+			endpoint = DefaultZipkinCollectorEndpoint
+		}
+
+		name = wellknown.Zipkin
+		config = &envoy_config_trace_v2.ZipkinConfig{
+			CollectorCluster:         Clustername(t.Cluster),
+			CollectorEndpoint:        endpoint,
+			CollectorEndpointVersion: envoy_config_trace_v2.ZipkinConfig_HTTP_JSON,
+			TraceId_128Bit:           true,
+		}
+	}
+
+	return &envoy_config_trace_v2.Tracing_Http{
+		Name: name,
+		ConfigType: &envoy_config_trace_v2.Tracing_Http_TypedConfig{
+			TypedConfig: protobuf.MustMarshalAny(config),
+		},
+	}
+}
+
+func customTags(tags []dag.TracingCustomTag) []*envoy_type_tracing_v2.CustomTag {
+	var customTags []*envoy_type_tracing_v2.CustomTag
+
+	for _, tag := range tags {
+		ct := &envoy_type_tracing_v2.CustomTag{
+			Tag: tag.TagName,
+		}
+
+		switch {
+		case tag.RequestHeader != "":
+			ct.Type = &envoy_type_tracing_v2.CustomTag_RequestHeader{
+				RequestHeader: &envoy_type_tracing_v2.CustomTag_Header{
+					Name: tag.RequestHeader,
+				},
+			}
+		default:
+			ct.Type = &envoy_type_tracing_v2.CustomTag_Literal_{
+				Literal: &envoy_type_tracing_v2.CustomTag_Literal{
+					Value: tag.Literal,
+				},
+			}
+		}
+
+		customTags = append(customTags, ct)
+	}
+
+	return customTags
+}
+
+// RouteTracing returns the route tracing configuration that
+// overrides the sampling rate of the HTTP connection manager.
+func RouteTracing(tp *dag.TracingPolicy) *envoy_api_v2_route.Tracing {
+	if tp == nil {
+		return nil
+	}
+
+	// Envoy applies sampling rates in millionths, so a
+	// percentage is scaled by 10,000.
+	return &envoy_api_v2_route.Tracing{
+		RandomSampling: &envoy_type.FractionalPercent{
+			Numerator:   uint32(math.Round(tp.SamplingRate * 10000)),
+			Denominator: envoy_type.FractionalPercent_MILLION,
+		},
+	}
+}
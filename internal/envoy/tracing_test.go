@@ -0,0 +1,142 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"testing"
+
+	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	http "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	envoy_config_trace_v2 "github.com/envoyproxy/go-control-plane/envoy/config/trace/v2"
+	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type"
+	envoy_type_tracing_v2 "github.com/envoyproxy/go-control-plane/envoy/type/tracing/v2"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/projectcontour/contour/internal/assert"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/protobuf"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestTracingConfig(t *testing.T) {
+	collector := &dag.Cluster{
+		Upstream: &dag.Service{
+			Name:        "collector",
+			Namespace:   "tracing",
+			ServicePort: v1.ServicePort{Port: 9411},
+		},
+	}
+
+	tests := map[string]struct {
+		tracing *dag.Tracing
+		want    *http.HttpConnectionManager_Tracing
+	}{
+		"nil": {
+			tracing: nil,
+			want:    nil,
+		},
+		"zipkin": {
+			tracing: &dag.Tracing{
+				Provider:     "zipkin",
+				Cluster:      collector,
+				SamplingRate: 50,
+				CustomTags: []dag.TracingCustomTag{{
+					TagName: "cluster",
+					Literal: "prod",
+				}, {
+					TagName:       "tenant",
+					RequestHeader: "x-tenant",
+				}},
+			},
+			want: &http.HttpConnectionManager_Tracing{
+				RandomSampling: &envoy_type.Percent{Value: 50},
+				CustomTags: []*envoy_type_tracing_v2.CustomTag{{
+					Tag: "cluster",
+					Type: &envoy_type_tracing_v2.CustomTag_Literal_{
+						Literal: &envoy_type_tracing_v2.CustomTag_Literal{
+							Value: "prod",
+						},
+					},
+				}, {
+					Tag: "tenant",
+					Type: &envoy_type_tracing_v2.CustomTag_RequestHeader{
+						RequestHeader: &envoy_type_tracing_v2.CustomTag_Header{
+							Name: "x-tenant",
+						},
+					},
+				}},
+				Provider: &envoy_config_trace_v2.Tracing_Http{
+					Name: wellknown.Zipkin,
+					ConfigType: &envoy_config_trace_v2.Tracing_Http_TypedConfig{
+						TypedConfig: protobuf.MustMarshalAny(&envoy_config_trace_v2.ZipkinConfig{
+							CollectorCluster:         "tracing/collector/9411/da39a3ee5e",
+							CollectorEndpoint:        "/api/v2/spans",
+							CollectorEndpointVersion: envoy_config_trace_v2.ZipkinConfig_HTTP_JSON,
+							TraceId_128Bit:           true,
+						}),
+					},
+				},
+			},
+		},
+		"opentelemetry": {
+			tracing: &dag.Tracing{
+				Provider:     "opentelemetry",
+				Cluster:      collector,
+				SamplingRate: 100,
+			},
+			want: &http.HttpConnectionManager_Tracing{
+				RandomSampling: &envoy_type.Percent{Value: 100},
+				Provider: &envoy_config_trace_v2.Tracing_Http{
+					Name: OpenCensusTracerName,
+					ConfigType: &envoy_config_trace_v2.Tracing_Http_TypedConfig{
+						TypedConfig: protobuf.MustMarshalAny(&envoy_config_trace_v2.OpenCensusConfig{
+							OcagentExporterEnabled: true,
+							OcagentGrpcService: &envoy_api_v2_core.GrpcService{
+								TargetSpecifier: &envoy_api_v2_core.GrpcService_EnvoyGrpc_{
+									EnvoyGrpc: &envoy_api_v2_core.GrpcService_EnvoyGrpc{
+										ClusterName: "tracing/collector/9411/da39a3ee5e",
+									},
+								},
+							},
+							IncomingTraceContext: []envoy_config_trace_v2.OpenCensusConfig_TraceContext{
+								envoy_config_trace_v2.OpenCensusConfig_TRACE_CONTEXT,
+								envoy_config_trace_v2.OpenCensusConfig_B3,
+							},
+							OutgoingTraceContext: []envoy_config_trace_v2.OpenCensusConfig_TraceContext{
+								envoy_config_trace_v2.OpenCensusConfig_TRACE_CONTEXT,
+							},
+						}),
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, TracingConfig(tc.tracing))
+		})
+	}
+}
+
+func TestRouteTracing(t *testing.T) {
+	assert.Equal(t, (*envoy_api_v2_route.Tracing)(nil), RouteTracing(nil))
+
+	assert.Equal(t, &envoy_api_v2_route.Tracing{
+		RandomSampling: &envoy_type.FractionalPercent{
+			Numerator:   5000,
+			Denominator: envoy_type.FractionalPercent_MILLION,
+		},
+	}, RouteTracing(&dag.TracingPolicy{SamplingRate: 0.5}))
+}
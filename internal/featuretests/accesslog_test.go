@@ -0,0 +1,157 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuretests
+
+import (
+	"testing"
+
+	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	envoy_api_v2_listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/envoy"
+	"github.com/projectcontour/contour/internal/fixture"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestGRPCAccessLogService(t *testing.T) {
+	rh, c, done := setup(t,
+		func(conf *contour.ListenerConfig) {
+			conf.AccessLogType = "grpc"
+		},
+		func(eh *contour.EventHandler) {
+			eh.Builder.AccessLogService = &dag.AccessLogServiceConfig{
+				ExtensionService: types.NamespacedName{
+					Namespace: "projectcontour",
+					Name:      "als",
+				},
+				LogName: "contour",
+			}
+		})
+	defer done()
+
+	rh.OnAdd(fixture.NewService("backend").
+		WithPorts(v1.ServicePort{Port: 80, TargetPort: intstr.FromInt(8080)}))
+	rh.OnAdd(fixture.NewService("projectcontour/als").
+		WithPorts(v1.ServicePort{Port: 9001, TargetPort: intstr.FromInt(9001)}))
+
+	rh.OnAdd(&v1alpha1.ExtensionService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "als",
+			Namespace: "projectcontour",
+		},
+		Spec: v1alpha1.ExtensionServiceSpec{
+			Services: []projcontour.Service{{
+				Name: "als",
+				Port: 9001,
+			}},
+		},
+	})
+
+	rh.OnAdd(fixture.NewProxy("http").
+		WithSpec(projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn: "www.example.com",
+			},
+			Routes: []projcontour.Route{{
+				Services: []projcontour.Service{{
+					Name: "backend",
+					Port: 80,
+				}},
+			}},
+		}))
+
+	rh.OnAdd(fixture.NewProxy("tcp").
+		WithSpec(projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn: "tcp.example.com",
+				TLS: &projcontour.TLS{
+					Passthrough: true,
+				},
+			},
+			TCPProxy: &projcontour.TCPProxy{
+				Services: []projcontour.Service{{
+					Name: "backend",
+					Port: 80,
+				}},
+			},
+		}))
+
+	als := &dag.AccessLogService{
+		ExtensionCluster: &dag.ExtensionCluster{Name: "extension/projectcontour/als"},
+		LogName:          "contour",
+	}
+
+	backend := &dag.TCPProxy{
+		Clusters: []*dag.Cluster{{
+			Upstream: &dag.Service{
+				Name:        "backend",
+				Namespace:   "default",
+				ServicePort: v1.ServicePort{Port: 80},
+			},
+		}},
+	}
+
+	c.Request(listenerType).Equals(&v2.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			&v2.Listener{
+				Name:          contour.ENVOY_HTTP_LISTENER,
+				Address:       envoy.SocketAddress("0.0.0.0", 8080),
+				SocketOptions: envoy.TCPKeepaliveSocketOptions(),
+				FilterChains: envoy.FilterChains(envoy.HTTPConnectionManagerBuilder().
+					DefaultFilters().
+					RouteConfigName(contour.ENVOY_HTTP_LISTENER).
+					MetricsPrefix(contour.ENVOY_HTTP_LISTENER).
+					AccessLoggers(envoy.GRPCAccessLogHTTP(als)).
+					Get(),
+				),
+			},
+			&v2.Listener{
+				Name:    contour.ENVOY_HTTPS_LISTENER,
+				Address: envoy.SocketAddress("0.0.0.0", 8443),
+				FilterChains: []*envoy_api_v2_listener.FilterChain{{
+					Filters: envoy.Filters(
+						envoy.TCPProxy(contour.ENVOY_HTTPS_LISTENER, backend, envoy.GRPCAccessLogTCP(als)),
+					),
+					FilterChainMatch: &envoy_api_v2_listener.FilterChainMatch{
+						ServerNames: []string{"tcp.example.com"},
+					},
+				}},
+				ListenerFilters: envoy.ListenerFilters(
+					envoy.TLSInspector(),
+				),
+				SocketOptions: envoy.TCPKeepaliveSocketOptions(),
+			},
+			staticListener(),
+		),
+	})
+
+	// The access log service cluster is programmed even
+	// though nothing routes to it.
+	c.Request(clusterType, "extension/projectcontour/als").Equals(&v2.DiscoveryResponse{
+		TypeUrl: clusterType,
+		Resources: resources(t,
+			envoy.ExtensionCluster(&dag.ExtensionCluster{
+				Name:     "extension/projectcontour/als",
+				Protocol: "h2c",
+			}),
+		),
+	})
+}
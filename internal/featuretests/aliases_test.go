@@ -0,0 +1,196 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuretests
+
+import (
+	"testing"
+	"time"
+
+	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	envoy_api_v2_auth "github.com/envoyproxy/go-control-plane/envoy/api/v2/auth"
+	envoy_api_v2_listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/envoy"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHTTPProxyAliases(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	// sec1 is valid for example.com and www.example.com.
+	sec1 := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret",
+			Namespace: "default",
+		},
+		Type: "kubernetes.io/tls",
+		Data: secretdata(EC_CERTIFICATE, EC_PRIVATE_KEY),
+	}
+	rh.OnAdd(sec1)
+
+	rh.OnAdd(fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Name: "http", Port: 80}))
+
+	proxy := func(aliases ...string) *projcontour.HTTPProxy {
+		return fixture.NewProxy("simple").WithSpec(projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn:    "example.com",
+				Aliases: aliases,
+				TLS: &projcontour.TLS{
+					SecretName: sec1.Name,
+				},
+			},
+			Routes: []projcontour.Route{{
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 80,
+				}},
+			}},
+		})
+	}
+
+	withAliases := func(vh *envoy_api_v2_route.VirtualHost, aliases ...string) *envoy_api_v2_route.VirtualHost {
+		for _, alias := range aliases {
+			vh.Domains = append(vh.Domains, envoy.Domains(alias)...)
+		}
+		return vh
+	}
+
+	p1 := proxy("www.example.com")
+	rh.OnAdd(p1)
+
+	c.Request(routeType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			envoy.RouteConfiguration("https/example.com",
+				withAliases(envoy.VirtualHost("example.com",
+					&envoy_api_v2_route.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/kuard/80/da39a3ee5e"),
+					},
+				), "www.example.com"),
+			),
+			envoy.RouteConfiguration("ingress_http",
+				withAliases(envoy.VirtualHost("example.com",
+					&envoy_api_v2_route.Route{
+						Match:  routePrefix("/"),
+						Action: envoy.UpgradeHTTPS(),
+					},
+				), "www.example.com"),
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(p1).Like(
+		projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid},
+	)
+
+	c.Request(listenerType, "ingress_https").Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			&v2.Listener{
+				Name:    "ingress_https",
+				Address: envoy.SocketAddress("0.0.0.0", 8443),
+				ListenerFilters: envoy.ListenerFilters(
+					envoy.TLSInspector(),
+				),
+				FilterChains: []*envoy_api_v2_listener.FilterChain{
+					envoy.FilterChainTLS("example.com",
+						envoy.DownstreamTLSContext(
+							&dag.Secret{Object: sec1},
+							envoy_api_v2_auth.TlsParameters_TLSv1_1,
+							envoy_api_v2_auth.TlsParameters_TLSv1_3,
+							nil,
+							nil,
+							nil,
+							"h2", "http/1.1"),
+						envoy.Filters(httpsFilterFor("example.com", "www.example.com")),
+						"www.example.com",
+					),
+				},
+				SocketOptions: envoy.TCPKeepaliveSocketOptions(),
+			},
+		),
+		TypeUrl: listenerType,
+	})
+
+	// p2 has an alias that the certificate does not cover.
+	p2 := proxy("www.example.com", "api.example.com")
+	rh.OnUpdate(p1, p2)
+
+	c.Request(listenerType, "ingress_https").Equals(&v2.DiscoveryResponse{
+		Resources: nil,
+		TypeUrl:   listenerType,
+	}).Status(p2).Like(
+		projcontour.HTTPProxyStatus{
+			CurrentStatus: k8s.StatusInvalid,
+			Description:   `Spec.VirtualHost.TLS Secret "secret" is invalid: certificate is not valid for "api.example.com"`,
+		},
+	)
+
+	// p3 uses www.example.com as its fqdn, which conflicts
+	// with the alias of the older p1.
+	rh.OnUpdate(p2, p1)
+	p3 := fixture.NewProxy("other").WithSpec(projcontour.HTTPProxySpec{
+		VirtualHost: &projcontour.VirtualHost{
+			Fqdn: "www.example.com",
+		},
+		Routes: []projcontour.Route{{
+			Services: []projcontour.Service{{
+				Name: "kuard",
+				Port: 80,
+			}},
+		}},
+	})
+	p3.CreationTimestamp = metav1.NewTime(p1.CreationTimestamp.Add(time.Minute))
+	rh.OnAdd(p3)
+
+	c.Request(listenerType, "ingress_https").Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			&v2.Listener{
+				Name:    "ingress_https",
+				Address: envoy.SocketAddress("0.0.0.0", 8443),
+				ListenerFilters: envoy.ListenerFilters(
+					envoy.TLSInspector(),
+				),
+				FilterChains: []*envoy_api_v2_listener.FilterChain{
+					envoy.FilterChainTLS("example.com",
+						envoy.DownstreamTLSContext(
+							&dag.Secret{Object: sec1},
+							envoy_api_v2_auth.TlsParameters_TLSv1_1,
+							envoy_api_v2_auth.TlsParameters_TLSv1_3,
+							nil,
+							nil,
+							nil,
+							"h2", "http/1.1"),
+						envoy.Filters(httpsFilterFor("example.com", "www.example.com")),
+						"www.example.com",
+					),
+				},
+				SocketOptions: envoy.TCPKeepaliveSocketOptions(),
+			},
+		),
+		TypeUrl: listenerType,
+	}).Status(p1).Like(
+		projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid},
+	).Status(p3).Like(
+		projcontour.HTTPProxyStatus{
+			CurrentStatus: k8s.StatusInvalid,
+			Description:   `fqdn "www.example.com" is already used by HTTPProxy default/simple`,
+		},
+	)
+}
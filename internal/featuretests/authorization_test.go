@@ -0,0 +1,264 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuretests
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	envoy_api_v2_endpoint "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
+	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/ptypes/any"
+	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/envoy"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
+	"github.com/projectcontour/contour/internal/protobuf"
+	"github.com/projectcontour/contour/internal/timeout"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestAuthorization(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	sec := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret",
+			Namespace: "default",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: secretdata(CERTIFICATE, RSA_PRIVATE_KEY),
+	}
+	rh.OnAdd(sec)
+
+	rh.OnAdd(fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+	rh.OnAdd(fixture.NewService("auth").
+		WithPorts(v1.ServicePort{Name: "grpc", Port: 9443, TargetPort: intstr.FromInt(9443)}))
+
+	rh.OnAdd(&v1alpha1.ExtensionService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "auth",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.ExtensionServiceSpec{
+			Services: []projcontour.Service{{
+				Name: "auth",
+				Port: 9443,
+			}},
+		},
+	})
+
+	proxy := fixture.NewProxy("proxy").
+		WithSpec(projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &projcontour.TLS{
+					SecretName: sec.Name,
+				},
+				Authorization: &projcontour.AuthorizationServer{
+					ExtensionServiceRef: projcontour.ExtensionServiceReference{
+						Name: "auth",
+					},
+					ResponseTimeout: "10s",
+					AuthPolicy: &projcontour.AuthorizationPolicy{
+						Context: map[string]string{
+							"scope": "vhost",
+						},
+					},
+				},
+			},
+			Routes: []projcontour.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/public")),
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+				AuthPolicy: &projcontour.AuthorizationPolicy{
+					Disabled: true,
+				},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/admin")),
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+				AuthPolicy: &projcontour.AuthorizationPolicy{
+					Context: map[string]string{
+						"scope": "admin",
+						"route": "admin",
+					},
+				},
+			}},
+		})
+	rh.OnAdd(proxy)
+
+	c.Status(proxy).Like(projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid})
+
+	httpsFilter := envoy.HTTPConnectionManagerBuilder().
+		AddFilter(envoy.FilterMisdirectedRequests("example.com")).
+		AddFilter(envoy.FilterExternalAuthz("extension/default/auth", false, timeout.DurationSetting(10*time.Second))).
+		DefaultFilters().
+		RouteConfigName(path.Join("https", "example.com")).
+		MetricsPrefix(contour.ENVOY_HTTPS_LISTENER).
+		AccessLoggers(envoy.FileAccessLogEnvoy("/dev/stdout")).
+		Get()
+
+	c.Request(listenerType, "ingress_https").Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			&v2.Listener{
+				Name:    "ingress_https",
+				Address: envoy.SocketAddress("0.0.0.0", 8443),
+				ListenerFilters: envoy.ListenerFilters(
+					envoy.TLSInspector(),
+				),
+				FilterChains: appendFilterChains(
+					filterchaintls("example.com", sec, httpsFilter, nil, "h2", "http/1.1"),
+				),
+				SocketOptions: envoy.TCPKeepaliveSocketOptions(),
+			},
+		),
+		TypeUrl: listenerType,
+	})
+
+	authzContext := func(settings map[string]string) map[string]*any.Any {
+		return map[string]*any.Any{
+			wellknown.HTTPExternalAuthorization: envoy.RouteAuthzContext(settings),
+		}
+	}
+
+	c.Request(routeType, "https/example.com").Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			envoy.RouteConfiguration("https/example.com",
+				envoy.VirtualHost("example.com",
+					&envoy_api_v2_route.Route{
+						Match:                routePrefix("/public"),
+						Action:               routeCluster("default/kuard/8080/da39a3ee5e"),
+						TypedPerFilterConfig: map[string]*any.Any{wellknown.HTTPExternalAuthorization: envoy.RouteAuthzDisabled()},
+					},
+					&envoy_api_v2_route.Route{
+						Match:                routePrefix("/admin"),
+						Action:               routeCluster("default/kuard/8080/da39a3ee5e"),
+						TypedPerFilterConfig: authzContext(map[string]string{"scope": "admin", "route": "admin"}),
+					},
+					&envoy_api_v2_route.Route{
+						Match:                routePrefix("/"),
+						Action:               routeCluster("default/kuard/8080/da39a3ee5e"),
+						TypedPerFilterConfig: authzContext(map[string]string{"scope": "vhost"}),
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	})
+
+	c.Request(clusterType, "extension/default/auth").Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			&v2.Cluster{
+				Name:                 "extension/default/auth",
+				ClusterDiscoveryType: envoy.ClusterDiscoveryType(v2.Cluster_EDS),
+				EdsClusterConfig: &v2.Cluster_EdsClusterConfig{
+					EdsConfig:   envoy.ConfigSource("contour"),
+					ServiceName: "extension/default/auth",
+				},
+				ConnectTimeout: protobuf.Duration(250 * time.Millisecond),
+				LbPolicy:       v2.Cluster_ROUND_ROBIN,
+				CommonLbConfig: &v2.Cluster_CommonLbConfig{
+					HealthyPanicThreshold: &envoy_type.Percent{},
+					LocalityConfigSpecifier: &v2.Cluster_CommonLbConfig_LocalityWeightedLbConfig_{
+						LocalityWeightedLbConfig: &v2.Cluster_CommonLbConfig_LocalityWeightedLbConfig{},
+					},
+				},
+				Http2ProtocolOptions: &envoy_api_v2_core.Http2ProtocolOptions{},
+			},
+		),
+		TypeUrl: clusterType,
+	})
+
+	// The endpoints of the authorization service are
+	// aggregated into the extension cluster.
+	rh.OnAdd(endpoints("default", "auth", v1.EndpointSubset{
+		Addresses: addresses("10.0.0.1", "10.0.0.2"),
+		Ports:     ports(port("grpc", 9443)),
+	}))
+
+	c.Request(endpointType, "extension/default/auth").Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			&v2.ClusterLoadAssignment{
+				ClusterName: "extension/default/auth",
+				Endpoints: []*envoy_api_v2_endpoint.LocalityLbEndpoints{{
+					Locality: &envoy_api_v2_core.Locality{
+						SubZone: "default/auth/grpc",
+					},
+					LbEndpoints: []*envoy_api_v2_endpoint.LbEndpoint{
+						envoy.LBEndpoint(envoy.SocketAddress("10.0.0.1", 9443)),
+						envoy.LBEndpoint(envoy.SocketAddress("10.0.0.2", 9443)),
+					},
+					LoadBalancingWeight: protobuf.UInt32(1),
+				}},
+			},
+		),
+		TypeUrl: endpointType,
+	})
+
+	// Routes that permit insecure requests must disable authorization.
+	insecure := proxy.DeepCopy()
+	insecure.Spec.Routes[0].PermitInsecure = true
+	rh.OnUpdate(proxy, insecure)
+
+	c.Status(insecure).Like(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   `Spec.VirtualHost.Authorization: route "prefix: /" permits insecure requests but does not disable authorization`,
+	})
+
+	// Authorization requires TLS.
+	cleartext := proxy.DeepCopy()
+	cleartext.Spec.VirtualHost.TLS = nil
+	rh.OnUpdate(insecure, cleartext)
+
+	c.Status(cleartext).Like(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   "Spec.VirtualHost.Authorization requires that Spec.VirtualHost.TLS.SecretName be set",
+	})
+
+	// A missing extension service makes the proxy invalid.
+	missing := proxy.DeepCopy()
+	missing.Spec.VirtualHost.Authorization.ExtensionServiceRef.Name = "missing"
+	rh.OnUpdate(cleartext, missing)
+
+	c.Status(missing).Like(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   `Spec.VirtualHost.Authorization.ExtensionServiceRef is invalid: extension service "default/missing" not found`,
+	})
+
+	c.Request(clusterType, "extension/default/auth").Equals(&v2.DiscoveryResponse{
+		TypeUrl: clusterType,
+	})
+}
@@ -204,4 +204,54 @@ func TestClusterServiceTLSBackendCAValidation(t *testing.T) {
 		TypeUrl:   secretType,
 	})
 
+	hp2 := &projcontour.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "simple",
+			Namespace: svc.Namespace,
+		},
+		Spec: projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{Fqdn: "www.example.com"},
+			Routes: []projcontour.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/a")),
+				Services: []projcontour.Service{{
+					Name: svc.Name,
+					Port: 443,
+					UpstreamValidation: &projcontour.UpstreamValidation{
+						CACertificate: secret.Name,
+						SubjectAltNames: []projcontour.SubjectAltNameMatch{{
+							Type:  "URI",
+							Exact: "spiffe://cluster/ns/default/sa/kuard",
+						}, {
+							Suffix: ".default.svc.cluster.local",
+						}},
+					},
+				}},
+			}},
+		},
+	}
+	rh.OnUpdate(hp1, hp2)
+
+	// assert that the cluster matches the subject alternative names.
+	sanCluster := cluster("default/kuard/443/5e787e3366", "default/kuard/securebackend", "default_kuard_443")
+	sanCluster.TransportSocket = envoy.UpstreamTLSTransportSocket(
+		envoy.UpstreamTLSContext(
+			&dag.PeerValidationContext{
+				CACertificate: &dag.Secret{Object: secret},
+				SubjectAltNames: []dag.SubjectAltNameMatch{{
+					MatchType: "exact",
+					Value:     "spiffe://cluster/ns/default/sa/kuard",
+				}, {
+					MatchType: "suffix",
+					Value:     ".default.svc.cluster.local",
+				}},
+			},
+			"",
+			nil,
+		),
+	)
+
+	c.Request(clusterType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t, sanCluster),
+		TypeUrl:   clusterType,
+	})
 }
@@ -0,0 +1,247 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuretests
+
+import (
+	"testing"
+
+	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/envoy"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func clientCertCluster(c *v2.Cluster, clientSecret *v1.Secret) *v2.Cluster {
+	c.TransportSocket = envoy.UpstreamTLSTransportSocket(
+		envoy.UpstreamTLSContext(nil, "", &dag.Secret{Object: clientSecret}),
+	)
+	return c
+}
+
+func TestClusterServiceTLSBackendClientCertificate(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	clientSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "envoy-client",
+			Namespace: "default",
+		},
+		Type: "kubernetes.io/tls",
+		Data: secretdata(CERTIFICATE, RSA_PRIVATE_KEY),
+	}
+
+	svc := fixture.NewService("default/kuard").
+		Annotate("projectcontour.io/upstream-protocol.tls", "securebackend").
+		WithPorts(v1.ServicePort{Name: "securebackend", Port: 443, TargetPort: intstr.FromInt(8080)})
+
+	p1 := fixture.NewProxy("simple").WithSpec(projcontour.HTTPProxySpec{
+		VirtualHost: &projcontour.VirtualHost{Fqdn: "www.example.com"},
+		Routes: []projcontour.Route{{
+			Services: []projcontour.Service{{
+				Name:              svc.Name,
+				Port:              443,
+				ClientCertificate: clientSecret.Name,
+			}},
+		}},
+	})
+
+	rh.OnAdd(clientSecret)
+	rh.OnAdd(svc)
+	rh.OnAdd(p1)
+
+	// assert that the cluster presents the client certificate.
+	c.Request(clusterType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			clientCertCluster(cluster("default/kuard/443/df3a6b2329", "default/kuard/securebackend", "default_kuard_443"), clientSecret),
+		),
+		TypeUrl: clusterType,
+	}).Status(p1).Like(
+		projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid},
+	)
+
+	// assert that the client certificate is delivered over SDS.
+	c.Request(secretType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t, secret(clientSecret)),
+		TypeUrl:   secretType,
+	})
+
+	// A client certificate in another namespace needs a delegation.
+	adminSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "envoy-client",
+			Namespace: "admin",
+		},
+		Type: "kubernetes.io/tls",
+		Data: secretdata(CERTIFICATE, RSA_PRIVATE_KEY),
+	}
+	rh.OnAdd(adminSecret)
+
+	p1 = update(rh, p1, func(p *projcontour.HTTPProxy) {
+		p.Spec.Routes[0].Services[0].ClientCertificate = "admin/envoy-client"
+	})
+
+	c.Request(clusterType).Equals(&v2.DiscoveryResponse{
+		Resources: nil,
+		TypeUrl:   clusterType,
+	}).Status(p1).Equals(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   `Service [kuard:443] TLS client certificate error: Secret "admin/envoy-client" certificate delegation not permitted`,
+		Conditions:    invalidCondition(`Service [kuard:443] TLS client certificate error: Secret "admin/envoy-client" certificate delegation not permitted`),
+	})
+
+	rh.OnAdd(&projcontour.TLSCertificateDelegation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "delegation",
+			Namespace: "admin",
+		},
+		Spec: projcontour.TLSCertificateDelegationSpec{
+			Delegations: []projcontour.CertificateDelegation{{
+				SecretName:       "envoy-client",
+				TargetNamespaces: []string{"default"},
+			}},
+		},
+	})
+
+	c.Request(clusterType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			clientCertCluster(cluster("default/kuard/443/4532fc6e93", "default/kuard/securebackend", "default_kuard_443"), adminSecret),
+		),
+		TypeUrl: clusterType,
+	}).Status(p1).Like(
+		projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid},
+	)
+}
+
+func TestClusterServiceTLSBackendDefaultClientCertificate(t *testing.T) {
+	rh, c, done := setup(t, func(eh *contour.EventHandler) {
+		eh.Builder.ClientCertificate = &types.NamespacedName{
+			Name:      "envoy-client",
+			Namespace: "default",
+		}
+	})
+	defer done()
+
+	clientSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "envoy-client",
+			Namespace: "default",
+		},
+		Type: "kubernetes.io/tls",
+		Data: secretdata(CERTIFICATE, RSA_PRIVATE_KEY),
+	}
+
+	svc := fixture.NewService("default/kuard").
+		Annotate("projectcontour.io/upstream-protocol.tls", "securebackend").
+		WithPorts(
+			v1.ServicePort{Name: "securebackend", Port: 443, TargetPort: intstr.FromInt(8080)},
+			v1.ServicePort{Name: "http", Port: 80, TargetPort: intstr.FromInt(8080)})
+
+	p1 := fixture.NewProxy("simple").WithSpec(projcontour.HTTPProxySpec{
+		VirtualHost: &projcontour.VirtualHost{Fqdn: "www.example.com"},
+		Routes: []projcontour.Route{{
+			Conditions: matchconditions(prefixMatchCondition("/secure")),
+			Services: []projcontour.Service{{
+				Name: svc.Name,
+				Port: 443,
+			}},
+		}, {
+			Services: []projcontour.Service{{
+				Name: svc.Name,
+				Port: 80,
+			}},
+		}},
+	})
+
+	rh.OnAdd(clientSecret)
+	rh.OnAdd(svc)
+	rh.OnAdd(p1)
+
+	// assert that only the TLS cluster presents the default client certificate.
+	c.Request(clusterType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			clientCertCluster(cluster("default/kuard/443/df3a6b2329", "default/kuard/securebackend", "default_kuard_443"), clientSecret),
+			cluster("default/kuard/80/da39a3ee5e", "default/kuard/http", "default_kuard_80"),
+		),
+		TypeUrl: clusterType,
+	})
+}
+
+func TestClusterServiceTLSBackendDefaultClientCertificateInOtherNamespace(t *testing.T) {
+	rh, c, done := setup(t, func(eh *contour.EventHandler) {
+		eh.Builder.ClientCertificate = &types.NamespacedName{
+			Name:      "envoy-client",
+			Namespace: "projectcontour",
+		}
+		eh.Builder.Source.ConfiguredSecrets = []types.NamespacedName{*eh.Builder.ClientCertificate}
+	})
+	defer done()
+
+	clientSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "envoy-client",
+			Namespace: "projectcontour",
+		},
+		Type: "kubernetes.io/tls",
+		Data: secretdata(CERTIFICATE, RSA_PRIVATE_KEY),
+	}
+
+	svc := fixture.NewService("default/kuard").
+		Annotate("projectcontour.io/upstream-protocol.tls", "securebackend").
+		WithPorts(v1.ServicePort{Name: "securebackend", Port: 443, TargetPort: intstr.FromInt(8080)})
+
+	p1 := fixture.NewProxy("simple").WithSpec(projcontour.HTTPProxySpec{
+		VirtualHost: &projcontour.VirtualHost{Fqdn: "www.example.com"},
+		Routes: []projcontour.Route{{
+			Services: []projcontour.Service{{
+				Name: svc.Name,
+				Port: 443,
+			}},
+		}},
+	})
+
+	rh.OnAdd(svc)
+	rh.OnAdd(p1)
+
+	// the default client certificate is missing, so the cluster
+	// presents no client certificate, but the proxy is still valid.
+	c.Request(clusterType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			tlsCluster(cluster("default/kuard/443/da39a3ee5e", "default/kuard/securebackend", "default_kuard_443"), nil, "", ""),
+		),
+		TypeUrl: clusterType,
+	}).Status(p1).Like(
+		projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid},
+	)
+
+	rh.OnAdd(clientSecret)
+
+	// the default client certificate is not subject to certificate
+	// delegation, so it is presented to services in any namespace.
+	c.Request(clusterType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			clientCertCluster(cluster("default/kuard/443/be964c8327", "default/kuard/securebackend", "default_kuard_443"), clientSecret),
+		),
+		TypeUrl: clusterType,
+	}).Status(p1).Like(
+		projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid},
+	)
+}
@@ -475,6 +475,83 @@ func TestClusterCircuitbreakerAnnotations(t *testing.T) {
 	})
 }
 
+// Routes to the same Service can have different circuit breaker
+// thresholds, which are merged with the Service annotations.
+func TestClusterCircuitBreakerPolicyPerRoute(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	s1 := fixture.NewService("kuard").
+		Annotate("projectcontour.io/max-connections", "9000").
+		Annotate("projectcontour.io/max-retries", "7").
+		WithPorts(v1.ServicePort{Port: 80, TargetPort: intstr.FromString("8080")})
+	rh.OnAdd(s1)
+
+	proxy := fixture.NewProxy("kuard").
+		WithFQDN("www.example.com").
+		WithSpec(projcontour.HTTPProxySpec{
+			Routes: []projcontour.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/a")),
+				Services: []projcontour.Service{{
+					Name: s1.Name,
+					Port: 80,
+				}},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/b")),
+				Services: []projcontour.Service{{
+					Name: s1.Name,
+					Port: 80,
+					CircuitBreakerPolicy: &projcontour.CircuitBreakerPolicy{
+						MaxRequests: 10,
+						MaxRetries:  1,
+					},
+					ConnectionPoolPolicy: &projcontour.ConnectionPoolPolicy{
+						MaxRequestsPerConnection: 100,
+					},
+				}},
+			}},
+		})
+	rh.OnAdd(proxy)
+
+	c.Request(clusterType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			DefaultCluster(&v2.Cluster{
+				Name:                 "default/kuard/80/94b146221d",
+				AltStatName:          "default_kuard_80",
+				ClusterDiscoveryType: envoy.ClusterDiscoveryType(v2.Cluster_EDS),
+				EdsClusterConfig: &v2.Cluster_EdsClusterConfig{
+					EdsConfig:   envoy.ConfigSource("contour"),
+					ServiceName: "default/kuard",
+				},
+				CircuitBreakers: &envoy_cluster.CircuitBreakers{
+					Thresholds: []*envoy_cluster.CircuitBreakers_Thresholds{{
+						MaxConnections: protobuf.UInt32(9000),
+						MaxRequests:    protobuf.UInt32(10),
+						MaxRetries:     protobuf.UInt32(1),
+					}},
+				},
+				MaxRequestsPerConnection: protobuf.UInt32(100),
+			}),
+			DefaultCluster(&v2.Cluster{
+				Name:                 "default/kuard/80/da39a3ee5e",
+				AltStatName:          "default_kuard_80",
+				ClusterDiscoveryType: envoy.ClusterDiscoveryType(v2.Cluster_EDS),
+				EdsClusterConfig: &v2.Cluster_EdsClusterConfig{
+					EdsConfig:   envoy.ConfigSource("contour"),
+					ServiceName: "default/kuard",
+				},
+				CircuitBreakers: &envoy_cluster.CircuitBreakers{
+					Thresholds: []*envoy_cluster.CircuitBreakers_Thresholds{{
+						MaxConnections: protobuf.UInt32(9000),
+						MaxRetries:     protobuf.UInt32(7),
+					}},
+				},
+			}),
+		),
+		TypeUrl: clusterType,
+	})
+}
+
 // issue 581, different service parameters should generate
 // a single CDS entry if they differ only in weight.
 func TestClusterPerServiceParameters(t *testing.T) {
@@ -0,0 +1,122 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuretests
+
+import (
+	"testing"
+	"time"
+
+	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/envoy"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
+	"github.com/projectcontour/contour/internal/timeout"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestCORSPolicy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+
+	proxy := fixture.NewProxy("proxy").
+		WithSpec(projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn: "example.com",
+				CORSPolicy: &projcontour.CORSPolicy{
+					AllowCredentials: true,
+					AllowOrigin:      []string{"https://example.com", `https://.*\.example\.com`},
+					AllowMethods:     []projcontour.CORSHeaderValue{"GET", "POST", "OPTIONS"},
+					AllowHeaders:     []projcontour.CORSHeaderValue{"authorization", "cache-control"},
+					ExposeHeaders:    []projcontour.CORSHeaderValue{"Content-Length", "Content-Range"},
+					MaxAge:           "10m",
+				},
+			},
+			Routes: []projcontour.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		})
+	rh.OnAdd(proxy)
+
+	c.Status(proxy).Like(projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid})
+
+	vhost := envoy.VirtualHost("example.com",
+		&envoy_api_v2_route.Route{
+			Match:  routePrefix("/"),
+			Action: routeCluster("default/kuard/8080/da39a3ee5e"),
+		},
+	)
+	vhost.Cors = envoy.CORSPolicy(&dag.CORSPolicy{
+		AllowCredentials: true,
+		AllowOrigin: []dag.CORSAllowOriginMatch{
+			{Exact: "https://example.com"},
+			{Regex: `https://.*\.example\.com`},
+		},
+		AllowMethods:  []string{"GET", "POST", "OPTIONS"},
+		AllowHeaders:  []string{"authorization", "cache-control"},
+		ExposeHeaders: []string{"Content-Length", "Content-Range"},
+		MaxAge:        timeout.DurationSetting(10 * time.Minute),
+	})
+
+	c.Request(routeType, contour.ENVOY_HTTP_LISTENER).Equals(&v2.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy.RouteConfiguration(contour.ENVOY_HTTP_LISTENER, vhost),
+		),
+	})
+
+	// An invalid origin regex makes the proxy invalid, and is
+	// reported as an error of the Valid condition.
+	invalid := proxy.DeepCopy()
+	invalid.Spec.VirtualHost.CORSPolicy.AllowOrigin = []string{"https://[example.com"}
+	rh.OnUpdate(proxy, invalid)
+
+	msg := "Spec.VirtualHost.CORSPolicy: invalid allowOrigin \"https://[example.com\": error parsing regexp: missing closing ]: `[example.com`"
+	c.Status(invalid).Equals(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   msg,
+		Conditions: []projcontour.DetailedCondition{{
+			Condition: projcontour.Condition{
+				Type:    projcontour.ValidConditionType,
+				Status:  projcontour.ConditionFalse,
+				Reason:  "Invalid",
+				Message: msg,
+			},
+			Errors: []projcontour.SubCondition{{
+				Type:    projcontour.ConditionTypeCORSError,
+				Status:  projcontour.ConditionTrue,
+				Reason:  "PolicyDidNotParse",
+				Message: msg,
+			}},
+		}},
+	})
+
+	c.Request(routeType, contour.ENVOY_HTTP_LISTENER).Equals(&v2.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy.RouteConfiguration(contour.ENVOY_HTTP_LISTENER),
+		),
+	})
+}
@@ -118,3 +118,134 @@ func TestDownstreamTLSCertificateValidation(t *testing.T) {
 	)
 
 }
+
+func TestDownstreamTLSCertificateRevocation(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	serverTLSSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "serverTLSSecret",
+			Namespace: "default",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: secretdata(CERTIFICATE, RSA_PRIVATE_KEY),
+	}
+	rh.OnAdd(serverTLSSecret)
+
+	clientCASecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "clientCASecret",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			dag.CACertificateKey: []byte(CERTIFICATE),
+		},
+	}
+	rh.OnAdd(clientCASecret)
+
+	crlSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "crlSecret",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			dag.CRLKey: []byte(CRL),
+		},
+	}
+	rh.OnAdd(crlSecret)
+
+	rh.OnAdd(fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Name: "http", Port: 8080, TargetPort: intstr.FromInt(8080)}))
+
+	proxy := fixture.NewProxy("example.com").
+		WithSpec(projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn: "example.com",
+				TLS: &projcontour.TLS{
+					SecretName: serverTLSSecret.Name,
+					ClientValidation: &projcontour.DownstreamValidation{
+						CACertificate:             clientCASecret.Name,
+						CertificateRevocationList: crlSecret.Name,
+					},
+				},
+			},
+			Routes: []projcontour.Route{{
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		})
+	rh.OnAdd(proxy)
+
+	ingress_https := &v2.Listener{
+		Name:    "ingress_https",
+		Address: envoy.SocketAddress("0.0.0.0", 8443),
+		ListenerFilters: envoy.ListenerFilters(
+			envoy.TLSInspector(),
+		),
+		FilterChains: appendFilterChains(
+			filterchaintls("example.com", serverTLSSecret,
+				httpsFilterFor("example.com"),
+				&dag.PeerValidationContext{
+					CACertificate: &dag.Secret{
+						Object: clientCASecret,
+					},
+					CRL: &dag.Secret{
+						Object: crlSecret,
+					},
+				},
+				"h2", "http/1.1",
+			),
+		),
+		SocketOptions: envoy.TCPKeepaliveSocketOptions(),
+	}
+
+	c.Request(listenerType, "ingress_https").Equals(&v2.DiscoveryResponse{
+		Resources: resources(t, ingress_https),
+		TypeUrl:   listenerType,
+	}).Status(proxy).Like(
+		projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid},
+	)
+
+	// The CRL is delivered over SDS.
+	c.Request(secretType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			envoy.CRLSecret(&dag.Secret{Object: crlSecret}),
+			secret(serverTLSSecret),
+		),
+		TypeUrl: secretType,
+	})
+
+	// Updating the CRL changes the SDS secret, but not the listener.
+	crlSecret2 := crlSecret.DeepCopy()
+	crlSecret2.Data[dag.CRLKey] = []byte(CRL + "\n" + CRL)
+	rh.OnUpdate(crlSecret, crlSecret2)
+
+	c.Request(listenerType, "ingress_https").Equals(&v2.DiscoveryResponse{
+		Resources: resources(t, ingress_https),
+		TypeUrl:   listenerType,
+	})
+
+	c.Request(secretType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			envoy.CRLSecret(&dag.Secret{Object: crlSecret2}),
+			secret(serverTLSSecret),
+		),
+		TypeUrl: secretType,
+	})
+
+	// A missing CRL Secret invalidates the proxy.
+	rh.OnDelete(crlSecret2)
+
+	c.Request(listenerType, "ingress_https").Equals(&v2.DiscoveryResponse{
+		Resources: nil,
+		TypeUrl:   listenerType,
+	}).Status(proxy).Like(
+		projcontour.HTTPProxyStatus{
+			CurrentStatus: k8s.StatusInvalid,
+			Description:   `Spec.VirtualHost.TLS client validation is invalid: invalid CRL Secret "default/crlSecret": Secret not found`,
+		},
+	)
+}
@@ -25,6 +25,7 @@ import (
 	envoy_api_v2_listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
 	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
 	envoy_config_v2_tcpproxy "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/tcp_proxy/v2"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/golang/protobuf/proto"
 	"github.com/projectcontour/contour/internal/contour"
@@ -104,6 +105,15 @@ func routePrefix(prefix string, headers ...dag.HeaderMatchCondition) *envoy_api_
 	})
 }
 
+func routeExact(path string, headers ...dag.HeaderMatchCondition) *envoy_api_v2_route.RouteMatch {
+	return envoy.RouteMatch(&dag.Route{
+		PathMatchCondition: &dag.ExactMatchCondition{
+			Path: path,
+		},
+		HeaderMatchConditions: headers,
+	})
+}
+
 func routeHostRewrite(cluster, newHostName string) *envoy_api_v2_route.Route_Route {
 	return &envoy_api_v2_route.Route_Route{
 		Route: &envoy_api_v2_route.RouteAction{
@@ -146,6 +156,7 @@ func tlsCluster(c *v2.Cluster, ca []byte, subjectName string, sni string, alpnPr
 				}},
 				SubjectName: subjectName},
 			sni,
+			nil,
 			alpnProtocols...,
 		),
 	)
@@ -179,6 +190,14 @@ func withPrefixRewrite(route *envoy_api_v2_route.Route_Route, replacement string
 	return route
 }
 
+func withRegexRewrite(route *envoy_api_v2_route.Route_Route, pattern, substitution string) *envoy_api_v2_route.Route_Route {
+	route.Route.RegexRewrite = &matcher.RegexMatchAndSubstitute{
+		Pattern:      envoy.SafeRegexMatch(pattern),
+		Substitution: substitution,
+	}
+	return route
+}
+
 func withRetryPolicy(route *envoy_api_v2_route.Route_Route, retryOn string, numRetries uint32, perTryTimeout time.Duration) *envoy_api_v2_route.Route_Route {
 	route.Route.RetryPolicy = &envoy_api_v2_route.RetryPolicy{
 		RetryOn: retryOn,
@@ -255,6 +274,9 @@ func filterchaintls(domain string, secret *v1.Secret, filter *envoy_api_v2_liste
 		envoy.DownstreamTLSContext(
 			&dag.Secret{Object: secret},
 			envoy_api_v2_auth.TlsParameters_TLSv1_1,
+			envoy_api_v2_auth.TlsParameters_TLSv1_3,
+			nil,
+			nil,
 			peerValidationContext,
 			alpn...),
 		envoy.Filters(filter),
@@ -267,6 +289,9 @@ func filterchaintlsfallback(fallbackSecret *v1.Secret, peerValidationContext *da
 		envoy.DownstreamTLSContext(
 			&dag.Secret{Object: fallbackSecret},
 			envoy_api_v2_auth.TlsParameters_TLSv1_1,
+			envoy_api_v2_auth.TlsParameters_TLSv1_3,
+			nil,
+			nil,
 			peerValidationContext,
 			alpn...),
 		envoy.Filters(
@@ -280,9 +305,9 @@ func filterchaintlsfallback(fallbackSecret *v1.Secret, peerValidationContext *da
 	)
 }
 
-func httpsFilterFor(vhost string) *envoy_api_v2_listener.Filter {
+func httpsFilterFor(vhost string, aliases ...string) *envoy_api_v2_listener.Filter {
 	return envoy.HTTPConnectionManagerBuilder().
-		AddFilter(envoy.FilterMisdirectedRequests(vhost)).
+		AddFilter(envoy.FilterMisdirectedRequests(vhost, aliases...)).
 		DefaultFilters().
 		RouteConfigName(path.Join("https", vhost)).
 		MetricsPrefix(contour.ENVOY_HTTPS_LISTENER).
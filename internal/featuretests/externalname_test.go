@@ -206,7 +206,7 @@ func TestExternalNameService(t *testing.T) {
 				},
 				&v2.Cluster{
 					TransportSocket: envoy.UpstreamTLSTransportSocket(
-						envoy.UpstreamTLSContext(nil, "external.address", "h2"),
+						envoy.UpstreamTLSContext(nil, "external.address", nil, "h2"),
 					),
 				},
 			),
@@ -258,7 +258,7 @@ func TestExternalNameService(t *testing.T) {
 				externalNameCluster("default/kuard/80/da39a3ee5e", "default/kuard", "default_kuard_80", "foo.io", 80),
 				&v2.Cluster{
 					TransportSocket: envoy.UpstreamTLSTransportSocket(
-						envoy.UpstreamTLSContext(nil, "external.address"),
+						envoy.UpstreamTLSContext(nil, "external.address", nil),
 					),
 				},
 			),
@@ -0,0 +1,118 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuretests
+
+import (
+	"testing"
+	"time"
+
+	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/ptypes/any"
+	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/envoy"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestHTTPProxyFaultInjection(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+
+	vhost := fixture.NewProxy("kuard").WithSpec(
+		projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn: "kuard.projectcontour.io",
+			},
+			Routes: []projcontour.Route{{
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+				FaultInjectionPolicy: &projcontour.FaultInjectionPolicy{
+					Delay: &projcontour.FaultDelay{
+						FixedDelay: "2s",
+						Percentage: "10",
+					},
+					Abort: &projcontour.FaultAbort{
+						HTTPStatus: 503,
+						Percentage: "5",
+					},
+					Headers: []projcontour.HeaderMatchCondition{{
+						Name:  "x-fault",
+						Exact: "true",
+					}},
+				},
+			}},
+		})
+
+	rh.OnAdd(vhost)
+
+	c.Request(routeType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			envoy.RouteConfiguration("ingress_http",
+				envoy.VirtualHost("kuard.projectcontour.io",
+					&envoy_api_v2_route.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/kuard/8080/da39a3ee5e"),
+						TypedPerFilterConfig: map[string]*any.Any{
+							wellknown.Fault: envoy.FaultConfig(&dag.FaultInjectionPolicy{
+								Delay: &dag.FaultDelay{
+									FixedDelay: 2 * time.Second,
+									Percentage: 10,
+								},
+								Abort: &dag.FaultAbort{
+									HTTPStatus: 503,
+									Percentage: 5,
+								},
+								Headers: []dag.HeaderMatchCondition{{
+									Name:      "x-fault",
+									Value:     "true",
+									MatchType: "exact",
+								}},
+							}),
+						},
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(vhost).Like(
+		projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid},
+	)
+
+	// An abort needs exactly one of an HTTP or gRPC status.
+	vhost = update(rh, vhost,
+		func(vhost *projcontour.HTTPProxy) {
+			vhost.Spec.Routes[0].FaultInjectionPolicy.Abort.GRPCStatus = 14
+		})
+
+	c.Request(routeType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			envoy.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	}).Status(vhost).Equals(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   "fault injection policy: abort must specify exactly one of httpStatus or grpcStatus",
+		Conditions:    invalidCondition("fault injection policy: abort must specify exactly one of httpStatus or grpcStatus"),
+	})
+}
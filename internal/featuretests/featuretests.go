@@ -266,6 +266,19 @@ func (s *statusResult) Equals(want projcontour.HTTPProxyStatus) *Contour {
 	return s.Contour
 }
 
+// invalidCondition returns the Valid condition of an HTTPProxy
+// that is invalid with the given description.
+func invalidCondition(desc string) []projcontour.DetailedCondition {
+	return []projcontour.DetailedCondition{{
+		Condition: projcontour.Condition{
+			Type:    projcontour.ValidConditionType,
+			Status:  projcontour.ConditionFalse,
+			Reason:  "Invalid",
+			Message: desc,
+		},
+	}}
+}
+
 // Like asserts that the status result is not an error and matches
 // non-empty fields in the wanted status.
 func (s *statusResult) Like(want projcontour.HTTPProxyStatus) *Contour {
@@ -19,6 +19,7 @@ import (
 	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/projectcontour/contour/internal/envoy"
 	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
 	v1 "k8s.io/api/core/v1"
 
 	"testing"
@@ -219,3 +220,133 @@ func TestHeaderPolicy_ReplaceHeader_HTTProxy(t *testing.T) {
 		TypeUrl: clusterType,
 	})
 }
+
+func TestHeaderPolicy_DynamicValues_HTTPProxy(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("svc1").
+		WithPorts(v1.ServicePort{Port: 80, TargetPort: intstr.FromInt(8080)}),
+	)
+
+	headerRoute := func(hv ...projcontour.HeaderValue) projcontour.HTTPProxySpec {
+		return projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{Fqdn: "hello.world"},
+			Routes: []projcontour.Route{{
+				Services: []projcontour.Service{{
+					Name: "svc1",
+					Port: 80,
+				}},
+				RequestHeadersPolicy: &projcontour.HeadersPolicy{
+					Set: hv,
+				},
+			}},
+		}
+	}
+
+	headerValue := func(key, value string) *envoy_api_v2_core.HeaderValueOption {
+		return &envoy_api_v2_core.HeaderValueOption{
+			Header: &envoy_api_v2_core.HeaderValue{
+				Key:   key,
+				Value: value,
+			},
+			Append: &wrappers.BoolValue{
+				Value: false,
+			},
+		}
+	}
+
+	// Only values that opt in are interpolated by Envoy.
+	p1 := fixture.NewProxy("simple").WithSpec(headerRoute(
+		projcontour.HeaderValue{
+			Name:    "X-Real-IP",
+			Value:   "%DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT%",
+			Dynamic: true,
+		},
+		projcontour.HeaderValue{
+			Name:  "X-Literal",
+			Value: "%HOSTNAME%",
+		},
+	))
+	rh.OnAdd(p1)
+
+	c.Request(routeType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			envoy.RouteConfiguration("ingress_http",
+				envoy.VirtualHost("hello.world",
+					&envoy_api_v2_route.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/svc1/80/da39a3ee5e"),
+						RequestHeadersToAdd: []*envoy_api_v2_core.HeaderValueOption{
+							headerValue("X-Literal", "%%HOSTNAME%%"),
+							headerValue("X-Real-Ip", "%DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT%"),
+						},
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	}).Status(p1).Like(
+		projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid},
+	)
+
+	// Unknown variables are rejected.
+	p2 := fixture.NewProxy("simple").WithSpec(headerRoute(
+		projcontour.HeaderValue{
+			Name:    "X-Unknown",
+			Value:   "%START_TIME%",
+			Dynamic: true,
+		},
+	))
+	rh.OnUpdate(p1, p2)
+
+	c.Request(routeType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			envoy.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	}).Status(p2).Like(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   `invalid set header "X-Unknown": unsupported variable "%START_TIME%"`,
+	})
+
+	// Request headers that carry credentials are rejected.
+	p3 := fixture.NewProxy("simple").WithSpec(headerRoute(
+		projcontour.HeaderValue{
+			Name:    "X-Auth",
+			Value:   "%REQ(Cookie)%",
+			Dynamic: true,
+		},
+	))
+	rh.OnUpdate(p2, p3)
+
+	c.Request(routeType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			envoy.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	}).Status(p3).Like(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   `invalid set header "X-Auth": request header "Cookie" cannot be used in "%REQ(Cookie)%"`,
+	})
+
+	// Dynamic values can't rewrite the Host header.
+	p4 := fixture.NewProxy("simple").WithSpec(headerRoute(
+		projcontour.HeaderValue{
+			Name:    "Host",
+			Value:   "%REQ(X-Forwarded-For)%",
+			Dynamic: true,
+		},
+	))
+	rh.OnUpdate(p3, p4)
+
+	c.Request(routeType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			envoy.RouteConfiguration("ingress_http"),
+		),
+		TypeUrl: routeType,
+	}).Status(p4).Like(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   `dynamic values are not supported for the "Host" header`,
+	})
+}
@@ -29,6 +29,18 @@ func prefixMatchCondition(prefix string) projcontour.MatchCondition {
 	}
 }
 
+func exactMatchCondition(path string) projcontour.MatchCondition {
+	return projcontour.MatchCondition{
+		Exact: path,
+	}
+}
+
+func regexMatchCondition(regex string) projcontour.MatchCondition {
+	return projcontour.MatchCondition{
+		Regex: regex,
+	}
+}
+
 func headerContainsMatchCondition(name, value string) projcontour.MatchCondition {
 	return projcontour.MatchCondition{
 		Header: &projcontour.HeaderMatchCondition{
@@ -73,3 +85,12 @@ func headerPresentMatchCondition(name string) projcontour.MatchCondition {
 		},
 	}
 }
+
+func queryParamExactMatchCondition(name, value string) projcontour.MatchCondition {
+	return projcontour.MatchCondition{
+		QueryParameter: &projcontour.QueryParameterMatchCondition{
+			Name:  name,
+			Exact: value,
+		},
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/projectcontour/contour/internal/dag"
 	"github.com/projectcontour/contour/internal/envoy"
 	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -423,6 +424,9 @@ func TestHTTPProxyTLSListener(t *testing.T) {
 				envoy.DownstreamTLSContext(
 					&dag.Secret{Object: secret1},
 					envoy_api_v2_auth.TlsParameters_TLSv1_3,
+					envoy_api_v2_auth.TlsParameters_TLSv1_3,
+					nil,
+					nil,
 					nil,
 					"h2", "http/1.1"),
 				envoy.Filters(httpsFilterFor("kuard.example.com")),
@@ -1053,6 +1057,9 @@ func TestHTTPProxyMinimumTLSVersion(t *testing.T) {
 				envoy.DownstreamTLSContext(
 					&dag.Secret{Object: secret1},
 					envoy_api_v2_auth.TlsParameters_TLSv1_2,
+					envoy_api_v2_auth.TlsParameters_TLSv1_3,
+					nil,
+					nil,
 					nil,
 					"h2", "http/1.1"),
 				envoy.Filters(httpsFilterFor("kuard.example.com")),
@@ -1118,6 +1125,9 @@ func TestHTTPProxyMinimumTLSVersion(t *testing.T) {
 				envoy.DownstreamTLSContext(
 					&dag.Secret{Object: secret1},
 					envoy_api_v2_auth.TlsParameters_TLSv1_3,
+					envoy_api_v2_auth.TlsParameters_TLSv1_3,
+					nil,
+					nil,
 					nil,
 					"h2", "http/1.1"),
 				envoy.Filters(httpsFilterFor("kuard.example.com")),
@@ -1144,6 +1154,147 @@ func TestHTTPProxyMinimumTLSVersion(t *testing.T) {
 	})
 }
 
+func TestHTTPProxyTLSParameters(t *testing.T) {
+	rh, c, done := setup(t, func(conf *contour.ListenerConfig) {
+		conf.MaximumTLSVersion = envoy_api_v2_auth.TlsParameters_TLSv1_2
+		conf.CipherSuites = []string{"ECDHE-ECDSA-AES256-GCM-SHA384", "ECDHE-RSA-AES256-GCM-SHA384"}
+		conf.ECDHCurves = []string{"P-256"}
+	})
+
+	defer done()
+
+	secret1 := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret",
+			Namespace: "default",
+		},
+		Type: "kubernetes.io/tls",
+		Data: secretdata(CERTIFICATE, RSA_PRIVATE_KEY),
+	}
+	rh.OnAdd(secret1)
+
+	rh.OnAdd(fixture.NewService("backend").
+		WithPorts(v1.ServicePort{Name: "http", Port: 80}))
+
+	proxy := func(tls *projcontour.TLS) *projcontour.HTTPProxy {
+		return &projcontour.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "simple",
+				Namespace: "default",
+			},
+			Spec: projcontour.HTTPProxySpec{
+				VirtualHost: &projcontour.VirtualHost{
+					Fqdn: "kuard.example.com",
+					TLS:  tls,
+				},
+				Routes: []projcontour.Route{{
+					Services: []projcontour.Service{{
+						Name: "backend",
+						Port: 80,
+					}},
+				}},
+			},
+		}
+	}
+
+	httpsListener := func(max envoy_api_v2_auth.TlsParameters_TlsProtocol, ciphers, curves []string) *v2.Listener {
+		return &v2.Listener{
+			Name:    "ingress_https",
+			Address: envoy.SocketAddress("0.0.0.0", 8443),
+			ListenerFilters: envoy.ListenerFilters(
+				envoy.TLSInspector(),
+			),
+			FilterChains: []*envoy_api_v2_listener.FilterChain{
+				envoy.FilterChainTLS(
+					"kuard.example.com",
+					envoy.DownstreamTLSContext(
+						&dag.Secret{Object: secret1},
+						envoy_api_v2_auth.TlsParameters_TLSv1_1,
+						max,
+						ciphers,
+						curves,
+						nil,
+						"h2", "http/1.1"),
+					envoy.Filters(httpsFilterFor("kuard.example.com")),
+				),
+			},
+			SocketOptions: envoy.TCPKeepaliveSocketOptions(),
+		}
+	}
+
+	// p1 uses the configured defaults.
+	p1 := proxy(&projcontour.TLS{
+		SecretName: "secret",
+	})
+	rh.OnAdd(p1)
+
+	c.Request(listenerType, "ingress_https").Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			httpsListener(
+				envoy_api_v2_auth.TlsParameters_TLSv1_2,
+				[]string{"ECDHE-ECDSA-AES256-GCM-SHA384", "ECDHE-RSA-AES256-GCM-SHA384"},
+				[]string{"P-256"},
+			),
+		),
+		TypeUrl: listenerType,
+	})
+
+	// p2 overrides the configured defaults.
+	p2 := proxy(&projcontour.TLS{
+		SecretName:             "secret",
+		MaximumProtocolVersion: "1.3",
+		CipherSuites:           []string{"[ECDHE-ECDSA-AES128-GCM-SHA256|ECDHE-ECDSA-CHACHA20-POLY1305]"},
+		ECDHCurves:             []string{"X25519", "P-384"},
+	})
+	rh.OnUpdate(p1, p2)
+
+	c.Request(listenerType, "ingress_https").Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			httpsListener(
+				envoy_api_v2_auth.TlsParameters_TLSv1_3,
+				[]string{"[ECDHE-ECDSA-AES128-GCM-SHA256|ECDHE-ECDSA-CHACHA20-POLY1305]"},
+				[]string{"X25519", "P-384"},
+			),
+		),
+		TypeUrl: listenerType,
+	})
+
+	// p3 has a cipher suite that Envoy does not support.
+	p3 := proxy(&projcontour.TLS{
+		SecretName:   "secret",
+		CipherSuites: []string{"ECDHE-RSA-RC4-SHA"},
+	})
+	rh.OnUpdate(p2, p3)
+
+	c.Request(listenerType, "ingress_https").Equals(&v2.DiscoveryResponse{
+		Resources: nil,
+		TypeUrl:   listenerType,
+	}).Status(p3).Like(
+		projcontour.HTTPProxyStatus{
+			CurrentStatus: k8s.StatusInvalid,
+			Description:   `Spec.VirtualHost.TLS.CipherSuites is invalid: unsupported cipher suite "ECDHE-RSA-RC4-SHA"`,
+		},
+	)
+
+	// p4 has a maximum version that is lower than its minimum version.
+	p4 := proxy(&projcontour.TLS{
+		SecretName:             "secret",
+		MinimumProtocolVersion: "1.3",
+		MaximumProtocolVersion: "1.2",
+	})
+	rh.OnUpdate(p3, p4)
+
+	c.Request(listenerType, "ingress_https").Equals(&v2.DiscoveryResponse{
+		Resources: nil,
+		TypeUrl:   listenerType,
+	}).Status(p4).Like(
+		projcontour.HTTPProxyStatus{
+			CurrentStatus: k8s.StatusInvalid,
+			Description:   `Spec.VirtualHost.TLS maximum protocol version "1.2" is lower than the minimum protocol version "1.3"`,
+		},
+	)
+}
+
 func TestLDSHTTPProxyRootCannotDelegateToAnotherRoot(t *testing.T) {
 	rh, c, done := setup(t)
 	defer done()
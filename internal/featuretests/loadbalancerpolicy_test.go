@@ -107,3 +107,76 @@ func TestLoadBalancerPolicySessionAffinity(t *testing.T) {
 		TypeUrl: routeType,
 	})
 }
+
+func TestLoadBalancerPolicyRequestHash(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	s1 := fixture.NewService("app").WithPorts(
+		v1.ServicePort{Port: 80, TargetPort: intstr.FromInt(8080)})
+	rh.OnAdd(s1)
+
+	proxy1 := fixture.NewProxy("simple").
+		WithFQDN("www.example.com").
+		WithSpec(projcontour.HTTPProxySpec{
+			Routes: []projcontour.Route{{
+				Conditions:       matchconditions(prefixMatchCondition("/ws")),
+				EnableWebsockets: true,
+				LoadBalancerPolicy: &projcontour.LoadBalancerPolicy{
+					Strategy:      "RequestHash",
+					HashAlgorithm: "Maglev",
+					RequestHashPolicies: []projcontour.RequestHashPolicy{{
+						Terminal: true,
+						CookieHashOptions: &projcontour.CookieHashOptions{
+							Name: "JSESSIONID",
+						},
+					}, {
+						HashSourceIP: true,
+					}},
+				},
+				Services: []projcontour.Service{{
+					Name: s1.Name,
+					Port: 80,
+				}},
+			}},
+		})
+	rh.OnAdd(proxy1)
+
+	route := withWebsocket(routeCluster("default/app/80/843e4ded8f"))
+	route.Route.HashPolicy = []*envoy_api_v2_route.RouteAction_HashPolicy{{
+		Terminal: true,
+		PolicySpecifier: &envoy_api_v2_route.RouteAction_HashPolicy_Cookie_{
+			Cookie: &envoy_api_v2_route.RouteAction_HashPolicy_Cookie{
+				Name: "JSESSIONID",
+			},
+		},
+	}, {
+		PolicySpecifier: &envoy_api_v2_route.RouteAction_HashPolicy_ConnectionProperties_{
+			ConnectionProperties: &envoy_api_v2_route.RouteAction_HashPolicy_ConnectionProperties{
+				SourceIp: true,
+			},
+		},
+	}}
+
+	c.Request(routeType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			envoy.RouteConfiguration("ingress_http",
+				envoy.VirtualHost("www.example.com",
+					&envoy_api_v2_route.Route{
+						Match:  routePrefix("/ws"),
+						Action: route,
+					},
+				),
+			),
+		),
+		TypeUrl: routeType,
+	})
+
+	maglev := cluster("default/app/80/843e4ded8f", "default/app", "default_app_80")
+	maglev.LbPolicy = v2.Cluster_MAGLEV
+
+	c.Request(clusterType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t, maglev),
+		TypeUrl:   clusterType,
+	})
+}
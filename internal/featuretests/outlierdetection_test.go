@@ -0,0 +1,174 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuretests
+
+import (
+	"testing"
+	"time"
+
+	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	envoy_cluster "github.com/envoyproxy/go-control-plane/envoy/api/v2/cluster"
+	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
+	"github.com/projectcontour/contour/internal/protobuf"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func withOutlierDetection(c *v2.Cluster, od *envoy_cluster.OutlierDetection) *v2.Cluster {
+	c.OutlierDetection = od
+	return c
+}
+
+func TestHTTPProxyOutlierDetection(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+
+	vhost := fixture.NewProxy("kuard").WithSpec(
+		projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn: "kuard.projectcontour.io",
+			},
+			Routes: []projcontour.Route{{
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+					OutlierDetectionPolicy: &projcontour.OutlierDetectionPolicy{
+						Consecutive5xxErrors:     3,
+						ConsecutiveGatewayErrors: 2,
+						Interval:                 "5s",
+						BaseEjectionTime:         "1m",
+						MaxEjectionPercent:       uint32Ptr(50),
+					},
+				}},
+			}},
+		})
+
+	rh.OnAdd(vhost)
+
+	c.Request(clusterType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			withOutlierDetection(
+				cluster("default/kuard/8080/342c74c2da", "default/kuard", "default_kuard_8080"),
+				&envoy_cluster.OutlierDetection{
+					Consecutive_5Xx:                    protobuf.UInt32(3),
+					ConsecutiveGatewayFailure:          protobuf.UInt32(2),
+					EnforcingConsecutiveGatewayFailure: protobuf.UInt32(100),
+					Interval:                           protobuf.Duration(5 * time.Second),
+					BaseEjectionTime:                   protobuf.Duration(time.Minute),
+					MaxEjectionPercent:                 protobuf.UInt32(50),
+				},
+			),
+		),
+		TypeUrl: clusterType,
+	}).Status(vhost).Like(
+		projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid},
+	)
+
+	vhost = update(rh, vhost,
+		func(vhost *projcontour.HTTPProxy) {
+			vhost.Spec.Routes[0].Services[0].OutlierDetectionPolicy.Interval = "often"
+		})
+
+	c.Request(clusterType).Equals(&v2.DiscoveryResponse{
+		Resources: nil,
+		TypeUrl:   clusterType,
+	}).Status(vhost).Equals(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   `service "kuard": outlier detection policy: invalid interval "often"`,
+		Conditions:    invalidCondition(`service "kuard": outlier detection policy: invalid interval "often"`),
+	})
+}
+
+func TestTCPProxyOutlierDetection(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	s1 := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret",
+			Namespace: "default",
+		},
+		Type: "kubernetes.io/tls",
+		Data: secretdata(CERTIFICATE, RSA_PRIVATE_KEY),
+	}
+	rh.OnAdd(s1)
+
+	rh.OnAdd(fixture.NewService("backend").
+		WithPorts(v1.ServicePort{Port: 80, TargetPort: intstr.FromInt(8080)}))
+	rh.OnAdd(fixture.NewService("other").
+		WithPorts(v1.ServicePort{Port: 80, TargetPort: intstr.FromInt(8080)}))
+
+	hp1 := &projcontour.HTTPProxy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "simple",
+			Namespace: s1.Namespace,
+		},
+		Spec: projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn: "kuard-tcp.example.com",
+				TLS: &projcontour.TLS{
+					SecretName: s1.Name,
+				},
+			},
+			TCPProxy: &projcontour.TCPProxy{
+				// The policy of the tcpproxy applies to "backend", but
+				// "other" has its own policy.
+				OutlierDetectionPolicy: &projcontour.OutlierDetectionPolicy{
+					Consecutive5xxErrors: 3,
+					Interval:             "5s",
+				},
+				Services: []projcontour.Service{{
+					Name: "backend",
+					Port: 80,
+				}, {
+					Name: "other",
+					Port: 80,
+					OutlierDetectionPolicy: &projcontour.OutlierDetectionPolicy{
+						MaxEjectionPercent: uint32Ptr(100),
+					},
+				}},
+			},
+		},
+	}
+	rh.OnAdd(hp1)
+
+	c.Request(clusterType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			withOutlierDetection(
+				cluster("default/backend/80/ad5c465ebe", "default/backend", "default_backend_80"),
+				&envoy_cluster.OutlierDetection{
+					Consecutive_5Xx: protobuf.UInt32(3),
+					Interval:        protobuf.Duration(5 * time.Second),
+				},
+			),
+			withOutlierDetection(
+				cluster("default/other/80/2e429990e3", "default/other", "default_other_80"),
+				&envoy_cluster.OutlierDetection{
+					MaxEjectionPercent: protobuf.UInt32(100),
+				},
+			),
+		),
+		TypeUrl: clusterType,
+	})
+}
+
+func uint32Ptr(v uint32) *uint32 {
+	return &v
+}
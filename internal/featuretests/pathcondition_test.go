@@ -0,0 +1,119 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuretests
+
+import (
+	"testing"
+
+	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/envoy"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestExactAndRegexPathConditions(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+
+	child := fixture.NewProxy("child").
+		WithSpec(projcontour.HTTPProxySpec{
+			Routes: []projcontour.Route{{
+				Conditions: matchconditions(exactMatchCondition("/healthz")),
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}, {
+				Conditions: matchconditions(regexMatchCondition("/api/v[0-9]+/users")),
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		})
+	rh.OnAdd(child)
+
+	root := fixture.NewProxy("root").
+		WithSpec(projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Includes: []projcontour.Include{{
+				Name:       "child",
+				Conditions: matchconditions(prefixMatchCondition("/legacy/")),
+			}},
+			Routes: []projcontour.Route{{
+				Conditions: matchconditions(exactMatchCondition("/")),
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		})
+	rh.OnAdd(root)
+
+	c.Status(root).Like(projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid})
+
+	c.Request(routeType, contour.ENVOY_HTTP_LISTENER).Equals(&v2.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy.RouteConfiguration(contour.ENVOY_HTTP_LISTENER,
+				envoy.VirtualHost("example.com",
+					&envoy_api_v2_route.Route{
+						Match:  routeExact("/legacy/healthz"),
+						Action: routeCluster("default/kuard/8080/da39a3ee5e"),
+					},
+					&envoy_api_v2_route.Route{
+						Match:  routeExact("/"),
+						Action: routeCluster("default/kuard/8080/da39a3ee5e"),
+					},
+					&envoy_api_v2_route.Route{
+						Match:  routeRegex("/legacy/api/v[0-9]+/users"),
+						Action: routeCluster("default/kuard/8080/da39a3ee5e"),
+					},
+				),
+			),
+		),
+	})
+
+	// Regex conditions must compile.
+	invalid := child.DeepCopy()
+	invalid.Spec.Routes[1].Conditions = matchconditions(regexMatchCondition("/api/v[0-9+/users"))
+	rh.OnUpdate(child, invalid)
+
+	c.Status(invalid).Like(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   "route: invalid regex condition \"/api/v[0-9+/users\": error parsing regexp: missing closing ]: `[0-9+/users`",
+	})
+
+	// Exact and regex conditions are not allowed on includes.
+	rh.OnUpdate(invalid, child)
+
+	exactInclude := root.DeepCopy()
+	exactInclude.Spec.Includes[0].Conditions = matchconditions(exactMatchCondition("/legacy"))
+	rh.OnUpdate(root, exactInclude)
+
+	c.Status(exactInclude).Like(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   "include: exact and regex conditions are not allowed on includes",
+	})
+}
@@ -0,0 +1,115 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuretests
+
+import (
+	"testing"
+
+	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher"
+	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/envoy"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestQueryParameterConditions(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+	rh.OnAdd(fixture.NewService("kuard-beta").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+
+	child := fixture.NewProxy("beta").
+		WithSpec(projcontour.HTTPProxySpec{
+			Routes: []projcontour.Route{{
+				Services: []projcontour.Service{{
+					Name: "kuard-beta",
+					Port: 8080,
+				}},
+			}},
+		})
+	rh.OnAdd(child)
+
+	root := fixture.NewProxy("root").
+		WithSpec(projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Includes: []projcontour.Include{{
+				Name: "beta",
+				Conditions: matchconditions(
+					prefixMatchCondition("/"),
+					queryParamExactMatchCondition("beta", "true"),
+				),
+			}},
+			Routes: []projcontour.Route{{
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		})
+	rh.OnAdd(root)
+
+	c.Status(root).Like(projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid})
+
+	betaMatch := routePrefix("/")
+	betaMatch.QueryParameters = []*envoy_api_v2_route.QueryParameterMatcher{{
+		Name: "beta",
+		QueryParameterMatchSpecifier: &envoy_api_v2_route.QueryParameterMatcher_StringMatch{
+			StringMatch: &matcher.StringMatcher{
+				MatchPattern: &matcher.StringMatcher_Exact{Exact: "true"},
+			},
+		},
+	}}
+
+	c.Request(routeType, contour.ENVOY_HTTP_LISTENER).Equals(&v2.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy.RouteConfiguration(contour.ENVOY_HTTP_LISTENER,
+				envoy.VirtualHost("example.com",
+					&envoy_api_v2_route.Route{
+						Match:  betaMatch,
+						Action: routeCluster("default/kuard-beta/8080/da39a3ee5e"),
+					},
+					&envoy_api_v2_route.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/kuard/8080/da39a3ee5e"),
+					},
+				),
+			),
+		),
+	})
+
+	// A query parameter condition must set exactly one match operator.
+	invalid := child.DeepCopy()
+	invalid.Spec.Routes[0].Conditions = matchconditions(projcontour.MatchCondition{
+		QueryParameter: &projcontour.QueryParameterMatchCondition{
+			Name: "debug",
+		},
+	})
+	rh.OnUpdate(child, invalid)
+
+	c.Status(invalid).Like(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   `query parameter condition "debug" must set exactly one of present, exact, prefix, contains or regex`,
+	})
+}
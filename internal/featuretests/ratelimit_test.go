@@ -0,0 +1,348 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuretests
+
+import (
+	"testing"
+	"time"
+
+	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	"github.com/golang/protobuf/ptypes/any"
+	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/apis/projectcontour/v1alpha1"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/envoy"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
+	"github.com/projectcontour/contour/internal/timeout"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestGlobalRateLimiting(t *testing.T) {
+	rh, c, done := setup(t, func(eh *contour.EventHandler) {
+		eh.Builder.RateLimitService = &dag.RateLimitServiceConfig{
+			ExtensionService: types.NamespacedName{
+				Namespace: "default",
+				Name:      "ratelimit",
+			},
+			Domain: "contour",
+		}
+	})
+	defer done()
+
+	rh.OnAdd(fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+	rh.OnAdd(fixture.NewService("ratelimit").
+		WithPorts(v1.ServicePort{Name: "grpc", Port: 8081, TargetPort: intstr.FromInt(8081)}))
+
+	rh.OnAdd(&v1alpha1.ExtensionService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ratelimit",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.ExtensionServiceSpec{
+			Services: []projcontour.Service{{
+				Name: "ratelimit",
+				Port: 8081,
+			}},
+			TimeoutPolicy: &projcontour.TimeoutPolicy{
+				Response: "100ms",
+			},
+		},
+	})
+
+	proxy := fixture.NewProxy("proxy").
+		WithSpec(projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn: "example.com",
+				RateLimitPolicy: &projcontour.RateLimitPolicy{
+					Global: &projcontour.GlobalRateLimitPolicy{
+						Descriptors: []projcontour.RateLimitDescriptor{{
+							Entries: []projcontour.RateLimitDescriptorEntry{{
+								RemoteAddress: &projcontour.RemoteAddressDescriptor{},
+							}},
+						}},
+					},
+				},
+			},
+			Routes: []projcontour.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+				RateLimitPolicy: &projcontour.RateLimitPolicy{
+					Global: &projcontour.GlobalRateLimitPolicy{
+						Descriptors: []projcontour.RateLimitDescriptor{{
+							Entries: []projcontour.RateLimitDescriptorEntry{{
+								GenericKey: &projcontour.GenericKeyDescriptor{Value: "kuard"},
+							}, {
+								RequestHeader: &projcontour.RequestHeaderDescriptor{
+									HeaderName:    "X-Tenant",
+									DescriptorKey: "tenant",
+								},
+							}},
+						}},
+					},
+				},
+			}},
+		})
+	rh.OnAdd(proxy)
+
+	c.Status(proxy).Like(projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid})
+
+	rls := &dag.RateLimitService{
+		Cluster: &dag.ExtensionCluster{Name: "extension/default/ratelimit"},
+		Domain:  "contour",
+		Timeout: timeout.DurationSetting(100 * time.Millisecond),
+	}
+
+	c.Request(listenerType, contour.ENVOY_HTTP_LISTENER).Equals(&v2.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			&v2.Listener{
+				Name:          contour.ENVOY_HTTP_LISTENER,
+				Address:       envoy.SocketAddress("0.0.0.0", 8080),
+				SocketOptions: envoy.TCPKeepaliveSocketOptions(),
+				FilterChains: envoy.FilterChains(envoy.HTTPConnectionManagerBuilder().
+					AddFilter(envoy.FilterGlobalRateLimit(rls)).
+					DefaultFilters().
+					RouteConfigName(contour.ENVOY_HTTP_LISTENER).
+					MetricsPrefix(contour.ENVOY_HTTP_LISTENER).
+					AccessLoggers(envoy.FileAccessLogEnvoy(contour.DEFAULT_HTTP_ACCESS_LOG)).
+					Get(),
+				),
+			}),
+	})
+
+	vhost := envoy.VirtualHost("example.com",
+		&envoy_api_v2_route.Route{
+			Match: routePrefix("/"),
+			Action: &envoy_api_v2_route.Route_Route{
+				Route: &envoy_api_v2_route.RouteAction{
+					ClusterSpecifier: &envoy_api_v2_route.RouteAction_Cluster{
+						Cluster: "default/kuard/8080/da39a3ee5e",
+					},
+					RateLimits: []*envoy_api_v2_route.RateLimit{{
+						Actions: []*envoy_api_v2_route.RateLimit_Action{{
+							ActionSpecifier: &envoy_api_v2_route.RateLimit_Action_GenericKey_{
+								GenericKey: &envoy_api_v2_route.RateLimit_Action_GenericKey{
+									DescriptorValue: "kuard",
+								},
+							},
+						}, {
+							ActionSpecifier: &envoy_api_v2_route.RateLimit_Action_RequestHeaders_{
+								RequestHeaders: &envoy_api_v2_route.RateLimit_Action_RequestHeaders{
+									HeaderName:    "X-Tenant",
+									DescriptorKey: "tenant",
+								},
+							},
+						}},
+					}},
+				},
+			},
+		},
+	)
+	vhost.RateLimits = []*envoy_api_v2_route.RateLimit{{
+		Actions: []*envoy_api_v2_route.RateLimit_Action{{
+			ActionSpecifier: &envoy_api_v2_route.RateLimit_Action_RemoteAddress_{
+				RemoteAddress: &envoy_api_v2_route.RateLimit_Action_RemoteAddress{},
+			},
+		}},
+	}}
+
+	c.Request(routeType, contour.ENVOY_HTTP_LISTENER).Equals(&v2.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy.RouteConfiguration(contour.ENVOY_HTTP_LISTENER, vhost),
+		),
+	})
+
+	// The rate limit service cluster is programmed even
+	// though nothing routes to it.
+	c.Request(clusterType, "extension/default/ratelimit").Equals(&v2.DiscoveryResponse{
+		TypeUrl: clusterType,
+		Resources: resources(t,
+			envoy.ExtensionCluster(&dag.ExtensionCluster{
+				Name:     "extension/default/ratelimit",
+				Protocol: "h2c",
+				TimeoutPolicy: dag.TimeoutPolicy{
+					ResponseTimeout: timeout.DurationSetting(100 * time.Millisecond),
+				},
+			}),
+		),
+	})
+}
+
+func TestGlobalRateLimitingWithoutService(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+
+	global := &projcontour.RateLimitPolicy{
+		Global: &projcontour.GlobalRateLimitPolicy{
+			Descriptors: []projcontour.RateLimitDescriptor{{
+				Entries: []projcontour.RateLimitDescriptorEntry{{
+					RemoteAddress: &projcontour.RemoteAddressDescriptor{},
+				}},
+			}},
+		},
+	}
+
+	proxy := fixture.NewProxy("proxy").
+		WithSpec(projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn:            "example.com",
+				RateLimitPolicy: global,
+			},
+			Routes: []projcontour.Route{{
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		})
+	rh.OnAdd(proxy)
+
+	c.Status(proxy).Like(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   "Spec.VirtualHost.RateLimitPolicy.Global: no global rate limit service is configured",
+	})
+
+	route := proxy.DeepCopy()
+	route.Spec.VirtualHost.RateLimitPolicy = nil
+	route.Spec.Routes[0].RateLimitPolicy = global
+	rh.OnUpdate(proxy, route)
+
+	c.Status(route).Like(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   "route.rateLimitPolicy.global: no global rate limit service is configured",
+	})
+
+	c.Request(listenerType, contour.ENVOY_HTTP_LISTENER).Equals(&v2.DiscoveryResponse{
+		TypeUrl: listenerType,
+	})
+}
+
+func TestLocalRateLimiting(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+
+	proxy := fixture.NewProxy("proxy").
+		WithSpec(projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn: "example.com",
+				RateLimitPolicy: &projcontour.RateLimitPolicy{
+					Local: &projcontour.LocalRateLimitPolicy{
+						Requests: 100,
+						Unit:     "minute",
+					},
+				},
+			},
+			Routes: []projcontour.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/login")),
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+				RateLimitPolicy: &projcontour.RateLimitPolicy{
+					Local: &projcontour.LocalRateLimitPolicy{
+						Requests:           5,
+						Unit:               "second",
+						Burst:              5,
+						ResponseStatusCode: 503,
+					},
+				},
+			}},
+		})
+	rh.OnAdd(proxy)
+
+	c.Status(proxy).Like(projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid})
+
+	c.Request(listenerType, contour.ENVOY_HTTP_LISTENER).Equals(&v2.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			&v2.Listener{
+				Name:          contour.ENVOY_HTTP_LISTENER,
+				Address:       envoy.SocketAddress("0.0.0.0", 8080),
+				SocketOptions: envoy.TCPKeepaliveSocketOptions(),
+				FilterChains: envoy.FilterChains(envoy.HTTPConnectionManagerBuilder().
+					AddFilter(envoy.FilterLocalRateLimit()).
+					DefaultFilters().
+					RouteConfigName(contour.ENVOY_HTTP_LISTENER).
+					MetricsPrefix(contour.ENVOY_HTTP_LISTENER).
+					AccessLoggers(envoy.FileAccessLogEnvoy(contour.DEFAULT_HTTP_ACCESS_LOG)).
+					Get(),
+				),
+			}),
+	})
+
+	vhost := envoy.VirtualHost("example.com",
+		&envoy_api_v2_route.Route{
+			Match:  routePrefix("/login"),
+			Action: routeCluster("default/kuard/8080/da39a3ee5e"),
+			TypedPerFilterConfig: map[string]*any.Any{
+				envoy.LocalRateLimitFilterName: envoy.LocalRateLimitConfig(&dag.LocalRateLimitPolicy{
+					MaxTokens:          10,
+					TokensPerFill:      5,
+					FillInterval:       time.Second,
+					ResponseStatusCode: 503,
+				}),
+			},
+		},
+		&envoy_api_v2_route.Route{
+			Match:  routePrefix("/"),
+			Action: routeCluster("default/kuard/8080/da39a3ee5e"),
+		},
+	)
+	vhost.TypedPerFilterConfig = map[string]*any.Any{
+		envoy.LocalRateLimitFilterName: envoy.LocalRateLimitConfig(&dag.LocalRateLimitPolicy{
+			MaxTokens:     100,
+			TokensPerFill: 100,
+			FillInterval:  time.Minute,
+		}),
+	}
+
+	c.Request(routeType, contour.ENVOY_HTTP_LISTENER).Equals(&v2.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy.RouteConfiguration(contour.ENVOY_HTTP_LISTENER, vhost),
+		),
+	})
+
+	invalid := proxy.DeepCopy()
+	invalid.Spec.Routes[1].RateLimitPolicy.Local.Unit = "day"
+	rh.OnUpdate(proxy, invalid)
+
+	c.Status(invalid).Like(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   "route.rateLimitPolicy is invalid: local.unit must be one of 'second', 'minute', or 'hour'",
+	})
+}
@@ -0,0 +1,135 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuretests
+
+import (
+	"testing"
+
+	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/envoy"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestRedirectAndDirectResponse(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+
+	proxy := fixture.NewProxy("proxy").
+		WithSpec(projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn: "example.com",
+			},
+			Routes: []projcontour.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/old")),
+				RequestRedirectPolicy: &projcontour.HTTPRequestRedirectPolicy{
+					Hostname:   "new.example.com",
+					Prefix:     "/new",
+					StatusCode: 301,
+				},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/blocked")),
+				DirectResponsePolicy: &projcontour.HTTPDirectResponsePolicy{
+					StatusCode: 404,
+				},
+			}, {
+				Conditions: matchconditions(prefixMatchCondition("/maintenance")),
+				DirectResponsePolicy: &projcontour.HTTPDirectResponsePolicy{
+					StatusCode: 503,
+					Body:       "down for maintenance",
+				},
+			}},
+		})
+	rh.OnAdd(proxy)
+
+	c.Status(proxy).Like(projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid})
+
+	c.Request(routeType, contour.ENVOY_HTTP_LISTENER).Equals(&v2.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy.RouteConfiguration(contour.ENVOY_HTTP_LISTENER,
+				envoy.VirtualHost("example.com",
+					&envoy_api_v2_route.Route{
+						Match: routePrefix("/old"),
+						Action: envoy.RouteRedirect(&dag.Redirect{
+							Hostname:   "new.example.com",
+							Prefix:     "/new",
+							StatusCode: 301,
+						}),
+					},
+					&envoy_api_v2_route.Route{
+						Match:  routePrefix("/maintenance"),
+						Action: envoy.RouteDirectResponse(&dag.DirectResponse{StatusCode: 503, Body: "down for maintenance"}),
+					},
+					&envoy_api_v2_route.Route{
+						Match:  routePrefix("/blocked"),
+						Action: envoy.RouteDirectResponse(&dag.DirectResponse{StatusCode: 404}),
+					},
+					&envoy_api_v2_route.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/kuard/8080/da39a3ee5e"),
+					},
+				),
+			),
+		),
+	})
+
+	// A route must not have both services and an action.
+	both := proxy.DeepCopy()
+	both.Spec.Routes[1].Services = []projcontour.Service{{
+		Name: "kuard",
+		Port: 8080,
+	}}
+	rh.OnUpdate(proxy, both)
+
+	c.Status(both).Like(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   "route.services cannot be set when route.requestRedirectPolicy is set",
+	})
+
+	// A route needs either services or an action.
+	neither := proxy.DeepCopy()
+	neither.Spec.Routes[2].DirectResponsePolicy = nil
+	rh.OnUpdate(both, neither)
+
+	c.Status(neither).Like(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   "route.services must have at least one entry",
+	})
+
+	// Invalid policies are reported.
+	invalid := proxy.DeepCopy()
+	invalid.Spec.Routes[3].DirectResponsePolicy.StatusCode = 100
+	rh.OnUpdate(neither, invalid)
+
+	c.Status(invalid).Like(projcontour.HTTPProxyStatus{
+		CurrentStatus: k8s.StatusInvalid,
+		Description:   "route.directResponsePolicy is invalid: statusCode must be in the 200-599 range",
+	})
+}
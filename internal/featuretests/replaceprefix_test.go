@@ -105,6 +105,7 @@ func basic(t *testing.T) {
 	}).Status(vhost).Equals(projcontour.HTTPProxyStatus{
 		CurrentStatus: k8s.StatusInvalid,
 		Description:   "ambiguous prefix replacement",
+		Conditions:    invalidCondition("ambiguous prefix replacement"),
 	})
 
 	// The replacement isn't ambiguous any more because only one of the prefixes matches.
@@ -156,6 +157,7 @@ func basic(t *testing.T) {
 	}).Status(vhost).Equals(projcontour.HTTPProxyStatus{
 		CurrentStatus: k8s.StatusInvalid,
 		Description:   "duplicate replacement prefix '/foo'",
+		Conditions:    invalidCondition("duplicate replacement prefix '/foo'"),
 	})
 
 	// The "/api" prefix should have precedence over the empty prefix.
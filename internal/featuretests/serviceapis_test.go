@@ -0,0 +1,141 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuretests
+
+import (
+	"testing"
+
+	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	"github.com/projectcontour/contour/internal/assert"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/envoy"
+	"github.com/projectcontour/contour/internal/fixture"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	serviceapis "sigs.k8s.io/service-apis/api/v1alpha1"
+)
+
+func TestServiceAPIsGateway(t *testing.T) {
+	rh, c, done := setup(t)
+	defer done()
+
+	rh.OnAdd(fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+
+	rh.OnAdd(&serviceapis.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "contour",
+		},
+		Spec: serviceapis.GatewayClassSpec{
+			Controller: dag.DefaultGatewayController,
+		},
+	})
+
+	path := "/"
+	route := &serviceapis.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "route",
+			Namespace: "default",
+		},
+		Spec: serviceapis.HTTPRouteSpec{
+			Hosts: []serviceapis.HTTPRouteHost{{
+				Hostnames: []string{"example.com"},
+				Rules: []serviceapis.HTTPRouteRule{{
+					Match: &serviceapis.HTTPRouteMatch{
+						PathType: serviceapis.PathTypePrefix,
+						Path:     &path,
+					},
+					Action: &serviceapis.HTTPRouteAction{
+						ForwardTo: &v1.TypedLocalObjectReference{
+							Kind: "Service",
+							Name: "kuard",
+						},
+					},
+				}},
+			}},
+		},
+	}
+	rh.OnAdd(route)
+
+	group := serviceapis.GroupVersion.Group
+	gw := &serviceapis.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "gateway",
+			Namespace: "default",
+		},
+		Spec: serviceapis.GatewaySpec{
+			Class: "contour",
+			Listeners: []serviceapis.Listener{{
+				Name: "http",
+			}},
+			Routes: []v1.TypedLocalObjectReference{{
+				APIGroup: &group,
+				Kind:     "HTTPRoute",
+				Name:     "route",
+			}},
+		},
+	}
+	rh.OnAdd(gw)
+
+	c.Request(routeType, contour.ENVOY_HTTP_LISTENER).Equals(&v2.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy.RouteConfiguration(contour.ENVOY_HTTP_LISTENER,
+				envoy.VirtualHost("example.com",
+					&envoy_api_v2_route.Route{
+						Match:  routePrefix("/"),
+						Action: routeCluster("default/kuard/8080/da39a3ee5e"),
+					},
+				),
+			),
+		),
+	})
+
+	obj, err := c.statusCache.GetServiceAPIStatus(route)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []v1.ObjectReference{{
+		APIVersion: serviceapis.GroupVersion.String(),
+		Kind:       "Gateway",
+		Namespace:  "default",
+		Name:       "gateway",
+	}}, obj.(*serviceapis.HTTPRoute).Status.Gateways)
+
+	// Removing the Service makes the route invalid, and
+	// the error is reported on the Gateway.
+	rh.OnDelete(fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+
+	c.Request(routeType, contour.ENVOY_HTTP_LISTENER).Equals(&v2.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy.RouteConfiguration(contour.ENVOY_HTTP_LISTENER),
+		),
+	})
+
+	obj, err = c.statusCache.GetServiceAPIStatus(gw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, serviceapis.GatewayCondition{
+		Type:    serviceapis.ConditionInvalidRoutes,
+		Status:  v1.ConditionTrue,
+		Reason:  string(serviceapis.ConditionInvalidRoutes),
+		Message: `HTTPRoute "route": rules[0]: service "default/kuard" not found`,
+	}, obj.(*serviceapis.Gateway).Status.Conditions[2])
+}
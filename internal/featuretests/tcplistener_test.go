@@ -0,0 +1,90 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuretests
+
+import (
+	"testing"
+
+	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/envoy"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestTCPListener(t *testing.T) {
+	rh, c, done := setup(t, func(eh *contour.EventHandler) {
+		eh.Builder.ListenerPortRange = &dag.PortRange{Min: 5000, Max: 6000}
+	})
+	defer done()
+
+	svc := fixture.NewService("postgres").
+		WithPorts(v1.ServicePort{Name: "postgres", Port: 5432})
+	rh.OnAdd(svc)
+
+	proxy := func(name string, port int) *projcontour.HTTPProxy {
+		return fixture.NewProxy(name).WithSpec(projcontour.HTTPProxySpec{
+			Listener: &projcontour.Listener{
+				Port: port,
+			},
+			TCPProxy: &projcontour.TCPProxy{
+				Services: []projcontour.Service{{
+					Name: svc.Name,
+					Port: 5432,
+				}},
+			},
+		})
+	}
+
+	p1 := proxy("postgres", 5432)
+	rh.OnAdd(p1)
+
+	c.Request(listenerType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			envoy.Listener("ingress_tcp_5432", "0.0.0.0", 5432, nil,
+				tcpproxy("ingress_tcp_5432", "default/postgres/5432/da39a3ee5e"),
+			),
+			staticListener(),
+		),
+		TypeUrl: listenerType,
+	}).Status(p1).Like(
+		projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid},
+	)
+
+	c.Request(clusterType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			cluster("default/postgres/5432/da39a3ee5e", "default/postgres/postgres", "default_postgres_5432"),
+		),
+		TypeUrl: clusterType,
+	})
+
+	// p2 is outside the permitted port range.
+	p2 := proxy("postgres", 7432)
+	rh.OnUpdate(p1, p2)
+
+	c.Request(listenerType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			staticListener(),
+		),
+		TypeUrl: listenerType,
+	}).Status(p2).Like(
+		projcontour.HTTPProxyStatus{
+			CurrentStatus: k8s.StatusInvalid,
+			Description:   "Spec.Listener port 7432 is outside the permitted range 5000-6000",
+		},
+	)
+}
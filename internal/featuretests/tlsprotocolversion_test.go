@@ -128,6 +128,9 @@ func TestTLSMinimumProtocolVersion(t *testing.T) {
 				envoy.DownstreamTLSContext(
 					&dag.Secret{Object: sec1},
 					envoy_api_v2_auth.TlsParameters_TLSv1_3,
+					envoy_api_v2_auth.TlsParameters_TLSv1_3,
+					nil,
+					nil,
 					nil,
 					"h2", "http/1.1"),
 				envoy.Filters(httpsFilterFor("kuard.example.com")),
@@ -0,0 +1,193 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuretests
+
+import (
+	"testing"
+
+	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	envoy_api_v2_route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/envoy"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestTracing(t *testing.T) {
+	collector := types.NamespacedName{Namespace: "tracing", Name: "jaeger-collector"}
+
+	rh, c, done := setup(t, func(eh *contour.EventHandler) {
+		eh.Builder.Tracing = &dag.TracingConfig{
+			Provider:     "zipkin",
+			Service:      collector,
+			Port:         9411,
+			SamplingRate: 10,
+			CustomTags: []dag.TracingCustomTag{{
+				TagName:       "tenant",
+				RequestHeader: "x-tenant",
+			}},
+		}
+		eh.Builder.Source.ConfiguredServices = []types.NamespacedName{collector}
+	})
+	defer done()
+
+	rh.OnAdd(fixture.NewService("kuard").
+		WithPorts(v1.ServicePort{Port: 8080, TargetPort: intstr.FromInt(8080)}))
+	rh.OnAdd(fixture.NewService("tracing/jaeger-collector").
+		WithPorts(v1.ServicePort{Port: 9411, TargetPort: intstr.FromInt(9411)}))
+
+	proxy := fixture.NewProxy("proxy").
+		WithSpec(projcontour.HTTPProxySpec{
+			VirtualHost: &projcontour.VirtualHost{
+				Fqdn: "example.com",
+			},
+			TracingPolicy: &projcontour.TracingPolicy{
+				SamplingRate: "100",
+			},
+			Routes: []projcontour.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/")),
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		})
+	rh.OnAdd(proxy)
+
+	c.Status(proxy).Like(projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid})
+
+	tracing := &dag.Tracing{
+		Provider: "zipkin",
+		Cluster: &dag.Cluster{
+			Upstream: &dag.Service{
+				Name:        "jaeger-collector",
+				Namespace:   "tracing",
+				ServicePort: v1.ServicePort{Port: 9411},
+			},
+		},
+		SamplingRate: 10,
+		CustomTags: []dag.TracingCustomTag{{
+			TagName:       "tenant",
+			RequestHeader: "x-tenant",
+		}},
+	}
+
+	c.Request(listenerType, contour.ENVOY_HTTP_LISTENER).Equals(&v2.DiscoveryResponse{
+		TypeUrl: listenerType,
+		Resources: resources(t,
+			&v2.Listener{
+				Name:          contour.ENVOY_HTTP_LISTENER,
+				Address:       envoy.SocketAddress("0.0.0.0", 8080),
+				SocketOptions: envoy.TCPKeepaliveSocketOptions(),
+				FilterChains: envoy.FilterChains(envoy.HTTPConnectionManagerBuilder().
+					DefaultFilters().
+					RouteConfigName(contour.ENVOY_HTTP_LISTENER).
+					MetricsPrefix(contour.ENVOY_HTTP_LISTENER).
+					AccessLoggers(envoy.FileAccessLogEnvoy(contour.DEFAULT_HTTP_ACCESS_LOG)).
+					Tracing(envoy.TracingConfig(tracing)).
+					Get(),
+				),
+			}),
+	})
+
+	c.Request(routeType, contour.ENVOY_HTTP_LISTENER).Equals(&v2.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy.RouteConfiguration(contour.ENVOY_HTTP_LISTENER,
+				envoy.VirtualHost("example.com",
+					&envoy_api_v2_route.Route{
+						Match:   routePrefix("/"),
+						Action:  routeCluster("default/kuard/8080/da39a3ee5e"),
+						Tracing: envoy.RouteTracing(&dag.TracingPolicy{SamplingRate: 100}),
+					},
+				),
+			),
+		),
+	})
+
+	// The trace collector cluster is programmed even
+	// though nothing routes to it.
+	c.Request(clusterType, "tracing/jaeger-collector/9411/da39a3ee5e").Equals(&v2.DiscoveryResponse{
+		TypeUrl: clusterType,
+		Resources: resources(t,
+			cluster("tracing/jaeger-collector/9411/da39a3ee5e", "tracing/jaeger-collector", "tracing_jaeger-collector_9411"),
+		),
+	})
+
+	// Included proxies use the tracing policy of the root,
+	// unless they have their own.
+	inherit := fixture.NewProxy("inherit").
+		WithSpec(projcontour.HTTPProxySpec{
+			Routes: []projcontour.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/inherit")),
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		})
+	override := fixture.NewProxy("override").
+		WithSpec(projcontour.HTTPProxySpec{
+			TracingPolicy: &projcontour.TracingPolicy{
+				SamplingRate: "50",
+			},
+			Routes: []projcontour.Route{{
+				Conditions: matchconditions(prefixMatchCondition("/override")),
+				Services: []projcontour.Service{{
+					Name: "kuard",
+					Port: 8080,
+				}},
+			}},
+		})
+	rh.OnAdd(inherit)
+	rh.OnAdd(override)
+
+	root := proxy.DeepCopy()
+	root.Spec.Includes = []projcontour.Include{{
+		Name: inherit.Name,
+	}, {
+		Name: override.Name,
+	}}
+	rh.OnUpdate(proxy, root)
+
+	c.Request(routeType, contour.ENVOY_HTTP_LISTENER).Equals(&v2.DiscoveryResponse{
+		TypeUrl: routeType,
+		Resources: resources(t,
+			envoy.RouteConfiguration(contour.ENVOY_HTTP_LISTENER,
+				envoy.VirtualHost("example.com",
+					&envoy_api_v2_route.Route{
+						Match:   routePrefix("/override"),
+						Action:  routeCluster("default/kuard/8080/da39a3ee5e"),
+						Tracing: envoy.RouteTracing(&dag.TracingPolicy{SamplingRate: 50}),
+					},
+					&envoy_api_v2_route.Route{
+						Match:   routePrefix("/inherit"),
+						Action:  routeCluster("default/kuard/8080/da39a3ee5e"),
+						Tracing: envoy.RouteTracing(&dag.TracingPolicy{SamplingRate: 100}),
+					},
+					&envoy_api_v2_route.Route{
+						Match:   routePrefix("/"),
+						Action:  routeCluster("default/kuard/8080/da39a3ee5e"),
+						Tracing: envoy.RouteTracing(&dag.TracingPolicy{SamplingRate: 100}),
+					},
+				),
+			),
+		),
+	})
+}
@@ -0,0 +1,124 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuretests
+
+import (
+	"testing"
+
+	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	"github.com/projectcontour/contour/internal/contour"
+	"github.com/projectcontour/contour/internal/dag"
+	"github.com/projectcontour/contour/internal/envoy"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/projectcontour/contour/internal/k8s"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestUDPListener(t *testing.T) {
+	rh, c, done := setup(t, func(eh *contour.EventHandler) {
+		eh.Builder.ListenerPortRange = &dag.PortRange{Min: 5000, Max: 6000}
+	})
+	defer done()
+
+	svc := fixture.NewService("coredns").
+		WithPorts(
+			v1.ServicePort{Name: "dns-tcp", Port: 53, Protocol: "TCP"},
+			v1.ServicePort{Name: "dns", Port: 53, Protocol: "UDP"},
+		)
+	rh.OnAdd(svc)
+
+	rh.OnAdd(endpoints("default", "coredns", v1.EndpointSubset{
+		Addresses: addresses("10.10.1.1"),
+		Ports: ports(
+			port("dns-tcp", 53),
+			v1.EndpointPort{Name: "dns", Port: 53, Protocol: "UDP"},
+		),
+	}))
+
+	p1 := fixture.NewProxy("dns").WithSpec(projcontour.HTTPProxySpec{
+		Listener: &projcontour.Listener{
+			Port: 5353,
+		},
+		UDPProxy: &projcontour.UDPProxy{
+			Service: projcontour.UDPProxyService{
+				Name: svc.Name,
+				Port: 53,
+			},
+		},
+	})
+	rh.OnAdd(p1)
+
+	udpCluster := &dag.Cluster{
+		Upstream: &dag.Service{
+			Name:        svc.Name,
+			Namespace:   svc.Namespace,
+			ServicePort: svc.Spec.Ports[1],
+		},
+	}
+
+	c.Request(listenerType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			envoy.UDPListener("ingress_udp_5353", "0.0.0.0", 5353,
+				envoy.UDPProxy("ingress_udp_5353", udpCluster),
+			),
+			staticListener(),
+		),
+		TypeUrl: listenerType,
+	}).Status(p1).Like(
+		projcontour.HTTPProxyStatus{CurrentStatus: k8s.StatusValid},
+	)
+
+	c.Request(clusterType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			cluster("default/coredns/53/36cf8bee2c", "default/coredns/dns", "default_coredns_53"),
+		),
+		TypeUrl: clusterType,
+	})
+
+	c.Request(endpointType, "default/coredns/dns").Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			envoy.ClusterLoadAssignment("default/coredns/dns",
+				envoy.UDPSocketAddress("10.10.1.1", 53),
+			),
+		),
+		TypeUrl: endpointType,
+	})
+
+	// p2 references a port that the Service does not have.
+	p2 := fixture.NewProxy("dns").WithSpec(projcontour.HTTPProxySpec{
+		Listener: &projcontour.Listener{
+			Port: 5353,
+		},
+		UDPProxy: &projcontour.UDPProxy{
+			Service: projcontour.UDPProxyService{
+				Name: svc.Name,
+				Port: 5300,
+			},
+		},
+	})
+	rh.OnUpdate(p1, p2)
+
+	c.Request(listenerType).Equals(&v2.DiscoveryResponse{
+		Resources: resources(t,
+			staticListener(),
+		),
+		TypeUrl: listenerType,
+	}).Status(p2).Like(
+		projcontour.HTTPProxyStatus{
+			CurrentStatus: k8s.StatusInvalid,
+			Description:   `Spec.UDPProxy: unresolved service reference: UDP port 5300 on service "default/coredns" not matched`,
+		},
+	)
+}
@@ -17,12 +17,15 @@ import (
 	"github.com/google/go-cmp/cmp"
 	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
 	"k8s.io/api/networking/v1beta1"
+	serviceapis "sigs.k8s.io/service-apis/api/v1alpha1"
 )
 
 // IsStatusEqual checks that two objects of supported Kubernetes types
 // have equivalent Status structs.
 // Currently supports:
 // networking.k8s.io/ingress/v1beta1
+// projectcontour.io/httpproxy/v1
+// networking.x.k8s.io/{gatewayclass,gateway,httproute}/v1alpha1
 func IsStatusEqual(objA, objB interface{}) bool {
 
 	switch a := objA.(type) {
@@ -40,6 +43,27 @@ func IsStatusEqual(objA, objB interface{}) bool {
 				return true
 			}
 		}
+	case *serviceapis.GatewayClass:
+		switch b := objB.(type) {
+		case *serviceapis.GatewayClass:
+			if cmp.Equal(a.Status, b.Status) {
+				return true
+			}
+		}
+	case *serviceapis.Gateway:
+		switch b := objB.(type) {
+		case *serviceapis.Gateway:
+			if cmp.Equal(a.Status, b.Status) {
+				return true
+			}
+		}
+	case *serviceapis.HTTPRoute:
+		switch b := objB.(type) {
+		case *serviceapis.HTTPRoute:
+			if cmp.Equal(a.Status, b.Status) {
+				return true
+			}
+		}
 	}
 
 	return false
@@ -38,7 +38,8 @@ func DefaultResources() []schema.GroupVersionResource {
 	}
 }
 
-// +kubebuilder:rbac:groups="networking.k8s.io",resources=gatewayclasses;gateways;httproutes;tcproutes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="networking.x.k8s.io",resources=gatewayclasses;gateways;httproutes;tcproutes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="networking.x.k8s.io",resources=gatewayclasses/status;gateways/status;httproutes/status,verbs=create;get;update
 
 // ServiceAPIResources ...
 func ServiceAPIResources() []schema.GroupVersionResource {
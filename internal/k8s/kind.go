@@ -18,6 +18,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	serviceapis "sigs.k8s.io/service-apis/api/v1alpha1"
 )
 
 // KindOf returns the kind string for the given Kubernetes object.
@@ -39,6 +40,14 @@ func KindOf(obj interface{}) string {
 		return "HTTPProxy"
 	case *projectcontour.TLSCertificateDelegation:
 		return "TLSCertificateDelegation"
+	case *serviceapis.GatewayClass:
+		return "GatewayClass"
+	case *serviceapis.Gateway:
+		return "Gateway"
+	case *serviceapis.HTTPRoute:
+		return "HTTPRoute"
+	case *serviceapis.TcpRoute:
+		return "TcpRoute"
 	case *unstructured.Unstructured:
 		return obj.GetKind()
 	default:
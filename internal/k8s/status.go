@@ -18,6 +18,8 @@ import (
 	"fmt"
 
 	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	serviceapis "sigs.k8s.io/service-apis/api/v1alpha1"
 )
 
 const (
@@ -28,18 +30,27 @@ const (
 
 // StatusClient updates the HTTPProxyStatus on a Kubernetes object.
 type StatusClient interface {
-	SetStatus(status string, desc string, obj interface{}) error
+	SetStatus(status string, desc string, errors []projcontour.SubCondition, obj interface{}) error
 	GetStatus(obj interface{}) (*projcontour.HTTPProxyStatus, error)
+
+	// SetServiceAPIStatus writes the status of the given
+	// GatewayClass, Gateway or HTTPRoute back to the API server.
+	SetServiceAPIStatus(obj interface{}) error
 }
 
 // StatusCacher keeps a cache of the latest status updates for Kubernetes objects.
 type StatusCacher struct {
-	objectStatus map[string]projcontour.HTTPProxyStatus
+	objectStatus      map[string]projcontour.HTTPProxyStatus
+	serviceAPIObjects map[string]interface{}
 }
 
 func objectKey(obj interface{}) string {
-	switch obj := obj.(type) {
-	case *projcontour.HTTPProxy:
+	switch obj.(type) {
+	case *projcontour.HTTPProxy,
+		*serviceapis.GatewayClass,
+		*serviceapis.Gateway,
+		*serviceapis.HTTPRoute:
+		obj := obj.(Object)
 		return fmt.Sprintf("%s/%s/%s",
 			KindOf(obj),
 			obj.GetObjectMeta().GetNamespace(),
@@ -53,7 +64,10 @@ func objectKey(obj interface{}) string {
 // the status cache.
 func (c *StatusCacher) IsCacheable(obj interface{}) bool {
 	switch obj.(type) {
-	case *projcontour.HTTPProxy:
+	case *projcontour.HTTPProxy,
+		*serviceapis.GatewayClass,
+		*serviceapis.Gateway,
+		*serviceapis.HTTPRoute:
 		return true
 	default:
 		return false
@@ -65,6 +79,9 @@ func (c *StatusCacher) Delete(obj interface{}) {
 	if c.objectStatus != nil {
 		delete(c.objectStatus, objectKey(obj))
 	}
+	if c.serviceAPIObjects != nil {
+		delete(c.serviceAPIObjects, objectKey(obj))
+	}
 }
 
 // GetStatus returns the status (if any) for this given object.
@@ -81,8 +98,9 @@ func (c *StatusCacher) GetStatus(obj interface{}) (*projcontour.HTTPProxyStatus,
 	return &s, nil
 }
 
-// SetStatus sets the HTTPProxy status field to an Valid or Invalid status
-func (c *StatusCacher) SetStatus(status, desc string, obj interface{}) error {
+// SetStatus sets the HTTPProxy status field to an Valid or Invalid status,
+// and its Valid condition.
+func (c *StatusCacher) SetStatus(status, desc string, errors []projcontour.SubCondition, obj interface{}) error {
 	if c.objectStatus == nil {
 		c.objectStatus = make(map[string]projcontour.HTTPProxyStatus)
 	}
@@ -90,11 +108,35 @@ func (c *StatusCacher) SetStatus(status, desc string, obj interface{}) error {
 	c.objectStatus[objectKey(obj)] = projcontour.HTTPProxyStatus{
 		CurrentStatus: status,
 		Description:   desc,
+		Conditions: []projcontour.DetailedCondition{
+			validCondition(status, desc, errors, 0),
+		},
 	}
 
 	return nil
 }
 
+// GetServiceAPIStatus returns the last Service APIs object (if any)
+// whose status was set for the given object.
+func (c *StatusCacher) GetServiceAPIStatus(obj interface{}) (interface{}, error) {
+	o, ok := c.serviceAPIObjects[objectKey(obj)]
+	if !ok {
+		return nil, fmt.Errorf("no status for key '%s'", objectKey(obj))
+	}
+
+	return o, nil
+}
+
+// SetServiceAPIStatus caches the given Service APIs object.
+func (c *StatusCacher) SetServiceAPIStatus(obj interface{}) error {
+	if c.serviceAPIObjects == nil {
+		c.serviceAPIObjects = make(map[string]interface{})
+	}
+
+	c.serviceAPIObjects[objectKey(obj)] = obj
+	return nil
+}
+
 // StatusWriter updates the object's HTTPProxyStatus field.
 type StatusWriter struct {
 	Updater StatusUpdater
@@ -105,8 +147,9 @@ func (irs *StatusWriter) GetStatus(obj interface{}) (*projcontour.HTTPProxyStatu
 	return nil, errors.New("not implemented")
 }
 
-// SetStatus sets the HTTPProxy status field to an Valid or Invalid status
-func (irs *StatusWriter) SetStatus(status, desc string, existing interface{}) error {
+// SetStatus sets the HTTPProxy status field to an Valid or Invalid status,
+// and updates its Valid condition.
+func (irs *StatusWriter) SetStatus(status, desc string, errors []projcontour.SubCondition, existing interface{}) error {
 	switch exist := existing.(type) {
 	case *projcontour.HTTPProxy:
 		// StatusUpdateWriters only apply an update if required, so
@@ -120,6 +163,8 @@ func (irs *StatusWriter) SetStatus(status, desc string, existing interface{}) er
 					dco := o.DeepCopy()
 					dco.Status.CurrentStatus = status
 					dco.Status.Description = desc
+					dco.Status.Conditions = setValidCondition(dco.Status.Conditions,
+						validCondition(status, desc, errors, o.Generation))
 					return dco
 				default:
 					panic(fmt.Sprintf("Unsupported object %s/%s in status Address mutator",
@@ -130,3 +175,141 @@ func (irs *StatusWriter) SetStatus(status, desc string, existing interface{}) er
 	}
 	return nil
 }
+
+// SetServiceAPIStatus copies the status of the given GatewayClass, Gateway
+// or HTTPRoute to the stored object. The transition time of conditions
+// whose status did not change is preserved.
+func (irs *StatusWriter) SetServiceAPIStatus(updated interface{}) error {
+	switch u := updated.(type) {
+	case *serviceapis.GatewayClass:
+		irs.Updater.Update(u.Name, u.Namespace,
+			serviceapis.GroupVersion.WithResource("gatewayclasses"),
+			StatusMutatorFunc(func(obj interface{}) interface{} {
+				o, ok := obj.(*serviceapis.GatewayClass)
+				if !ok {
+					panic(fmt.Sprintf("Unsupported object %s/%s in GatewayClass status mutator", u.Namespace, u.Name))
+				}
+				dco := o.DeepCopy()
+				dco.Status = *u.Status.DeepCopy()
+				setGatewayClassConditionTimes(dco.Status.Conditions, o.Status.Conditions)
+				return dco
+			}))
+	case *serviceapis.Gateway:
+		irs.Updater.Update(u.Name, u.Namespace,
+			serviceapis.GroupVersion.WithResource("gateways"),
+			StatusMutatorFunc(func(obj interface{}) interface{} {
+				o, ok := obj.(*serviceapis.Gateway)
+				if !ok {
+					panic(fmt.Sprintf("Unsupported object %s/%s in Gateway status mutator", u.Namespace, u.Name))
+				}
+				dco := o.DeepCopy()
+				dco.Status = *u.Status.DeepCopy()
+				setGatewayConditionTimes(dco.Status.Conditions, o.Status.Conditions)
+				for i := range dco.Status.Listeners {
+					var existing []serviceapis.ListenerCondition
+					for _, l := range o.Status.Listeners {
+						if l.Name == dco.Status.Listeners[i].Name {
+							existing = l.Conditions
+						}
+					}
+					setListenerConditionTimes(dco.Status.Listeners[i].Conditions, existing)
+				}
+				return dco
+			}))
+	case *serviceapis.HTTPRoute:
+		irs.Updater.Update(u.Name, u.Namespace,
+			serviceapis.GroupVersion.WithResource("httproutes"),
+			StatusMutatorFunc(func(obj interface{}) interface{} {
+				o, ok := obj.(*serviceapis.HTTPRoute)
+				if !ok {
+					panic(fmt.Sprintf("Unsupported object %s/%s in HTTPRoute status mutator", u.Namespace, u.Name))
+				}
+				dco := o.DeepCopy()
+				dco.Status = *u.Status.DeepCopy()
+				return dco
+			}))
+	default:
+		return fmt.Errorf("unsupported Service APIs object %T", updated)
+	}
+	return nil
+}
+
+// validCondition returns the Valid condition of an HTTPProxy
+// with the given status.
+func validCondition(status, desc string, errors []projcontour.SubCondition, generation int64) projcontour.DetailedCondition {
+	cond := projcontour.DetailedCondition{
+		Condition: projcontour.Condition{
+			Type:               projcontour.ValidConditionType,
+			Status:             projcontour.ConditionFalse,
+			ObservedGeneration: generation,
+			Message:            desc,
+		},
+		Errors: errors,
+	}
+
+	switch status {
+	case StatusValid:
+		cond.Status = projcontour.ConditionTrue
+		cond.Reason = "Valid"
+	case StatusOrphaned:
+		cond.Reason = "Orphaned"
+	default:
+		cond.Reason = "Invalid"
+	}
+	return cond
+}
+
+// setValidCondition replaces the Valid condition in conditions with
+// cond. Other conditions are left untouched, and the transition time
+// is preserved if the status of the condition did not change.
+func setValidCondition(conditions []projcontour.DetailedCondition, cond projcontour.DetailedCondition) []projcontour.DetailedCondition {
+	cond.LastTransitionTime = metav1.Now()
+
+	var updated []projcontour.DetailedCondition
+	for _, c := range conditions {
+		if c.Type != cond.Type {
+			updated = append(updated, c)
+			continue
+		}
+		if c.Status == cond.Status {
+			cond.LastTransitionTime = c.LastTransitionTime
+		}
+	}
+	return append(updated, cond)
+}
+
+func setGatewayClassConditionTimes(conditions, existing []serviceapis.GatewayClassCondition) {
+	now := metav1.Now()
+	for i := range conditions {
+		conditions[i].LastTransitionTime = &now
+		for _, e := range existing {
+			if e.Type == conditions[i].Type && e.Status == conditions[i].Status && e.LastTransitionTime != nil {
+				conditions[i].LastTransitionTime = e.LastTransitionTime.DeepCopy()
+			}
+		}
+	}
+}
+
+func setGatewayConditionTimes(conditions, existing []serviceapis.GatewayCondition) {
+	now := metav1.Now()
+	for i := range conditions {
+		conditions[i].LastTransitionTime = now
+		for _, e := range existing {
+			if e.Type == conditions[i].Type && e.Status == conditions[i].Status {
+				conditions[i].LastTransitionTime = e.LastTransitionTime
+			}
+		}
+	}
+}
+
+func setListenerConditionTimes(conditions, existing []serviceapis.ListenerCondition) {
+	now := metav1.Now()
+	for i := range conditions {
+		conditions[i].LastTransitionTime = now
+		for _, e := range existing {
+			if e.Type == conditions[i].Type && e.Status == conditions[i].Status {
+				conditions[i].LastTransitionTime = e.LastTransitionTime
+			}
+		}
+	}
+}
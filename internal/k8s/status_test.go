@@ -17,18 +17,22 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/projectcontour/contour/internal/assert"
 
 	projcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
 	projectcontour "github.com/projectcontour/contour/apis/projectcontour/v1"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	serviceapis "sigs.k8s.io/service-apis/api/v1alpha1"
 )
 
 func TestSetHTTPProxyStatus(t *testing.T) {
 	type testcase struct {
 		msg      string
 		desc     string
+		errors   []projcontour.SubCondition
 		existing *projectcontour.HTTPProxy
 		expected *projectcontour.HTTPProxy
 	}
@@ -46,7 +50,7 @@ func TestSetHTTPProxyStatus(t *testing.T) {
 
 			suc.AddObject(tc.existing.Name, tc.existing.Namespace, projcontour.HTTPProxyGVR, tc.existing)
 
-			if err := proxysw.SetStatus(tc.msg, tc.desc, tc.existing); err != nil {
+			if err := proxysw.SetStatus(tc.msg, tc.desc, tc.errors, tc.existing); err != nil {
 				t.Fatal(fmt.Errorf("unable to set proxy status: %s", err))
 			}
 
@@ -56,6 +60,19 @@ func TestSetHTTPProxyStatus(t *testing.T) {
 				return
 			}
 
+			// A condition that transitioned has the time of the
+			// update, so only check that it is set.
+			if p, ok := toProxy.(*projcontour.HTTPProxy); ok && tc.expected != nil {
+				for i := range p.Status.Conditions {
+					if i < len(tc.expected.Status.Conditions) && tc.expected.Status.Conditions[i].LastTransitionTime.IsZero() {
+						if p.Status.Conditions[i].LastTransitionTime.IsZero() {
+							t.Fatalf("condition %q has no transition time", p.Status.Conditions[i].Type)
+						}
+						p.Status.Conditions[i].LastTransitionTime = metav1.Time{}
+					}
+				}
+			}
+
 			assert.Equal(t, toProxy, tc.expected)
 
 			if toProxy == nil && tc.expected != nil {
@@ -64,6 +81,20 @@ func TestSetHTTPProxyStatus(t *testing.T) {
 		})
 	}
 
+	transitionTime := metav1.NewTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	validCondition := func(t metav1.Time) projcontour.DetailedCondition {
+		return projcontour.DetailedCondition{
+			Condition: projcontour.Condition{
+				Type:               projcontour.ValidConditionType,
+				Status:             projcontour.ConditionTrue,
+				LastTransitionTime: t,
+				Reason:             "Valid",
+				Message:            "this is a valid HTTPProxy",
+			},
+		}
+	}
+
 	run(t, "simple update", testcase{
 		msg:  "valid",
 		desc: "this is a valid HTTPProxy",
@@ -85,6 +116,9 @@ func TestSetHTTPProxyStatus(t *testing.T) {
 			Status: projcontour.HTTPProxyStatus{
 				CurrentStatus: "valid",
 				Description:   "this is a valid HTTPProxy",
+				Conditions: []projcontour.DetailedCondition{
+					validCondition(metav1.Time{}),
+				},
 			},
 		},
 	})
@@ -100,6 +134,9 @@ func TestSetHTTPProxyStatus(t *testing.T) {
 			Status: projcontour.HTTPProxyStatus{
 				CurrentStatus: "valid",
 				Description:   "this is a valid HTTPProxy",
+				Conditions: []projcontour.DetailedCondition{
+					validCondition(transitionTime),
+				},
 			},
 		},
 		expected: &projcontour.HTTPProxy{
@@ -110,6 +147,9 @@ func TestSetHTTPProxyStatus(t *testing.T) {
 			Status: projcontour.HTTPProxyStatus{
 				CurrentStatus: "valid",
 				Description:   "this is a valid HTTPProxy",
+				Conditions: []projcontour.DetailedCondition{
+					validCondition(transitionTime),
+				},
 			},
 		},
 	})
@@ -125,6 +165,22 @@ func TestSetHTTPProxyStatus(t *testing.T) {
 			Status: projcontour.HTTPProxyStatus{
 				CurrentStatus: "invalid",
 				Description:   "boo hiss",
+				Conditions: []projcontour.DetailedCondition{{
+					Condition: projcontour.Condition{
+						Type:               "example.com/Ready",
+						Status:             projcontour.ConditionTrue,
+						LastTransitionTime: transitionTime,
+						Reason:             "Ready",
+					},
+				}, {
+					Condition: projcontour.Condition{
+						Type:               projcontour.ValidConditionType,
+						Status:             projcontour.ConditionFalse,
+						LastTransitionTime: transitionTime,
+						Reason:             "Invalid",
+						Message:            "boo hiss",
+					},
+				}},
 			},
 		},
 		expected: &projcontour.HTTPProxy{
@@ -135,6 +191,60 @@ func TestSetHTTPProxyStatus(t *testing.T) {
 			Status: projcontour.HTTPProxyStatus{
 				CurrentStatus: "valid",
 				Description:   "this is a valid HTTPProxy",
+				Conditions: []projcontour.DetailedCondition{{
+					Condition: projcontour.Condition{
+						Type:               "example.com/Ready",
+						Status:             projcontour.ConditionTrue,
+						LastTransitionTime: transitionTime,
+						Reason:             "Ready",
+					},
+				},
+					validCondition(metav1.Time{}),
+				},
+			},
+		},
+	})
+
+	run(t, "invalid with errors", testcase{
+		msg:  "invalid",
+		desc: "Spec.VirtualHost.CORSPolicy: invalid allowOrigin",
+		errors: []projcontour.SubCondition{{
+			Type:    projcontour.ConditionTypeCORSError,
+			Status:  projcontour.ConditionTrue,
+			Reason:  "PolicyDidNotParse",
+			Message: "Spec.VirtualHost.CORSPolicy: invalid allowOrigin",
+		}},
+		existing: &projcontour.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "test",
+				Namespace:  "default",
+				Generation: 2,
+			},
+		},
+		expected: &projcontour.HTTPProxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "test",
+				Namespace:  "default",
+				Generation: 2,
+			},
+			Status: projcontour.HTTPProxyStatus{
+				CurrentStatus: "invalid",
+				Description:   "Spec.VirtualHost.CORSPolicy: invalid allowOrigin",
+				Conditions: []projcontour.DetailedCondition{{
+					Condition: projcontour.Condition{
+						Type:               projcontour.ValidConditionType,
+						Status:             projcontour.ConditionFalse,
+						ObservedGeneration: 2,
+						Reason:             "Invalid",
+						Message:            "Spec.VirtualHost.CORSPolicy: invalid allowOrigin",
+					},
+					Errors: []projcontour.SubCondition{{
+						Type:    projcontour.ConditionTypeCORSError,
+						Status:  projcontour.ConditionTrue,
+						Reason:  "PolicyDidNotParse",
+						Message: "Spec.VirtualHost.CORSPolicy: invalid allowOrigin",
+					}},
+				}},
 			},
 		},
 	})
@@ -168,3 +278,60 @@ func TestGetStatus(t *testing.T) {
 		expectedError:  errors.New("not implemented"),
 	})
 }
+
+func TestSetServiceAPIStatus(t *testing.T) {
+	gvr := serviceapis.GroupVersion.WithResource("gateways")
+	then := metav1.NewTime(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	existing := &serviceapis.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "gateway",
+			Namespace: "default",
+		},
+		Status: serviceapis.GatewayStatus{
+			Conditions: []serviceapis.GatewayCondition{{
+				Type:               serviceapis.ConditionNoSuchGatewayClass,
+				Status:             v1.ConditionFalse,
+				LastTransitionTime: then,
+			}, {
+				Type:               serviceapis.ConditionInvalidRoutes,
+				Status:             v1.ConditionFalse,
+				LastTransitionTime: then,
+			}},
+		},
+	}
+
+	updated := existing.DeepCopy()
+	updated.Status.Conditions = []serviceapis.GatewayCondition{{
+		Type:   serviceapis.ConditionNoSuchGatewayClass,
+		Status: v1.ConditionFalse,
+	}, {
+		Type:    serviceapis.ConditionInvalidRoutes,
+		Status:  v1.ConditionTrue,
+		Reason:  string(serviceapis.ConditionInvalidRoutes),
+		Message: "HTTPRoute \"route\": not found",
+	}}
+
+	suc := &StatusUpdateCacher{}
+	suc.AddObject(existing.Name, existing.Namespace, gvr, existing)
+
+	sw := StatusWriter{Updater: suc}
+	if err := sw.SetServiceAPIStatus(updated); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := suc.GetObject(existing.Name, existing.Namespace, gvr).(*serviceapis.Gateway)
+	if !ok {
+		t.Fatalf("Did not get an updated Gateway")
+	}
+
+	conditions := got.Status.Conditions
+	assert.Equal(t, 2, len(conditions))
+
+	// The unchanged condition keeps its transition time.
+	assert.Equal(t, then, conditions[0].LastTransitionTime)
+
+	// The changed condition has a new transition time.
+	assert.Equal(t, v1.ConditionTrue, conditions[1].Status)
+	assert.Equal(t, true, conditions[1].LastTransitionTime.After(then.Time))
+}
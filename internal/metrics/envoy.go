@@ -0,0 +1,123 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"io"
+	"strings"
+
+	envoy_service_metrics_v2 "github.com/envoyproxy/go-control-plane/envoy/service/metrics/v2"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	outlierEjectionsActiveStat   = "outlier_detection.ejections_active"
+	outlierEjectionsEnforcedStat = "outlier_detection.ejections_enforced_total"
+)
+
+// EnvoyMetricsService implements the Envoy metrics service. Envoys that
+// are bootstrapped with a metrics service stats sink stream their
+// statistics to it, and the outlier detection ejections of each cluster
+// are recorded in Metrics.
+type EnvoyMetricsService struct {
+	logrus.FieldLogger
+	Metrics *Metrics
+}
+
+// outlierEjections are the outlier detection statistics of a cluster.
+type outlierEjections struct {
+	active, enforced float64
+}
+
+// StreamMetrics receives the statistics of a single Envoy. The Envoy's
+// metrics are removed when the stream ends.
+func (s *EnvoyMetricsService) StreamMetrics(stream envoy_service_metrics_v2.MetricsService_StreamMetricsServer) error {
+	var envoy string
+	clusters := make(map[string]*outlierEjections)
+
+	defer func() {
+		for cluster := range clusters {
+			s.Metrics.DeleteOutlierEjections(envoy, cluster)
+		}
+	}()
+
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&envoy_service_metrics_v2.StreamMetricsResponse{})
+		}
+		if err != nil {
+			s.WithField("envoy", envoy).WithError(err).Debug("metrics stream terminated")
+			return err
+		}
+
+		// Only the first message of a stream identifies the Envoy.
+		if id := msg.GetIdentifier(); id != nil {
+			envoy = id.GetNode().GetId()
+		}
+
+		updated := make(map[string]bool)
+		for _, family := range msg.GetEnvoyMetrics() {
+			cluster, stat, ok := clusterStat(family.GetName())
+			if !ok || len(family.GetMetric()) == 0 {
+				continue
+			}
+
+			ej, ok := clusters[cluster]
+			if !ok {
+				ej = &outlierEjections{}
+				clusters[cluster] = ej
+			}
+
+			switch stat {
+			case outlierEjectionsActiveStat:
+				ej.active = metricValue(family.GetMetric()[0])
+			case outlierEjectionsEnforcedStat:
+				ej.enforced = metricValue(family.GetMetric()[0])
+			}
+			updated[cluster] = true
+		}
+
+		for cluster := range updated {
+			ej := clusters[cluster]
+			s.Metrics.SetOutlierEjections(envoy, cluster, ej.active, ej.enforced)
+		}
+	}
+}
+
+// clusterStat splits the name of an outlier detection statistic,
+// "cluster.<name>.outlier_detection.<stat>", into the cluster name
+// and the statistic.
+func clusterStat(name string) (string, string, bool) {
+	if !strings.HasPrefix(name, "cluster.") {
+		return "", "", false
+	}
+	name = strings.TrimPrefix(name, "cluster.")
+
+	for _, stat := range []string{outlierEjectionsActiveStat, outlierEjectionsEnforcedStat} {
+		if strings.HasSuffix(name, "."+stat) {
+			return strings.TrimSuffix(name, "."+stat), stat, true
+		}
+	}
+	return "", "", false
+}
+
+// metricValue returns the value of a gauge or counter.
+func metricValue(m *io_prometheus_client.Metric) float64 {
+	if g := m.GetGauge(); g != nil {
+		return g.GetValue()
+	}
+	return m.GetCounter().GetValue()
+}
@@ -0,0 +1,152 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"io"
+	"testing"
+
+	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	envoy_service_metrics_v2 "github.com/envoyproxy/go-control-plane/envoy/service/metrics/v2"
+	"github.com/golang/protobuf/proto"
+	"github.com/projectcontour/contour/internal/fixture"
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+)
+
+// metricsStream is a MetricsService_StreamMetricsServer that
+// returns msgs, and then calls done before returning io.EOF.
+type metricsStream struct {
+	grpc.ServerStream
+	msgs []*envoy_service_metrics_v2.StreamMetricsMessage
+	done func()
+}
+
+func (s *metricsStream) Recv() (*envoy_service_metrics_v2.StreamMetricsMessage, error) {
+	if len(s.msgs) == 0 {
+		s.done()
+		return nil, io.EOF
+	}
+	msg := s.msgs[0]
+	s.msgs = s.msgs[1:]
+	return msg, nil
+}
+
+func (s *metricsStream) SendAndClose(*envoy_service_metrics_v2.StreamMetricsResponse) error {
+	return nil
+}
+
+func gauge(name string, value float64) *io_prometheus_client.MetricFamily {
+	return &io_prometheus_client.MetricFamily{
+		Name: proto.String(name),
+		Type: io_prometheus_client.MetricType_GAUGE.Enum(),
+		Metric: []*io_prometheus_client.Metric{{
+			Gauge: &io_prometheus_client.Gauge{Value: proto.Float64(value)},
+		}},
+	}
+}
+
+func counter(name string, value float64) *io_prometheus_client.MetricFamily {
+	return &io_prometheus_client.MetricFamily{
+		Name: proto.String(name),
+		Type: io_prometheus_client.MetricType_COUNTER.Enum(),
+		Metric: []*io_prometheus_client.Metric{{
+			Counter: &io_prometheus_client.Counter{Value: proto.Float64(value)},
+		}},
+	}
+}
+
+// gatherValues returns the values of the named metric, keyed
+// by the envoy and cluster labels.
+func gatherValues(t *testing.T, r *prometheus.Registry, name string) map[string]float64 {
+	t.Helper()
+
+	families, err := r.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := make(map[string]float64)
+	for _, mf := range families {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			var envoy, cluster string
+			for _, l := range m.GetLabel() {
+				switch l.GetName() {
+				case "envoy":
+					envoy = l.GetValue()
+				case "cluster":
+					cluster = l.GetValue()
+				}
+			}
+			values[envoy+"/"+cluster] = m.GetGauge().GetValue()
+		}
+	}
+	return values
+}
+
+func TestEnvoyMetricsServiceOutlierEjections(t *testing.T) {
+	r := prometheus.NewRegistry()
+	svc := &EnvoyMetricsService{
+		FieldLogger: fixture.NewTestLogger(t),
+		Metrics:     NewMetrics(r),
+	}
+
+	stream := &metricsStream{
+		msgs: []*envoy_service_metrics_v2.StreamMetricsMessage{{
+			Identifier: &envoy_service_metrics_v2.StreamMetricsMessage_Identifier{
+				Node: &envoy_api_v2_core.Node{Id: "envoy-1"},
+			},
+			EnvoyMetrics: []*io_prometheus_client.MetricFamily{
+				gauge("cluster.default_kuard_80.outlier_detection.ejections_active", 1),
+				counter("cluster.default_kuard_80.outlier_detection.ejections_enforced_total", 3),
+				counter("cluster.default_kuard_80.upstream_rq_total", 100),
+				gauge("server.live", 1),
+			},
+		}, {
+			EnvoyMetrics: []*io_prometheus_client.MetricFamily{
+				gauge("cluster.default_kuard_80.outlier_detection.ejections_active", 2),
+				counter("cluster.default_kuard_80.outlier_detection.ejections_enforced_total", 5),
+			},
+		}},
+		done: func() {
+			assertValues(t, map[string]float64{"envoy-1/default_kuard_80": 2}, gatherValues(t, r, OutlierEjectionsActiveGauge))
+			assertValues(t, map[string]float64{"envoy-1/default_kuard_80": 5}, gatherValues(t, r, OutlierEjectionsEnforcedGauge))
+		},
+	}
+
+	if err := svc.StreamMetrics(stream); err != nil {
+		t.Fatal(err)
+	}
+
+	// The metrics of an Envoy are removed when its stream ends.
+	assertValues(t, map[string]float64{}, gatherValues(t, r, OutlierEjectionsActiveGauge))
+	assertValues(t, map[string]float64{}, gatherValues(t, r, OutlierEjectionsEnforcedGauge))
+}
+
+func assertValues(t *testing.T, want, got map[string]float64) {
+	t.Helper()
+
+	if len(want) != len(got) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	}
+}
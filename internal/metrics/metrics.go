@@ -37,6 +37,9 @@ type Metrics struct {
 	CacheHandlerOnUpdateSummary prometheus.Summary
 	EventHandlerOperations      *prometheus.CounterVec
 
+	outlierEjectionsActiveGauge   *prometheus.GaugeVec
+	outlierEjectionsEnforcedGauge *prometheus.GaugeVec
+
 	// Keep a local cache of metrics for comparison on updates
 	proxyMetricCache *RouteMetric
 }
@@ -67,6 +70,9 @@ const (
 	DAGRebuildGauge             = "contour_dagrebuild_timestamp"
 	cacheHandlerOnUpdateSummary = "contour_cachehandler_onupdate_duration_seconds"
 	eventHandlerOperations      = "contour_eventhandler_operation_total"
+
+	OutlierEjectionsActiveGauge   = "contour_outlier_detection_ejections_active"
+	OutlierEjectionsEnforcedGauge = "contour_outlier_detection_ejections_enforced"
 )
 
 // NewMetrics creates a new set of metrics and registers them with
@@ -139,6 +145,20 @@ func NewMetrics(registry *prometheus.Registry) *Metrics {
 			},
 			[]string{"op", "kind"},
 		),
+		outlierEjectionsActiveGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: OutlierEjectionsActiveGauge,
+				Help: "Number of upstream hosts currently ejected by outlier detection, by Envoy and cluster stat name. Reported by Envoys bootstrapped with --metrics-service.",
+			},
+			[]string{"envoy", "cluster"},
+		),
+		outlierEjectionsEnforcedGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: OutlierEjectionsEnforcedGauge,
+				Help: "Total number of upstream hosts ejected by outlier detection since Envoy started, by Envoy and cluster stat name. Reported by Envoys bootstrapped with --metrics-service.",
+			},
+			[]string{"envoy", "cluster"},
+		),
 	}
 	m.buildInfoGauge.WithLabelValues(build.Branch, build.Sha, build.Version).Set(1)
 	m.register(registry)
@@ -157,6 +177,8 @@ func (m *Metrics) register(registry *prometheus.Registry) {
 		m.dagRebuildGauge,
 		m.CacheHandlerOnUpdateSummary,
 		m.EventHandlerOperations,
+		m.outlierEjectionsActiveGauge,
+		m.outlierEjectionsEnforcedGauge,
 	)
 }
 
@@ -182,6 +204,8 @@ func (m *Metrics) Zero() {
 
 	m.EventHandlerOperations.WithLabelValues("add", "Secret").Inc()
 
+	m.SetOutlierEjections("", "", 0, 0)
+
 	prometheus.NewTimer(m.CacheHandlerOnUpdateSummary).ObserveDuration()
 }
 
@@ -240,6 +264,20 @@ func (m *Metrics) SetHTTPProxyMetric(metrics RouteMetric) {
 	}
 }
 
+// SetOutlierEjections records the number of active and enforced outlier
+// detection ejections that an Envoy reports for a cluster.
+func (m *Metrics) SetOutlierEjections(envoy, cluster string, active, enforced float64) {
+	m.outlierEjectionsActiveGauge.WithLabelValues(envoy, cluster).Set(active)
+	m.outlierEjectionsEnforcedGauge.WithLabelValues(envoy, cluster).Set(enforced)
+}
+
+// DeleteOutlierEjections removes the outlier detection ejections
+// that an Envoy reported for a cluster.
+func (m *Metrics) DeleteOutlierEjections(envoy, cluster string) {
+	m.outlierEjectionsActiveGauge.DeleteLabelValues(envoy, cluster)
+	m.outlierEjectionsEnforcedGauge.DeleteLabelValues(envoy, cluster)
+}
+
 // Handler returns a http Handler for a metrics endpoint.
 func Handler(registry *prometheus.Registry) http.Handler {
 	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
@@ -59,6 +59,26 @@ func (s headerMatcherSorter) Less(i, j int) bool {
 	panic("bad comparison")
 }
 
+// Sorts QueryParameterMatcher objects, first by the parameter name,
+// then by their matcher conditions (textually).
+type queryParameterMatcherSorter []*envoy_api_v2_route.QueryParameterMatcher
+
+func (s queryParameterMatcherSorter) Len() int      { return len(s) }
+func (s queryParameterMatcherSorter) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s queryParameterMatcherSorter) Less(i, j int) bool {
+	val := strings.Compare(s[i].Name, s[j].Name)
+	switch val {
+	case -1:
+		return true
+	case 1:
+		return false
+	case 0:
+		return proto.CompactTextString(s[i]) < proto.CompactTextString(s[j])
+	}
+
+	panic("bad comparison")
+}
+
 // longestRouteByHeaders compares the HeaderMatcher slices for lhs and rhs and
 // returns true if lhs is longer.
 func longestRouteByHeaders(lhs, rhs *envoy_api_v2_route.Route) bool {
@@ -78,16 +98,65 @@ func longestRouteByHeaders(lhs, rhs *envoy_api_v2_route.Route) bool {
 	return len(lhs.Match.Headers) > len(rhs.Match.Headers)
 }
 
+// longestRouteByQueryParameters compares the QueryParameterMatcher
+// slices for lhs and rhs and returns true if lhs is longer.
+func longestRouteByQueryParameters(lhs, rhs *envoy_api_v2_route.Route) bool {
+	if len(lhs.Match.QueryParameters) == len(rhs.Match.QueryParameters) {
+		pair := make([]*envoy_api_v2_route.QueryParameterMatcher, 2)
+
+		for i := 0; i < len(lhs.Match.QueryParameters); i++ {
+			pair[0] = lhs.Match.QueryParameters[i]
+			pair[1] = rhs.Match.QueryParameters[i]
+
+			if queryParameterMatcherSorter(pair).Less(0, 1) {
+				return true
+			}
+		}
+	}
+
+	return len(lhs.Match.QueryParameters) > len(rhs.Match.QueryParameters)
+}
+
+// longestRouteByConditions returns true if lhs has more header
+// conditions than rhs or, when both have equivalent header conditions,
+// more query parameter conditions.
+func longestRouteByConditions(lhs, rhs *envoy_api_v2_route.Route) bool {
+	if longestRouteByHeaders(lhs, rhs) {
+		return true
+	}
+	if longestRouteByHeaders(rhs, lhs) {
+		return false
+	}
+	return longestRouteByQueryParameters(lhs, rhs)
+}
+
 // Sorts the given Route slice in place. Routes are ordered first by
-// longest prefix (or regex), then by the length of the HeaderMatch
-// slice (if any). The HeaderMatch slice is also ordered by the matching
-// header name.
+// exact path, then by longest regex, then by longest prefix, then by
+// the length of the HeaderMatch slice (if any), then by the length of
+// the QueryParameterMatch slice (if any). The HeaderMatch and
+// QueryParameterMatch slices are also ordered by the matching name.
 type routeSorter []*envoy_api_v2_route.Route
 
 func (s routeSorter) Len() int      { return len(s) }
 func (s routeSorter) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
 func (s routeSorter) Less(i, j int) bool {
 	switch a := s[i].Match.PathSpecifier.(type) {
+	case *envoy_api_v2_route.RouteMatch_Path:
+		switch b := s[j].Match.PathSpecifier.(type) {
+		case *envoy_api_v2_route.RouteMatch_Path:
+			cmp := strings.Compare(a.Path, b.Path)
+			switch cmp {
+			case 1:
+				return true
+			case -1:
+				return false
+			default:
+				return longestRouteByConditions(s[i], s[j])
+			}
+		default:
+			// Exact paths sort before prefixes and regexes.
+			return true
+		}
 	case *envoy_api_v2_route.RouteMatch_Prefix:
 		switch b := s[j].Match.PathSpecifier.(type) {
 		case *envoy_api_v2_route.RouteMatch_Prefix:
@@ -99,7 +168,7 @@ func (s routeSorter) Less(i, j int) bool {
 			case -1:
 				return false
 			default:
-				return longestRouteByHeaders(s[i], s[j])
+				return longestRouteByConditions(s[i], s[j])
 			}
 		}
 	case *envoy_api_v2_route.RouteMatch_SafeRegex:
@@ -113,7 +182,7 @@ func (s routeSorter) Less(i, j int) bool {
 			case -1:
 				return false
 			default:
-				return longestRouteByHeaders(s[i], s[j])
+				return longestRouteByConditions(s[i], s[j])
 			}
 		case *envoy_api_v2_route.RouteMatch_Prefix:
 			return true
@@ -215,6 +284,8 @@ func For(v interface{}) sort.Interface {
 		return routeSorter(v)
 	case []*envoy_api_v2_route.HeaderMatcher:
 		return headerMatcherSorter(v)
+	case []*envoy_api_v2_route.QueryParameterMatcher:
+		return queryParameterMatcherSorter(v)
 	case []*v2.Cluster:
 		return clusterSorter(v)
 	case []*v2.ClusterLoadAssignment:
@@ -79,6 +79,12 @@ func matchPrefix(str string) *envoy_api_v2_route.RouteMatch_Prefix {
 	}
 }
 
+func matchExact(str string) *envoy_api_v2_route.RouteMatch_Path {
+	return &envoy_api_v2_route.RouteMatch_Path{
+		Path: str,
+	}
+}
+
 func matchRegex(str string) *envoy_api_v2_route.RouteMatch_SafeRegex {
 	return &envoy_api_v2_route.RouteMatch_SafeRegex{
 		SafeRegex: &matcher.RegexMatcher{
@@ -105,6 +111,28 @@ func presentHeader(name string) *envoy_api_v2_route.HeaderMatcher {
 	}
 }
 
+func presentQueryParameter(name string) *envoy_api_v2_route.QueryParameterMatcher {
+	return &envoy_api_v2_route.QueryParameterMatcher{
+		Name: name,
+		QueryParameterMatchSpecifier: &envoy_api_v2_route.QueryParameterMatcher_PresentMatch{
+			PresentMatch: true,
+		},
+	}
+}
+
+func exactQueryParameter(name string, value string) *envoy_api_v2_route.QueryParameterMatcher {
+	return &envoy_api_v2_route.QueryParameterMatcher{
+		Name: name,
+		QueryParameterMatchSpecifier: &envoy_api_v2_route.QueryParameterMatcher_StringMatch{
+			StringMatch: &matcher.StringMatcher{
+				MatchPattern: &matcher.StringMatcher_Exact{
+					Exact: value,
+				},
+			},
+		},
+	}
+}
+
 func TestSortRoutesLongestPath(t *testing.T) {
 	want := []*envoy_api_v2_route.Route{
 		&envoy_api_v2_route.Route{
@@ -140,6 +168,41 @@ func TestSortRoutesLongestPath(t *testing.T) {
 	assert.Equal(t, have, want)
 }
 
+func TestSortRoutesExactPath(t *testing.T) {
+	want := []*envoy_api_v2_route.Route{
+		// Exact matches sort before regex and prefix matches.
+		&envoy_api_v2_route.Route{
+			Match: &envoy_api_v2_route.RouteMatch{
+				PathSpecifier: matchExact("/path/exact2"),
+			}},
+
+		&envoy_api_v2_route.Route{
+			Match: &envoy_api_v2_route.RouteMatch{
+				PathSpecifier: matchExact("/path/exact"),
+			}},
+
+		&envoy_api_v2_route.Route{
+			Match: &envoy_api_v2_route.RouteMatch{
+				PathSpecifier: matchRegex("/path/.*"),
+			}},
+
+		&envoy_api_v2_route.Route{
+			Match: &envoy_api_v2_route.RouteMatch{
+				PathSpecifier: matchPrefix("/path"),
+			}},
+	}
+
+	have := []*envoy_api_v2_route.Route{
+		want[3],
+		want[2],
+		want[1],
+		want[0],
+	}
+
+	sort.Stable(For(have))
+	assert.Equal(t, have, want)
+}
+
 func TestSortRoutesLongestHeaders(t *testing.T) {
 	want := []*envoy_api_v2_route.Route{
 		// Although the header names are the same, this value
@@ -178,6 +241,40 @@ func TestSortRoutesLongestHeaders(t *testing.T) {
 	assert.Equal(t, have, want)
 }
 
+func TestSortRoutesQueryParameters(t *testing.T) {
+	want := []*envoy_api_v2_route.Route{
+		// More query parameter conditions sort first.
+		&envoy_api_v2_route.Route{
+			Match: &envoy_api_v2_route.RouteMatch{
+				PathSpecifier: matchPrefix("/path"),
+				QueryParameters: []*envoy_api_v2_route.QueryParameterMatcher{
+					presentQueryParameter("foo"),
+					presentQueryParameter("bar"),
+				},
+			}},
+		&envoy_api_v2_route.Route{
+			Match: &envoy_api_v2_route.RouteMatch{
+				PathSpecifier: matchPrefix("/path"),
+				QueryParameters: []*envoy_api_v2_route.QueryParameterMatcher{
+					presentQueryParameter("foo"),
+				},
+			}},
+		&envoy_api_v2_route.Route{
+			Match: &envoy_api_v2_route.RouteMatch{
+				PathSpecifier: matchPrefix("/path"),
+			}},
+	}
+
+	have := []*envoy_api_v2_route.Route{
+		want[2],
+		want[1],
+		want[0],
+	}
+
+	sort.Stable(For(have))
+	assert.Equal(t, have, want)
+}
+
 func TestSortSecrets(t *testing.T) {
 	want := []*envoy_api_v2_auth.Secret{
 		&envoy_api_v2_auth.Secret{Name: "first"},
@@ -213,6 +310,26 @@ func TestSortHeaderMatchers(t *testing.T) {
 	assert.Equal(t, have, want)
 }
 
+func TestSortQueryParameterMatchers(t *testing.T) {
+	want := []*envoy_api_v2_route.QueryParameterMatcher{
+		exactQueryParameter("long-param-name", "long-param-value"),
+		// Note that if the parameter names are the same, we
+		// order by the protobuf string, in which case "present"
+		// is less than "string".
+		presentQueryParameter("param-name"),
+		exactQueryParameter("param-name", "anything"),
+	}
+
+	have := []*envoy_api_v2_route.QueryParameterMatcher{
+		want[2],
+		want[1],
+		want[0],
+	}
+
+	sort.Stable(For(have))
+	assert.Equal(t, have, want)
+}
+
 func TestSortClusters(t *testing.T) {
 	want := []*v2.Cluster{
 		&v2.Cluster{Name: "first"},